@@ -0,0 +1,80 @@
+package db
+
+import "strings"
+
+// GroupedCounts 按列分组统计记录数，返回 列值 → 数量
+// 生成 SELECT column, COUNT(*) FROM ... GROUP BY column，保留当前构建器的WHERE等条件，
+// 在克隆的构建器上执行，不修改当前构建器；列值为NULL的分组键为 nil，字节数组转为字符串
+// 例如：counts, err := db.Table("orders").GroupedCounts("status")
+func (qb *QueryBuilder) GroupedCounts(column string) (map[interface{}]int64, error) {
+	groups, err := qb.Aggregate(column, "COUNT(*)")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[interface{}]int64, len(groups))
+	for key, value := range groups {
+		count, err := castToInt(value)
+		if err != nil {
+			return nil, WrapError(err, ErrCodeQueryFailed, "分组计数结果转换失败").
+				WithContext("column", column)
+		}
+		counts[key] = count
+	}
+	return counts, nil
+}
+
+// Aggregate 按列分组计算聚合表达式，返回 分组列值 → 聚合结果
+// aggExpr 为原生聚合表达式，如 "SUM(amount)"、"MAX(created_at)"，不能包含多条语句或子查询
+// 例如：totals, err := db.Table("orders").Where("paid", "=", true).Aggregate("customer_id", "SUM(amount)")
+func (qb *QueryBuilder) Aggregate(groupCol, aggExpr string) (map[interface{}]interface{}, error) {
+	if err := qb.validateColumnName(groupCol); err != nil {
+		return nil, err
+	}
+	if err := validateAggregateExpression(aggExpr); err != nil {
+		return nil, err
+	}
+
+	defer qb.releaseTimeout()
+
+	query := qb.aggregateBuilder()
+	query.selectColumns = nil
+	query.selectRaw = []RawExpression{
+		{SQL: qb.quoteIdentifier(groupCol) + " AS torm_group"},
+		{SQL: aggExpr + " AS torm_aggregate"},
+	}
+	query.groupByColumns = []string{groupCol}
+	query.orderByColumns = nil
+	query.distinct = false
+
+	rows, err := query.GetRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[interface{}]interface{}, len(rows))
+	for _, row := range rows {
+		result[aggregateValue(row["torm_group"])] = aggregateValue(row["torm_aggregate"])
+	}
+	return result, nil
+}
+
+// validateAggregateExpression 校验聚合表达式，拒绝空表达式、语句分隔符、注释和语句关键字
+func validateAggregateExpression(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return NewError(ErrCodeInvalidParameter, "聚合表达式不能为空")
+	}
+	if strings.Contains(expr, ";") || strings.Contains(expr, "--") || strings.Contains(expr, "/*") ||
+		dangerousKeywordRegex.MatchString(expr) {
+		return NewErrorf(ErrCodeInvalidParameter, "聚合表达式包含不允许的内容: %s", expr)
+	}
+	return nil
+}
+
+// aggregateValue 将扫描得到的字节数组转为字符串，使其可以作为map的键
+func aggregateValue(value interface{}) interface{} {
+	if data, ok := value.([]byte); ok {
+		return string(data)
+	}
+	return value
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpdateBatchMaxParams 单条 UpdateBatch 语句允许的最大参数数量
+// 超过时改为在事务中逐条更新，避免触发数据库的参数数量限制（如SQL Server为2100）
+var UpdateBatchMaxParams = 2000
+
+// InsertBatchChunkSize InsertBatch 单条INSERT语句默认包含的最大行数
+// 超过时拆分为多条语句，可通过 SetInsertChunkSize 按查询调整
+var InsertBatchChunkSize = 1000
+
+// UpdateBatch 按keyColumn批量更新多条记录，一次往返完成
+// 生成 UPDATE t SET col = CASE key WHEN ? THEN ? ... ELSE col END WHERE key IN (...)
+// 每条记录必须包含keyColumn，记录中缺少的列保持原值
+func (qb *QueryBuilder) UpdateBatch(records []map[string]interface{}, keyColumn string) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if qb.err != nil {
+		return 0, qb.err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err := qb.validateColumnName(keyColumn); err != nil {
+		return 0, err
+	}
+
+	// 处理时间字段，并收集需要更新的列
+	rows := make([]map[string]interface{}, len(records))
+	columnSet := make(map[string]bool)
+	for i, record := range records {
+		if _, exists := record[keyColumn]; !exists {
+			return 0, NewErrorf(ErrCodeInvalidParameter, "第%d条记录缺少键列 %s", i, keyColumn)
+		}
+		row := record
+		if qb.timeManager != nil && len(qb.timeFields) > 0 {
+			row = qb.timeFieldManager().ProcessUpdateData(record, qb.timeFields)
+		}
+		for column := range row {
+			if column == keyColumn {
+				continue
+			}
+			if err := qb.validateColumnName(column); err != nil {
+				return 0, err
+			}
+			columnSet[column] = true
+		}
+		rows[i] = row
+	}
+	if len(columnSet) == 0 {
+		return 0, NewError(ErrCodeInvalidParameter, "批量更新没有需要更新的列")
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	sqlStr, args := qb.buildUpdateBatchSQL(rows, keyColumn, columns)
+	if len(args) > UpdateBatchMaxParams {
+		return qb.updateBatchInTransaction(rows, keyColumn)
+	}
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
+
+	var result interface{ RowsAffected() (int64, error) }
+	var err error
+	if qb.transaction != nil {
+		result, err = execTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return 0, connErr
+		}
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return 0, WrapError(err, ErrCodeQueryFailed, "批量更新失败").
+			WithContext("sql", sqlStr).
+			WithContext("table", qb.tableName).
+			WithContext("records", len(records))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, WrapError(err, ErrCodeQueryFailed, "获取影响行数失败")
+	}
+	return affected, nil
+}
+
+// buildUpdateBatchSQL 构建CASE WHEN批量更新SQL
+func (qb *QueryBuilder) buildUpdateBatchSQL(rows []map[string]interface{}, keyColumn string, columns []string) (string, []interface{}) {
+	if scoped := qb.scopedBuilder(); scoped != qb {
+		return scoped.buildUpdateBatchSQL(rows, keyColumn, columns)
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+
+	fromClause := qb.writeUpdateTable(&sql)
+	sql.WriteString(" SET ")
+
+	key := qb.quoteIdentifier(keyColumn)
+	for i, column := range columns {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		quoted := qb.quoteIdentifier(column)
+		sql.WriteString(fmt.Sprintf("%s = CASE %s", quoted, key))
+		for _, row := range rows {
+			if value, exists := row[column]; exists {
+				sql.WriteString(" WHEN ? THEN ?")
+				args = append(args, row[keyColumn], value)
+			}
+		}
+		sql.WriteString(fmt.Sprintf(" ELSE %s END", quoted))
+	}
+
+	placeholders := make([]string, len(rows))
+	for i, row := range rows {
+		placeholders[i] = "?"
+		args = append(args, row[keyColumn])
+	}
+	sql.WriteString(fromClause)
+	sql.WriteString(fmt.Sprintf(" WHERE %s IN (%s)", key, strings.Join(placeholders, ", ")))
+
+	// 保留构建器上已有的条件
+	for _, condition := range qb.groupWhereConditions(qb.whereConditions) {
+		sql.WriteString(" AND ")
+		if condition.Raw != "" {
+			sql.WriteString(condition.Raw)
+			args = append(args, condition.Values...)
+		} else {
+			sql.WriteString(fmt.Sprintf("%s %s ?", qb.quoteIdentifier(condition.Column), condition.Operator))
+			args = append(args, condition.Value)
+		}
+	}
+
+	return qb.processPlaceholders(sql.String(), 0), args
+}
+
+// updateBatchInTransaction 在事务中逐条更新，用于参数数量超过单条语句限制的情况
+func (qb *QueryBuilder) updateBatchInTransaction(rows []map[string]interface{}, keyColumn string) (int64, error) {
+	tx := qb.transaction
+	ownTx := tx == nil && !qb.dryRun // 演练模式不开启事务
+	if ownTx {
+		conn, err := qb.getConnection()
+		if err != nil {
+			return 0, err
+		}
+		tx, err = conn.Begin()
+		if err != nil {
+			return 0, WrapError(err, ErrCodeTransactionFailed, "开始事务失败")
+		}
+	}
+
+	var total int64
+	var statements []string
+	var statementArgs []interface{}
+	for _, row := range rows {
+		data := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			if column != keyColumn {
+				data[column] = value
+			}
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		query := qb.Clone()
+		query.transaction = tx
+		query.timeFields = nil // 时间字段已在UpdateBatch中处理
+		affected, err := query.Where(keyColumn, "=", row[keyColumn]).Update(data)
+		if err != nil {
+			if ownTx {
+				tx.Rollback()
+			}
+			return 0, err
+		}
+		total += affected
+		if qb.dryRun {
+			statement, args := query.LastSQL()
+			statements = append(statements, statement)
+			statementArgs = append(statementArgs, args...)
+		}
+	}
+
+	// 演练模式记录逐条更新的全部语句，以分号分隔
+	if qb.dryRun {
+		qb.recordSQL(strings.Join(statements, "; "), statementArgs)
+		return 0, nil
+	}
+	if ownTx {
+		if err := tx.Commit(); err != nil {
+			return 0, WrapError(err, ErrCodeTransactionCommitFailed, "提交事务失败")
+		}
+	}
+	return total, nil
+}
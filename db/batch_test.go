@@ -0,0 +1,175 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+// countingConnection 统计执行语句次数的连接，用于比较往返次数
+type countingConnection struct {
+	ConnectionInterface
+	execs int
+}
+
+func (c *countingConnection) Exec(query string, args ...interface{}) (sql.Result, error) {
+	c.execs++
+	return c.ConnectionInterface.Exec(query, args...)
+}
+
+func (c *countingConnection) Begin() (TransactionInterface, error) {
+	tx, err := c.ConnectionInterface.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &countingTransaction{TransactionInterface: tx, conn: c}, nil
+}
+
+type countingTransaction struct {
+	TransactionInterface
+	conn *countingConnection
+}
+
+func (t *countingTransaction) Exec(query string, args ...interface{}) (sql.Result, error) {
+	t.conn.execs++
+	return t.TransactionInterface.Exec(query, args...)
+}
+
+// 测试批量更新：单条CASE语句一次往返，超过参数限制时回退为事务内逐条更新
+func TestUpdateBatch(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	conn, _ := table().getConnection()
+	counter := &countingConnection{ConnectionInterface: conn}
+
+	records := []map[string]interface{}{
+		{"id": 1, "status": "banned", "age": 21},
+		{"id": 2, "status": "pending"},
+		{"id": 4, "age": 40},
+	}
+
+	qb := table()
+	qb.connection = counter
+	affected, err := qb.UpdateBatch(records, "id")
+	if err != nil {
+		t.Fatalf("UpdateBatch failed: %v", err)
+	}
+	if affected != 3 || counter.execs != 1 {
+		t.Errorf("Expected 3 rows in 1 round trip, got %d rows in %d", affected, counter.execs)
+	}
+
+	rows, _ := table().OrderBy("id", "ASC").Get()
+	if rows[0]["status"] != "banned" || rows[0]["age"] != int64(21) ||
+		rows[1]["status"] != "pending" || rows[1]["age"] != int64(25) ||
+		rows[2]["status"] != "banned" || rows[3]["age"] != int64(40) {
+		t.Errorf("Unexpected rows after UpdateBatch: %v", rows)
+	}
+
+	// 超过参数限制回退为逐条更新
+	original := UpdateBatchMaxParams
+	UpdateBatchMaxParams = 4
+	defer func() { UpdateBatchMaxParams = original }()
+
+	counter.execs = 0
+	qb = table()
+	qb.connection = counter
+	affected, err = qb.UpdateBatch(records, "id")
+	if err != nil {
+		t.Fatalf("UpdateBatch fallback failed: %v", err)
+	}
+	if affected != 3 || counter.execs != 3 {
+		t.Errorf("Expected 3 rows in 3 statements, got %d rows in %d", affected, counter.execs)
+	}
+
+	// 演练模式记录实际会执行的逐条更新语句
+	dry := newFakeBuilder("mysql", "users").DryRun()
+	if _, err := dry.UpdateBatch([]map[string]interface{}{{"id": 1, "age": 21}, {"id": 2, "age": 26}, {"id": 3, "age": 31}}, "id"); err != nil {
+		t.Fatalf("Dry-run fallback failed: %v", err)
+	}
+	sql, args := dry.LastSQL()
+	if sql != "UPDATE users SET age = ? WHERE id = ?; UPDATE users SET age = ? WHERE id = ?; UPDATE users SET age = ? WHERE id = ?" || len(args) != 6 {
+		t.Errorf("Unexpected dry-run fallback SQL: %s %v", sql, args)
+	}
+
+	if _, err := table().UpdateBatch([]map[string]interface{}{{"status": "x"}}, "id"); err == nil {
+		t.Error("Expected error when a record is missing the key column")
+	}
+
+	// 构建时记录的错误优先返回
+	if _, err := table().WhereLike("name;", "a").UpdateBatch([]map[string]interface{}{{"id": 1, "age": 22}}, "id"); err == nil {
+		t.Error("Expected recorded builder error to be returned")
+	}
+}
+
+// 测试批量更新SQL参数化
+func TestBuildUpdateBatchSQL(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": 1, "name": "a'b"},
+		{"id": 2, "name": "c"},
+	}
+	sql, args := newFakeBuilder("postgres", "users").Where("tenant_id", "=", 9).
+		buildUpdateBatchSQL(rows, "id", []string{"name"})
+
+	expected := "UPDATE users SET name = CASE id WHEN $1 THEN $2 WHEN $3 THEN $4 ELSE name END WHERE id IN ($5, $6) AND tenant_id = $7"
+	if sql != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, sql)
+	}
+	if len(args) != 7 || args[1] != "a'b" || args[6] != 9 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+// 测试批量插入分块：按块拆分为多条语句，失败时整体回滚
+func TestInsertBatchChunking(t *testing.T) {
+	table := setupTestTable(t, nil)
+	conn, _ := table().getConnection()
+	counter := &countingConnection{ConnectionInterface: conn}
+
+	data := make([]map[string]interface{}, 5)
+	for i := range data {
+		data[i] = map[string]interface{}{"name": "user", "status": "active", "age": 20 + i}
+	}
+
+	qb := table().SetInsertChunkSize(2)
+	qb.connection = counter
+	affected, err := qb.InsertBatch(data)
+	if err != nil {
+		t.Fatalf("InsertBatch failed: %v", err)
+	}
+	if affected != 5 || counter.execs != 3 {
+		t.Errorf("Expected 5 rows in 3 statements, got %d rows in %d", affected, counter.execs)
+	}
+
+	// 最后一块主键冲突，之前的块应一并回滚
+	failing := []map[string]interface{}{
+		{"id": 100, "name": "x", "status": "active", "age": 1},
+		{"id": 101, "name": "y", "status": "active", "age": 1},
+		{"id": 1, "name": "z", "status": "active", "age": 1},
+	}
+	if _, err := table().SetInsertChunkSize(2).InsertBatch(failing); err == nil {
+		t.Fatal("Expected duplicate key error")
+	}
+	if count, _ := table().Count(); count != 5 {
+		t.Errorf("Expected rollback to leave 5 rows, got %d", count)
+	}
+
+	if rows := newFakeBuilder("sqlserver", "users").insertChunkRows(30); rows != 70 {
+		t.Errorf("Expected SQL Server chunk of 70 rows, got %d", rows)
+	}
+
+	// 演练模式记录所有块的语句
+	rows := []map[string]interface{}{{"name": "a"}, {"name": "b"}, {"name": "c"}}
+	dry := newFakeBuilder("mysql", "users").DryRun().SetInsertChunkSize(2)
+	if _, err := dry.InsertBatch(rows); err != nil {
+		t.Fatalf("Dry-run InsertBatch failed: %v", err)
+	}
+	if sql, args := dry.LastSQL(); sql != "INSERT INTO users (name) VALUES (?), (?); INSERT INTO users (name) VALUES (?)" || len(args) != 3 {
+		t.Errorf("Expected all chunks in dry run, got %s %v", sql, args)
+	}
+	dry = newFakeBuilder("sqlserver", "users").DryRun()
+	if _, err := dry.InsertIgnoreBatch(rows); err != nil {
+		t.Fatalf("Dry-run InsertIgnoreBatch failed: %v", err)
+	}
+	if sql, args := dry.LastSQL(); strings.Count(sql, "INSERT INTO") != 3 || len(args) != 3 || args[2] != "c" {
+		t.Errorf("Expected one statement per row on SQL Server, got %s %v", sql, args)
+	}
+}
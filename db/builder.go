@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -39,11 +41,16 @@ var (
 	}
 
 	// 预编译常用正则表达式
-	placeholderRegex = regexp.MustCompile(`\?`)
-	operatorRegex    = regexp.MustCompile(`^\s*(=|!=|<>|>|>=|<|<=|LIKE|NOT LIKE|IN|NOT IN|BETWEEN|NOT BETWEEN)\s*$`)
+	placeholderRegex      = regexp.MustCompile(`\?`)
+	dangerousKeywordRegex = regexp.MustCompile(`(?i)\b(DROP|DELETE|UPDATE|INSERT|ALTER|CREATE|TRUNCATE|EXEC|EXECUTE|SCRIPT|UNION|SELECT)\b`)
+	operatorRegex         = regexp.MustCompile(`^\s*(=|!=|<>|>|>=|<|<=|LIKE|NOT LIKE|IN|NOT IN|BETWEEN|NOT BETWEEN)\s*$`)
+	tableWildcardRegex    = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*\.\*$`)
 )
 
 // QueryBuilder 查询构建器 - TORM的核心
+// 构建器不是并发安全的：Where、Limit 等方法直接修改并返回当前构建器。
+// 在多个goroutine中复用基础查询时，每个goroutine先调用 Clone 得到独立副本再追加条件，
+// 同一构建器被多个goroutine同时执行时返回错误，见 Clone
 type QueryBuilder struct {
 	connection     ConnectionInterface
 	connectionName string // 连接名，延迟获取连接
@@ -52,31 +59,77 @@ type QueryBuilder struct {
 
 	// 查询组件
 	selectColumns    []string
+	selectRaw        []RawExpression // 原生选择表达式（不经过列名清理）
 	whereConditions  []WhereCondition
 	joinClauses      []JoinClause
 	orderByColumns   []OrderByClause
 	groupByColumns   []string
 	havingConditions []WhereCondition
 
+	// 全局作用域，构建SQL时应用到副本上，见 scopedBuilder
+	globalScopes []globalScope
+
 	// 分页和限制
 	limitCount  int
 	offsetCount int
 
 	// 事务相关
 	transaction TransactionInterface
+	lockMode    string // 悲观锁模式: "update", "share"
 
 	// 缓存相关
 	cacheEnabled bool
 	cacheTTL     time.Duration
 	cacheTags    []string
 	cacheKey     string
+	cacheRefresh bool // 跳过缓存读取但仍写入缓存，见 FreshCache
 
 	// 时间管理
 	timeManager *TimeFieldManager
 	timeFields  []TimeFieldInfo
 
+	// 表名按原样使用，不加连接配置的表前缀
+	rawTable bool
+
+	// 构建过程中记录的错误，在执行时返回，见 Err
+	err error
+
+	// 占位符保持为 ?，用于内联到外层查询的子查询，由外层统一编号
+	rawPlaceholders bool
+
+	// InsertBatch 每条语句的最大行数，0 表示使用 InsertBatchChunkSize
+	insertChunkSize int
+
+	// 待应用到下一个列比较条件或排序的排序规则，见 Collate
+	collation string
+
+	// 为表名和列名加引号，见 QuoteIdentifiers
+	quoteIdents bool
+
+	// WhereInChunks 添加的分块 IN 条件，Get 时可按块拆分执行
+	chunkedIn []*chunkedInCondition
+
+	// WITH 子句中的公用表表达式，见 With
+	ctes []commonTableExpr
+
+	// 相关子查询所属外层查询的表名，见 OuterTable
+	outerTable string
+
+	// SELECT DISTINCT，作用于整个选择列表，见 Distinct
+	distinct bool
+
+	// 正在执行查询的标记，用于检测多个goroutine同时执行同一构建器
+	executing int32
+
+	// 演练模式：写操作只生成SQL，不执行
+	dryRun   bool
+	lastSQL  string
+	lastArgs []interface{}
+
 	// 上下文
-	ctx context.Context
+	ctx       context.Context
+	parentCtx context.Context    // WithTimeout 之前的上下文
+	cancel    context.CancelFunc // WithTimeout 的取消函数，执行后调用
 }
 
 // WhereCondition WHERE条件
@@ -98,10 +151,19 @@ type JoinClause struct {
 	Values    []interface{} // 绑定参数
 }
 
+// RawExpression 原生SQL表达式
+type RawExpression struct {
+	SQL    string
+	Values []interface{} // 绑定参数
+}
+
 // OrderByClause 排序子句
 type OrderByClause struct {
 	Column    string
-	Direction string // ASC, DESC
+	Direction string        // ASC, DESC
+	Bindings  []interface{} // 表达式中占位符对应的参数，非空时Column按可信表达式处理
+	Collation string        // 排序规则，见 Collate
+	Raw       bool          // 原生排序表达式（OrderByRaw），按原样输出，不追加默认方向
 }
 
 // NewQueryBuilder 创建新的查询构建器 - 连接池优化版本
@@ -126,21 +188,40 @@ func (qb *QueryBuilder) resetQueryBuilder() {
 
 	// 重用切片，只重置长度
 	qb.selectColumns = qb.selectColumns[:0]
+	qb.selectRaw = nil
 	qb.whereConditions = qb.whereConditions[:0]
 	qb.joinClauses = qb.joinClauses[:0]
 	qb.orderByColumns = qb.orderByColumns[:0]
 	qb.groupByColumns = qb.groupByColumns[:0]
 	qb.havingConditions = qb.havingConditions[:0]
 	qb.timeFields = qb.timeFields[:0]
+	qb.globalScopes = nil
 
 	// 重置其他字段
 	qb.limitCount = 0
 	qb.offsetCount = 0
 	qb.transaction = nil
+	qb.lockMode = ""
 	qb.cacheEnabled = false
 	qb.cacheTTL = 0
 	qb.cacheTags = nil
 	qb.cacheKey = ""
+	qb.cacheRefresh = false
+	qb.rawTable = false
+	qb.err = nil
+	qb.rawPlaceholders = false
+	qb.insertChunkSize = 0
+	qb.collation = ""
+	qb.quoteIdents = false
+	qb.chunkedIn = nil
+	qb.ctes = nil
+	qb.outerTable = ""
+	qb.distinct = false
+	qb.executing = 0
+	qb.dryRun = false
+	qb.lastSQL = ""
+	qb.lastArgs = nil
+	qb.releaseTimeout()
 	qb.ctx = context.Background()
 }
 
@@ -214,6 +295,33 @@ func (qb *QueryBuilder) GetModel() interface{} {
 	return qb.model
 }
 
+// WithTimestamps 不绑定模型时为 Insert/Update 自动填充时间列，列名为空表示不填充
+// 插入时填充创建时间（已提供时保持不变）和更新时间，更新时只填充更新时间
+func (qb *QueryBuilder) WithTimestamps(createdColumn, updatedColumn string) *QueryBuilder {
+	if qb.timeManager == nil {
+		qb.timeManager = NewTimeFieldManager()
+	}
+
+	timeType := reflect.TypeOf(time.Time{})
+	if createdColumn != "" {
+		qb.timeFields = append(qb.timeFields, TimeFieldInfo{
+			FieldName:    createdColumn,
+			ColumnName:   createdColumn,
+			FieldType:    timeType,
+			IsCreateTime: true,
+		})
+	}
+	if updatedColumn != "" {
+		qb.timeFields = append(qb.timeFields, TimeFieldInfo{
+			FieldName:    updatedColumn,
+			ColumnName:   updatedColumn,
+			FieldType:    timeType,
+			IsUpdateTime: true,
+		})
+	}
+	return qb
+}
+
 // getTableNameFromModel 从模型获取表名
 func getTableNameFromModel(model interface{}) string {
 	// 优先检查模型是否实现了TableName方法（静态方法，推荐用法）
@@ -407,10 +515,17 @@ func (qb *QueryBuilder) Where(args ...interface{}) *QueryBuilder {
 				Raw:   sql,
 				Logic: "AND",
 			})
+		} else {
+			qb.invalidArguments("Where", args)
 		}
 	case 2:
 		// Where("name = ?", value) 或 Where("status IN (?)", []string{"active", "pending"})
 		if sql, ok := args[0].(string); ok {
+			// 命名参数，如 Where("age > :min", map[string]interface{}{"min": 18})
+			if _, named := args[1].(map[string]interface{}); named {
+				qb.whereConditions = append(qb.whereConditions, qb.rawCondition(sql, args[1:], "AND"))
+				break
+			}
 			// 检查第二个参数是否是数组/切片
 			if qb.isSliceOrArray(args[1]) {
 				// 处理数组参数，如 Where("status IN (?)", []string{"active", "pending"})
@@ -437,19 +552,18 @@ func (qb *QueryBuilder) Where(args ...interface{}) *QueryBuilder {
 					Logic:  "AND",
 				})
 			}
+		} else {
+			qb.invalidArguments("Where", args)
 		}
 	case 3:
 		// Where("name", "=", value)
-		if column, ok := args[0].(string); ok {
-			if operator, ok := args[1].(string); ok {
-				qb.whereConditions = append(qb.whereConditions, WhereCondition{
-					Column:   column,
-					Operator: operator,
-					Value:    args[2],
-					Logic:    "AND",
-				})
-			}
+		column, columnOK := args[0].(string)
+		operator, operatorOK := args[1].(string)
+		if !columnOK || !operatorOK {
+			qb.invalidArguments("Where", args)
+			break
 		}
+		qb.whereConditions = append(qb.whereConditions, qb.columnCondition(column, operator, args[2], "AND"))
 	default:
 		// Where("status IN (?, ?)", "active", "pending") - 多参数
 		if len(args) > 1 {
@@ -459,6 +573,8 @@ func (qb *QueryBuilder) Where(args ...interface{}) *QueryBuilder {
 					Values: args[1:], // 剩余所有参数作为值
 					Logic:  "AND",
 				})
+			} else {
+				qb.invalidArguments("Where", args)
 			}
 		}
 	}
@@ -474,9 +590,16 @@ func (qb *QueryBuilder) OrWhere(args ...interface{}) *QueryBuilder {
 				Raw:   sql,
 				Logic: "OR",
 			})
+		} else {
+			qb.invalidArguments("OrWhere", args)
 		}
 	case 2:
 		if sql, ok := args[0].(string); ok {
+			// 命名参数，如 Where("age > :min", map[string]interface{}{"min": 18})
+			if _, named := args[1].(map[string]interface{}); named {
+				qb.whereConditions = append(qb.whereConditions, qb.rawCondition(sql, args[1:], "OR"))
+				break
+			}
 			// 检查第二个参数是否是数组/切片
 			if qb.isSliceOrArray(args[1]) {
 				// 处理数组参数
@@ -503,18 +626,17 @@ func (qb *QueryBuilder) OrWhere(args ...interface{}) *QueryBuilder {
 					Logic:  "OR",
 				})
 			}
+		} else {
+			qb.invalidArguments("OrWhere", args)
 		}
 	case 3:
-		if column, ok := args[0].(string); ok {
-			if operator, ok := args[1].(string); ok {
-				qb.whereConditions = append(qb.whereConditions, WhereCondition{
-					Column:   column,
-					Operator: operator,
-					Value:    args[2],
-					Logic:    "OR",
-				})
-			}
+		column, columnOK := args[0].(string)
+		operator, operatorOK := args[1].(string)
+		if !columnOK || !operatorOK {
+			qb.invalidArguments("OrWhere", args)
+			break
 		}
+		qb.whereConditions = append(qb.whereConditions, qb.columnCondition(column, operator, args[2], "OR"))
 	default:
 		// OrWhere("status IN (?, ?)", "active", "pending") - 多参数
 		if len(args) > 1 {
@@ -524,12 +646,165 @@ func (qb *QueryBuilder) OrWhere(args ...interface{}) *QueryBuilder {
 					Values: args[1:], // 剩余所有参数作为值
 					Logic:  "OR",
 				})
+			} else {
+				qb.invalidArguments("OrWhere", args)
 			}
 		}
 	}
 	return qb
 }
 
+// Err 返回构建查询时记录的错误，如 Where 传入了不支持的参数格式
+// 存在错误时 Get、Count、Update、Delete 等执行方法直接返回该错误，避免条件被忽略后操作整张表
+func (qb *QueryBuilder) Err() error {
+	return qb.err
+}
+
+// setErr 记录构建查询时的错误，只保留第一个错误
+func (qb *QueryBuilder) setErr(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
+
+// invalidArguments 记录条件方法的参数格式错误
+func (qb *QueryBuilder) invalidArguments(method string, args []interface{}) {
+	qb.setErr(NewErrorf(ErrCodeInvalidParameter, "%s 的参数格式不支持", method).
+		WithContext("args", args))
+}
+
+// newColumnCondition 构建 列 操作符 值 条件
+// 值为nil时 = 转换为 IS NULL，!= 和 <> 转换为 IS NOT NULL，因为 col = NULL 永远不成立
+func newColumnCondition(column, operator string, value interface{}, logic string) WhereCondition {
+	if isNilValue(value) {
+		switch strings.ToUpper(strings.TrimSpace(operator)) {
+		case "=", "IS":
+			return WhereCondition{Raw: column + " IS NULL", Logic: logic}
+		case "!=", "<>", "IS NOT":
+			return WhereCondition{Raw: column + " IS NOT NULL", Logic: logic}
+		}
+	}
+	return WhereCondition{
+		Column:   column,
+		Operator: operator,
+		Value:    value,
+		Logic:    logic,
+	}
+}
+
+// isNilValue 判断值是否为nil或nil指针
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// WhereGroup 添加带括号的AND条件组
+// 例如：Where("a", "=", 1).WhereGroup(func(q *QueryBuilder) { q.Where("b", "=", 2).OrWhere("c", "=", 3) })
+// 生成：a = ? AND (b = ? OR c = ?)
+func (qb *QueryBuilder) WhereGroup(fn func(*QueryBuilder)) *QueryBuilder {
+	return qb.addWhereGroup("AND", fn)
+}
+
+// OrWhereGroup 添加带括号的OR条件组
+func (qb *QueryBuilder) OrWhereGroup(fn func(*QueryBuilder)) *QueryBuilder {
+	return qb.addWhereGroup("OR", fn)
+}
+
+// addWhereGroup 在子构建器上执行闭包，并将其条件合并为一个带括号的条件
+func (qb *QueryBuilder) addWhereGroup(logic string, fn func(*QueryBuilder)) *QueryBuilder {
+	if fn == nil {
+		return qb
+	}
+
+	// 子构建器共享连接和标识符引号设置，保证方言相关的条件（如WhereLike）生成一致
+	sub := &QueryBuilder{
+		connection:      qb.connection,
+		connectionName:  qb.connectionName,
+		tableName:       qb.tableName,
+		whereConditions: make([]WhereCondition, 0, 4),
+		quoteIdents:     qb.quoteIdents,
+		ctx:             context.Background(),
+	}
+	fn(sub)
+	if sub.err != nil {
+		qb.setErr(sub.err)
+	}
+	if len(sub.whereConditions) == 0 {
+		return qb
+	}
+
+	raw, values := qb.joinWhereConditions(sub.whereConditions)
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    "(" + raw + ")",
+		Values: values,
+		Logic:  logic,
+	})
+	return qb
+}
+
+// WhereAny 多列匹配同一个值，任一列满足即可，生成一个带括号的OR条件组
+// 例如：WhereAny([]string{"name", "email"}, "LIKE", "%tom%")
+// 生成：(name LIKE ? OR email LIKE ?)，参数按列的顺序各绑定一次
+func (qb *QueryBuilder) WhereAny(columns []string, operator string, value interface{}) *QueryBuilder {
+	return qb.addMultiColumnWhere("OR", columns, operator, value)
+}
+
+// WhereAll 多列匹配同一个值，所有列都需满足，生成一个带括号的AND条件组
+func (qb *QueryBuilder) WhereAll(columns []string, operator string, value interface{}) *QueryBuilder {
+	return qb.addMultiColumnWhere("AND", columns, operator, value)
+}
+
+// addMultiColumnWhere 为每一列生成相同的比较条件，并以 logic 连接成一个条件组
+func (qb *QueryBuilder) addMultiColumnWhere(logic string, columns []string, operator string, value interface{}) *QueryBuilder {
+	if len(columns) == 0 {
+		return qb
+	}
+	operator = qb.checkedOperator(operator)
+
+	conditions := make([]WhereCondition, 0, len(columns))
+	for _, column := range columns {
+		if err := qb.validateColumnName(column); err != nil {
+			qb.setErr(err)
+			return qb
+		}
+		if isNilValue(value) {
+			// nil 值转换为 IS NULL 原生条件，列引用在此时加引号
+			column = qb.quoteIdentifier(column)
+		}
+		conditions = append(conditions, newColumnCondition(column, operator, value, logic))
+	}
+
+	raw, values := qb.joinWhereConditions(conditions)
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    "(" + raw + ")",
+		Values: values,
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// joinWhereConditions 将条件列表拼接为SQL片段，列名按引号设置处理，占位符保持为 ?，参数按顺序合并
+func (qb *QueryBuilder) joinWhereConditions(conditions []WhereCondition) (string, []interface{}) {
+	var raw strings.Builder
+	var values []interface{}
+	for i, condition := range conditions {
+		if i > 0 {
+			raw.WriteString(" " + condition.Logic + " ")
+		}
+		if condition.Raw != "" {
+			raw.WriteString(condition.Raw)
+			values = append(values, condition.Values...)
+		} else {
+			raw.WriteString(fmt.Sprintf("%s %s ?", qb.quoteIdentifier(condition.Column), condition.Operator))
+			values = append(values, condition.Value)
+		}
+	}
+	return raw.String(), values
+}
+
 // Join 内连接 - 支持多种调用方式
 func (qb *QueryBuilder) Join(args ...interface{}) *QueryBuilder {
 	return qb.addJoin("INNER", args...)
@@ -691,10 +966,41 @@ func (qb *QueryBuilder) OrderBy(column, direction string) *QueryBuilder {
 	qb.orderByColumns = append(qb.orderByColumns, OrderByClause{
 		Column:    column,
 		Direction: strings.ToUpper(direction),
+		Collation: qb.takeCollation(),
 	})
 	return qb
 }
 
+// OrderByWhitelist 按白名单校验后排序，适用于排序字段来自用户输入的场景
+// column 不在 allowed 中或 direction 不是 ASC/DESC（空值视为 ASC）时返回错误，且不修改排序
+func (qb *QueryBuilder) OrderByWhitelist(column, direction string, allowed []string) error {
+	column = strings.TrimSpace(column)
+	allowedColumn := false
+	for _, name := range allowed {
+		if name == column {
+			allowedColumn = true
+			break
+		}
+	}
+	if column == "" || !allowedColumn {
+		return NewError(ErrCodeInvalidParameter, "不允许的排序字段").
+			WithContext("column", column).
+			WithContext("allowed", allowed)
+	}
+
+	direction = strings.ToUpper(strings.TrimSpace(direction))
+	if direction == "" {
+		direction = "ASC"
+	}
+	if direction != "ASC" && direction != "DESC" {
+		return NewError(ErrCodeInvalidParameter, "排序方向只能是ASC或DESC").
+			WithContext("direction", direction)
+	}
+
+	qb.OrderBy(column, direction)
+	return nil
+}
+
 // GroupBy 分组
 func (qb *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
 	qb.groupByColumns = append(qb.groupByColumns, columns...)
@@ -711,6 +1017,8 @@ func (qb *QueryBuilder) Having(args ...interface{}) *QueryBuilder {
 				Raw:   sql,
 				Logic: "AND",
 			})
+		} else {
+			qb.invalidArguments("Having", args)
 		}
 	case 2:
 		// Having("COUNT(*) > ?", 5) 或 Having("status IN (?)", []string{"active", "pending"})
@@ -741,18 +1049,16 @@ func (qb *QueryBuilder) Having(args ...interface{}) *QueryBuilder {
 					Logic:  "AND",
 				})
 			}
+		} else {
+			qb.invalidArguments("Having", args)
 		}
 	case 3:
 		// Having("column", ">", value)
-		if column, ok := args[0].(string); ok {
-			if operator, ok := args[1].(string); ok {
-				qb.havingConditions = append(qb.havingConditions, WhereCondition{
-					Column:   column,
-					Operator: operator,
-					Value:    args[2],
-					Logic:    "AND",
-				})
-			}
+		// 表达式包含 ? 或第二个参数不是操作符时按原生SQL处理，如 Having("COUNT(*) BETWEEN ? AND ?", 5, 10)
+		if expr, ok := args[0].(string); ok {
+			qb.havingConditions = append(qb.havingConditions, qb.havingTripleCondition(expr, args[1:], "AND"))
+		} else {
+			qb.invalidArguments("Having", args)
 		}
 	default:
 		// Having("column IN (?, ?)", value1, value2) - 多参数
@@ -763,12 +1069,36 @@ func (qb *QueryBuilder) Having(args ...interface{}) *QueryBuilder {
 					Values: args[1:], // 剩余所有参数作为值
 					Logic:  "AND",
 				})
+			} else {
+				qb.invalidArguments("Having", args)
 			}
 		}
 	}
 	return qb
 }
 
+// havingTripleCondition 解析三个参数的HAVING条件
+// expr 包含占位符时为原生SQL，其余参数为绑定值，参数类型不影响判断（如字符串 "5"）；
+// 否则第二个参数为操作符，按 列 操作符 值 处理，无效操作符记录错误
+func (qb *QueryBuilder) havingTripleCondition(expr string, rest []interface{}, logic string) WhereCondition {
+	if operator, ok := rest[0].(string); ok && !strings.Contains(expr, "?") {
+		if operator = qb.checkedOperator(operator); isListOperator(operator) {
+			return qb.listCondition(expr, operator, rest[1], logic)
+		}
+		return WhereCondition{
+			Column:   expr,
+			Operator: operator,
+			Value:    rest[1],
+			Logic:    logic,
+		}
+	}
+	return WhereCondition{
+		Raw:    expr,
+		Values: rest,
+		Logic:  logic,
+	}
+}
+
 // OrHaving 添加OR HAVING条件
 func (qb *QueryBuilder) OrHaving(args ...interface{}) *QueryBuilder {
 	switch len(args) {
@@ -779,6 +1109,8 @@ func (qb *QueryBuilder) OrHaving(args ...interface{}) *QueryBuilder {
 				Raw:   sql,
 				Logic: "OR",
 			})
+		} else {
+			qb.invalidArguments("OrHaving", args)
 		}
 	case 2:
 		// OrHaving("COUNT(*) > ?", 5) 或 OrHaving("status IN (?)", []string{"active", "pending"})
@@ -809,18 +1141,16 @@ func (qb *QueryBuilder) OrHaving(args ...interface{}) *QueryBuilder {
 					Logic:  "OR",
 				})
 			}
+		} else {
+			qb.invalidArguments("OrHaving", args)
 		}
 	case 3:
 		// OrHaving("column", ">", value)
-		if column, ok := args[0].(string); ok {
-			if operator, ok := args[1].(string); ok {
-				qb.havingConditions = append(qb.havingConditions, WhereCondition{
-					Column:   column,
-					Operator: operator,
-					Value:    args[2],
-					Logic:    "OR",
-				})
-			}
+		// 表达式包含 ? 或第二个参数不是操作符时按原生SQL处理，如 OrHaving("COUNT(*) BETWEEN ? AND ?", 5, 10)
+		if expr, ok := args[0].(string); ok {
+			qb.havingConditions = append(qb.havingConditions, qb.havingTripleCondition(expr, args[1:], "OR"))
+		} else {
+			qb.invalidArguments("OrHaving", args)
 		}
 	default:
 		// OrHaving("column IN (?, ?)", value1, value2) - 多参数
@@ -831,6 +1161,8 @@ func (qb *QueryBuilder) OrHaving(args ...interface{}) *QueryBuilder {
 					Values: args[1:], // 剩余所有参数作为值
 					Logic:  "OR",
 				})
+			} else {
+				qb.invalidArguments("OrHaving", args)
 			}
 		}
 	}
@@ -849,25 +1181,15 @@ func (qb *QueryBuilder) HavingRaw(args ...interface{}) *QueryBuilder {
 			})
 		}
 	case 2:
-		// Having("COUNT(*) > ?", 5)
+		// Having("COUNT(*) > ?", 5) 或 Having("COUNT(*) > :min", map[string]interface{}{"min": 5})
 		if sql, ok := args[0].(string); ok {
-			qb.havingConditions = append(qb.havingConditions, WhereCondition{
-				Raw:    sql,
-				Values: []interface{}{args[1]},
-				Logic:  "AND",
-			})
+			qb.havingConditions = append(qb.havingConditions, qb.rawCondition(sql, args[1:], "AND"))
 		}
 	case 3:
-		// Having("column", ">", value)
-		if column, ok := args[0].(string); ok {
-			if operator, ok := args[1].(string); ok {
-				qb.havingConditions = append(qb.havingConditions, WhereCondition{
-					Column:   column,
-					Operator: operator,
-					Value:    args[2],
-					Logic:    "AND",
-				})
-			}
+		// HavingRaw("column", ">", value)
+		// 表达式包含 ? 或第二个参数不是操作符时按原生SQL处理，如 HavingRaw("COUNT(*) BETWEEN ? AND ?", 5, 10)
+		if expr, ok := args[0].(string); ok {
+			qb.havingConditions = append(qb.havingConditions, qb.havingTripleCondition(expr, args[1:], "AND"))
 		}
 	default:
 		// Having("column IN (?, ?)", value1, value2) - 多参数
@@ -911,6 +1233,22 @@ func (qb *QueryBuilder) CacheWithTags(ttl time.Duration, tags ...string) *QueryB
 	return qb
 }
 
+// FreshCache 本次查询跳过已有的缓存，从数据库读取后重新写入缓存，需与 Cache 或 CacheWithTags 一起使用
+// 适用于数据刚更新后刷新缓存，如 query.Cache(time.Minute).FreshCache().Get()
+func (qb *QueryBuilder) FreshCache() *QueryBuilder {
+	qb.cacheRefresh = true
+	return qb
+}
+
+// WithoutCache 本次查询不读取也不写入缓存，覆盖之前的 Cache 设置
+func (qb *QueryBuilder) WithoutCache() *QueryBuilder {
+	qb.cacheEnabled = false
+	qb.cacheRefresh = false
+	qb.cacheTTL = 0
+	qb.cacheTags = nil
+	return qb
+}
+
 // CacheKey 设置自定义缓存键
 func (qb *QueryBuilder) CacheKey(key string) *QueryBuilder {
 	qb.cacheKey = key
@@ -919,13 +1257,23 @@ func (qb *QueryBuilder) CacheKey(key string) *QueryBuilder {
 
 // Get 执行查询并返回数据（支持访问器处理）
 func (qb *QueryBuilder) Get() ([]map[string]interface{}, error) {
-	// 如果启用了缓存并且不在事务中，尝试从缓存获取
-	if qb.cacheEnabled && qb.transaction == nil {
-		cacheKey := qb.generateCacheKey()
-		if cached, err := GetDefaultCache().Get(cacheKey); err == nil {
-			if result, ok := cached.([]map[string]interface{}); ok {
-				return qb.applyAccessors(result), nil
-			}
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
+	qb.checkLockTransaction()
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	if indexes := qb.chunkedInIndexes(); indexes != nil {
+		return qb.getInChunks(indexes)
+	}
+
+	// 如果启用了缓存并且不在事务中，尝试从缓存获取（FreshCache 时跳过）
+	if qb.cacheEnabled && !qb.cacheRefresh && qb.transaction == nil {
+		if result, ok := qb.readQueryCache(qb.generateCacheKey()); ok {
+			return qb.applyAccessors(result), nil
 		}
 	}
 
@@ -935,13 +1283,13 @@ func (qb *QueryBuilder) Get() ([]map[string]interface{}, error) {
 	var err error
 
 	if qb.transaction != nil {
-		rows, err = qb.transaction.Query(sqlStr, args...)
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 	} else {
 		conn, connErr := qb.getConnection()
 		if connErr != nil {
 			return nil, connErr
 		}
-		rows, err = conn.Query(sqlStr, args...)
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
 	if err != nil {
@@ -970,14 +1318,7 @@ func (qb *QueryBuilder) Get() ([]map[string]interface{}, error) {
 
 	// 如果启用了缓存，将原始结果存入缓存
 	if qb.cacheEnabled && qb.transaction == nil {
-		cacheKey := qb.generateCacheKey()
-		if len(qb.cacheTags) > 0 {
-			if memCache, ok := GetDefaultCache().(*MemoryCache); ok {
-				memCache.SetWithTags(cacheKey, result, qb.cacheTTL, qb.cacheTags)
-			}
-		} else {
-			GetDefaultCache().Set(cacheKey, result, qb.cacheTTL)
-		}
+		qb.writeQueryCache(qb.generateCacheKey(), result)
 	}
 
 	// 应用访问器处理
@@ -985,15 +1326,17 @@ func (qb *QueryBuilder) Get() ([]map[string]interface{}, error) {
 }
 
 // First 获取第一条记录（支持访问器处理）
+// 在克隆的构建器上执行，不修改当前构建器的LIMIT
 func (qb *QueryBuilder) First(dest ...interface{}) (map[string]interface{}, error) {
-	qb.Limit(1)
-	results, err := qb.Get()
+	defer qb.releaseTimeout()
+
+	results, err := qb.Clone().Limit(1).Get()
 	if err != nil {
 		return nil, err
 	}
 
 	if len(results) == 0 {
-		return nil, ErrRecordNotFound.WithContext("table", qb.tableName)
+		return nil, newNotFoundError("table", qb.tableName)
 	}
 
 	return results[0], nil
@@ -1001,13 +1344,20 @@ func (qb *QueryBuilder) First(dest ...interface{}) (map[string]interface{}, erro
 
 // GetRaw 执行查询并返回原始数据（不应用访问器处理）
 func (qb *QueryBuilder) GetRaw() ([]map[string]interface{}, error) {
-	// 如果启用了缓存并且不在事务中，尝试从缓存获取
-	if qb.cacheEnabled && qb.transaction == nil {
-		cacheKey := qb.generateCacheKey() + "_raw"
-		if cached, err := GetDefaultCache().Get(cacheKey); err == nil {
-			if result, ok := cached.([]map[string]interface{}); ok {
-				return result, nil
-			}
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
+	qb.checkLockTransaction()
+	if qb.err != nil {
+		return nil, qb.err
+	}
+
+	// 如果启用了缓存并且不在事务中，尝试从缓存获取（FreshCache 时跳过）
+	if qb.cacheEnabled && !qb.cacheRefresh && qb.transaction == nil {
+		if result, ok := qb.readQueryCache(qb.generateCacheKey() + "_raw"); ok {
+			return result, nil
 		}
 	}
 
@@ -1017,13 +1367,13 @@ func (qb *QueryBuilder) GetRaw() ([]map[string]interface{}, error) {
 	var err error
 
 	if qb.transaction != nil {
-		rows, err = qb.transaction.Query(sqlStr, args...)
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 	} else {
 		conn, connErr := qb.getConnection()
 		if connErr != nil {
 			return nil, connErr
 		}
-		rows, err = conn.Query(sqlStr, args...)
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
 	if err != nil {
@@ -1052,14 +1402,7 @@ func (qb *QueryBuilder) GetRaw() ([]map[string]interface{}, error) {
 
 	// 如果启用了缓存，将原始结果存入缓存
 	if qb.cacheEnabled && qb.transaction == nil {
-		cacheKey := qb.generateCacheKey() + "_raw"
-		if len(qb.cacheTags) > 0 {
-			if memCache, ok := GetDefaultCache().(*MemoryCache); ok {
-				memCache.SetWithTags(cacheKey, result, qb.cacheTTL, qb.cacheTags)
-			}
-		} else {
-			GetDefaultCache().Set(cacheKey, result, qb.cacheTTL)
-		}
+		qb.writeQueryCache(qb.generateCacheKey()+"_raw", result)
 	}
 
 	// 直接返回原始结果，不应用访问器处理
@@ -1068,33 +1411,139 @@ func (qb *QueryBuilder) GetRaw() ([]map[string]interface{}, error) {
 
 // FirstRaw 获取第一条记录的原始数据（不应用访问器处理）
 func (qb *QueryBuilder) FirstRaw() (map[string]interface{}, error) {
-	qb.Limit(1)
-	results, err := qb.GetRaw()
+	defer qb.releaseTimeout()
+
+	results, err := qb.Clone().Limit(1).GetRaw()
 	if err != nil {
 		return nil, err
 	}
 
 	if len(results) == 0 {
-		return nil, ErrRecordNotFound.WithContext("table", qb.tableName)
+		return nil, newNotFoundError("table", qb.tableName)
 	}
 
 	return results[0], nil
 }
 
 // Count 计算记录数量
+// 存在 GROUP BY 时统计分组数量；构建和执行都在克隆上进行，同一基础查询可以在多个goroutine中同时 Count
 func (qb *QueryBuilder) Count() (int64, error) {
-	// 备份原始查询配置
-	originalSelect := qb.selectColumns
-	originalLimit := qb.limitCount
-	originalOffset := qb.offsetCount
+	release, err := qb.beginCloneExecution()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
 
-	// 设置COUNT查询
-	qb.selectColumns = []string{"COUNT(*) as count"}
-	qb.limitCount = 0  // 移除LIMIT
-	qb.offsetCount = 0 // 移除OFFSET
+	counter := qb.Clone()
+	sqlStr, args := counter.buildCountSQL()
+	return counter.executeCount(sqlStr, args)
+}
 
-	// 构建SQL和参数
-	sqlStr, args := qb.buildSelectSQL()
+// beginCloneExecution 准备在克隆上执行的只读查询，不修改当前构建器
+// 设置了 WithTimeout 的构建器执行后需要释放超时，此时按普通执行方法独占构建器
+func (qb *QueryBuilder) beginCloneExecution() (func(), error) {
+	if qb.cancel == nil {
+		return func() {}, nil
+	}
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	return qb.endExecution, nil
+}
+
+// buildCountSQL 构建COUNT查询SQL
+// 在克隆的构建器上改写选择列表，不修改当前构建器，基础查询可以同时被其他goroutine克隆或执行
+func (qb *QueryBuilder) buildCountSQL() (string, []interface{}) {
+	counter := qb.aggregateBuilder()
+	defer qb.mergeBuildErr(counter)
+
+	if len(counter.groupByColumns) == 0 && !counter.distinct {
+		// 设置COUNT查询
+		counter.selectColumns = []string{"COUNT(*) as count"}
+		counter.selectRaw = nil
+		return counter.buildSelectSQL()
+	}
+
+	// 分组或去重查询：将查询SQL作为子查询，统计分组数量或去重后的行数
+	counter.orderByColumns = nil
+	if len(counter.selectColumns) == 0 && len(counter.selectRaw) == 0 && len(counter.groupByColumns) > 0 {
+		counter.selectColumns = counter.groupByColumns
+	}
+
+	subSQL, args := counter.buildSelectSQL()
+	return fmt.Sprintf("SELECT COUNT(*) as count FROM (%s) torm_count", subSQL), args
+}
+
+// aggregateBuilder 克隆用于聚合查询的构建器，移除LIMIT、OFFSET和锁（聚合查询不加锁）
+func (qb *QueryBuilder) aggregateBuilder() *QueryBuilder {
+	aggregate := qb.Clone()
+	aggregate.limitCount = 0
+	aggregate.offsetCount = 0
+	aggregate.lockMode = ""
+	return aggregate
+}
+
+// mergeBuildErr 将克隆构建器在构建SQL时记录的错误合并到当前构建器
+// Count 等方法在克隆上调用构建函数，错误合并到该克隆，不修改原构建器
+func (qb *QueryBuilder) mergeBuildErr(clone *QueryBuilder) {
+	if clone.err != nil {
+		qb.setErr(clone.err)
+	}
+}
+
+// CountDistinct 统计指定列去重后的数量
+func (qb *QueryBuilder) CountDistinct(columns ...string) (int64, error) {
+	if len(columns) == 0 {
+		return 0, NewError(ErrCodeInvalidParameter, "CountDistinct至少需要一个列名")
+	}
+	for _, column := range columns {
+		if err := qb.validateColumnName(column); err != nil {
+			return 0, err
+		}
+	}
+
+	release, err := qb.beginCloneExecution()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	counter := qb.Clone()
+	sqlStr, args := counter.buildCountDistinctSQL(columns)
+	return counter.executeCount(sqlStr, args)
+}
+
+// buildCountDistinctSQL 构建COUNT(DISTINCT ...)查询SQL
+// 多列去重仅MySQL支持 COUNT(DISTINCT a, b)，其他数据库使用 SELECT DISTINCT 子查询
+func (qb *QueryBuilder) buildCountDistinctSQL(columns []string) (string, []interface{}) {
+	counter := qb.aggregateBuilder()
+	defer qb.mergeBuildErr(counter)
+
+	counter.selectRaw = nil
+	counter.orderByColumns = nil
+
+	if len(columns) == 1 || counter.getDriverName() == "mysql" {
+		counter.distinct = false
+		counter.selectColumns = []string{fmt.Sprintf("COUNT(DISTINCT %s) as count", strings.Join(columns, ", "))}
+		return counter.buildSelectSQL()
+	}
+
+	counter.distinct = true
+	counter.selectColumns = columns
+	subSQL, args := counter.buildSelectSQL()
+	return fmt.Sprintf("SELECT COUNT(*) as count FROM (%s) torm_count", subSQL), args
+}
+
+// executeCount 执行COUNT查询并将结果转换为int64
+func (qb *QueryBuilder) executeCount(sqlStr string, args []interface{}) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if qb.err != nil {
+		return 0, qb.err
+	}
 
 	// 记录日志用于调试
 	start := time.Now()
@@ -1109,20 +1558,15 @@ func (qb *QueryBuilder) Count() (int64, error) {
 	var err error
 
 	if qb.transaction != nil {
-		rows, err = qb.transaction.Query(sqlStr, args...)
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 	} else {
 		conn, connErr := qb.getConnection()
 		if connErr != nil {
 			return 0, connErr
 		}
-		rows, err = conn.Query(sqlStr, args...)
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
-	// 恢复原始查询配置
-	qb.selectColumns = originalSelect
-	qb.limitCount = originalLimit
-	qb.offsetCount = originalOffset
-
 	if err != nil {
 		wrappedErr := WrapError(err, ErrCodeQueryFailed, "Count查询执行失败").
 			WithContext("sql", sqlStr).
@@ -1192,16 +1636,24 @@ func (qb *QueryBuilder) Count() (int64, error) {
 
 // Insert 插入数据
 func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
 	if len(data) == 0 {
 		return 0, ErrInvalidParameter.WithDetails("插入数据不能为空")
 	}
 
 	// 处理时间字段
 	if qb.timeManager != nil && len(qb.timeFields) > 0 {
-		data = qb.timeManager.ProcessInsertData(data, qb.timeFields)
+		data = qb.timeFieldManager().ProcessInsertData(data, qb.timeFields)
 	}
 
 	sqlStr, args := qb.buildInsertSQL(data)
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
 	driverName := qb.getDriverName()
 
 	if driverName == "postgres" {
@@ -1214,14 +1666,15 @@ func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
 		var err error
 
 		if qb.transaction != nil {
-			err = qb.transaction.QueryRow(sqlStr, args...).Scan(&lastID)
+			err = queryRowTxWithContext(qb.context(), qb.transaction, sqlStr, args...).Scan(&lastID)
 		} else {
 			conn, connErr := qb.getConnection()
 			if connErr != nil {
 				return 0, connErr
 			}
 			db := conn.GetDB()
-			err = db.QueryRow(sqlStr, args...).Scan(&lastID)
+			profileQuery(qb.context(), sqlStr)
+			err = db.QueryRowContext(qb.context(), sqlStr, args...).Scan(&lastID)
 		}
 
 		if err != nil {
@@ -1230,13 +1683,13 @@ func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
 			var result interface{}
 
 			if qb.transaction != nil {
-				result, err = qb.transaction.Exec(originalSQL, args...)
+				result, err = execTxWithContext(qb.context(), qb.transaction, originalSQL, args...)
 			} else {
 				conn, connErr := qb.getConnection()
 				if connErr != nil {
 					return 0, connErr
 				}
-				result, err = conn.Exec(originalSQL, args...)
+				result, err = execWithContext(qb.context(), conn, originalSQL, args...)
 			}
 
 			if err != nil {
@@ -1269,13 +1722,13 @@ func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
 		var err error
 
 		if qb.transaction != nil {
-			result, err = qb.transaction.Exec(sqlStr, args...)
+			result, err = execTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 		} else {
 			conn, connErr := qb.getConnection()
 			if connErr != nil {
 				return 0, connErr
 			}
-			result, err = conn.Exec(sqlStr, args...)
+			result, err = execWithContext(qb.context(), conn, sqlStr, args...)
 		}
 
 		if err != nil {
@@ -1306,73 +1759,169 @@ func (qb *QueryBuilder) Insert(data map[string]interface{}) (int64, error) {
 	}
 }
 
-// Update 更新数据
-func (qb *QueryBuilder) Update(data map[string]interface{}) (int64, error) {
+// SupportsReturning 当前数据库是否支持 INSERT ... RETURNING
+func (qb *QueryBuilder) SupportsReturning() bool {
+	switch qb.getDriverName() {
+	case "postgres", "postgresql", "sqlite", "sqlite3":
+		return true
+	}
+	return false
+}
+
+// InsertReturning 插入数据并返回插入后的完整行（包含数据库默认值和生成列）
+// 仅支持PostgreSQL和SQLite（3.35+），其他数据库返回 ErrCodeNotImplemented
+func (qb *QueryBuilder) InsertReturning(data map[string]interface{}) (map[string]interface{}, error) {
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
 	if len(data) == 0 {
-		return 0, ErrInvalidParameter.WithDetails("更新数据不能为空")
+		return nil, NewError(ErrCodeInvalidParameter, "插入数据不能为空")
+	}
+	if !qb.SupportsReturning() {
+		return nil, NewError(ErrCodeNotImplemented, "当前数据库不支持INSERT RETURNING").
+			WithContext("driver", qb.getDriverName())
 	}
 
 	// 处理时间字段
 	if qb.timeManager != nil && len(qb.timeFields) > 0 {
-		data = qb.timeManager.ProcessUpdateData(data, qb.timeFields)
+		data = qb.timeFieldManager().ProcessInsertData(data, qb.timeFields)
 	}
 
-	sqlStr, args := qb.buildUpdateSQL(data)
+	sqlStr, args := qb.buildInsertSQL(data)
+	sqlStr += " RETURNING *"
+	if qb.recordSQL(sqlStr, args) {
+		return nil, nil
+	}
 
-	var result interface{}
+	var rows *sql.Rows
 	var err error
-
 	if qb.transaction != nil {
-		result, err = qb.transaction.Exec(sqlStr, args...)
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 	} else {
 		conn, connErr := qb.getConnection()
 		if connErr != nil {
-			return 0, connErr
+			return nil, connErr
 		}
-		result, err = conn.Exec(sqlStr, args...)
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
 	if err != nil {
-		// 检查是否为重复键错误
-		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "UNIQUE") {
-			return 0, WrapError(err, ErrCodeDuplicateKey, "违反唯一性约束").
-				WithContext("sql", sqlStr).
-				WithContext("args", args).
-				WithContext("table", qb.tableName)
+		code := ErrCodeQueryFailed
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(strings.ToLower(err.Error()), "unique") {
+			code = ErrCodeDuplicateKey
 		}
-		return 0, WrapError(err, ErrCodeQueryFailed, "更新数据失败").
+		return nil, WrapError(err, code, "插入数据失败").
 			WithContext("sql", sqlStr).
 			WithContext("args", args).
 			WithContext("table", qb.tableName)
 	}
+	defer rows.Close()
 
-	if sqlResult, ok := result.(interface{ RowsAffected() (int64, error) }); ok {
-		affected, err := sqlResult.RowsAffected()
-		if err != nil {
-			return 0, WrapError(err, ErrCodeQueryFailed, "获取影响行数失败")
-		}
-		return affected, nil
+	result, err := qb.scanRows(rows)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "扫描插入结果失败").
+			WithContext("sql", sqlStr).
+			WithContext("table", qb.tableName)
 	}
+	if len(result) == 0 {
+		return nil, NewError(ErrCodeQueryFailed, "插入未返回数据").
+			WithContext("table", qb.tableName)
+	}
+	return result[0], nil
+}
 
-	return 0, NewError(ErrCodeQueryFailed, "无法获取影响行数").
+// Update 更新数据
+func (qb *QueryBuilder) Update(data map[string]interface{}) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if len(data) == 0 {
+		return 0, ErrInvalidParameter.WithDetails("更新数据不能为空")
+	}
+	if qb.err != nil {
+		return 0, qb.err
+	}
+
+	// 处理时间字段
+	if qb.timeManager != nil && len(qb.timeFields) > 0 {
+		data = qb.timeFieldManager().ProcessUpdateData(data, qb.timeFields)
+	}
+
+	sqlStr, args := qb.buildUpdateSQL(data)
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
+
+	var result interface{}
+	var err error
+
+	if qb.transaction != nil {
+		result, err = execTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return 0, connErr
+		}
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
+	}
+
+	if err != nil {
+		// 检查是否为重复键错误
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "UNIQUE") {
+			return 0, WrapError(err, ErrCodeDuplicateKey, "违反唯一性约束").
+				WithContext("sql", sqlStr).
+				WithContext("args", args).
+				WithContext("table", qb.tableName)
+		}
+		return 0, WrapError(err, ErrCodeQueryFailed, "更新数据失败").
+			WithContext("sql", sqlStr).
+			WithContext("args", args).
+			WithContext("table", qb.tableName)
+	}
+
+	if sqlResult, ok := result.(interface{ RowsAffected() (int64, error) }); ok {
+		affected, err := sqlResult.RowsAffected()
+		if err != nil {
+			return 0, WrapError(err, ErrCodeQueryFailed, "获取影响行数失败")
+		}
+		return affected, nil
+	}
+
+	return 0, NewError(ErrCodeQueryFailed, "无法获取影响行数").
 		WithContext("table", qb.tableName)
 }
 
 // Delete 删除数据
 func (qb *QueryBuilder) Delete() (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if qb.err != nil {
+		return 0, qb.err
+	}
+
 	sqlStr, args := qb.buildDeleteSQL()
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
 
 	var result interface{}
 	var err error
 
 	if qb.transaction != nil {
-		result, err = qb.transaction.Exec(sqlStr, args...)
+		result, err = execTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
 	} else {
 		conn, connErr := qb.getConnection()
 		if connErr != nil {
 			return 0, connErr
 		}
-		result, err = conn.Exec(sqlStr, args...)
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
 	if err != nil {
@@ -1394,8 +1943,24 @@ func (qb *QueryBuilder) Delete() (int64, error) {
 		WithContext("table", qb.tableName)
 }
 
+// ForceDelete 物理删除匹配当前WHERE条件的记录，忽略软删除作用域，可用于批量清理已软删除的数据
+// 没有WHERE条件时返回错误，避免误删整张表
+func (qb *QueryBuilder) ForceDelete() (int64, error) {
+	if len(qb.whereConditions) == 0 {
+		qb.releaseTimeout()
+		return 0, NewError(ErrCodeInvalidParameter, "ForceDelete 缺少WHERE条件，拒绝删除整张表").
+			WithContext("table", qb.tableName)
+	}
+	return qb.WithoutGlobalScope(SoftDeleteScope).Delete()
+}
+
 // buildSelectSQL 构建SELECT SQL
 func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
+	qb.checkLockTransaction()
+	if scoped := qb.scopedBuilder(); scoped != qb {
+		return scoped.buildSelectSQL()
+	}
+
 	var sql strings.Builder
 	var args []interface{}
 	argIndex := 0
@@ -1406,14 +1971,32 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 		return "SELECT 1 WHERE 1=0", []interface{}{}
 	}
 
+	// WITH子句，CTE的参数排在最前
+	if withSQL, withArgs := qb.buildWithClause(); withSQL != "" {
+		sql.WriteString(withSQL)
+		args = append(args, withArgs...)
+		argIndex += len(withArgs)
+	}
+
 	// SELECT子句
 	sql.WriteString("SELECT ")
-	if len(qb.selectColumns) > 0 {
+	if qb.distinct {
+		sql.WriteString("DISTINCT ")
+	}
+	if len(qb.selectColumns) > 0 || len(qb.selectRaw) > 0 {
 		// 验证和清理选择列
-		validColumns := make([]string, 0, len(qb.selectColumns))
+		validColumns := make([]string, 0, len(qb.selectColumns)+len(qb.selectRaw))
 		for _, col := range qb.selectColumns {
 			if cleanCol := qb.sanitizeColumn(col); cleanCol != "" {
-				validColumns = append(validColumns, cleanCol)
+				validColumns = append(validColumns, qb.quoteIdentifier(cleanCol))
+			}
+		}
+		// 原生选择表达式
+		for _, expr := range qb.selectRaw {
+			validColumns = append(validColumns, qb.processPlaceholders(expr.SQL, argIndex))
+			if len(expr.Values) > 0 {
+				args = append(args, expr.Values...)
+				argIndex += len(expr.Values)
 			}
 		}
 		if len(validColumns) > 0 {
@@ -1427,13 +2010,19 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 
 	// FROM子句
 	sql.WriteString(" FROM ")
-	sql.WriteString(qb.sanitizeTableName(qb.tableName))
+	sql.WriteString(qb.tableReference(qb.tableName, qb.rawTable))
+
+	// 锁定子句（仅在事务中生效）
+	lockClause, lockAsTableHint := qb.buildLockClause()
+	if lockAsTableHint {
+		sql.WriteString(lockClause)
+	}
 
 	// JOIN子句
 	for _, join := range qb.joinClauses {
 		// 验证JOIN类型
 		cleanJoinType := qb.sanitizeJoinType(join.Type)
-		cleanTable := qb.sanitizeTableName(join.Table)
+		cleanTable := qb.tableReference(join.Table, false)
 
 		if cleanJoinType == "CROSS" {
 			// CROSS JOIN 不需要 ON 条件
@@ -1472,7 +2061,7 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 				}
 			} else {
 				placeholder := qb.buildPlaceholder(argIndex)
-				sql.WriteString(fmt.Sprintf("%s %s %s", condition.Column, condition.Operator, placeholder))
+				sql.WriteString(fmt.Sprintf("%s %s %s", qb.quoteIdentifier(condition.Column), condition.Operator, placeholder))
 				args = append(args, condition.Value)
 				argIndex++
 			}
@@ -1485,7 +2074,7 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 		validGroupBy := make([]string, 0, len(qb.groupByColumns))
 		for _, col := range qb.groupByColumns {
 			if cleanCol := qb.sanitizeColumn(col); cleanCol != "" {
-				validGroupBy = append(validGroupBy, cleanCol)
+				validGroupBy = append(validGroupBy, qb.quoteIdentifier(cleanCol))
 			}
 		}
 		if len(validGroupBy) > 0 {
@@ -1513,7 +2102,7 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 				}
 			} else {
 				placeholder := qb.buildPlaceholder(argIndex)
-				sql.WriteString(fmt.Sprintf("%s %s %s", condition.Column, condition.Operator, placeholder))
+				sql.WriteString(fmt.Sprintf("%s %s %s", qb.quoteIdentifier(condition.Column), condition.Operator, placeholder))
 				args = append(args, condition.Value)
 				argIndex++
 			}
@@ -1525,8 +2114,26 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 		sql.WriteString(" ORDER BY ")
 		validOrderBy := make([]string, 0, len(qb.orderByColumns))
 		for _, order := range qb.orderByColumns {
-			cleanColumn := qb.sanitizeColumn(order.Column)
+			if order.Raw {
+				// 原生排序表达式按原样输出，参数按出现位置追加在WHERE/HAVING参数之后
+				validOrderBy = append(validOrderBy, qb.processPlaceholders(order.Column, argIndex))
+				args = append(args, order.Bindings...)
+				argIndex += len(order.Bindings)
+				continue
+			}
+			if len(order.Bindings) > 0 {
+				// 带参数绑定的排序表达式由构建器内部生成，不做清理
+				expr := qb.processPlaceholders(order.Column, argIndex)
+				validOrderBy = append(validOrderBy, expr+" "+qb.sanitizeDirection(order.Direction))
+				args = append(args, order.Bindings...)
+				argIndex += len(order.Bindings)
+				continue
+			}
+			cleanColumn := qb.quoteIdentifier(qb.sanitizeColumn(order.Column))
 			cleanDirection := qb.sanitizeDirection(order.Direction)
+			if cleanColumn != "" && order.Collation != "" {
+				cleanColumn += " COLLATE " + qb.quotedCollation(order.Collation)
+			}
 			if cleanColumn != "" && cleanDirection != "" {
 				validOrderBy = append(validOrderBy, fmt.Sprintf("%s %s", cleanColumn, cleanDirection))
 			}
@@ -1570,18 +2177,61 @@ func (qb *QueryBuilder) buildSelectSQL() (string, []interface{}) {
 		}
 	}
 
+	if !lockAsTableHint {
+		sql.WriteString(lockClause)
+	}
+
 	return sql.String(), args
 }
 
+// buildLockClause 构建悲观锁子句，返回子句和是否为表提示（SQL Server）
+func (qb *QueryBuilder) buildLockClause() (string, bool) {
+	if qb.lockMode == "" || qb.transaction == nil {
+		// 事务外的锁由 checkLockTransaction 记录错误
+		return "", false
+	}
+
+	switch qb.getDriverName() {
+	case "sqlserver", "mssql":
+		if qb.lockMode == "share" {
+			return " WITH (HOLDLOCK, ROWLOCK)", true
+		}
+		return " WITH (UPDLOCK, ROWLOCK)", true
+	case "postgres", "postgresql":
+		if qb.lockMode == "share" {
+			return " FOR SHARE", false
+		}
+		return " FOR UPDATE", false
+	case "sqlite", "sqlite3":
+		// SQLite 不支持行级锁，事务本身即为数据库级锁
+		return "", false
+	default:
+		if qb.lockMode == "share" {
+			return " LOCK IN SHARE MODE", false
+		}
+		return " FOR UPDATE", false
+	}
+}
+
+// sortedColumns 按字母顺序返回数据的列名，使生成的SQL和参数顺序稳定
+func sortedColumns(data map[string]interface{}) []string {
+	columns := make([]string, 0, len(data))
+	for column := range data {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
 // buildInsertSQL 构建INSERT SQL
 func (qb *QueryBuilder) buildInsertSQL(data map[string]interface{}) (string, []interface{}) {
 	columns := make([]string, 0, len(data))
 	placeholders := make([]string, 0, len(data))
 	args := make([]interface{}, 0, len(data))
 
-	for column, value := range data {
-		columns = append(columns, column)
-		args = append(args, value)
+	for _, column := range sortedColumns(data) {
+		columns = append(columns, qb.quoteIdentifier(column))
+		args = append(args, data[column])
 	}
 
 	// 根据数据库类型生成占位符
@@ -1598,7 +2248,7 @@ func (qb *QueryBuilder) buildInsertSQL(data map[string]interface{}) (string, []i
 	}
 
 	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		qb.tableName,
+		qb.quoteIdentifier(qb.fullTableName()),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "))
 
@@ -1607,22 +2257,26 @@ func (qb *QueryBuilder) buildInsertSQL(data map[string]interface{}) (string, []i
 
 // buildUpdateSQL 构建UPDATE SQL
 func (qb *QueryBuilder) buildUpdateSQL(data map[string]interface{}) (string, []interface{}) {
+	if scoped := qb.scopedBuilder(); scoped != qb {
+		return scoped.buildUpdateSQL(data)
+	}
+
 	var sql strings.Builder
 	var args []interface{}
 
-	sql.WriteString("UPDATE ")
-	sql.WriteString(qb.tableName)
+	fromClause := qb.writeUpdateTable(&sql)
 	sql.WriteString(" SET ")
 
 	setParts := make([]string, 0, len(data))
 	argIndex := 0
-	for column, value := range data {
+	for _, column := range sortedColumns(data) {
 		placeholder := qb.buildPlaceholder(argIndex)
-		setParts = append(setParts, column+" = "+placeholder)
-		args = append(args, value)
+		setParts = append(setParts, qb.quoteIdentifier(column)+" = "+placeholder)
+		args = append(args, data[column])
 		argIndex++
 	}
 	sql.WriteString(strings.Join(setParts, ", "))
+	sql.WriteString(fromClause)
 
 	// WHERE子句
 	if len(qb.whereConditions) > 0 {
@@ -1642,7 +2296,7 @@ func (qb *QueryBuilder) buildUpdateSQL(data map[string]interface{}) (string, []i
 				}
 			} else {
 				placeholder := qb.buildPlaceholder(argIndex)
-				sql.WriteString(fmt.Sprintf("%s %s %s", condition.Column, condition.Operator, placeholder))
+				sql.WriteString(fmt.Sprintf("%s %s %s", qb.quoteIdentifier(condition.Column), condition.Operator, placeholder))
 				args = append(args, condition.Value)
 				argIndex++
 			}
@@ -1654,12 +2308,15 @@ func (qb *QueryBuilder) buildUpdateSQL(data map[string]interface{}) (string, []i
 
 // buildDeleteSQL 构建DELETE SQL
 func (qb *QueryBuilder) buildDeleteSQL() (string, []interface{}) {
+	if scoped := qb.scopedBuilder(); scoped != qb {
+		return scoped.buildDeleteSQL()
+	}
+
 	var sql strings.Builder
 	var args []interface{}
 	argIndex := 0
 
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(qb.tableName)
+	qb.writeDeleteTable(&sql)
 
 	// WHERE子句
 	if len(qb.whereConditions) > 0 {
@@ -1678,7 +2335,7 @@ func (qb *QueryBuilder) buildDeleteSQL() (string, []interface{}) {
 				}
 			} else {
 				placeholder := qb.buildPlaceholder(argIndex)
-				sql.WriteString(fmt.Sprintf("%s %s %s", condition.Column, condition.Operator, placeholder))
+				sql.WriteString(fmt.Sprintf("%s %s %s", qb.quoteIdentifier(condition.Column), condition.Operator, placeholder))
 				args = append(args, condition.Value)
 				argIndex++
 			}
@@ -1695,29 +2352,69 @@ func (qb *QueryBuilder) scanRows(rows *sql.Rows) ([]map[string]interface{}, erro
 		return nil, err
 	}
 
+	// 列类型用于按数据库类型转换（如DECIMAL），驱动不支持时按值推断
+	types, _ := rows.ColumnTypes()
+
 	var results []map[string]interface{}
 
 	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
+		row, err := qb.scanRow(rows, columns, types)
+		if err != nil {
 			return nil, err
 		}
+		results = append(results, row)
+	}
+
+	return results, rows.Err()
+}
 
-		row := make(map[string]interface{})
-		for i, column := range columns {
+// scanRow 扫描当前行并转换数据库值，types 可以为nil
+func (qb *QueryBuilder) scanRow(rows *sql.Rows, columns []string, types []*sql.ColumnType) (map[string]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if i < len(types) && types[i] != nil {
+			if converted, ok, err := convertWithScanConverter(values[i], types[i]); ok {
+				if err != nil {
+					return nil, WrapError(err, ErrCodeQueryFailed, "自定义扫描转换失败").
+						WithContext("column", column).
+						WithContext("type", types[i].DatabaseTypeName())
+				}
+				row[column] = converted
+				continue
+			}
+			row[column] = qb.convertColumnValue(values[i], types[i])
+		} else {
 			row[column] = qb.convertDatabaseValue(values[i])
 		}
-
-		results = append(results, row)
 	}
+	return row, nil
+}
 
-	return results, nil
+// convertColumnValue 根据列的数据库类型转换值，未识别的类型交给 convertDatabaseValue
+func (qb *QueryBuilder) convertColumnValue(value interface{}, columnType *sql.ColumnType) interface{} {
+	if value == nil {
+		return nil
+	}
+	if isDecimalColumn(columnType) {
+		if converted, ok := convertDecimalValue(value, GetDecimalMode()); ok {
+			return converted
+		}
+	}
+	if converted, ok := qb.convertTypedText(value, columnType); ok {
+		return converted
+	}
+	return qb.convertDatabaseValue(value)
 }
 
 // convertDatabaseValue 转换数据库返回值为合适的Go类型
@@ -1728,6 +2425,9 @@ func (qb *QueryBuilder) convertDatabaseValue(value interface{}) interface{} {
 
 	switch v := value.(type) {
 	case []byte:
+		if !IsSmartConversionEnabled() {
+			return plainByteValue(v)
+		}
 		// 智能处理字节数组 - 需要判断其实际内容类型
 		return qb.convertByteArraySmart(v)
 	case int8:
@@ -1757,6 +2457,9 @@ func (qb *QueryBuilder) convertDatabaseValue(value interface{}) interface{} {
 	case bool:
 		return v
 	case string:
+		if !IsSmartConversionEnabled() {
+			return v
+		}
 		// 字符串可能是Base64编码的，但要谨慎处理
 		return qb.tryBase64DecodeIfText(v)
 	case time.Time:
@@ -1770,7 +2473,7 @@ func (qb *QueryBuilder) convertDatabaseValue(value interface{}) interface{} {
 	}
 }
 
-// convertByteArraySmart 智能转换字节数组
+// convertByteArraySmart 智能转换字节数组，仅在 SetSmartConversion(true) 时使用
 func (qb *QueryBuilder) convertByteArraySmart(data []byte) interface{} {
 	if len(data) == 0 {
 		return ""
@@ -1916,8 +2619,10 @@ func (qb *QueryBuilder) tryParseTime(str string) (time.Time, bool) {
 		"01-02-2006",
 	}
 
+	// 不带时区的格式按连接配置的时区解析
+	loc := qb.location()
 	for _, format := range timeFormats {
-		if t, err := time.Parse(format, str); err == nil {
+		if t, err := time.ParseInLocation(format, str, loc); err == nil {
 			return t, true
 		}
 	}
@@ -1927,9 +2632,9 @@ func (qb *QueryBuilder) tryParseTime(str string) (time.Time, bool) {
 		if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
 			// 区分秒级和毫秒级时间戳
 			if timestamp > 1e10 { // 毫秒级时间戳
-				return time.Unix(timestamp/1000, (timestamp%1000)*1000000), true
+				return time.Unix(timestamp/1000, (timestamp%1000)*1000000).In(loc), true
 			} else { // 秒级时间戳
-				return time.Unix(timestamp, 0), true
+				return time.Unix(timestamp, 0).In(loc), true
 			}
 		}
 	}
@@ -2189,6 +2894,20 @@ func (qb *QueryBuilder) isSliceOrArray(value interface{}) bool {
 	}
 }
 
+// location 获取连接配置的时区，无法获取连接时为服务器本地时区
+func (qb *QueryBuilder) location() *time.Location {
+	conn, err := qb.getConnection()
+	if err != nil || conn.GetConfig() == nil {
+		return time.Local
+	}
+	return conn.GetConfig().GetLocation()
+}
+
+// timeFieldManager 返回使用连接时区的时间字段管理器副本，共享的管理器不会被修改
+func (qb *QueryBuilder) timeFieldManager() *TimeFieldManager {
+	return qb.timeManager.WithLocation(qb.location())
+}
+
 // getDriverName 获取数据库驱动名称
 func (qb *QueryBuilder) getDriverName() string {
 	conn, err := qb.getConnection()
@@ -2200,6 +2919,9 @@ func (qb *QueryBuilder) getDriverName() string {
 
 // buildPlaceholder 根据数据库类型构建占位符
 func (qb *QueryBuilder) buildPlaceholder(index int) string {
+	if qb.rawPlaceholders {
+		return "?"
+	}
 	driverName := qb.getDriverName()
 	switch driverName {
 	case "postgres", "postgresql", "pq":
@@ -2216,6 +2938,9 @@ func (qb *QueryBuilder) buildPlaceholder(index int) string {
 
 // processPlaceholders 处理原始SQL中的占位符
 func (qb *QueryBuilder) processPlaceholders(sql string, startIndex int) string {
+	if qb.rawPlaceholders {
+		return sql
+	}
 	driverName := qb.getDriverName()
 
 	switch driverName {
@@ -2391,9 +3116,13 @@ func (qb *QueryBuilder) generateCacheKey() string {
 		return qb.cacheKey
 	}
 
+	if scoped := qb.scopedBuilder(); scoped != qb {
+		return scoped.generateCacheKey()
+	}
 	cacheData := map[string]interface{}{
 		"table":  qb.tableName,
 		"select": qb.selectColumns,
+		"raw":    qb.selectRaw,
 		"where":  qb.whereConditions,
 		"join":   qb.joinClauses,
 		"group":  qb.groupByColumns,
@@ -2411,9 +3140,94 @@ func (qb *QueryBuilder) generateCacheKey() string {
 // From 设置查询表名
 func (qb *QueryBuilder) From(table string) *QueryBuilder {
 	qb.tableName = table
+	qb.rawTable = false
+	return qb
+}
+
+// RawTable 设置查询表名，表名按原样使用，不加连接配置的表前缀
+func (qb *QueryBuilder) RawTable(table string) *QueryBuilder {
+	qb.tableName = table
+	qb.rawTable = true
 	return qb
 }
 
+// prefixTable 为表名加上连接配置的表前缀
+func (qb *QueryBuilder) prefixTable(table string) string {
+	conn, err := qb.getConnection()
+	if err != nil {
+		return table
+	}
+	return conn.GetConfig().PrefixTable(table)
+}
+
+// fullTableName 构建SQL使用的主表名（含表前缀）
+func (qb *QueryBuilder) fullTableName() string {
+	if qb.rawTable {
+		return qb.tableName
+	}
+	return qb.prefixTable(qb.tableName)
+}
+
+// tableReference 构建FROM和JOIN中的表引用
+// 加了前缀的表以原表名作为别名，使 users.id 这类按原表名限定的列在查询中仍然有效；
+// 支持 "users u" 和 "users AS u" 形式的显式别名，此时只为表名加前缀
+func (qb *QueryBuilder) tableReference(table string, raw bool) string {
+	if fields := strings.Fields(table); len(fields) == 2 || (len(fields) == 3 && strings.EqualFold(fields[1], "AS")) {
+		name := fields[0]
+		if !raw && !strings.Contains(name, ".") {
+			name = qb.prefixTable(name)
+		}
+		return qb.quoteTableReference(qb.sanitizeTableName(name) + " " + qb.sanitizeTableName(fields[len(fields)-1]))
+	}
+	if raw {
+		return qb.quoteTableReference(qb.sanitizeTableName(table))
+	}
+	prefixed := qb.prefixTable(table)
+	if prefixed == table || strings.ContainsAny(table, ". \t") {
+		return qb.quoteTableReference(qb.sanitizeTableName(prefixed))
+	}
+	return qb.quoteTableReference(qb.sanitizeTableName(prefixed) + " " + qb.sanitizeTableName(table))
+}
+
+// modificationTable 返回UPDATE/DELETE的目标表和别名
+// 与 tableReference 一致，加了前缀的表以原表名作为别名，没有别名时 alias 为空
+func (qb *QueryBuilder) modificationTable() (table, alias string) {
+	fullName := qb.fullTableName()
+	table = qb.quoteIdentifier(fullName)
+	if qb.rawTable || fullName == qb.tableName || strings.ContainsAny(qb.tableName, ". \t") {
+		return table, ""
+	}
+	return table, qb.quoteIdentifier(qb.sanitizeTableName(qb.tableName))
+}
+
+// writeUpdateTable 写入 UPDATE 及目标表，SQL Server 的别名需要在 SET 之后用 FROM 声明，返回该子句
+func (qb *QueryBuilder) writeUpdateTable(sql *strings.Builder) string {
+	table, alias := qb.modificationTable()
+	switch driver := qb.getDriverName(); {
+	case alias == "":
+		sql.WriteString("UPDATE " + table)
+	case driver == "sqlserver" || driver == "mssql":
+		sql.WriteString("UPDATE " + alias)
+		return " FROM " + table + " AS " + alias
+	default:
+		sql.WriteString("UPDATE " + table + " AS " + alias)
+	}
+	return ""
+}
+
+// writeDeleteTable 写入 DELETE 及目标表，MySQL 和 SQL Server 删除带别名的表时需要使用多表语法
+func (qb *QueryBuilder) writeDeleteTable(sql *strings.Builder) {
+	table, alias := qb.modificationTable()
+	switch driver := qb.getDriverName(); {
+	case alias == "":
+		sql.WriteString("DELETE FROM " + table)
+	case driver == "mysql" || driver == "sqlserver" || driver == "mssql":
+		sql.WriteString("DELETE " + alias + " FROM " + table + " AS " + alias)
+	default:
+		sql.WriteString("DELETE FROM " + table + " AS " + alias)
+	}
+}
+
 // Model 设置关联的模型实例并自动获取表名
 func (qb *QueryBuilder) Model(model interface{}) *QueryBuilder {
 	qb.model = model
@@ -2437,7 +3251,7 @@ func (qb *QueryBuilder) WhereIn(field string, values []interface{}) *QueryBuilde
 		placeholders[i] = "?"
 	}
 
-	sql := fmt.Sprintf("%s IN (%s)", field, strings.Join(placeholders, ", "))
+	sql := fmt.Sprintf("%s IN (%s)", qb.quoteIdentifier(field), strings.Join(placeholders, ", "))
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
 		Raw:    sql,
 		Values: values,
@@ -2457,7 +3271,7 @@ func (qb *QueryBuilder) WhereNotIn(field string, values []interface{}) *QueryBui
 		placeholders[i] = "?"
 	}
 
-	sql := fmt.Sprintf("%s NOT IN (%s)", field, strings.Join(placeholders, ", "))
+	sql := fmt.Sprintf("%s NOT IN (%s)", qb.quoteIdentifier(field), strings.Join(placeholders, ", "))
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
 		Raw:    sql,
 		Values: values,
@@ -2466,40 +3280,102 @@ func (qb *QueryBuilder) WhereNotIn(field string, values []interface{}) *QueryBui
 	return qb
 }
 
-// WhereBetween WHERE BETWEEN条件
+// WhereBetween WHERE BETWEEN条件，values 必须恰好包含两个值，否则记录错误并在执行时返回
+// time.Time 值按 WhereTime 的规则转换到连接时区后绑定
 func (qb *QueryBuilder) WhereBetween(field string, values []interface{}) *QueryBuilder {
-	if len(values) != 2 {
-		return qb
+	return qb.addBetweenCondition("WhereBetween", field, "BETWEEN", values)
+}
+
+// WhereNotBetween WHERE NOT BETWEEN条件，values 必须恰好包含两个值
+func (qb *QueryBuilder) WhereNotBetween(field string, values []interface{}) *QueryBuilder {
+	return qb.addBetweenCondition("WhereNotBetween", field, "NOT BETWEEN", values)
+}
+
+// WhereBetweenColumns 列值介于另外两列之间，如 WhereBetweenColumns("price", "min_price", "max_price")
+// 生成：price BETWEEN min_price AND max_price
+func (qb *QueryBuilder) WhereBetweenColumns(column, minColumn, maxColumn string) *QueryBuilder {
+	for _, name := range []string{column, minColumn, maxColumn} {
+		if err := qb.validateColumnName(name); err != nil {
+			qb.setErr(err)
+			return qb
+		}
 	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:   fmt.Sprintf("%s BETWEEN %s AND %s", qb.quoteIdentifier(column), qb.quoteIdentifier(minColumn), qb.quoteIdentifier(maxColumn)),
+		Logic: "AND",
+	})
+	return qb
+}
 
-	sql := fmt.Sprintf("%s BETWEEN ? AND ?", field)
+// WhereColumn 比较两列的值，如 WhereColumn("orders.user_id", "=", "users.id")
+// 两侧都作为列名处理，不绑定参数，常用于相关子查询和 JOIN 之外的列比较
+func (qb *QueryBuilder) WhereColumn(first, operator, second string) *QueryBuilder {
+	for _, name := range []string{first, second} {
+		if err := qb.validateColumnName(name); err != nil {
+			qb.setErr(err)
+			return qb
+		}
+	}
+	if !operatorRegex.MatchString(strings.ToUpper(operator)) {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "无效的比较运算符: %s", operator).
+			WithContext("column", first))
+		return qb
+	}
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:    sql,
-		Values: values,
-		Logic:  "AND",
+		Raw:   fmt.Sprintf("%s %s %s", qb.quoteIdentifier(first), operator, qb.quoteIdentifier(second)),
+		Logic: "AND",
 	})
 	return qb
 }
 
-// WhereNotBetween WHERE NOT BETWEEN条件
-func (qb *QueryBuilder) WhereNotBetween(field string, values []interface{}) *QueryBuilder {
+// addBetweenCondition 追加 BETWEEN 或 NOT BETWEEN 条件，参数个数不为2时记录错误而不是忽略条件
+func (qb *QueryBuilder) addBetweenCondition(method, field, operator string, values []interface{}) *QueryBuilder {
 	if len(values) != 2 {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "%s 需要恰好两个值，实际为 %d 个", method, len(values)).
+			WithContext("column", field))
 		return qb
 	}
 
-	sql := fmt.Sprintf("%s NOT BETWEEN ? AND ?", field)
+	bound := make([]interface{}, len(values))
+	for i, value := range values {
+		bound[i] = qb.bindTimeValue(value)
+	}
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:    sql,
-		Values: values,
+		Raw:    fmt.Sprintf("%s %s ? AND ?", qb.quoteIdentifier(field), operator),
+		Values: bound,
 		Logic:  "AND",
 	})
 	return qb
 }
 
+// bindTimeValue 将 time.Time 转换到连接配置的时区后按 time.Time 绑定，由驱动按列类型编码，其他值原样返回
+// SQLite 没有时间类型，驱动会将 time.Time 编码为带时区偏移的文本，无法与存储的时间文本比较，因此格式化为字符串
+func (qb *QueryBuilder) bindTimeValue(value interface{}) interface{} {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return value
+		}
+		t = *v
+	default:
+		return value
+	}
+
+	t = t.In(qb.location())
+	switch qb.getDriverName() {
+	case "sqlite", "sqlite3":
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return t
+}
+
 // WhereNull WHERE IS NULL条件
 func (qb *QueryBuilder) WhereNull(field string) *QueryBuilder {
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:   fmt.Sprintf("%s IS NULL", field),
+		Raw:   fmt.Sprintf("%s IS NULL", qb.quoteIdentifier(field)),
 		Logic: "AND",
 	})
 	return qb
@@ -2508,50 +3384,71 @@ func (qb *QueryBuilder) WhereNull(field string) *QueryBuilder {
 // WhereNotNull WHERE IS NOT NULL条件
 func (qb *QueryBuilder) WhereNotNull(field string) *QueryBuilder {
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:   fmt.Sprintf("%s IS NOT NULL", field),
+		Raw:   fmt.Sprintf("%s IS NOT NULL", qb.quoteIdentifier(field)),
 		Logic: "AND",
 	})
 	return qb
 }
 
-// WhereExists WHERE EXISTS条件
-func (qb *QueryBuilder) WhereExists(subQuery interface{}) *QueryBuilder {
-	var sql string
-	var values []interface{}
-
-	switch sq := subQuery.(type) {
-	case string:
-		sql = fmt.Sprintf("EXISTS (%s)", sq)
-	case *QueryBuilder:
-		subSQL, subArgs := sq.buildSelectSQL()
-		sql = fmt.Sprintf("EXISTS (%s)", subSQL)
-		values = subArgs
-	default:
-		sql = fmt.Sprintf("EXISTS (%v)", subQuery)
+// WhereTime 时间比较条件，t 会先转换到连接配置的时区（默认服务器本地时区）再绑定
+// 例如：WhereTime("created_at", ">=", time.Now().Add(-24*time.Hour))
+func (qb *QueryBuilder) WhereTime(column, operator string, t time.Time) *QueryBuilder {
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	if !operatorRegex.MatchString(strings.ToUpper(operator)) {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "WhereTime 不支持的操作符: %s", operator))
+		return qb
 	}
+	return qb.Where(column, operator, qb.bindTimeValue(t))
+}
 
-	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:    sql,
-		Values: values,
-		Logic:  "AND",
-	})
-	return qb
+// buildSubquerySQL 构建内联到外层查询的子查询SQL
+// 占位符保持为 ?，作为外层的原生条件按外层的参数序号统一转换，避免 PostgreSQL 的 $n 与外层冲突
+func (qb *QueryBuilder) buildSubquerySQL() (string, []interface{}) {
+	sub := qb.Clone()
+	sub.rawPlaceholders = true
+	return sub.buildSelectSQL()
 }
 
-// WhereNotExists WHERE NOT EXISTS条件
+// WhereExists WHERE EXISTS条件，subQuery 可以是SQL字符串、*QueryBuilder 或 func(*QueryBuilder)
+// 闭包形式用于相关子查询，子构建器与外层共享连接，通过 From 指定子查询的表，
+// 例如：WhereExists(func(sub *QueryBuilder) { sub.From("orders").WhereColumn("orders.user_id", "=", "users.id") })
+func (qb *QueryBuilder) WhereExists(subQuery interface{}) *QueryBuilder {
+	return qb.addExistsCondition("EXISTS", subQuery)
+}
+
+// WhereNotExists WHERE NOT EXISTS条件，subQuery 的形式与 WhereExists 相同
 func (qb *QueryBuilder) WhereNotExists(subQuery interface{}) *QueryBuilder {
+	return qb.addExistsCondition("NOT EXISTS", subQuery)
+}
+
+// addExistsCondition 追加 EXISTS 或 NOT EXISTS 条件，子查询的参数合并到外层
+func (qb *QueryBuilder) addExistsCondition(keyword string, subQuery interface{}) *QueryBuilder {
 	var sql string
 	var values []interface{}
 
 	switch sq := subQuery.(type) {
 	case string:
-		sql = fmt.Sprintf("NOT EXISTS (%s)", sq)
+		sql = fmt.Sprintf("%s (%s)", keyword, sq)
+	case func(*QueryBuilder):
+		sub := qb.newCorrelatedSubquery()
+		sq(sub)
+		if sub.tableName == "" {
+			qb.setErr(NewErrorf(ErrCodeInvalidParameter, "%s 子查询未指定表名，请在闭包中调用 From", keyword))
+			return qb
+		}
+		return qb.addExistsCondition(keyword, sub)
 	case *QueryBuilder:
-		subSQL, subArgs := sq.buildSelectSQL()
-		sql = fmt.Sprintf("NOT EXISTS (%s)", subSQL)
+		subSQL, subArgs := sq.buildSubquerySQL()
+		sql = fmt.Sprintf("%s (%s)", keyword, subSQL)
 		values = subArgs
+		if sq.err != nil {
+			qb.setErr(sq.err)
+		}
 	default:
-		sql = fmt.Sprintf("NOT EXISTS (%v)", subQuery)
+		sql = fmt.Sprintf("%s (%v)", keyword, subQuery)
 	}
 
 	qb.whereConditions = append(qb.whereConditions, WhereCondition{
@@ -2562,50 +3459,95 @@ func (qb *QueryBuilder) WhereNotExists(subQuery interface{}) *QueryBuilder {
 	return qb
 }
 
+// newCorrelatedSubquery 创建与外层共享连接和引号设置的子构建器，并记录外层表名
+func (qb *QueryBuilder) newCorrelatedSubquery() *QueryBuilder {
+	return &QueryBuilder{
+		connection:      qb.connection,
+		connectionName:  qb.connectionName,
+		transaction:     qb.transaction,
+		timeManager:     qb.timeManager,
+		quoteIdents:     qb.quoteIdents,
+		outerTable:      qb.tableName,
+		whereConditions: make([]WhereCondition, 0, 4),
+		ctx:             context.Background(),
+	}
+}
+
+// OuterTable 返回相关子查询所属外层查询的表名（不含表前缀），非子查询时为空
+// 加了前缀的表以原表名作为别名，因此可以直接用于限定外层的列，如 OuterTable() + ".id"
+func (qb *QueryBuilder) OuterTable() string {
+	return qb.outerTable
+}
+
 // WhereRaw 原生WHERE条件
+// 支持命名参数：WhereRaw("age > :min AND age < :max", map[string]interface{}{"min": 18, "max": 65})
 func (qb *QueryBuilder) WhereRaw(raw string, bindings ...interface{}) *QueryBuilder {
-	qb.whereConditions = append(qb.whereConditions, WhereCondition{
-		Raw:    raw,
-		Values: bindings,
-		Logic:  "AND",
-	})
+	qb.whereConditions = append(qb.whereConditions, qb.rawCondition(raw, bindings, "AND"))
 	return qb
 }
 
-// SelectRaw 原生SELECT语句
+// SelectRaw 原生SELECT表达式，? 按顺序绑定 bindings，也支持 WhereRaw 的命名参数
+// 参数排在WHERE参数之前，如 SelectRaw("price * ? AS total", 1.1)
+// 原生表达式输出在 Select 指定的列之后
 func (qb *QueryBuilder) SelectRaw(raw string, bindings ...interface{}) *QueryBuilder {
-	// 这里需要处理原生SQL和绑定参数，暂时简单处理
-	qb.selectColumns = append(qb.selectColumns, raw)
+	expr := qb.rawCondition(raw, bindings, "")
+	qb.selectRaw = append(qb.selectRaw, RawExpression{SQL: expr.Raw, Values: expr.Values})
 	return qb
 }
 
-// FieldRaw 原生字段表达式
+// FieldRaw 原生字段表达式，与 SelectRaw 相同
 func (qb *QueryBuilder) FieldRaw(raw string, bindings ...interface{}) *QueryBuilder {
-	// FieldRaw用于添加复杂字段表达式
-	// 注意：当前实现不支持参数绑定，bindings参数保留以便将来扩展
-	// 建议直接在raw中包含完整的表达式，或使用SelectRaw
+	return qb.SelectRaw(raw, bindings...)
+}
 
-	// 添加原生字段表达式到选择列
-	qb.selectColumns = append(qb.selectColumns, raw)
+// LockForUpdate 排他锁（SELECT ... FOR UPDATE），只能在事务中使用，事务外执行查询时返回错误
+func (qb *QueryBuilder) LockForUpdate() *QueryBuilder {
+	qb.lockMode = "update"
+	qb.warnUnsupportedLock()
 	return qb
 }
 
-// Distinct 去重查询
-func (qb *QueryBuilder) Distinct() *QueryBuilder {
-	// 修改第一个选择列为DISTINCT
-	if len(qb.selectColumns) > 0 {
-		qb.selectColumns[0] = "DISTINCT " + qb.selectColumns[0]
-	} else {
-		qb.selectColumns = []string{"DISTINCT *"}
+// SharedLock 共享锁（LOCK IN SHARE MODE / FOR SHARE），只能在事务中使用，事务外执行查询时返回错误
+func (qb *QueryBuilder) SharedLock() *QueryBuilder {
+	qb.lockMode = "share"
+	qb.warnUnsupportedLock()
+	return qb
+}
+
+// checkLockTransaction 事务外使用锁时记录错误，避免查询在没有锁的情况下静默执行
+// 锁可以在 InTransaction 之前设置，因此在构建和执行查询时检查
+func (qb *QueryBuilder) checkLockTransaction() {
+	if qb.lockMode != "" && qb.transaction == nil {
+		qb.setErr(NewError(ErrCodeInvalidParameter, "LockForUpdate 和 SharedLock 只能在事务中使用，请先调用 InTransaction").
+			WithContext("table", qb.tableName).
+			WithContext("lock", qb.lockMode))
 	}
+}
+
+// warnUnsupportedLock 对不支持行级锁的数据库输出警告
+func (qb *QueryBuilder) warnUnsupportedLock() {
+	switch qb.getDriverName() {
+	case "sqlite", "sqlite3":
+		LogError(NewError(ErrCodeNotImplemented, "SQLite不支持行级锁，锁定子句将被忽略").
+			WithContext("table", qb.tableName).
+			WithContext("lock", qb.lockMode))
+	}
+}
+
+// Distinct 去重查询，生成 SELECT DISTINCT col1, col2, ...，对整个选择列表去重
+// 可以在 Select 之前或之后调用；Count 统计去重后的行数
+func (qb *QueryBuilder) Distinct() *QueryBuilder {
+	qb.distinct = true
 	return qb
 }
 
-// OrderByRaw 原生排序
+// OrderByRaw 原生排序，表达式中的 ? 按顺序绑定 bindings，如 OrderByRaw("FIELD(status, ?, ?)", "a", "b")
 func (qb *QueryBuilder) OrderByRaw(raw string, bindings ...interface{}) *QueryBuilder {
 	qb.orderByColumns = append(qb.orderByColumns, OrderByClause{
 		Column:    raw,
 		Direction: "", // 原生SQL不需要方向
+		Bindings:  bindings,
+		Raw:       true,
 	})
 	return qb
 }
@@ -2635,40 +3577,37 @@ func (qb *QueryBuilder) OrderRand() *QueryBuilder {
 	return qb
 }
 
-// OrderField 字段排序
+// OrderField 字段排序，按values给定的顺序排列，值以参数绑定方式传入
 func (qb *QueryBuilder) OrderField(field string, values []interface{}, direction string) *QueryBuilder {
-	// 生成FIELD()或CASE WHEN排序
-	driverName := qb.getDriverName()
-
-	if driverName == "mysql" {
-		// MySQL使用FIELD()函数，直接生成完整SQL
-		valueParts := make([]string, len(values)+1)
-		valueParts[0] = field
-		for i, value := range values {
-			// 将值直接嵌入SQL（安全性：这里的值通常是预定义的枚举值）
-			valueParts[i+1] = fmt.Sprintf("'%v'", value)
-		}
-		orderExpr := fmt.Sprintf("FIELD(%s)", strings.Join(valueParts, ", "))
+	if len(values) == 0 || qb.validateColumnName(field) != nil {
+		return qb
+	}
 
-		qb.orderByColumns = append(qb.orderByColumns, OrderByClause{
-			Column:    orderExpr,
-			Direction: direction,
-		})
+	// 生成FIELD()或CASE WHEN排序
+	var orderExpr string
+	if qb.getDriverName() == "mysql" {
+		// MySQL使用FIELD()函数
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		orderExpr = fmt.Sprintf("FIELD(%s, %s)", field, placeholders)
 	} else {
-		// 其他数据库使用CASE WHEN，直接生成完整SQL
+		// 其他数据库使用CASE WHEN
 		var caseSQL strings.Builder
 		caseSQL.WriteString("CASE ")
-		for i, value := range values {
-			caseSQL.WriteString(fmt.Sprintf("WHEN %s = '%v' THEN %d ", field, value, i))
+		for i := range values {
+			caseSQL.WriteString(fmt.Sprintf("WHEN %s = ? THEN %d ", field, i))
 		}
 		caseSQL.WriteString("ELSE 999 END")
-
-		qb.orderByColumns = append(qb.orderByColumns, OrderByClause{
-			Column:    caseSQL.String(),
-			Direction: direction,
-		})
+		orderExpr = caseSQL.String()
 	}
 
+	bindings := make([]interface{}, len(values))
+	copy(bindings, values)
+	qb.orderByColumns = append(qb.orderByColumns, OrderByClause{
+		Column:    orderExpr,
+		Direction: direction,
+		Bindings:  bindings,
+	})
+
 	return qb
 }
 
@@ -2685,16 +3624,83 @@ func (qb *QueryBuilder) WithContext(ctx context.Context) *QueryBuilder {
 	return qb
 }
 
-// WithTimeout 设置超时
+// WithTimeout 设置下一次执行的超时时间，执行完成后释放计时器并恢复原上下文
 func (qb *QueryBuilder) WithTimeout(timeout time.Duration) *QueryBuilder {
-	ctx, cancel := context.WithTimeout(qb.ctx, timeout)
-	// 注意：这里我们保存cancel函数，但实际使用时需要在适当时机调用
-	// 对于查询构建器，通常在查询执行完成后调用cancel
-	_ = cancel // 暂时忽略warning，实际项目中需要合理管理
+	qb.releaseTimeout()
+	parent := qb.baseContext()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	qb.parentCtx = parent
+	qb.cancel = cancel
 	qb.ctx = ctx
 	return qb
 }
 
+// context 获取执行使用的上下文
+// 未通过 WithTimeout 设置超时且上下文没有截止时间时，应用连接配置的 QueryTimeout，执行完成后由 releaseTimeout 释放
+func (qb *QueryBuilder) context() context.Context {
+	ctx := qb.baseContext()
+	if qb.cancel != nil {
+		return ctx
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx
+	}
+
+	timeout := qb.defaultQueryTimeout()
+	if timeout <= 0 {
+		return ctx
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	qb.parentCtx = qb.ctx
+	qb.cancel = cancel
+	qb.ctx = timeoutCtx
+	return timeoutCtx
+}
+
+// baseContext 获取设置的上下文，未设置时为 context.Background()
+func (qb *QueryBuilder) baseContext() context.Context {
+	if qb.ctx == nil {
+		return context.Background()
+	}
+	return qb.ctx
+}
+
+// defaultQueryTimeout 获取连接配置的默认查询超时
+func (qb *QueryBuilder) defaultQueryTimeout() time.Duration {
+	conn, err := qb.getConnection()
+	if err != nil || conn.GetConfig() == nil {
+		return 0
+	}
+	return conn.GetConfig().QueryTimeout
+}
+
+// releaseTimeout 释放WithTimeout创建的计时器并恢复原上下文
+func (qb *QueryBuilder) releaseTimeout() {
+	if qb.cancel == nil {
+		return
+	}
+	qb.cancel()
+	qb.cancel = nil
+	qb.ctx = qb.parentCtx
+	qb.parentCtx = nil
+}
+
+// beginExecution 标记构建器开始执行，构建器已在其他goroutine中执行时返回错误
+// 调用方在返回前执行 endExecution
+func (qb *QueryBuilder) beginExecution() error {
+	if !atomic.CompareAndSwapInt32(&qb.executing, 0, 1) {
+		return NewError(ErrCodeQueryFailed, "查询构建器正在其他goroutine中执行，并发复用时请先调用 Clone").
+			WithContext("table", qb.tableName)
+	}
+	return nil
+}
+
+// endExecution 释放执行超时并清除执行标记
+func (qb *QueryBuilder) endExecution() {
+	qb.releaseTimeout()
+	atomic.StoreInt32(&qb.executing, 0)
+}
+
 // Find 根据条件查找（支持访问器处理）
 func (qb *QueryBuilder) Find(args ...interface{}) (map[string]interface{}, error) {
 	// 支持 Find(id) 或 Find(dest) 模式
@@ -2707,23 +3713,28 @@ func (qb *QueryBuilder) Find(args ...interface{}) (map[string]interface{}, error
 }
 
 // Last 获取最后一条记录（支持访问器处理）
+// 在克隆的构建器上反转排序，不修改当前构建器
 func (qb *QueryBuilder) Last() (map[string]interface{}, error) {
+	defer qb.releaseTimeout()
+
+	query := qb.Clone()
+
 	// 反转排序以获取最后一条记录
-	if len(qb.orderByColumns) == 0 {
+	if len(query.orderByColumns) == 0 {
 		// 如果没有排序，默认按id降序
-		qb.OrderBy("id", "DESC")
+		query.OrderBy("id", "DESC")
 	} else {
 		// 反转现有排序
-		for i := range qb.orderByColumns {
-			if qb.orderByColumns[i].Direction == "ASC" {
-				qb.orderByColumns[i].Direction = "DESC"
+		for i := range query.orderByColumns {
+			if query.orderByColumns[i].Direction == "ASC" {
+				query.orderByColumns[i].Direction = "DESC"
 			} else {
-				qb.orderByColumns[i].Direction = "ASC"
+				query.orderByColumns[i].Direction = "ASC"
 			}
 		}
 	}
 
-	return qb.First()
+	return query.First()
 }
 
 // Exists 检查记录是否存在
@@ -2736,19 +3747,31 @@ func (qb *QueryBuilder) Exists() (bool, error) {
 }
 
 // InsertBatch 批量插入数据
+// 数据按块拆分为多条INSERT语句，保证每条语句的行数和占位符数量不超过限制；
+// 拆分为多条语句且不在事务中时，自动在事务中执行，任一块失败则全部回滚
 func (qb *QueryBuilder) InsertBatch(data []map[string]interface{}) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
 	if len(data) == 0 {
 		return 0, nil
 	}
 
+	columns := qb.prepareBatchInsert(data)
+	return qb.insertChunks(columns, data, qb.insertChunkRows(len(columns)), false)
+}
+
+// prepareBatchInsert 处理时间字段并返回所有行的列名，排序保证每块生成的SQL一致
+func (qb *QueryBuilder) prepareBatchInsert(data []map[string]interface{}) []string {
 	// 处理时间字段
 	if qb.timeManager != nil && len(qb.timeFields) > 0 {
 		for i, row := range data {
-			data[i] = qb.timeManager.ProcessInsertData(row, qb.timeFields)
+			data[i] = qb.timeFieldManager().ProcessInsertData(row, qb.timeFields)
 		}
 	}
 
-	// 获取所有列名
 	columnSet := make(map[string]bool)
 	for _, row := range data {
 		for column := range row {
@@ -2760,58 +3783,179 @@ func (qb *QueryBuilder) InsertBatch(data []map[string]interface{}) (int64, error
 	for column := range columnSet {
 		columns = append(columns, column)
 	}
+	sort.Strings(columns)
+	return columns
+}
 
-	// 构建SQL
-	var sql strings.Builder
-	sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ",
-		qb.tableName, strings.Join(columns, ", ")))
+// insertChunks 按块插入数据并累加影响行数，多块且不在事务中时在事务中执行
+func (qb *QueryBuilder) insertChunks(columns []string, data []map[string]interface{}, chunkSize int, ignore bool) (int64, error) {
+	var total int64
+	err := qb.eachInsertChunk(len(data), chunkSize, func(tx TransactionInterface, start, end int) error {
+		affected, err := qb.insertBatchChunk(tx, columns, data[start:end], ignore)
+		total += affected
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
 
-	// 构建VALUES部分
-	var args []interface{}
-	valueParts := make([]string, len(data))
+// eachInsertChunk 按块调用 fn，多块且不在事务中时在事务中执行，任一块失败则回滚
+func (qb *QueryBuilder) eachInsertChunk(count, chunkSize int, fn func(tx TransactionInterface, start, end int) error) error {
+	if count <= chunkSize {
+		return fn(qb.transaction, 0, count)
+	}
 
-	for i, row := range data {
-		placeholders := make([]string, len(columns))
-		for j, column := range columns {
-			if value, exists := row[column]; exists {
-				args = append(args, value)
-			} else {
-				args = append(args, nil)
-			}
+	tx := qb.transaction
+	ownTx := tx == nil && !qb.dryRun
+	if ownTx {
+		conn, err := qb.getConnection()
+		if err != nil {
+			return err
+		}
+		tx, err = conn.Begin()
+		if err != nil {
+			return WrapError(err, ErrCodeTransactionFailed, "开始事务失败")
+		}
+	}
 
-			// 根据数据库类型生成占位符
-			driverName := qb.getDriverName()
-			if driverName == "postgres" {
-				placeholders[j] = fmt.Sprintf("$%d", len(args))
-			} else {
-				placeholders[j] = "?"
+	var statements []string
+	var statementArgs []interface{}
+	for start := 0; start < count; start += chunkSize {
+		end := start + chunkSize
+		if end > count {
+			end = count
+		}
+		if err := fn(tx, start, end); err != nil {
+			if ownTx {
+				tx.Rollback()
 			}
+			return err
+		}
+		if qb.dryRun {
+			statements = append(statements, qb.lastSQL)
+			statementArgs = append(statementArgs, qb.lastArgs...)
 		}
-		valueParts[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
 	}
 
-	sql.WriteString(strings.Join(valueParts, ", "))
+	// 演练模式记录每一块的语句，以分号分隔
+	if qb.dryRun {
+		qb.recordSQL(strings.Join(statements, "; "), statementArgs)
+		return nil
+	}
+	if ownTx {
+		if err := tx.Commit(); err != nil {
+			return WrapError(err, ErrCodeTransactionCommitFailed, "提交事务失败")
+		}
+	}
+	return nil
+}
+
+// SetInsertChunkSize 设置 InsertBatch 每条INSERT语句包含的最大行数，n <= 0 时使用 InsertBatchChunkSize
+func (qb *QueryBuilder) SetInsertChunkSize(n int) *QueryBuilder {
+	qb.insertChunkSize = n
+	return qb
+}
+
+// insertChunkRows 计算每块的行数：不超过设置的块大小，且占位符总数不超过数据库限制
+func (qb *QueryBuilder) insertChunkRows(columnCount int) int {
+	size := qb.insertChunkSize
+	if size <= 0 {
+		size = InsertBatchChunkSize
+	}
+	if size <= 0 {
+		size = 1000
+	}
+	if columnCount > 0 {
+		if limit := maxPlaceholders(qb.getDriverName()) / columnCount; limit < size {
+			size = limit
+		}
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// maxPlaceholders 单条语句允许的最大占位符数量
+func maxPlaceholders(driver string) int {
+	switch driver {
+	case "sqlserver", "mssql":
+		return 2100
+	case "sqlite", "sqlite3":
+		return 32766
+	default:
+		// MySQL 和 PostgreSQL 均为 65535
+		return 65535
+	}
+}
+
+// insertBatchChunk 用一条INSERT语句插入一块数据，ignore为true时跳过违反唯一约束的行
+func (qb *QueryBuilder) insertBatchChunk(tx TransactionInterface, columns []string, data []map[string]interface{}, ignore bool) (int64, error) {
+	sqlStr, args := qb.buildBatchInsertSQL(columns, data, ignore)
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
 
 	// 执行插入
-	var result interface{}
+	var result interface{ RowsAffected() (int64, error) }
 	var err error
 
-	if qb.transaction != nil {
-		result, err = qb.transaction.Exec(sql.String(), args...)
+	if tx != nil {
+		result, err = execTxWithContext(qb.context(), tx, sqlStr, args...)
 	} else {
-		result, err = qb.connection.Exec(sql.String(), args...)
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return 0, connErr
+		}
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
 	}
 
 	if err != nil {
-		return 0, err
+		return 0, WrapError(err, ErrCodeQueryFailed, "批量插入失败").
+			WithContext("table", qb.tableName).
+			WithContext("rows", len(data))
 	}
 
 	// 返回影响的行数
-	if sqlResult, ok := result.(interface{ RowsAffected() (int64, error) }); ok {
-		return sqlResult.RowsAffected()
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return int64(len(data)), nil
 	}
+	return affected, nil
+}
+
+// buildBatchInsertSQL 构建插入一块数据的多行INSERT语句，ignore为true时改写为跳过重复键的形式
+func (qb *QueryBuilder) buildBatchInsertSQL(columns []string, data []map[string]interface{}, ignore bool) (string, []interface{}) {
+	var sql strings.Builder
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = qb.quoteIdentifier(column)
+	}
+	sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ",
+		qb.quoteIdentifier(qb.fullTableName()), strings.Join(quotedColumns, ", ")))
+
+	// 构建VALUES部分
+	args := make([]interface{}, 0, len(data)*len(columns))
+	valueParts := make([]string, len(data))
 
-	return int64(len(data)), nil
+	for i, row := range data {
+		placeholders := make([]string, len(columns))
+		for j, column := range columns {
+			// 根据数据库类型生成占位符
+			placeholders[j] = qb.buildPlaceholder(len(args))
+			args = append(args, row[column])
+		}
+		valueParts[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
+	}
+
+	sql.WriteString(strings.Join(valueParts, ", "))
+	sqlStr := sql.String()
+	if ignore {
+		sqlStr = qb.insertIgnoreSQL(sqlStr)
+	}
+	return sqlStr, args
 }
 
 // Exp 高级表达式
@@ -2829,39 +3973,85 @@ func (qb *QueryBuilder) Exp(field string, expression string, bindings ...interfa
 // ToSQL 构建SQL语句
 func (qb *QueryBuilder) ToSQL() (string, []interface{}, error) {
 	sql, args := qb.buildSelectSQL()
-	return sql, args, nil
+	return sql, args, qb.err
+}
+
+// DryRun 开启演练模式，Insert/Update/Delete 等写操作只生成SQL并返回 0, nil，不访问数据库
+// 生成的SQL通过 LastSQL 获取
+func (qb *QueryBuilder) DryRun() *QueryBuilder {
+	qb.dryRun = true
+	return qb
+}
+
+// LastSQL 返回最近一次写操作生成的SQL和参数
+func (qb *QueryBuilder) LastSQL() (string, []interface{}) {
+	return qb.lastSQL, qb.lastArgs
 }
 
-// Clone 克隆查询构建器
+// recordSQL 记录写操作生成的SQL，返回是否处于演练模式（调用方应跳过执行）
+func (qb *QueryBuilder) recordSQL(sqlStr string, args []interface{}) bool {
+	qb.lastSQL = sqlStr
+	qb.lastArgs = args
+	return qb.dryRun
+}
+
+// Clone 克隆查询构建器，返回的副本与原构建器互不影响，可以在其他goroutine中继续追加条件并执行
+// 连接和时间字段管理器在副本间共享，二者只读，时区按每次执行复制到新的管理器上
+// Clone 只读取原构建器，多个goroutine可以同时从同一个基础查询克隆：
+//
+//	base := db.Table("users").Where("status", "=", "active")
+//	go func() { base.Clone().Where("age", ">", 18).Get() }()
 func (qb *QueryBuilder) Clone() *QueryBuilder {
 	newBuilder := &QueryBuilder{
 		connection:       qb.connection,
+		connectionName:   qb.connectionName,
 		tableName:        qb.tableName,
 		model:            qb.model,
 		selectColumns:    make([]string, len(qb.selectColumns)),
+		selectRaw:        make([]RawExpression, len(qb.selectRaw)),
 		whereConditions:  make([]WhereCondition, len(qb.whereConditions)),
 		joinClauses:      make([]JoinClause, len(qb.joinClauses)),
 		orderByColumns:   make([]OrderByClause, len(qb.orderByColumns)),
 		groupByColumns:   make([]string, len(qb.groupByColumns)),
 		havingConditions: make([]WhereCondition, len(qb.havingConditions)),
+		globalScopes:     make([]globalScope, len(qb.globalScopes)),
 		limitCount:       qb.limitCount,
 		offsetCount:      qb.offsetCount,
 		transaction:      qb.transaction,
+		lockMode:         qb.lockMode,
 		cacheEnabled:     qb.cacheEnabled,
 		cacheTTL:         qb.cacheTTL,
 		cacheTags:        make([]string, len(qb.cacheTags)),
 		cacheKey:         qb.cacheKey,
+		cacheRefresh:     qb.cacheRefresh,
+		timeManager:      qb.timeManager,
+		timeFields:       make([]TimeFieldInfo, len(qb.timeFields)),
+		rawTable:         qb.rawTable,
+		err:              qb.err,
+		rawPlaceholders:  qb.rawPlaceholders,
+		insertChunkSize:  qb.insertChunkSize,
+		collation:        qb.collation,
+		quoteIdents:      qb.quoteIdents,
+		chunkedIn:        qb.chunkedIn,
+		ctes:             make([]commonTableExpr, len(qb.ctes)),
+		outerTable:       qb.outerTable,
+		distinct:         qb.distinct,
+		dryRun:           qb.dryRun,
 		ctx:              qb.ctx,
 	}
 
 	// 复制切片内容
 	copy(newBuilder.selectColumns, qb.selectColumns)
+	copy(newBuilder.selectRaw, qb.selectRaw)
 	copy(newBuilder.whereConditions, qb.whereConditions)
 	copy(newBuilder.joinClauses, qb.joinClauses)
 	copy(newBuilder.orderByColumns, qb.orderByColumns)
 	copy(newBuilder.groupByColumns, qb.groupByColumns)
 	copy(newBuilder.havingConditions, qb.havingConditions)
+	copy(newBuilder.globalScopes, qb.globalScopes)
 	copy(newBuilder.cacheTags, qb.cacheTags)
+	copy(newBuilder.timeFields, qb.timeFields)
+	copy(newBuilder.ctes, qb.ctes)
 
 	return newBuilder
 }
@@ -2886,18 +4076,78 @@ func (qb *QueryBuilder) WithModel(model interface{}) *QueryBuilder {
 	return qb
 }
 
-// InsertModel 插入模型实例
+// InsertModel 插入模型实例，返回插入ID
+// 零值字段不会写入（由数据库默认值处理），自动处理创建/更新时间字段，自增ID会回写到模型
 func (qb *QueryBuilder) InsertModel(model interface{}) (int64, error) {
-	// 这里需要将模型转换为map[string]interface{}
-	// 暂时返回错误，需要反射处理
-	return 0, fmt.Errorf("InsertModel not implemented yet")
+	structValue, err := modelStructValue(model)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := modelToMap(structValue)
+	if err != nil {
+		return 0, err
+	}
+	if qb.timeManager != nil {
+		data = qb.timeFieldManager().ProcessInsertData(data, qb.timeManager.AnalyzeModelTimeFields(model))
+	}
+
+	query := qb.Clone()
+	if query.tableName == "" {
+		query.tableName = getTableNameFromModel(model)
+	}
+
+	id, err := query.Insert(data)
+	qb.lastSQL, qb.lastArgs = query.LastSQL()
+	if err != nil || qb.dryRun {
+		return 0, err
+	}
+
+	if keys := modelPrimaryKeys(structValue.Type()); id > 0 && len(keys) == 1 && structValue.CanAddr() {
+		setModelPrimaryKey(structValue, keys[0], id)
+	}
+	return id, nil
 }
 
-// UpdateModel 更新模型实例
+// UpdateModel 按模型主键更新模型实例，返回受影响行数
+// 零值字段不会更新（需要置零请使用 Update），自动处理更新时间字段
 func (qb *QueryBuilder) UpdateModel(model interface{}) (int64, error) {
-	// 这里需要将模型转换为map[string]interface{}
-	// 暂时返回错误，需要反射处理
-	return 0, fmt.Errorf("UpdateModel not implemented yet")
+	structValue, err := modelStructValue(model)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := modelToMap(structValue)
+	if err != nil {
+		return 0, err
+	}
+
+	query := qb.Clone()
+	if query.tableName == "" {
+		query.tableName = getTableNameFromModel(model)
+	}
+
+	for _, key := range modelPrimaryKeys(structValue.Type()) {
+		value, exists := data[key]
+		if !exists {
+			return 0, NewError(ErrCodeInvalidParameter, "主键值不能为空").
+				WithContext("primary_key", key).
+				WithContext("table", query.tableName)
+		}
+		query.Where(key, "=", value)
+		delete(data, key)
+	}
+
+	if qb.timeManager != nil {
+		data = qb.timeFieldManager().ProcessUpdateData(data, qb.timeManager.AnalyzeModelTimeFields(model))
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	affected, err := query.Update(data)
+	qb.lastSQL, qb.lastArgs = query.LastSQL()
+	return affected, err
 }
 
 // validateTableName 验证表名
@@ -3017,6 +4267,11 @@ func (qb *QueryBuilder) isValidSQLExpression(expr string) bool {
 		}
 	}
 
+	// 带表名前缀的通配符，如 posts.*
+	if tableWildcardRegex.MatchString(expr) {
+		return true
+	}
+
 	// 检查是否是简单的列名（字母、数字、下划线、点号）
 	re := regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*(\s+AS\s+[a-zA-Z_][a-zA-Z0-9_]*)?$`)
 	return re.MatchString(expr)
@@ -3024,21 +4279,19 @@ func (qb *QueryBuilder) isValidSQLExpression(expr string) bool {
 
 // containsDangerousChars 检查是否包含危险字符
 func (qb *QueryBuilder) containsDangerousChars(input string) bool {
-	// 危险字符和SQL关键字
+	// 危险字符
 	dangerousPatterns := []string{
 		";", "--", "/*", "*/", "'", "\"", "\\",
-		"DROP", "DELETE", "UPDATE", "INSERT", "ALTER", "CREATE",
-		"TRUNCATE", "EXEC", "EXECUTE", "SCRIPT", "UNION", "SELECT",
 	}
 
-	upperInput := strings.ToUpper(input)
 	for _, pattern := range dangerousPatterns {
-		if strings.Contains(upperInput, pattern) {
+		if strings.Contains(input, pattern) {
 			return true
 		}
 	}
 
-	return false
+	// SQL关键字按完整单词匹配，避免误伤 created_at、updated_by 等列名
+	return dangerousKeywordRegex.MatchString(input)
 }
 
 // sanitizeOperator 清理操作符
@@ -3068,6 +4321,57 @@ func (qb *QueryBuilder) sanitizeOperator(operator string) string {
 	return "="
 }
 
+// isListOperator 判断操作符的值是否为列表，IN 和 BETWEEN 需要展开为多个占位符
+func isListOperator(operator string) bool {
+	switch operator {
+	case "IN", "NOT IN", "BETWEEN", "NOT BETWEEN":
+		return true
+	}
+	return false
+}
+
+// listCondition 构建 IN / BETWEEN 条件，切片值展开为 IN (?, ?, ...) 和 BETWEEN ? AND ?
+// column 为已引用的列或表达式；空列表的 IN 不匹配任何行，NOT IN 匹配所有行
+func (qb *QueryBuilder) listCondition(column, operator string, value interface{}, logic string) WhereCondition {
+	values := []interface{}{value}
+	if qb.isSliceOrArray(value) {
+		values = qb.convertToInterfaceSlice(value)
+	}
+	for i, v := range values {
+		values[i] = qb.bindTimeValue(v)
+	}
+
+	switch operator {
+	case "BETWEEN", "NOT BETWEEN":
+		if len(values) != 2 {
+			qb.setErr(NewErrorf(ErrCodeInvalidParameter, "%s 需要恰好两个值，实际为 %d 个，可使用 WhereBetween", operator, len(values)).
+				WithContext("column", column))
+			return WhereCondition{Raw: "1 = 0", Logic: logic}
+		}
+		return WhereCondition{Raw: fmt.Sprintf("%s %s ? AND ?", column, operator), Values: values, Logic: logic}
+	default:
+		if len(values) == 0 {
+			if operator == "IN" {
+				return WhereCondition{Raw: "1 = 0", Logic: logic}
+			}
+			return WhereCondition{Raw: "1 = 1", Logic: logic}
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		return WhereCondition{Raw: fmt.Sprintf("%s %s (%s)", column, operator, placeholders), Values: values, Logic: logic}
+	}
+}
+
+// checkedOperator 校验并规范化条件中的比较操作符
+// 不支持的操作符记录错误并替换为 =，保证其不会被拼接进SQL
+func (qb *QueryBuilder) checkedOperator(operator string) string {
+	sanitized := qb.sanitizeOperator(operator)
+	if sanitized == "" || sanitized != strings.ToUpper(strings.TrimSpace(operator)) {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "不支持的操作符: %s", operator))
+		return "="
+	}
+	return sanitized
+}
+
 // sanitizeDirection 清理排序方向
 func (qb *QueryBuilder) sanitizeDirection(direction string) string {
 	upperDirection := strings.ToUpper(strings.TrimSpace(direction))
@@ -3130,9 +4434,47 @@ func (qb *QueryBuilder) escapeValue(value interface{}) string {
 	return str
 }
 
-// FindModel 查找并填充模型
+// FindModel 按主键查找并填充模型，model 必须是结构体指针
+// 主键列取自 torm:"primary_key" 标签（默认 id），复合主键时 id 传入 map[string]interface{}
+// 未找到记录时返回 ErrRecordNotFound
 func (qb *QueryBuilder) FindModel(id interface{}, model interface{}) error {
-	// 这里需要将查询结果填充到模型中
-	// 暂时返回错误，需要反射处理
-	return fmt.Errorf("FindModel not implemented yet")
+	if id == nil {
+		return NewError(ErrCodeInvalidParameter, "主键值不能为空")
+	}
+	structValue, err := modelStructValue(model)
+	if err != nil {
+		return err
+	}
+	if reflect.ValueOf(model).Kind() != reflect.Ptr {
+		return NewError(ErrCodeInvalidParameter, "模型必须是结构体指针").
+			WithContext("type", fmt.Sprintf("%T", model))
+	}
+
+	query := qb.Clone()
+	if query.tableName == "" {
+		query.tableName = getTableNameFromModel(model)
+	}
+
+	keys := modelPrimaryKeys(structValue.Type())
+	if values, ok := id.(map[string]interface{}); ok {
+		for _, key := range keys {
+			value, exists := values[key]
+			if !exists {
+				return NewError(ErrCodeInvalidParameter, "主键值不能为空").WithContext("primary_key", key)
+			}
+			query.Where(key, "=", value)
+		}
+	} else {
+		if len(keys) > 1 {
+			return NewError(ErrCodeInvalidParameter, "复合主键需要传入 map[string]interface{}").
+				WithContext("primary_keys", keys)
+		}
+		query.Where(keys[0], "=", id)
+	}
+
+	row, err := query.FirstRaw()
+	if err != nil {
+		return err
+	}
+	return LoadModel(row, model)
 }
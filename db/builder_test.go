@@ -0,0 +1,3040 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupTestDB 创建内存SQLite测试连接，返回连接和连接名
+func setupTestDB(t *testing.T) (ConnectionInterface, string) {
+	t.Helper()
+
+	connName := fmt.Sprintf("test_%s", t.Name())
+	err := AddConnection(connName, &Config{
+		Driver:       "sqlite",
+		Database:     ":memory:",
+		MaxOpenConns: 1,
+	})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	// 连接管理器限制连接数量，测试结束后移除连接
+	t.Cleanup(func() {
+		RemoveConnection(connName)
+	})
+
+	conn, err := DB(connName)
+	if err != nil {
+		t.Fatalf("DB failed: %v", err)
+	}
+	return conn, connName
+}
+
+// setupTestTable 创建基于内存SQLite的测试表并返回查询构建器工厂
+func setupTestTable(t *testing.T, rows []map[string]interface{}) func() *QueryBuilder {
+	t.Helper()
+
+	conn, connName := setupTestDB(t)
+	_, err := conn.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status TEXT, age INTEGER)")
+	if err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	for _, row := range rows {
+		_, err := conn.Exec("INSERT INTO users (name, status, age) VALUES (?, ?, ?)", row["name"], row["status"], row["age"])
+		if err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+	}
+
+	return func() *QueryBuilder {
+		qb, err := Table("users", connName)
+		if err != nil {
+			t.Fatalf("Table failed: %v", err)
+		}
+		return qb
+	}
+}
+
+// testUsers 测试数据
+var testUsers = []map[string]interface{}{
+	{"name": "alice", "status": "active", "age": 20},
+	{"name": "bob", "status": "active", "age": 25},
+	{"name": "carol", "status": "banned", "age": 30},
+	{"name": "dave", "status": "pending", "age": 25},
+}
+
+// 测试GROUP BY时Count统计分组数量
+func TestCountWithGroupBy(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	count, err := table().Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected count 4, got %d", count)
+	}
+
+	count, err = table().GroupBy("status").Count()
+	if err != nil {
+		t.Fatalf("grouped Count failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 groups, got %d", count)
+	}
+
+	count, err = table().Where("age", ">", 20).GroupBy("status").Count()
+	if err != nil {
+		t.Fatalf("grouped Count with where failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 groups, got %d", count)
+	}
+}
+
+// 测试CountDistinct单列和多列去重统计
+func TestCountDistinct(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	count, err := table().CountDistinct("status")
+	if err != nil {
+		t.Fatalf("CountDistinct failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 distinct status, got %d", count)
+	}
+
+	count, err = table().CountDistinct("status", "age")
+	if err != nil {
+		t.Fatalf("CountDistinct with multiple columns failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 distinct (status, age), got %d", count)
+	}
+
+	if _, err := table().CountDistinct(); err == nil {
+		t.Error("Expected error when no column given")
+	}
+}
+
+// 测试Distinct作用于整个选择列表，Count统计去重后的行数
+func TestDistinct(t *testing.T) {
+	sql, _, err := newFakeBuilder("mysql", "users").Select("status", "age").Distinct().ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if sql != "SELECT DISTINCT status, age FROM users" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	// 在 Select 之前调用，以及没有选择列时
+	sql, _, _ = newFakeBuilder("mysql", "users").Distinct().Select("status").ToSQL()
+	if sql != "SELECT DISTINCT status FROM users" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	sql, _, _ = newFakeBuilder("mysql", "users").Distinct().ToSQL()
+	if sql != "SELECT DISTINCT * FROM users" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	sql, _ = newFakeBuilder("postgres", "users").Select("status").Distinct().Where("age", ">", 18).buildCountSQL()
+	if sql != "SELECT COUNT(*) as count FROM (SELECT DISTINCT status FROM users WHERE age > $1) torm_count" {
+		t.Errorf("Unexpected count SQL: %s", sql)
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().Select("status", "age").Distinct().Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Errorf("Expected 4 distinct (status, age) rows, got %d", len(rows))
+	}
+
+	count, err := table().Select("status").Distinct().Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 distinct status, got %d", count)
+	}
+}
+
+// 测试基础查询在多个goroutine中克隆复用，以及同一构建器被同时执行时返回错误
+func TestConcurrentBaseQuery(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	base := table().Where("status", "=", "active").OrderBy("id", "ASC").Limit(10)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rows, err := base.Clone().Where("age", "=", 25).Get()
+			if err == nil && len(rows) != 1 {
+				err = fmt.Errorf("expected 1 row, got %d", len(rows))
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			count, err := base.Count()
+			if err == nil && count != 2 {
+				err = fmt.Errorf("expected count 2, got %d", count)
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if _, err := base.First(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if base.limitCount != 10 || len(base.selectColumns) != 0 || len(base.whereConditions) != 1 {
+		t.Errorf("Expected base query to be unchanged, got limit=%d select=%v where=%d",
+			base.limitCount, base.selectColumns, len(base.whereConditions))
+	}
+
+	// 模拟另一个goroutine正在执行
+	base.executing = 1
+	if _, err := base.Get(); err == nil || !strings.Contains(err.Error(), "Clone") {
+		t.Errorf("Expected concurrent execution error, got %v", err)
+	}
+	base.executing = 0
+	if rows, err := base.Get(); err != nil || len(rows) != 2 {
+		t.Errorf("Expected 2 rows after execution finished, got %d, %v", len(rows), err)
+	}
+
+	// 迭代器关闭之前构建器处于执行状态
+	it, err := base.Rows()
+	if err != nil {
+		t.Fatalf("Rows failed: %v", err)
+	}
+	if _, err := base.Rows(); err == nil {
+		t.Errorf("Expected Rows to fail while an iterator is open")
+	}
+	it.Close()
+	if _, err := base.Get(); err != nil {
+		t.Errorf("Expected Get to succeed after the iterator closed, got %v", err)
+	}
+
+	// 设置了超时的构建器 Count 后释放超时，仍可继续执行
+	timed := table().WithTimeout(time.Second)
+	if count, err := timed.Count(); err != nil || count != 4 {
+		t.Errorf("Expected count 4 with timeout, got %d, %v", count, err)
+	}
+	if timed.cancel != nil {
+		t.Errorf("Expected Count to release the timeout")
+	}
+}
+
+// 测试First和Last不修改原构建器
+func TestFirstDoesNotMutateBuilder(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	qb := table().OrderBy("id", "ASC")
+	first, err := qb.First()
+	if err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+	if first["name"] != "alice" {
+		t.Errorf("Expected first name 'alice', got '%v'", first["name"])
+	}
+
+	last, err := qb.Last()
+	if err != nil {
+		t.Fatalf("Last failed: %v", err)
+	}
+	if last["name"] != "dave" {
+		t.Errorf("Expected last name 'dave', got '%v'", last["name"])
+	}
+
+	rows, err := qb.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != len(testUsers) {
+		t.Errorf("Expected %d rows after First/Last, got %d", len(testUsers), len(rows))
+	}
+	if len(rows) > 0 && rows[0]["name"] != "alice" {
+		t.Errorf("Expected ordering to be preserved, got first '%v'", rows[0]["name"])
+	}
+}
+
+// 测试JSON路径解析与校验
+func TestParseJsonPath(t *testing.T) {
+	segments, ok := parseJsonPath("$.notifications.email")
+	if !ok || buildJsonPath(segments) != "$.notifications.email" {
+		t.Errorf("Unexpected path: %v", segments)
+	}
+
+	segments, ok = parseJsonPath("tags[1]")
+	if !ok || buildJsonPath(segments) != "$.tags[1]" || buildPostgresJsonPath(segments) != "{tags,1}" {
+		t.Errorf("Unexpected path: %v", segments)
+	}
+
+	for _, path := range []string{"a') OR 1=1 --", "a..b", "a b", "a[x]"} {
+		if _, ok := parseJsonPath(path); ok {
+			t.Errorf("Expected path '%s' to be rejected", path)
+		}
+	}
+}
+
+// 测试JSON列查询
+func TestWhereJsonContains(t *testing.T) {
+	conn, connName := setupTestDB(t)
+	_, err := conn.Exec("CREATE TABLE profiles (id INTEGER PRIMARY KEY, settings TEXT)")
+	if err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	_, err = conn.Exec(`INSERT INTO profiles (id, settings) VALUES
+		(1, '{"theme":"dark","tags":["go","db"]}'),
+		(2, '{"theme":"light","tags":["js"]}')`)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	qb, _ := Table("profiles", connName)
+	rows, err := qb.Select("id").SelectJson("settings", "theme", "theme").WhereJsonContains("settings", "tags", "go").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(rows))
+	}
+	if rows[0]["theme"] != "dark" {
+		t.Errorf("Expected theme 'dark', got '%v'", rows[0]["theme"])
+	}
+
+	// 无效的列名或路径记录错误，而不是丢弃条件后查询整张表
+	for name, invalid := range map[string]*QueryBuilder{
+		"contains column": newFakeBuilder("mysql", "users").WhereJsonContains("meta;drop", "a", 1),
+		"contains path":   newFakeBuilder("mysql", "users").WhereJsonContains("meta", "a;drop", 1),
+		"select path":     newFakeBuilder("mysql", "users").SelectJson("meta", "a b", "x"),
+		"select alias":    newFakeBuilder("mysql", "users").SelectJson("meta", "a", "x;drop"),
+	} {
+		if _, _, err := invalid.ToSQL(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// fakeDriverConnection 仅提供驱动名称的测试连接，用于校验生成的SQL
+type fakeDriverConnection struct {
+	ConnectionInterface
+	driver string
+	config *Config
+}
+
+func (c *fakeDriverConnection) GetDriver() string {
+	return c.driver
+}
+
+func (c *fakeDriverConnection) GetConfig() *Config {
+	return c.config
+}
+
+// newFakeBuilder 创建指定驱动的查询构建器（不连接数据库）
+func newFakeBuilder(driver, table string) *QueryBuilder {
+	qb, _ := NewQueryBuilder("fake")
+	qb.connection = &fakeDriverConnection{driver: driver}
+	return qb.From(table)
+}
+
+// 测试悲观锁子句
+func TestLockClause(t *testing.T) {
+	var tx TransactionInterface = &DBTransaction{}
+
+	// 事务外加锁返回错误，而不是静默生成不加锁的查询
+	if _, _, err := newFakeBuilder("mysql", "users").Where("id", "=", 1).LockForUpdate().ToSQL(); err == nil {
+		t.Error("Expected error for lock outside transaction")
+	}
+	table := setupTestTable(t, testUsers)
+	if _, err := table().Where("id", "=", 1).SharedLock().Get(); err == nil {
+		t.Error("Expected Get with lock outside transaction to fail")
+	}
+	if _, err := table().LockForUpdate().First(); err == nil {
+		t.Error("Expected First with lock outside transaction to fail")
+	}
+
+	// 锁可以在 InTransaction 之前设置
+	sql, _, err := newFakeBuilder("mysql", "users").LockForUpdate().InTransaction(tx).Where("id", "=", 1).ToSQL()
+	if err != nil || sql != "SELECT * FROM users WHERE id = ? FOR UPDATE" {
+		t.Errorf("Expected lock inside transaction, got '%s' (%v)", sql, err)
+	}
+
+	tests := []struct {
+		driver   string
+		shared   bool
+		expected string
+	}{
+		{"mysql", false, "SELECT * FROM users WHERE id = ? LIMIT 1 FOR UPDATE"},
+		{"mysql", true, "SELECT * FROM users WHERE id = ? LIMIT 1 LOCK IN SHARE MODE"},
+		{"postgres", true, "SELECT * FROM users WHERE id = $1 LIMIT 1 FOR SHARE"},
+		{"mssql", false, "SELECT * FROM users WITH (UPDLOCK, ROWLOCK) WHERE id = @p1 OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY"},
+	}
+	for _, tt := range tests {
+		qb := newFakeBuilder(tt.driver, "users").InTransaction(tx).Where("id", "=", 1).Limit(1)
+		if tt.shared {
+			qb.SharedLock()
+		} else {
+			qb.LockForUpdate()
+		}
+		sql, _, _ := qb.ToSQL()
+		if sql != tt.expected {
+			t.Errorf("[%s] Expected '%s', got '%s'", tt.driver, tt.expected, sql)
+		}
+	}
+}
+
+// 测试游标分页
+func TestCursorPaginate(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	var names []interface{}
+	cursor := ""
+	for i := 0; i < 3; i++ {
+		page, err := table().CursorPaginate("id", 3, cursor)
+		if err != nil {
+			t.Fatalf("CursorPaginate failed: %v", err)
+		}
+		for _, row := range page.Data {
+			names = append(names, row["name"])
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Errorf("Expected empty next cursor on last page, got '%s'", page.NextCursor)
+			}
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if len(names) != len(testUsers) || names[3] != "dave" {
+		t.Errorf("Expected all users in order, got %v", names)
+	}
+
+	if _, err := table().CursorPaginate("id", 3, "not-a-cursor!"); err == nil {
+		t.Error("Expected error for invalid cursor")
+	}
+
+	// 查询列中没有游标列时自动补充
+	page, err := table().Select("name").CursorPaginate("id", 3, "")
+	if err != nil || !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("Expected next cursor when id is not selected, got %+v, %v", page, err)
+	}
+	page, err = table().Select("name").CursorPaginate("id", 3, page.NextCursor)
+	if err != nil || len(page.Data) != 1 || page.Data[0]["name"] != "dave" {
+		t.Errorf("Expected last page with dave, got %+v, %v", page, err)
+	}
+
+	page, err = table().SelectRaw("id AS user_id").CursorPaginate("id", 3, "")
+	if err != nil || page.NextCursor == "" || page.Data[0]["user_id"] == nil {
+		t.Errorf("Expected raw select to keep its columns and produce a cursor, got %+v, %v", page, err)
+	}
+}
+
+// 测试结构化分页并填充到结构体切片
+func TestPaginateInto(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	type user struct {
+		ID     int64  `json:"id"`
+		Name   string `json:"name"`
+		Status string
+		Age    *int
+	}
+
+	var users []user
+	paginator, err := table().OrderBy("id", "ASC").PaginateInto(&users, 2, 3)
+	if err != nil {
+		t.Fatalf("PaginateInto failed: %v", err)
+	}
+
+	if paginator.Total != 4 || paginator.LastPage != 2 || paginator.HasMore || !paginator.HasPrev {
+		t.Errorf("Unexpected paginator: %+v", paginator)
+	}
+	if len(users) != 1 || users[0].Name != "dave" || users[0].Status != "pending" {
+		t.Fatalf("Unexpected users: %+v", users)
+	}
+	if users[0].Age == nil || *users[0].Age != 25 {
+		t.Errorf("Expected age 25, got %v", users[0].Age)
+	}
+}
+
+// 测试OrderField使用参数绑定而不是拼接值
+func TestOrderFieldBindings(t *testing.T) {
+	values := []interface{}{"active", "x' OR '1'='1"}
+
+	sql, args, _ := newFakeBuilder("mysql", "users").Where("age", ">", 18).OrderField("status", values, "asc").ToSQL()
+	if sql != "SELECT * FROM users WHERE age > ? ORDER BY FIELD(status, ?, ?) ASC" {
+		t.Errorf("Unexpected MySQL SQL: %s", sql)
+	}
+	if len(args) != 3 || args[2] != values[1] {
+		t.Errorf("Unexpected MySQL args: %v", args)
+	}
+
+	sql, args, _ = newFakeBuilder("postgres", "users").Where("age", ">", 18).OrderField("status", values, "desc").ToSQL()
+	if sql != "SELECT * FROM users WHERE age > $1 ORDER BY CASE WHEN status = $2 THEN 0 WHEN status = $3 THEN 1 ELSE 999 END DESC" {
+		t.Errorf("Unexpected PostgreSQL SQL: %s", sql)
+	}
+	if len(args) != 3 || args[1] != "active" {
+		t.Errorf("Unexpected PostgreSQL args: %v", args)
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().OrderField("name", []interface{}{"carol", "it's", "alice"}, "ASC").OrderBy("id", "ASC").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 4 || rows[0]["name"] != "carol" || rows[1]["name"] != "alice" {
+		t.Errorf("Unexpected ordering: %v", rows)
+	}
+}
+
+// 测试白名单排序
+func TestOrderByWhitelist(t *testing.T) {
+	allowed := []string{"name", "age"}
+
+	qb := newFakeBuilder("mysql", "users")
+	if err := qb.OrderByWhitelist("age", "desc", allowed); err != nil {
+		t.Fatalf("OrderByWhitelist failed: %v", err)
+	}
+	if err := qb.OrderByWhitelist("name", "", allowed); err != nil {
+		t.Fatalf("OrderByWhitelist with empty direction failed: %v", err)
+	}
+	if err := qb.OrderByWhitelist("password", "asc", allowed); err == nil {
+		t.Error("Expected error for column not in whitelist")
+	}
+	if err := qb.OrderByWhitelist("name; DROP TABLE users", "asc", allowed); err == nil {
+		t.Error("Expected error for injected column")
+	}
+	if err := qb.OrderByWhitelist("name", "sideways", allowed); err == nil {
+		t.Error("Expected error for invalid direction")
+	}
+
+	sql, _, _ := qb.ToSQL()
+	if sql != "SELECT * FROM users ORDER BY age DESC, name ASC" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+}
+
+// 测试LoadModel按cast标签转换值
+func TestLoadModelCast(t *testing.T) {
+	type settings struct {
+		Theme string   `json:"theme"`
+		Tags  []string `json:"tags"`
+	}
+	type profile struct {
+		Settings *settings `json:"settings" torm:"cast:json"`
+		Meta     settings  `json:"meta" torm:"cast:json"`
+		Score    int       `json:"score" torm:"cast:int"`
+		Level    *int      `json:"level" torm:"cast:int"`
+		Active   bool      `json:"active" torm:"cast:bool"`
+		Verified *bool     `json:"verified" torm:"cast:bool"`
+		Born     time.Time `json:"born" torm:"cast:time"`
+	}
+
+	var p profile
+	err := LoadModel(map[string]interface{}{
+		"settings": []byte(`{"theme":"dark","tags":["go"]}`),
+		"meta":     map[string]interface{}{"theme": "light"},
+		"score":    "42",
+		"level":    []byte("7"),
+		"active":   "yes",
+		"verified": int64(0),
+		"born":     "2024-05-01 08:30:00",
+	}, &p)
+	if err != nil {
+		t.Fatalf("LoadModel failed: %v", err)
+	}
+
+	if p.Settings == nil || p.Settings.Theme != "dark" || len(p.Settings.Tags) != 1 {
+		t.Errorf("Unexpected settings: %+v", p.Settings)
+	}
+	if p.Meta.Theme != "light" {
+		t.Errorf("Unexpected meta: %+v", p.Meta)
+	}
+	if p.Score != 42 || p.Level == nil || *p.Level != 7 {
+		t.Errorf("Unexpected numbers: score=%d level=%v", p.Score, p.Level)
+	}
+	if !p.Active || p.Verified == nil || *p.Verified {
+		t.Errorf("Unexpected bools: active=%v verified=%v", p.Active, p.Verified)
+	}
+	if p.Born.Year() != 2024 || p.Born.Hour() != 8 {
+		t.Errorf("Unexpected time: %v", p.Born)
+	}
+
+	if err := LoadModel(map[string]interface{}{"score": "abc"}, &p); err == nil {
+		t.Error("Expected error for invalid int cast")
+	}
+}
+
+// 测试LoadModel处理NULL、sql.Null*类型和数值类型转换
+func TestLoadModelNullableAndNumeric(t *testing.T) {
+	type account struct {
+		ID        int             `json:"id"`
+		Nickname  *string         `json:"nickname"`
+		Email     sql.NullString  `json:"email"`
+		Age       int32           `json:"age"`
+		Score     float32         `json:"score"`
+		Level     *int            `json:"level"`
+		Balance   sql.NullFloat64 `json:"balance"`
+		Visits    sql.NullInt32   `json:"visits"`
+		LastLogin sql.NullTime    `json:"last_login"`
+		Flags     uint8           `json:"flags"`
+	}
+
+	a := account{Nickname: new(string), Email: sql.NullString{String: "old", Valid: true}}
+	err := LoadModel(map[string]interface{}{
+		"id":         int64(7),
+		"nickname":   nil,
+		"email":      nil,
+		"age":        int64(30),
+		"score":      float64(9.5),
+		"level":      int64(3),
+		"balance":    "12.5",
+		"visits":     int64(4),
+		"last_login": "2024-05-01 08:30:00",
+		"flags":      int64(255),
+	}, &a)
+	if err != nil {
+		t.Fatalf("LoadModel failed: %v", err)
+	}
+
+	if a.ID != 7 || a.Age != 30 || a.Score != 9.5 || a.Flags != 255 {
+		t.Errorf("Unexpected numbers: id=%d age=%d score=%v flags=%d", a.ID, a.Age, a.Score, a.Flags)
+	}
+	if a.Nickname != nil {
+		t.Errorf("Expected NULL to clear pointer field, got %q", *a.Nickname)
+	}
+	if a.Email.Valid {
+		t.Errorf("Expected NULL to set Email invalid, got %+v", a.Email)
+	}
+	if a.Level == nil || *a.Level != 3 {
+		t.Errorf("Unexpected level: %v", a.Level)
+	}
+	if !a.Balance.Valid || a.Balance.Float64 != 12.5 || !a.Visits.Valid || a.Visits.Int32 != 4 {
+		t.Errorf("Unexpected null numbers: balance=%+v visits=%+v", a.Balance, a.Visits)
+	}
+	if !a.LastLogin.Valid || a.LastLogin.Time.Year() != 2024 || a.LastLogin.Time.Hour() != 8 {
+		t.Errorf("Unexpected last login: %+v", a.LastLogin)
+	}
+
+	for column, value := range map[string]interface{}{
+		"flags": int64(256),
+		"age":   int64(1) << 40,
+		"id":    uint64(1) << 63,
+	} {
+		if err := LoadModel(map[string]interface{}{column: value}, &a); err == nil {
+			t.Errorf("Expected overflow error for %s = %v", column, value)
+		}
+	}
+	if err := LoadModel(map[string]interface{}{"flags": int64(-1)}, &a); err == nil {
+		t.Error("Expected error for negative value into unsigned field")
+	}
+}
+
+// 测试全局作用域和局部作用域
+func TestGlobalScopes(t *testing.T) {
+	tenant := func(q *QueryBuilder) *QueryBuilder { return q.Where("tenant_id", "=", 7) }
+	active := func(q *QueryBuilder) *QueryBuilder { return q.Where("status", "=", "active") }
+
+	sql, args, _ := newFakeBuilder("mysql", "users").
+		WithGlobalScope("tenant", tenant).
+		Where("name", "=", "a").OrWhere("name", "=", "b").
+		ToSQL()
+	if sql != "SELECT * FROM users WHERE (name = ? OR name = ?) AND tenant_id = ?" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if len(args) != 3 || args[2] != 7 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	sql, _, _ = newFakeBuilder("postgres", "users").
+		WithGlobalScope("tenant", tenant).
+		Scope(active).
+		ToSQL()
+	if sql != "SELECT * FROM users WHERE status = $1 AND tenant_id = $2" {
+		t.Errorf("Unexpected SQL with local scope: %s", sql)
+	}
+
+	qb := newFakeBuilder("mysql", "users").WithGlobalScope("tenant", tenant).WithoutGlobalScope("tenant")
+	if qb.HasGlobalScope("tenant") {
+		t.Error("Expected tenant scope to be removed")
+	}
+	sql, _, _ = qb.ToSQL()
+	if sql != "SELECT * FROM users" {
+		t.Errorf("Unexpected SQL without scope: %s", sql)
+	}
+
+	// 多次构建只应用一次
+	qb = newFakeBuilder("mysql", "users").WithGlobalScope("tenant", tenant)
+	qb.ToSQL()
+	sql, _, _ = qb.ToSQL()
+	if sql != "SELECT * FROM users WHERE tenant_id = ?" {
+		t.Errorf("Expected scope applied once, got: %s", sql)
+	}
+
+	// 构建SQL不修改构建器，之后仍可移除作用域
+	qb.WithoutGlobalScope("tenant")
+	if sql, _, _ = qb.ToSQL(); sql != "SELECT * FROM users" {
+		t.Errorf("Expected scope removable after ToSQL, got: %s", sql)
+	}
+	if len(qb.whereConditions) != 0 {
+		t.Errorf("Expected receiver conditions untouched, got %v", qb.whereConditions)
+	}
+}
+
+// 测试条件子句
+func TestWhen(t *testing.T) {
+	status, keyword := "active", ""
+	byStatus := func(q *QueryBuilder) *QueryBuilder { return q.Where("status", "=", status) }
+	byKeyword := func(q *QueryBuilder) *QueryBuilder { return q.Where("name", "LIKE", "%"+keyword+"%") }
+	latest := func(q *QueryBuilder) *QueryBuilder { return q.OrderBy("id", "desc") }
+
+	sql, args, _ := newFakeBuilder("mysql", "users").
+		When(status != "", byStatus).
+		When(keyword != "", byKeyword, latest).
+		ToSQL()
+	if sql != "SELECT * FROM users WHERE status = ? ORDER BY id DESC" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if len(args) != 1 || args[0] != "active" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// 条件为假且没有 elseFn 时不做修改
+	sql, _, _ = newFakeBuilder("mysql", "users").When(false, byStatus).ToSQL()
+	if sql != "SELECT * FROM users" {
+		t.Errorf("Unexpected SQL without else: %s", sql)
+	}
+}
+
+// 测试InsertModel和UpdateModel
+func TestInsertAndUpdateModel(t *testing.T) {
+	table := setupTestTable(t, nil)
+
+	type user struct {
+		ID     int64  `json:"id" torm:"primary_key"`
+		Name   string `json:"name"`
+		Status string `json:"userStatus" db:"status"` // db 标签优先于 json 标签
+		Age    int
+		Secret string `torm:"-"`
+	}
+
+	u := &user{Name: "erin", Status: "active", Age: 33, Secret: "x"}
+	id, err := table().InsertModel(u)
+	if err != nil {
+		t.Fatalf("InsertModel failed: %v", err)
+	}
+	if id == 0 || u.ID != id {
+		t.Fatalf("Expected id to be written back, got id=%d model=%d", id, u.ID)
+	}
+
+	u.Status = "banned"
+	u.Age = 0 // 零值不更新
+	affected, err := table().UpdateModel(u)
+	if err != nil {
+		t.Fatalf("UpdateModel failed: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 affected row, got %d", affected)
+	}
+
+	row, err := table().Where("id", "=", id).First()
+	if err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+	if row["status"] != "banned" || row["age"] != int64(33) || row["name"] != "erin" {
+		t.Errorf("Unexpected row: %v", row)
+	}
+
+	if _, err := table().UpdateModel(&user{Name: "nobody"}); err == nil {
+		t.Error("Expected error when primary key is empty")
+	}
+}
+
+// 测试FindModel按主键查找并填充结构体
+func TestFindModel(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	type user struct {
+		ID     int64  `json:"id" torm:"primary_key"`
+		Name   string `json:"name"`
+		Status string `json:"userStatus" db:"status"`
+		Age    int
+	}
+
+	var u user
+	if err := table().FindModel(3, &u); err != nil {
+		t.Fatalf("FindModel failed: %v", err)
+	}
+	if u.ID != 3 || u.Name != "carol" || u.Status != "banned" || u.Age != 30 {
+		t.Errorf("Unexpected model: %+v", u)
+	}
+
+	err := table().FindModel(99, &u)
+	if !IsNotFoundError(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	if err := table().FindModel(1, u); err == nil {
+		t.Error("Expected error for non-pointer model")
+	}
+}
+
+// 测试逐行迭代和提前结束
+func TestRowIterator(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	it, err := table().OrderBy("id", "ASC").Rows()
+	if err != nil {
+		t.Fatalf("Rows failed: %v", err)
+	}
+	var names []interface{}
+	for {
+		row, ok, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if !ok {
+			break
+		}
+		names = append(names, row["name"])
+	}
+	if len(names) != 4 || names[0] != "alice" || !it.closed {
+		t.Errorf("Unexpected iteration: %v closed=%v", names, it.closed)
+	}
+
+	// 提前结束后连接应被释放（测试连接池只有一个连接）
+	seen := 0
+	err = table().Each(func(row map[string]interface{}) bool {
+		seen++
+		return seen < 2
+	})
+	if err != nil || seen != 2 {
+		t.Fatalf("Each stopped incorrectly: seen=%d err=%v", seen, err)
+	}
+	if count, err := table().Count(); err != nil || count != 4 {
+		t.Errorf("Expected connection to be released after early stop, count=%d err=%v", count, err)
+	}
+}
+
+// slowCondition 递归CTE构造的耗时条件，用于测试查询取消
+const slowCondition = "(WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT COUNT(*) FROM c) > 0"
+
+// 测试上下文取消和超时会中断正在执行的查询
+func TestQueryContextCancellation(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := table().WithContext(ctx).WhereRaw(slowCondition).Get()
+	if err == nil {
+		t.Fatal("Expected error after context cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected query to stop soon after cancellation, took %v", elapsed)
+	}
+
+	qb := table().WithTimeout(50 * time.Millisecond)
+	if _, err := qb.WhereRaw(slowCondition).Count(); err == nil {
+		t.Fatal("Expected error after timeout")
+	}
+	if qb.cancel != nil || qb.context().Err() != nil {
+		t.Error("Expected timeout to be released after execution")
+	}
+
+	// 超时只作用于下一次执行
+	if count, err := table().WithTimeout(time.Second).Count(); err != nil || count != 4 {
+		t.Errorf("Expected count 4 within timeout, got %d err=%v", count, err)
+	}
+}
+
+// 测试LIKE条件通配符转义
+func TestWhereLike(t *testing.T) {
+	sql, args, _ := newFakeBuilder("mysql", "users").WhereLike("name", "50%_off").ToSQL()
+	if sql != `SELECT * FROM users WHERE name LIKE ? ESCAPE '\\'` {
+		t.Errorf("Unexpected MySQL SQL: %s", sql)
+	}
+	if len(args) != 1 || args[0] != `%50\%\_off%` {
+		t.Errorf("Unexpected MySQL args: %v", args)
+	}
+
+	sql, args, _ = newFakeBuilder("postgres", "users").WhereILike("name", "Al").ToSQL()
+	if sql != `SELECT * FROM users WHERE name ILIKE $1 ESCAPE '\'` || args[0] != "%Al%" {
+		t.Errorf("Unexpected PostgreSQL SQL: %s %v", sql, args)
+	}
+
+	table := setupTestTable(t, append(testUsers, map[string]interface{}{"name": "a_b%", "status": "active", "age": 40}))
+
+	count, err := table().WhereLike("name", "_").Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 row matching literal '_', got %d (%v)", count, err)
+	}
+	count, _ = table().WhereLikeStart("name", "a").Count()
+	if count != 2 {
+		t.Errorf("Expected 2 rows starting with 'a', got %d", count)
+	}
+	count, _ = table().WhereLikeEnd("name", "%").Count()
+	if count != 1 {
+		t.Errorf("Expected 1 row ending with '%%', got %d", count)
+	}
+	count, _ = table().WhereNotLike("name", "o").Count()
+	if count != 3 {
+		t.Errorf("Expected 3 rows not containing 'o', got %d", count)
+	}
+	count, _ = table().WhereILike("name", "ALI").Count()
+	if count != 1 {
+		t.Errorf("Expected 1 case-insensitive match, got %d", count)
+	}
+
+	// 无效列名记录错误，避免条件被忽略后匹配所有行
+	if _, err := table().WhereLike("name; DROP", "a").Count(); err == nil {
+		t.Errorf("Expected invalid column to fail WhereLike")
+	}
+	if _, err := table().WhereILike("name)", "a").Count(); err == nil {
+		t.Errorf("Expected invalid column to fail WhereILike")
+	}
+}
+
+// 测试嵌套条件分组
+func TestWhereGroup(t *testing.T) {
+	sql, args, _ := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereGroup(func(q *QueryBuilder) {
+			q.Where("age", ">", 20).OrWhere("name", "=", "alice")
+		}).
+		OrWhereGroup(func(q *QueryBuilder) {
+			q.Where("status", "=", "banned").WhereGroup(func(inner *QueryBuilder) {
+				inner.Where("age", "=", 30).OrWhere("age", "=", 40)
+			})
+		}).
+		ToSQL()
+	expected := "SELECT * FROM users WHERE status = $1 AND (age > $2 OR name = $3) OR (status = $4 AND (age = $5 OR age = $6))"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 6 || args[2] != "alice" || args[5] != 40 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	sql, _, _ = newFakeBuilder("mysql", "users").WhereGroup(func(q *QueryBuilder) {}).ToSQL()
+	if sql != "SELECT * FROM users" {
+		t.Errorf("Expected empty group to be ignored, got: %s", sql)
+	}
+
+	table := setupTestTable(t, testUsers)
+	count, err := table().Where("age", "=", 25).WhereGroup(func(q *QueryBuilder) {
+		q.Where("status", "=", "pending").OrWhere("name", "=", "carol")
+	}).Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 row, got %d (%v)", count, err)
+	}
+}
+
+// 测试调试用SQL输出和执行计划
+func TestDumpSQLAndExplain(t *testing.T) {
+	sql := newFakeBuilder("mysql", "users").Where("name", "=", "o'neil").Where("age", ">", 18).WhereNull("deleted_at").DumpSQL()
+	if sql != "SELECT * FROM users WHERE name = 'o''neil' AND age > 18 AND deleted_at IS NULL" {
+		t.Errorf("Unexpected MySQL dump: %s", sql)
+	}
+
+	args := make([]interface{}, 11)
+	for i := range args {
+		args[i] = i + 1
+	}
+	sql = newFakeBuilder("postgres", "users").WhereIn("id", args).Where("active", "=", true).DumpSQL()
+	if sql != "SELECT * FROM users WHERE id IN (1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11) AND active = TRUE" {
+		t.Errorf("Unexpected PostgreSQL dump: %s", sql)
+	}
+
+	table := setupTestTable(t, testUsers)
+	plan, err := table().Where("name", "=", "alice").Explain()
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if !strings.Contains(plan, "users") {
+		t.Errorf("Expected plan to mention users table, got: %s", plan)
+	}
+
+	if _, err := table().ExplainAnalyze(); err == nil {
+		t.Error("Expected ExplainAnalyze to be unsupported on SQLite")
+	}
+}
+
+// 测试链式调试辅助方法
+func TestTapDumpDD(t *testing.T) {
+	var out strings.Builder
+	previous := dumpWriter
+	dumpWriter = &out
+	defer func() { dumpWriter = previous }()
+
+	var tapped string
+	qb := newFakeBuilder("mysql", "users").
+		Where("age", ">", 18).
+		Tap(func(q *QueryBuilder) { tapped, _, _ = q.ToSQL() }).
+		Dump().
+		Where("status", "=", "active")
+	if tapped != "SELECT * FROM users WHERE age > ?" {
+		t.Errorf("Unexpected tapped SQL: %s", tapped)
+	}
+	if !strings.Contains(out.String(), "SELECT * FROM users WHERE age > ? | 参数: [18]") {
+		t.Errorf("Unexpected dump output: %s", out.String())
+	}
+	if sql, _, _ := qb.ToSQL(); sql != "SELECT * FROM users WHERE age > ? AND status = ?" {
+		t.Errorf("Expected chain to continue after Dump, got: %s", sql)
+	}
+
+	defer func() {
+		signal, ok := recover().(DDSignal)
+		if !ok {
+			t.Fatalf("Expected DDSignal panic")
+		}
+		if signal.SQL != "SELECT * FROM users WHERE age > ? AND status = ?" || len(signal.Args) != 2 {
+			t.Errorf("Unexpected DD signal: %+v", signal)
+		}
+	}()
+	qb.DD().Limit(1)
+	t.Error("Expected DD to stop execution")
+}
+
+// 测试排序规则
+func TestCollate(t *testing.T) {
+	sql, args, err := newFakeBuilder("mysql", "users").
+		Collate("utf8mb4_bin").Where("name", "=", "Alice").
+		Where("status", "=", "active").
+		Collate("utf8mb4_unicode_ci").OrderBy("name", "asc").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if sql != "SELECT * FROM users WHERE name COLLATE utf8mb4_bin = ? AND status = ? ORDER BY name COLLATE utf8mb4_unicode_ci ASC" {
+		t.Errorf("Unexpected MySQL SQL: %s", sql)
+	}
+	if len(args) != 2 || args[0] != "Alice" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	sql, _, err = newFakeBuilder("postgres", "users").
+		Where("id", ">", 1).Collate("C").OrWhere("name", "like", "A%").
+		Collate("C").OrderBy("name", "desc").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if sql != `SELECT * FROM users WHERE id > $1 OR name LIKE $2 COLLATE "C" ORDER BY name COLLATE "C" DESC` {
+		t.Errorf("Unexpected PostgreSQL SQL: %s", sql)
+	}
+
+	// 白名单外的排序规则和不支持的操作符记录错误
+	if _, _, err := newFakeBuilder("mysql", "users").Collate("x; DROP TABLE users").Where("name", "=", "a").ToSQL(); err == nil {
+		t.Error("Expected error for invalid collation")
+	}
+	if _, _, err := newFakeBuilder("postgres", "users").Collate("c").ToSQL(); err == nil {
+		t.Error("Expected PostgreSQL collation names to be case-sensitive")
+	}
+	if _, _, err := newFakeBuilder("mysql", "users").Collate("utf8mb4_bin").Where("id", "IN", []int{1}).ToSQL(); err == nil {
+		t.Error("Expected error for IN with collation")
+	}
+
+	// 扩展白名单，SQLite 实际执行
+	if err := AllowCollation("sqlite", "bad name"); err == nil {
+		t.Error("Expected error for invalid collation name")
+	}
+	if err := AllowCollation("postgresql", "de-x-icu"); err != nil {
+		t.Fatalf("AllowCollation failed: %v", err)
+	}
+	if _, _, err := newFakeBuilder("postgres", "users").Collate("de-x-icu").OrderBy("name", "asc").ToSQL(); err != nil {
+		t.Errorf("Expected allowed collation, got %v", err)
+	}
+	table := setupTestTable(t, testUsers)
+	count, err := table().Collate("nocase").Where("name", "=", "ALICE").Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected case-insensitive match, got %d (%v)", count, err)
+	}
+	count, err = table().Where("name", "=", "ALICE").Count()
+	if err != nil || count != 0 {
+		t.Errorf("Expected case-sensitive match by default, got %d (%v)", count, err)
+	}
+}
+
+// 测试标识符引号
+func TestQuoteIdentifiers(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"mysql", "SELECT `order`, `users`.`group` AS `g`, COUNT(*) as count FROM `users` WHERE `order` = ? AND `group` IN (?, ?) AND `desc` IS NULL GROUP BY `users`.`group` ORDER BY `order` DESC"},
+		{"postgres", `SELECT "order", "users"."group" AS "g", COUNT(*) as count FROM "users" WHERE "order" = $1 AND "group" IN ($2, $3) AND "desc" IS NULL GROUP BY "users"."group" ORDER BY "order" DESC`},
+		{"sqlserver", "SELECT [order], [users].[group] AS [g], COUNT(*) as count FROM [users] WHERE [order] = @p1 AND [group] IN (@p2, @p3) AND [desc] IS NULL GROUP BY [users].[group] ORDER BY [order] DESC"},
+	}
+	for _, tt := range tests {
+		sql, _, err := newFakeBuilder(tt.driver, "users").QuoteIdentifiers().
+			Select("order", "users.group as g", "COUNT(*) as count").
+			Where("order", "=", 1).
+			WhereIn("group", []interface{}{"a", "b"}).
+			WhereNull("desc").
+			GroupBy("users.group").
+			OrderBy("order", "desc").
+			ToSQL()
+		if err != nil {
+			t.Fatalf("%s: ToSQL failed: %v", tt.driver, err)
+		}
+		if sql != tt.want {
+			t.Errorf("%s: unexpected SQL:\n got: %s\nwant: %s", tt.driver, sql, tt.want)
+		}
+	}
+
+	// 条件组的子构建器继承引号设置
+	sql, _, _ := newFakeBuilder("mysql", "users").QuoteIdentifiers().
+		WhereGroup(func(q *QueryBuilder) { q.WhereNull("desc").WhereNotNull("order") }).
+		ToSQL()
+	if sql != "SELECT * FROM `users` WHERE (`desc` IS NULL AND `order` IS NOT NULL)" {
+		t.Errorf("Expected quoted group, got: %s", sql)
+	}
+
+	// 条件组、多列条件、LIKE、JSON和全文搜索中的列引用同样加引号
+	sql, _, _ = newFakeBuilder("mysql", "users").QuoteIdentifiers().
+		WhereGroup(func(q *QueryBuilder) { q.Where("order", "=", 1).OrWhere("group", "=", 2) }).
+		WhereAny([]string{"name", "desc"}, "=", nil).
+		WhereLike("title", "go").
+		WhereJsonContains("meta", "tags", "a").
+		WhereFulltext([]string{"title", "body"}, "go", "").
+		ToSQL()
+	want := "SELECT * FROM `users` WHERE (`order` = ? OR `group` = ?) AND (`name` IS NULL OR `desc` IS NULL)" +
+		" AND `title` LIKE ? ESCAPE '\\\\' AND JSON_CONTAINS(`meta`, ?, ?) AND MATCH(`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	if sql != want {
+		t.Errorf("Unexpected quoted conditions:\n got: %s\nwant: %s", sql, want)
+	}
+
+	// 全局作用域的OR分组同样加引号
+	sql, _, _ = newFakeBuilder("postgres", "users").QuoteIdentifiers().
+		WithGlobalScope("tenant", func(q *QueryBuilder) *QueryBuilder { return q.Where("tenant", "=", 1).OrWhere("shared", "=", true) }).
+		Where("order", "=", 1).
+		ToSQL()
+	if sql != `SELECT * FROM "users" WHERE "order" = $1 AND ("tenant" = $2 OR "shared" = $3)` {
+		t.Errorf("Unexpected quoted scope: %s", sql)
+	}
+
+	// 批量更新的表名、列名和键列加引号
+	batch := newFakeBuilder("mysql", "users").QuoteIdentifiers().DryRun()
+	if _, err := batch.UpdateBatch([]map[string]interface{}{{"id": 1, "order": 2}}, "id"); err != nil {
+		t.Fatalf("UpdateBatch failed: %v", err)
+	}
+	if sql, _ = batch.LastSQL(); sql != "UPDATE `users` SET `order` = CASE `id` WHEN ? THEN ? ELSE `order` END WHERE `id` IN (?)" {
+		t.Errorf("Unexpected quoted batch update: %s", sql)
+	}
+
+	// 默认不加引号
+	sql, _, _ = newFakeBuilder("mysql", "users").Select("users.*").Where("order", "=", 1).ToSQL()
+	if sql != "SELECT users.* FROM users WHERE order = ?" {
+		t.Errorf("Expected unquoted SQL by default, got: %s", sql)
+	}
+	sql, _, _ = newFakeBuilder("mysql", "users").QuoteIdentifiers().Select("users.*").ToSQL()
+	if sql != "SELECT `users`.* FROM `users`" {
+		t.Errorf("Unexpected quoted wildcard: %s", sql)
+	}
+
+	// 通过连接配置开启，保留字列名可正常读写
+	conn, connName := setupTestDB(t)
+	conn.GetConfig().SetQuoteIdentifiers(true)
+	if _, err := conn.Exec(`CREATE TABLE "orders" ("id" INTEGER PRIMARY KEY, "order" INTEGER, "group" TEXT)`); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	query := func() *QueryBuilder {
+		qb, err := Table("orders", connName)
+		if err != nil {
+			t.Fatalf("Table failed: %v", err)
+		}
+		return qb
+	}
+
+	if _, err := query().Insert(map[string]interface{}{"order": 1, "group": "a"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := query().InsertBatch([]map[string]interface{}{{"order": 2, "group": "b"}, {"order": 3, "group": "b"}}); err != nil {
+		t.Fatalf("InsertBatch failed: %v", err)
+	}
+	if _, err := query().Where("group", "=", "a").Update(map[string]interface{}{"order": 10}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	rows, err := query().Select("order", "group").OrderBy("order", "desc").GetRaw()
+	if err != nil {
+		t.Fatalf("GetRaw failed: %v", err)
+	}
+	if len(rows) != 3 || rows[0]["order"] != int64(10) || rows[0]["group"] != "a" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+	if _, err := query().Where("order", "=", 2).Delete(); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if count, err := query().Count(); err != nil || count != 2 {
+		t.Errorf("Expected 2 rows after delete, got %d (%v)", count, err)
+	}
+}
+
+// 测试演练模式
+func TestDryRun(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	qb := table().DryRun()
+	id, err := qb.Insert(map[string]interface{}{"name": "eve"})
+	if err != nil || id != 0 {
+		t.Fatalf("Expected dry-run insert to return 0, nil, got %d, %v", id, err)
+	}
+	sql, args := qb.LastSQL()
+	if sql != "INSERT INTO users (name) VALUES (?)" || len(args) != 1 || args[0] != "eve" {
+		t.Errorf("Unexpected insert SQL: %s %v", sql, args)
+	}
+
+	qb = table().DryRun().Where("name", "=", "alice")
+	if _, err := qb.Update(map[string]interface{}{"age": 21}); err != nil {
+		t.Fatalf("Dry-run update failed: %v", err)
+	}
+	sql, args = qb.LastSQL()
+	if sql != "UPDATE users SET age = ? WHERE name = ?" || len(args) != 2 {
+		t.Errorf("Unexpected update SQL: %s %v", sql, args)
+	}
+
+	qb = table().DryRun().Where("status", "=", "banned")
+	if _, err := qb.Delete(); err != nil {
+		t.Fatalf("Dry-run delete failed: %v", err)
+	}
+	if sql, _ = qb.LastSQL(); sql != "DELETE FROM users WHERE status = ?" {
+		t.Errorf("Unexpected delete SQL: %s", sql)
+	}
+
+	count, _ := table().Count()
+	if count != int64(len(testUsers)) {
+		t.Errorf("Dry run must not touch the database, got %d rows", count)
+	}
+	row, _ := table().Where("name", "=", "alice").First()
+	if row["age"] != int64(20) {
+		t.Errorf("Dry run must not update rows, got %v", row["age"])
+	}
+}
+
+// 测试nil值转换为IS NULL / IS NOT NULL
+func TestWhereNilValue(t *testing.T) {
+	var deletedAt *time.Time
+
+	sql, args, _ := newFakeBuilder("mysql", "users").Where("deleted_at", "=", nil).OrWhere("archived_at", "=", deletedAt).ToSQL()
+	if sql != "SELECT * FROM users WHERE deleted_at IS NULL OR archived_at IS NULL" || len(args) != 0 {
+		t.Errorf("Unexpected SQL for = nil: %s %v", sql, args)
+	}
+
+	sql, args, _ = newFakeBuilder("postgres", "users").Where("deleted_at", "!=", nil).Where("parent_id", "<>", nil).Where("age", ">", 18).ToSQL()
+	if sql != "SELECT * FROM users WHERE deleted_at IS NOT NULL AND parent_id IS NOT NULL AND age > $1" || len(args) != 1 {
+		t.Errorf("Unexpected SQL for != nil: %s %v", sql, args)
+	}
+
+	table := setupTestTable(t, append(testUsers, map[string]interface{}{"name": "nobody", "status": nil, "age": 50}))
+	count, err := table().Where("status", "=", nil).Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 row with NULL status, got %d (%v)", count, err)
+	}
+	count, _ = table().Where("status", "!=", nil).Count()
+	if count != int64(len(testUsers)) {
+		t.Errorf("Expected %d rows with non-NULL status, got %d", len(testUsers), count)
+	}
+}
+
+// 测试HAVING原生条件的参数绑定
+func TestHavingRawBindings(t *testing.T) {
+	sql, args, _ := newFakeBuilder("postgres", "orders").
+		Select("user_id").
+		Where("status", "=", "paid").
+		GroupBy("user_id").
+		HavingRaw("SUM(amount) > ?", 100).
+		OrHaving("COUNT(*) BETWEEN ? AND ?", 5, 10).
+		ToSQL()
+	expected := "SELECT user_id FROM orders WHERE status = $1 GROUP BY user_id HAVING SUM(amount) > $2 OR COUNT(*) BETWEEN $3 AND $4"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 4 || args[1] != 100 || args[3] != 10 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// 字符串参数的原生条件与列条件
+	sql, args, _ = newFakeBuilder("mysql", "orders").GroupBy("user_id").
+		Having("COUNT(*) BETWEEN ? AND ?", "5", "10").
+		OrHaving("total", ">=", 100).
+		ToSQL()
+	if sql != "SELECT * FROM orders GROUP BY user_id HAVING COUNT(*) BETWEEN ? AND ? OR total >= ?" || len(args) != 3 || args[0] != "5" {
+		t.Errorf("Unexpected HAVING with string args: %s %v", sql, args)
+	}
+	if _, _, err := newFakeBuilder("mysql", "orders").GroupBy("user_id").Having("total", "; DROP", 1).ToSQL(); err == nil {
+		t.Errorf("Expected invalid HAVING operator to fail")
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().Select("status").GroupBy("status").HavingRaw("COUNT(*) > ?", 1).Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["status"] != "active" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+// 测试DECIMAL列按配置返回字符串或big.Rat
+func TestDecimalMode(t *testing.T) {
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE prices (id INTEGER PRIMARY KEY, amount DECIMAL(10,2), ratio REAL)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO prices (id, amount, ratio) VALUES (1, 19.9, 0.5)"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	defer SetDecimalMode(DecimalAsFloat)
+
+	query := func() *QueryBuilder {
+		qb, _ := NewQueryBuilder(connName)
+		return qb.From("prices")
+	}
+
+	row, err := query().First()
+	if err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+	if _, ok := row["amount"].(float64); !ok {
+		t.Errorf("Expected float64 by default, got %T", row["amount"])
+	}
+	// 驱动以文本返回的定点数在默认模式下同样转换为 float64，不依赖智能类型推断
+	if v, ok := convertDecimalValue([]byte("19.90"), DecimalAsFloat); !ok || v != 19.9 {
+		t.Errorf("Expected 19.9 from DECIMAL text, got %T %v", v, v)
+	}
+	if v, ok := convertDecimalValue("$1,234.50", DecimalAsFloat); !ok || v != 1234.5 {
+		t.Errorf("Expected 1234.5 from MONEY text, got %T %v", v, v)
+	}
+
+	SetDecimalMode(DecimalAsString)
+	row, _ = query().First()
+	if row["amount"] != "19.9" {
+		t.Errorf("Expected string amount, got %T %v", row["amount"], row["amount"])
+	}
+	if _, ok := row["ratio"].(float64); !ok {
+		t.Errorf("Non-decimal columns should not be affected, got %T", row["ratio"])
+	}
+
+	SetDecimalMode(DecimalAsRat)
+	row, _ = query().First()
+	rat, ok := row["amount"].(*big.Rat)
+	if !ok || rat.Cmp(big.NewRat(199, 10)) != 0 {
+		t.Errorf("Expected *big.Rat 199/10, got %T %v", row["amount"], row["amount"])
+	}
+
+	// 模型字段的 cast:decimal
+	var price struct {
+		Amount    string   `torm:"cast:decimal"`
+		AmountRat *big.Rat `torm:"cast:decimal"`
+	}
+	err = LoadModel(map[string]interface{}{"amount": "0.10", "amount_rat": "0.30"}, &price)
+	if err != nil {
+		t.Fatalf("LoadModel failed: %v", err)
+	}
+	if price.Amount != "0.10" || price.AmountRat == nil || price.AmountRat.Cmp(big.NewRat(3, 10)) != 0 {
+		t.Errorf("Unexpected decimal fields: %q %v", price.Amount, price.AmountRat)
+	}
+}
+
+// 测试文本列不被智能推断改变类型
+func TestTextColumnsStayStrings(t *testing.T) {
+	table := setupTestTable(t, []map[string]interface{}{
+		{"name": "123", "status": "true", "age": 1},
+		{"name": "dGVzdA==", "status": "2024-01-01", "age": 2},
+		{"name": "1.5", "status": `{"a":1}`, "age": 3},
+	})
+
+	for _, smart := range []bool{false, true} {
+		SetSmartConversion(smart)
+		rows, err := table().OrderBy("age", "asc").Get()
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		expected := [][2]string{{"123", "true"}, {"dGVzdA==", "2024-01-01"}, {"1.5", `{"a":1}`}}
+		for i, row := range rows {
+			if row["name"] != expected[i][0] || row["status"] != expected[i][1] {
+				t.Errorf("smart=%v: expected strings %v, got %T(%v) %T(%v)",
+					smart, expected[i], row["name"], row["name"], row["status"], row["status"])
+			}
+			if _, ok := row["age"].(int64); !ok {
+				t.Errorf("smart=%v: expected int64 age, got %T", smart, row["age"])
+			}
+		}
+	}
+	SetSmartConversion(false)
+
+	// 没有列类型信息时，默认不做推断
+	qb := newFakeBuilder("mysql", "users")
+	if v := qb.convertDatabaseValue([]byte("123")); v != "123" {
+		t.Errorf("Expected plain string without smart conversion, got %T(%v)", v, v)
+	}
+	if v := qb.convertDatabaseValue("dGVzdA=="); v != "dGVzdA==" {
+		t.Errorf("Expected base64 text to stay untouched, got %v", v)
+	}
+
+	SetSmartConversion(true)
+	defer SetSmartConversion(false)
+	if v := qb.convertDatabaseValue([]byte("123")); v != int64(123) {
+		t.Errorf("Expected int64 with smart conversion, got %T(%v)", v, v)
+	}
+}
+
+// 测试时区感知的时间解析和绑定
+func TestTimezoneAwareTimes(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// 未配置时区时按服务器本地时区绑定，UTC 需要显式设置
+	local := time.Date(2024, 3, 10, 18, 0, 0, 0, shanghai)
+	_, args, _ := newFakeBuilder("mysql", "events").WhereTime("created_at", ">=", local).ToSQL()
+	if len(args) != 1 || !isTimeArg(args[0], local.In(time.Local)) {
+		t.Errorf("Expected local time binding, got %v", args)
+	}
+	utc := newFakeBuilder("mysql", "events")
+	utc.connection.(*fakeDriverConnection).config = (&Config{}).SetTimezone(time.UTC)
+	_, args, _ = utc.WhereTime("created_at", ">=", local).ToSQL()
+	if len(args) != 1 || !isTimeArg(args[0], time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected UTC binding, got %v", args)
+	}
+
+	qb := newFakeBuilder("mysql", "events")
+	qb.connection.(*fakeDriverConnection).config = (&Config{}).SetTimezone(newYork)
+	_, args, _ = qb.WhereTime("created_at", "<", local).ToSQL()
+	if len(args) != 1 || !isTimeArg(args[0], time.Date(2024, 3, 10, 6, 0, 0, 0, newYork)) {
+		t.Errorf("Expected New York binding across DST, got %v", args)
+	}
+	if _, _, err := newFakeBuilder("mysql", "events").WhereTime("created_at", "; DROP", local).ToSQL(); err == nil {
+		t.Errorf("Expected invalid operator to fail")
+	}
+	if _, _, err := newFakeBuilder("mysql", "events").WhereTime("created_at;", "=", local).ToSQL(); err == nil {
+		t.Errorf("Expected invalid column to fail")
+	}
+
+	// 时间字段管理器按配置的时区解析DATETIME字符串
+	parsed := NewTimeFieldManager().WithLocation(newYork).ParseTimeValue("2024-03-10 03:30:00", reflect.TypeOf(time.Time{}))
+	expected := time.Date(2024, 3, 10, 3, 30, 0, 0, newYork)
+	if pt, ok := parsed.(time.Time); !ok || !pt.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, parsed)
+	}
+	shared := NewTimeFieldManager()
+	if shared.WithLocation(newYork); shared.Location() != time.Local {
+		t.Errorf("Expected WithLocation to leave the shared manager unchanged")
+	}
+	parsed = NewTimeFieldManager().ParseTimeValue("2024-03-10 03:30:00", reflect.TypeOf(time.Time{}))
+	if pt, ok := parsed.(time.Time); !ok || pt.Location() != time.Local || pt.Hour() != 3 {
+		t.Errorf("Expected local time by default, got %v", parsed)
+	}
+
+	// 查询结果按连接时区解析
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE events (id INTEGER PRIMARY KEY, happened_at TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO events (id, happened_at) VALUES (1, '2024-03-10 18:00:00')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if err := SetTimezone(shanghai, connName); err != nil {
+		t.Fatalf("SetTimezone failed: %v", err)
+	}
+	query, _ := NewQueryBuilder(connName)
+	if pt, ok := query.tryParseTime("2024-03-10 18:00:00"); !ok || !pt.Equal(local) {
+		t.Errorf("Expected %v, got %v", local, pt)
+	}
+	query, _ = NewQueryBuilder(connName)
+	count, err := query.From("events").WhereTime("happened_at", "=", local.In(time.UTC)).Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected WhereTime to match stored local time, got %d (%v)", count, err)
+	}
+}
+
+// 测试按连接时区计算的日期区间条件
+func TestWhereDateHelpers(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+
+	qb := newFakeBuilder("postgres", "events")
+	qb.connection.(*fakeDriverConnection).config = (&Config{}).SetTimezone(shanghai)
+	// 上海时间1月31日凌晨，UTC仍是1月30日
+	start := time.Date(2024, 1, 1, 9, 30, 0, 0, shanghai)
+	end := time.Date(2024, 1, 30, 20, 0, 0, 0, time.UTC)
+	sql, args, err := qb.WhereDateRange("created_at", start, end).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if sql != "SELECT * FROM events WHERE created_at >= $1 AND created_at < $2" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if len(args) != 2 || !isTimeArg(args[0], time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai)) || !isTimeArg(args[1], time.Date(2024, 2, 1, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "events").WhereDateRange("created_at", end, start).ToSQL(); err == nil {
+		t.Error("Expected error when end is before start")
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	_, args, _ = newFakeBuilder("mysql", "events").WhereToday("created_at").ToSQL()
+	if len(args) != 2 || !isTimeArg(args[0], today) || !isTimeArg(args[1], today.AddDate(0, 0, 1)) {
+		t.Errorf("Unexpected today args: %v", args)
+	}
+
+	_, args, _ = newFakeBuilder("mysql", "events").WhereThisWeek("created_at").ToSQL()
+	weekStart, ok := args[0].(time.Time)
+	if !ok || weekStart.Weekday() != time.Monday || weekStart.After(today) || today.Sub(weekStart) >= 7*24*time.Hour {
+		t.Errorf("Unexpected week start: %v", args)
+	}
+
+	_, args, _ = newFakeBuilder("mysql", "events").WhereThisMonth("created_at").ToSQL()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if !isTimeArg(args[0], monthStart) || !isTimeArg(args[1], monthStart.AddDate(0, 1, 0)) {
+		t.Errorf("Unexpected month args: %v", args)
+	}
+
+	// 结束日期当天的记录包含在区间内
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE events (id INTEGER PRIMARY KEY, created_at TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO events (id, created_at) VALUES
+		(1, '2023-12-31 23:59:59'), (2, '2024-01-01 00:00:00'), (3, '2024-01-31 23:59:59'), (4, '2024-02-01 00:00:00')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	query, _ := Table("events", connName)
+	rows, err := query.WhereDateRange("created_at", time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)).
+		OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["id"] != int64(2) || rows[1]["id"] != int64(3) {
+		t.Errorf("Expected events 2 and 3, got %v", rows)
+	}
+}
+
+// 测试原生条件中的命名参数
+func TestNamedBindings(t *testing.T) {
+	sql, args, _ := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereRaw("age < :max AND age > :min AND name <> ':min' AND created_at::date = :day AND id IN (:ids)",
+			map[string]interface{}{"min": 18, "max": 65, "day": "2024-01-01", "ids": []int{1, 2}}).
+		ToSQL()
+	expected := "SELECT * FROM users WHERE status = $1 AND age < $2 AND age > $3 AND name <> ':min' AND created_at::date = $4 AND id IN ($5, $6)"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"active", 65, 18, "2024-01-01", 1, 2}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "users").bindNamed("age > :min", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for missing named parameter")
+	}
+	if _, _, err := newFakeBuilder("mysql", "users").WhereRaw("age > :min", map[string]interface{}{}).ToSQL(); err == nil {
+		t.Error("Expected WhereRaw to record the missing named parameter error")
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().
+		Where("age >= :min AND age <= :max", map[string]interface{}{"max": 25, "min": 21}).
+		OrWhere("name = :name", map[string]interface{}{"name": "carol"}).
+		OrderBy("id", "ASC").
+		Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 3 || rows[0]["name"] != "bob" || rows[2]["name"] != "dave" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+// 测试表前缀：查询和写入自动加前缀，加了前缀的表以原表名作为别名
+func TestTablePrefix(t *testing.T) {
+	config := &Config{Prefix: "app_"}
+	if got := config.PrefixTable("public.users"); got != "public.app_users" {
+		t.Errorf("Expected schema-qualified prefix, got '%s'", got)
+	}
+	if got := config.PrefixTable("users u"); got != "app_users u" {
+		t.Errorf("Expected alias to be kept, got '%s'", got)
+	}
+	if got := config.PrefixTable("public.app_users"); got != "public.app_users" {
+		t.Errorf("Expected already prefixed table to be kept, got '%s'", got)
+	}
+
+	qb, _ := NewQueryBuilder("fake")
+	qb.connection = &fakeDriverConnection{driver: "mysql", config: config}
+	sql, _, _ := qb.From("users").
+		LeftJoin("posts", "posts.user_id = users.id").
+		Where("users.id", "=", 1).
+		ToSQL()
+	expected := "SELECT * FROM app_users users LEFT JOIN app_posts posts ON posts.user_id = users.id WHERE users.id = ?"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+
+	if sql, _ := qb.Clone().buildInsertSQL(map[string]interface{}{"name": "a"}); sql != "INSERT INTO app_users (name) VALUES (?)" {
+		t.Errorf("Unexpected insert SQL: %s", sql)
+	}
+	if sql, _, _ := qb.Clone().RawTable("users").ToSQL(); !strings.HasPrefix(sql, "SELECT * FROM users LEFT JOIN app_posts") {
+		t.Errorf("Expected raw table without prefix, got: %s", sql)
+	}
+
+	// 显式别名只为表名加前缀
+	aliased, _ := NewQueryBuilder("fake")
+	aliased.connection = &fakeDriverConnection{driver: "mysql", config: config}
+	sql, _, _ = aliased.From("users AS u").Join("posts p", "p.user_id", "=", "u.id").ToSQL()
+	if sql != "SELECT * FROM app_users u INNER JOIN app_posts p ON p.user_id = u.id" {
+		t.Errorf("Unexpected aliased SQL: %s", sql)
+	}
+
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE app_users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if err := SetTablePrefix("app_", connName); err != nil {
+		t.Fatalf("SetTablePrefix failed: %v", err)
+	}
+	table, _ := Table("users", connName)
+	if _, err := table.Insert(map[string]interface{}{"name": "alice"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	table, _ = Table("users", connName)
+	row, err := table.Where("users.name", "=", "alice").First()
+	if err != nil || row["name"] != "alice" {
+		t.Errorf("Expected prefixed table query to find row, got %v, %v", row, err)
+	}
+
+	// UPDATE 和 DELETE 同样以原表名作为别名
+	for driver, want := range map[string][2]string{
+		"mysql":     {"UPDATE app_users AS users SET name = ? WHERE users.id = ?", "DELETE users FROM app_users AS users WHERE users.id = ?"},
+		"postgres":  {"UPDATE app_users AS users SET name = $1 WHERE users.id = $2", "DELETE FROM app_users AS users WHERE users.id = $1"},
+		"sqlserver": {"UPDATE users SET name = @p1 FROM app_users AS users WHERE users.id = @p2", "DELETE users FROM app_users AS users WHERE users.id = @p1"},
+	} {
+		fake := newFakeBuilder(driver, "users")
+		fake.connection = &fakeDriverConnection{driver: driver, config: config}
+		if sql, _ := fake.Clone().Where("users.id", "=", 1).buildUpdateSQL(map[string]interface{}{"name": "a"}); sql != want[0] {
+			t.Errorf("[%s] Unexpected update SQL:\n got: %s\nwant: %s", driver, sql, want[0])
+		}
+		if sql, _ := fake.Clone().Where("users.id", "=", 1).buildDeleteSQL(); sql != want[1] {
+			t.Errorf("[%s] Unexpected delete SQL:\n got: %s\nwant: %s", driver, sql, want[1])
+		}
+	}
+
+	table, _ = Table("users", connName)
+	if affected, err := table.Where("users.name", "=", "alice").Update(map[string]interface{}{"name": "bob"}); err != nil || affected != 1 {
+		t.Errorf("Expected prefixed table update to affect 1 row, got %d, %v", affected, err)
+	}
+	table, _ = Table("users", connName)
+	if affected, err := table.Where("users.name", "=", "bob").Delete(); err != nil || affected != 1 {
+		t.Errorf("Expected prefixed table delete to affect 1 row, got %d, %v", affected, err)
+	}
+}
+
+// 测试ForceDelete忽略软删除作用域，且拒绝无条件删除
+func TestForceDelete(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	softDeleted := func(q *QueryBuilder) *QueryBuilder {
+		return q.Where("status", "!=", "banned")
+	}
+
+	affected, err := table().WithGlobalScope(SoftDeleteScope, softDeleted).Where("status", "=", "banned").Delete()
+	if err != nil || affected != 0 {
+		t.Fatalf("Expected scoped Delete to skip soft-deleted rows, got %d, %v", affected, err)
+	}
+
+	affected, err = table().WithGlobalScope(SoftDeleteScope, softDeleted).Where("status", "=", "banned").ForceDelete()
+	if err != nil || affected != 1 {
+		t.Fatalf("Expected ForceDelete to remove 1 row, got %d, %v", affected, err)
+	}
+
+	if _, err := table().WithGlobalScope(SoftDeleteScope, softDeleted).ForceDelete(); err == nil {
+		t.Error("Expected ForceDelete without WHERE to fail")
+	}
+	if count, _ := table().Count(); count != 3 {
+		t.Errorf("Expected 3 rows left, got %d", count)
+	}
+}
+
+// 测试不绑定模型时WithTimestamps自动填充时间列
+func TestWithTimestamps(t *testing.T) {
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	table := func() *QueryBuilder {
+		qb, _ := Table("posts", connName)
+		return qb
+	}
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := table().WithTimestamps("created_at", "updated_at").Insert(map[string]interface{}{"title": "a", "created_at": created}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := table().WithTimestamps("created_at", "").Insert(map[string]interface{}{"title": "b"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	rows, _ := table().OrderBy("id", "ASC").Get()
+	if len(rows) != 2 || rows[0]["updated_at"] == nil || rows[1]["created_at"] == nil || rows[1]["updated_at"] != nil {
+		t.Fatalf("Unexpected timestamps after insert: %v", rows)
+	}
+	if got, ok := rows[0]["created_at"].(time.Time); !ok || !got.Equal(created) {
+		t.Errorf("Expected provided created_at to be kept, got %v", rows[0]["created_at"])
+	}
+
+	if _, err := table().WithTimestamps("created_at", "updated_at").Where("id", "=", 2).Update(map[string]interface{}{"title": "c"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	row, _ := table().Where("id", "=", 2).First()
+	if row["updated_at"] == nil || row["created_at"] == nil {
+		t.Errorf("Expected update to stamp updated_at only, got %v", row)
+	}
+}
+
+// 测试InsertIgnore跳过重复键并返回实际插入的行数
+func TestInsertIgnore(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	affected, err := table().InsertIgnore(map[string]interface{}{"id": 1, "name": "eve"})
+	if err != nil || affected != 0 {
+		t.Fatalf("Expected duplicate to be skipped, got %d, %v", affected, err)
+	}
+	affected, err = table().SetInsertChunkSize(2).InsertIgnoreBatch([]map[string]interface{}{
+		{"id": 2, "name": "x"},
+		{"id": 5, "name": "eve"},
+		{"id": 6, "name": "frank"},
+	})
+	if err != nil || affected != 2 {
+		t.Fatalf("Expected 2 rows inserted, got %d, %v", affected, err)
+	}
+	if row, _ := table().Where("id", "=", 1).First(); row["name"] != "alice" {
+		t.Errorf("Expected existing row to be unchanged, got %v", row)
+	}
+
+	tests := map[string]string{
+		"mysql":    "INSERT IGNORE INTO users (id) VALUES (?)",
+		"postgres": "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING",
+		"mssql":    "BEGIN TRY INSERT INTO users (id) VALUES (@p1) END TRY BEGIN CATCH IF ERROR_NUMBER() NOT IN (2601, 2627) THROW; END CATCH",
+	}
+	for driver, expected := range tests {
+		qb := newFakeBuilder(driver, "users").DryRun()
+		qb.InsertIgnore(map[string]interface{}{"id": 1})
+		if sql, _ := qb.LastSQL(); sql != expected {
+			t.Errorf("[%s] Expected '%s', got '%s'", driver, expected, sql)
+		}
+	}
+}
+
+// 测试UpdateReturning和DeleteReturning返回受影响的行
+func TestUpdateDeleteReturning(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	rows, err := table().Where("age", "=", 25).OrderBy("id", "ASC").UpdateReturning(map[string]interface{}{"status": "vip"}, "id", "status")
+	if err != nil {
+		t.Fatalf("UpdateReturning failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["status"] != "vip" || len(rows[0]) != 2 {
+		t.Errorf("Unexpected returned rows: %v", rows)
+	}
+
+	rows, err = table().Where("status", "=", "banned").DeleteReturning()
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "carol" {
+		t.Fatalf("Expected deleted carol, got %v, %v", rows, err)
+	}
+
+	dry := newFakeBuilder("postgres", "users").Where("id", "=", 1).DryRun()
+	dry.DeleteReturning("id", "name")
+	if sql, _ := dry.LastSQL(); sql != "DELETE FROM users WHERE id = $1 RETURNING id, name" {
+		t.Errorf("Unexpected delete SQL: %s", sql)
+	}
+
+	// 开启引号时返回列同样加引号
+	dry = newFakeBuilder("postgres", "users").QuoteIdentifiers().Where("id", "=", 1).DryRun()
+	dry.DeleteReturning("id", "order")
+	if sql, _ := dry.LastSQL(); sql != `DELETE FROM "users" WHERE "id" = $1 RETURNING "id", "order"` {
+		t.Errorf("Unexpected quoted delete SQL: %s", sql)
+	}
+
+	// 不支持RETURNING时的预演在克隆上生成修改语句
+	dry = newFakeBuilder("mysql", "users").Where("id", "=", 1).DryRun()
+	if _, err := dry.UpdateReturning(map[string]interface{}{"age": 30}, "id"); err != nil {
+		t.Fatalf("Dry-run UpdateReturning failed: %v", err)
+	}
+	if sql, _ := dry.LastSQL(); !strings.HasPrefix(sql, "UPDATE") {
+		t.Errorf("Expected recorded UPDATE, got %s", sql)
+	}
+	dry = newFakeBuilder("mysql", "users").Where("id", "=", 1).DryRun()
+	if _, err := dry.DeleteReturning("id"); err != nil {
+		t.Fatalf("Dry-run DeleteReturning failed: %v", err)
+	}
+	if sql, _ := dry.LastSQL(); !strings.HasPrefix(sql, "DELETE") {
+		t.Errorf("Expected recorded DELETE, got %s", sql)
+	}
+
+	// 不支持RETURNING时的回退：事务中先查询再修改
+	qb := table().Where("name", "=", "alice")
+	rows, err = qb.selectThenModify([]string{"id", "age"}, func(query *QueryBuilder) (int64, error) {
+		return query.Update(map[string]interface{}{"age": 21})
+	})
+	if err != nil || len(rows) != 1 || rows[0]["id"] != int64(1) {
+		t.Fatalf("Unexpected fallback result: %v, %v", rows, err)
+	}
+	if row, _ := table().Where("id", "=", 1).First(); row["age"] != int64(21) {
+		t.Errorf("Expected fallback update to be committed, got %v", row)
+	}
+}
+
+// 测试事务选项：连接默认值、显式选项和上下文传递
+func TestTxOptions(t *testing.T) {
+	config := &Config{}
+	if config.txOptions(nil) != nil {
+		t.Errorf("Expected nil options without connection defaults")
+	}
+	config.SetTxOptions(TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if opts := config.txOptions(nil); opts == nil || opts.Isolation != sql.LevelRepeatableRead || !opts.ReadOnly {
+		t.Errorf("Expected connection defaults, got %+v", opts)
+	}
+	if opts := config.txOptions(&TxOptions{Isolation: sql.LevelSerializable}); opts.Isolation != sql.LevelSerializable || opts.ReadOnly {
+		t.Errorf("Expected explicit options to override defaults, got %+v", opts)
+	}
+
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE logs (id INTEGER PRIMARY KEY, message TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	err := TransactionWithOptions(context.Background(), &TxOptions{Isolation: sql.LevelSerializable}, func(tx TransactionInterface) error {
+		_, err := tx.Exec("INSERT INTO logs (message) VALUES (?)", "hello")
+		return err
+	}, connName)
+	if err != nil {
+		t.Fatalf("TransactionWithOptions failed: %v", err)
+	}
+	var count int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count); err != nil || count != 1 {
+		t.Errorf("Expected committed row, got %d, %v", count, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := conn.BeginTx(ctx, nil); err == nil {
+		t.Errorf("Expected BeginTx to fail with cancelled context")
+	}
+}
+
+// 测试WhereAny和WhereAll生成带括号的多列条件组
+func TestWhereAnyAll(t *testing.T) {
+	sql, args, _ := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereAny([]string{"name", "email"}, "LIKE", "%tom%").
+		OrWhere("id", "=", 1).
+		ToSQL()
+	expected := "SELECT * FROM users WHERE status = $1 AND (name LIKE $2 OR email LIKE $3) OR id = $4"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 4 || args[1] != "%tom%" || args[2] != "%tom%" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	sql, _, _ = newFakeBuilder("mysql", "users").WhereAll([]string{"created_by", "updated_by"}, "=", nil).ToSQL()
+	if sql != "SELECT * FROM users WHERE (created_by IS NULL AND updated_by IS NULL)" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	// 无效的操作符或列名使查询失败，而不是丢弃整组条件后查询整张表
+	for name, invalid := range map[string]*QueryBuilder{
+		"operator": newFakeBuilder("mysql", "users").WhereAny([]string{"name", "email"}, "==", "x"),
+		"injected": newFakeBuilder("mysql", "users").WhereAny([]string{"name"}, "; DROP", 1),
+		"column":   newFakeBuilder("mysql", "users").WhereAll([]string{"name", "e;mail"}, "=", "x"),
+	} {
+		if sql, _, err := invalid.ToSQL(); err == nil || strings.Contains(sql, "DROP") {
+			t.Errorf("%s: expected error, got SQL %q", name, sql)
+		}
+	}
+
+	table := setupTestTable(t, testUsers)
+	count, err := table().WhereAny([]string{"name", "status"}, "=", "banned").Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 row, got %d (%v)", count, err)
+	}
+}
+
+// typedUser 类型化查询测试使用的模型
+type typedUser struct {
+	ID   int64  `json:"id" torm:"primary_key"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (typedUser) TableName() string { return "users" }
+
+// 测试All和FindByID返回类型化结果
+func TestTypedQueries(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	connName := table().connectionName
+
+	users, err := All[typedUser](connName)
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if len(users) != 4 || users[1].Name != "bob" || users[1].Age != 25 {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+
+	user, err := FindByID[typedUser](connName, 3)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if user.ID != 3 || user.Name != "carol" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	if _, err := FindByID[typedUser](connName, 99); !IsNotFoundError(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	query, err := QueryOf[typedUser](connName)
+	if err != nil {
+		t.Fatalf("QueryOf failed: %v", err)
+	}
+	users, err = query.Where("age", "=", 25).OrderBy("id", "DESC").Get()
+	if err != nil || len(users) != 2 || users[0].Name != "dave" {
+		t.Errorf("Unexpected typed query result: %+v, %v", users, err)
+	}
+
+	query, _ = QueryOf[typedUser](connName)
+	first, err := query.Where("name", "=", "alice").First()
+	if err != nil || first.ID != 1 || first.Age != 20 {
+		t.Errorf("Unexpected first result: %+v, %v", first, err)
+	}
+}
+
+// 测试不支持的条件参数格式记录为错误，而不是静默忽略
+func TestInvalidWhereArguments(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	qb := table().Where(1)
+	if qb.Err() == nil {
+		t.Fatal("Expected error for non-string Where argument")
+	}
+	if _, err := qb.Delete(); err == nil {
+		t.Error("Expected Delete to fail with invalid Where")
+	}
+	if count, _ := table().Count(); count != 4 {
+		t.Errorf("Expected no rows deleted, got count %d", count)
+	}
+
+	if _, err := table().OrWhere("age", 1, 2).Get(); err == nil {
+		t.Error("Expected error for non-string operator")
+	}
+	if _, err := table().WhereGroup(func(q *QueryBuilder) { q.Where(nil, "x") }).Update(map[string]interface{}{"age": 1}); err == nil {
+		t.Error("Expected error from nested group to propagate")
+	}
+	if _, _, err := newFakeBuilder("mysql", "users").GroupBy("status").Having(5).ToSQL(); err == nil {
+		t.Error("Expected ToSQL to report invalid Having")
+	}
+
+	if err := table().Where("status", "=", "active").Err(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// 测试EXISTS子查询的占位符按外层查询的参数顺序编号
+func TestWhereExistsPlaceholders(t *testing.T) {
+	orders := newFakeBuilder("postgres", "orders").
+		WhereRaw("orders.user_id = users.id").
+		Where("amount", ">", 100).
+		WhereIn("state", []interface{}{"paid", "shipped"})
+
+	sql, args, err := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereExists(orders).
+		Where("age", ">", 18).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	expected := "SELECT * FROM users WHERE status = $1 AND EXISTS (SELECT * FROM orders WHERE orders.user_id = users.id AND amount > $2 AND state IN ($3, $4)) AND age > $5"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 5 || args[1] != 100 || args[4] != 18 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// 子查询本身单独构建时仍使用 PostgreSQL 占位符
+	if sql, _, _ := orders.ToSQL(); !strings.Contains(sql, "amount > $1") {
+		t.Errorf("Expected subquery placeholders to be unaffected, got: %s", sql)
+	}
+}
+
+// 测试闭包形式的相关 EXISTS 子查询
+func TestWhereExistsClosure(t *testing.T) {
+	sql, args, err := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereExists(func(sub *QueryBuilder) {
+			sub.From("orders").WhereColumn("orders.user_id", "=", sub.OuterTable()+".id").Where("amount", ">", 100)
+		}).
+		WhereNotExists(func(sub *QueryBuilder) {
+			sub.From("bans").WhereColumn("bans.user_id", "=", "users.id")
+		}).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	expected := "SELECT * FROM users WHERE status = $1 AND EXISTS (SELECT * FROM orders WHERE orders.user_id = users.id AND amount > $2) " +
+		"AND NOT EXISTS (SELECT * FROM bans WHERE bans.user_id = users.id)"
+	if sql != expected {
+		t.Errorf("Unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != "active" || args[1] != 100 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "users").WhereExists(func(sub *QueryBuilder) {}).ToSQL(); err == nil {
+		t.Error("Expected error for subquery without table")
+	}
+	if _, _, err := newFakeBuilder("mysql", "users").WhereColumn("a", "; DROP", "b").ToSQL(); err == nil {
+		t.Error("Expected error for invalid operator")
+	}
+
+	table := setupTestTable(t, testUsers)
+	conn, err := table().getConnection()
+	if err != nil {
+		t.Fatalf("getConnection failed: %v", err)
+	}
+	if _, err := conn.Exec("CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, amount INTEGER)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO orders (user_id, amount) VALUES (1, 50), (2, 150), (4, 200)"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	rows, err := table().WhereExists(func(sub *QueryBuilder) {
+		sub.From("orders").WhereColumn("orders.user_id", "=", "users.id").Where("amount", ">", 100)
+	}).Where("status", "=", "active").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["name"] != "bob" {
+		t.Errorf("Expected only bob, got %v", rows)
+	}
+}
+
+// 测试连接级默认查询超时
+func TestDefaultQueryTimeout(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	conn, err := table().getConnection()
+	if err != nil {
+		t.Fatalf("getConnection failed: %v", err)
+	}
+	conn.GetConfig().SetQueryTimeout(time.Nanosecond)
+	defer conn.GetConfig().SetQueryTimeout(0)
+
+	qb := table()
+	if _, err := qb.Get(); err == nil {
+		t.Fatal("Expected default query timeout to cancel the query")
+	}
+	if qb.cancel != nil {
+		t.Error("Expected default timeout to be released after execution")
+	}
+
+	// 显式超时和带截止时间的上下文优先于默认超时
+	if rows, err := qb.WithTimeout(time.Minute).Get(); err != nil || len(rows) != 4 {
+		t.Errorf("Expected explicit timeout to override default, got %d rows, %v", len(rows), err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if count, err := table().WithContext(ctx).Count(); err != nil || count != 4 {
+		t.Errorf("Expected context deadline to override default, got %d, %v", count, err)
+	}
+
+	conn.GetConfig().SetQueryTimeout(0)
+	if _, err := table().Get(); err != nil {
+		t.Errorf("Expected no timeout when QueryTimeout is 0, got %v", err)
+	}
+}
+
+// 测试各数据库的全文搜索条件
+func TestWhereFulltext(t *testing.T) {
+	sql, args, _ := newFakeBuilder("mysql", "posts").WhereFulltext([]string{"title", "body"}, "+go -java", FulltextBoolean).ToSQL()
+	if sql != "SELECT * FROM posts WHERE MATCH(title, body) AGAINST (? IN BOOLEAN MODE)" || len(args) != 1 || args[0] != "+go -java" {
+		t.Errorf("Unexpected MySQL SQL: %s %v", sql, args)
+	}
+
+	sql, args, _ = newFakeBuilder("postgres", "posts").Where("status", "=", 1).WhereFulltext([]string{"title", "body"}, "golang", "").ToSQL()
+	expected := "SELECT * FROM posts WHERE status = $1 AND (to_tsvector('english', title) @@ plainto_tsquery('english', $2) OR to_tsvector('english', body) @@ plainto_tsquery('english', $3))"
+	if sql != expected || len(args) != 3 {
+		t.Errorf("Unexpected PostgreSQL SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "posts").WhereFulltext([]string{"title"}, "go", "fuzzy").ToSQL(); err == nil {
+		t.Error("Expected error for unsupported mode")
+	}
+	if sql, _, _ := newFakeBuilder("mysql", "posts").WhereFulltext([]string{"title"}, "", "").ToSQL(); sql != "SELECT * FROM posts" {
+		t.Errorf("Expected empty term to be ignored, got: %s", sql)
+	}
+
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE VIRTUAL TABLE posts USING fts5(title, body)"); err != nil {
+		t.Skipf("FTS5 not available: %v", err)
+	}
+	conn.Exec("INSERT INTO posts (title, body) VALUES ('hello world', 'go is fun'), ('other', 'hello there')")
+
+	qb, _ := Table("posts", connName)
+	count, err := qb.WhereFulltext([]string{"title"}, "hello", "").Count()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 match in title, got %d (%v)", count, err)
+	}
+	qb, _ = Table("posts", connName)
+	count, err = qb.WhereFulltext([]string{"title", "body"}, "hello", "").Count()
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 matches across columns, got %d (%v)", count, err)
+	}
+}
+
+// 测试INSERT和UPDATE按列名排序，生成的SQL和参数顺序稳定
+func TestInsertUpdateColumnOrder(t *testing.T) {
+	data := map[string]interface{}{"name": "alice", "age": 20, "status": "active", "email": "a@example.com", "city": "x"}
+
+	for i := 0; i < 20; i++ {
+		qb := newFakeBuilder("postgres", "users")
+		sql, args := qb.buildInsertSQL(data)
+		if sql != "INSERT INTO users (age, city, email, name, status) VALUES ($1, $2, $3, $4, $5)" {
+			t.Fatalf("Unexpected insert SQL: %s", sql)
+		}
+		if args[0] != 20 || args[3] != "alice" {
+			t.Fatalf("Unexpected insert args: %v", args)
+		}
+
+		sql, args = qb.Where("id", "=", 1).buildUpdateSQL(data)
+		if sql != "UPDATE users SET age = $1, city = $2, email = $3, name = $4, status = $5 WHERE id = $6" {
+			t.Fatalf("Unexpected update SQL: %s", sql)
+		}
+		if args[4] != "active" || args[5] != 1 {
+			t.Fatalf("Unexpected update args: %v", args)
+		}
+	}
+}
+
+// 测试预处理语句缓存的复用、淘汰和关闭连接时的释放
+func TestPrepareStmtCache(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	conn, _ := table().getConnection()
+	conn.GetConfig().PrepareStmtCacheSize = 2
+	sqlDB := conn.GetDB()
+
+	for i := 0; i < 3; i++ {
+		rows, err := table().Where("age", "=", 25).Get()
+		if err != nil || len(rows) != 2 {
+			t.Fatalf("Get failed: %d rows, %v", len(rows), err)
+		}
+	}
+	cache := stmtCacheFor(sqlDB, conn.GetConfig())
+	if cache.len() != 1 {
+		t.Errorf("Expected 1 cached statement, got %d", cache.len())
+	}
+
+	if _, err := table().Where("id", "=", 1).Update(map[string]interface{}{"age": 21}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if count, err := table().Count(); err != nil || count != 4 {
+		t.Fatalf("Count failed: %d, %v", count, err)
+	}
+	if cache.len() != 2 {
+		t.Errorf("Expected cache to be bounded to 2, got %d", cache.len())
+	}
+
+	// 被淘汰的语句再次执行时重新预处理
+	if rows, err := table().Where("age", "=", 25).Get(); err != nil || len(rows) != 2 {
+		t.Errorf("Expected evicted query to succeed, got %d rows, %v", len(rows), err)
+	}
+
+	conn.Close()
+	if _, ok := stmtCaches.Load(sqlDB); ok {
+		t.Error("Expected statement cache to be released when the connection closes")
+	}
+}
+
+// 测试闭包事务的提交、错误回滚和panic回滚
+func TestRunInTransaction(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	conn, _ := table().getConnection()
+
+	insert := func(tx TransactionInterface) error {
+		_, err := table().InTransaction(tx).Insert(map[string]interface{}{"name": "erin", "status": "active", "age": 40})
+		return err
+	}
+
+	if err := RunInTransaction(conn, insert); err != nil {
+		t.Fatalf("RunInTransaction failed: %v", err)
+	}
+
+	errRollback := fmt.Errorf("rollback")
+	err := RunInTransaction(conn, func(tx TransactionInterface) error {
+		if err := insert(tx); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Errorf("Expected fn error to be returned, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected panic to be re-raised, got %v", r)
+			}
+		}()
+		RunInTransaction(conn, func(tx TransactionInterface) error {
+			insert(tx)
+			panic("boom")
+		})
+	}()
+
+	if count, _ := table().Where("name", "=", "erin").Count(); count != 1 {
+		t.Errorf("Expected only the committed insert, got %d rows", count)
+	}
+}
+
+// 测试WhereBetween的参数校验、列比较和时间值绑定
+func TestWhereBetween(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	qb := table().WhereBetween("age", []interface{}{20})
+	if qb.Err() == nil {
+		t.Fatal("Expected error for wrong number of values")
+	}
+	if _, err := qb.Delete(); err == nil {
+		t.Error("Expected Delete to fail instead of deleting all rows")
+	}
+
+	count, err := table().WhereBetween("age", []interface{}{21, 29}).Count()
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 rows, got %d (%v)", count, err)
+	}
+
+	sql, _, _ := newFakeBuilder("mysql", "products").WhereBetweenColumns("price", "min_price", "max_price").ToSQL()
+	if sql != "SELECT * FROM products WHERE price BETWEEN min_price AND max_price" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	_, args, _ := newFakeBuilder("mysql", "orders").WhereBetween("created_at", []interface{}{start, start.Add(24 * time.Hour)}).ToSQL()
+	if len(args) != 2 || !isTimeArg(args[0], start.In(time.Local)) || !isTimeArg(args[1], start.Add(24*time.Hour).In(time.Local)) {
+		t.Errorf("Unexpected time args: %v", args)
+	}
+
+	// SQLite 没有时间类型，按连接时区格式化为文本绑定
+	_, args, _ = newFakeBuilder("sqlite", "orders").WhereBetween("created_at", []interface{}{start, start.Add(24 * time.Hour)}).ToSQL()
+	if len(args) != 2 || args[0] != start.In(time.Local).Format("2006-01-02 15:04:05") || args[1] != start.Add(24*time.Hour).In(time.Local).Format("2006-01-02 15:04:05") {
+		t.Errorf("Unexpected SQLite time args: %v", args)
+	}
+}
+
+// isTimeArg 绑定参数是否为指定时区下的指定时间
+func isTimeArg(arg interface{}, want time.Time) bool {
+	t, ok := arg.(time.Time)
+	return ok && t.Equal(want) && t.Location().String() == want.Location().String()
+}
+
+func TestWhereInChunks(t *testing.T) {
+	sql, args, err := newFakeBuilder("postgres", "users").
+		Where("status", "=", "active").
+		WhereInChunks("id", []interface{}{1, 2, 3, 4, 5}, 2).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	want := "SELECT * FROM users WHERE status = $1 AND (id IN ($2, $3) OR id IN ($4, $5) OR id IN ($6))"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if len(args) != 6 {
+		t.Errorf("Expected 6 args, got %v", args)
+	}
+
+	// 只有一个块时与 WhereIn 相同
+	sql, _, _ = newFakeBuilder("mysql", "users").WhereInChunks("id", []interface{}{1, 2}, 0).ToSQL()
+	if sql != "SELECT * FROM users WHERE id IN (?, ?)" {
+		t.Errorf("unexpected single chunk SQL: %s", sql)
+	}
+
+	newQuery := setupTestTable(t, testUsers)
+	ids := []interface{}{1, 2, 3, 4, 99}
+
+	// 按块拆分执行
+	results, err := newQuery().Where("status", "!=", "banned").WhereInChunks("id", ids, 2).Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected 3 rows, got %d", len(results))
+	}
+
+	// 有排序和分页时在一条语句中执行
+	results, err = newQuery().WhereInChunks("id", ids, 2).OrderBy("age", "desc").Limit(2).Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(results) != 2 || results[0]["name"] != "carol" {
+		t.Errorf("Unexpected ordered results: %v", results)
+	}
+
+	count, err := newQuery().WhereInChunks("id", ids, 2).Count()
+	if err != nil || count != 4 {
+		t.Errorf("Expected count 4, got %d (%v)", count, err)
+	}
+
+	// 每个块使用独立的缓存键，不会读到第一个块的缓存
+	key := fmt.Sprintf("chunked_%s", t.Name())
+	results, err = newQuery().Cache(time.Minute).CacheKey(key).WhereInChunks("id", []interface{}{1, 2, 3}, 1).Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if names := rowNames(results); names != "alice,bob,carol" {
+		t.Errorf("Expected each chunk to return its own row, got %s", names)
+	}
+
+	// 跨块的重复值不会产生重复的行
+	results, err = newQuery().WhereInChunks("id", []interface{}{1, 2, 1, 2}, 1).Get()
+	if err != nil || rowNames(results) != "alice,bob" {
+		t.Errorf("Expected duplicates to be removed, got %v (%v)", rowNames(results), err)
+	}
+
+	// 多次调用时按所有条件的块组合拆分执行
+	results, err = newQuery().
+		WhereInChunks("id", []interface{}{1, 2, 3, 4}, 2).
+		WhereInChunks("status", []interface{}{"active", "pending", "none"}, 2).
+		Get()
+	if err != nil || len(results) != 3 {
+		t.Errorf("Expected 3 rows for combined chunks, got %d (%v)", len(results), err)
+	}
+}
+
+// rowNames 按顺序拼接结果中的 name 列
+func rowNames(rows []map[string]interface{}) string {
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = fmt.Sprint(row["name"])
+	}
+	return strings.Join(names, ",")
+}
+
+func TestWhereJsonLengthAndArrayContains(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"mysql", "SELECT * FROM posts WHERE JSON_LENGTH(tags) > ? AND JSON_CONTAINS(tags, ?)"},
+		{"postgres", "SELECT * FROM posts WHERE jsonb_array_length(tags::jsonb) > $1 AND tags::jsonb @> $2::jsonb"},
+	}
+	for _, tt := range tests {
+		sql, args, err := newFakeBuilder(tt.driver, "posts").
+			WhereJsonLength("tags", ">", 1).
+			WhereArrayContains("tags", "go", "db").
+			ToSQL()
+		if err != nil {
+			t.Fatalf("%s: ToSQL failed: %v", tt.driver, err)
+		}
+		if sql != tt.want {
+			t.Errorf("%s: unexpected SQL:\n got: %s\nwant: %s", tt.driver, sql, tt.want)
+		}
+		if len(args) != 2 || args[1] != `["go","db"]` {
+			t.Errorf("%s: unexpected args: %v", tt.driver, args)
+		}
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "posts").WhereJsonLength("tags", "LIKE", 1).ToSQL(); err == nil {
+		t.Error("Expected error for unsupported operator")
+	}
+
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY, tags TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO posts (id, tags) VALUES (1, '["go","db"]'), (2, '["go"]'), (3, '[]')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	qb, _ := Table("posts", connName)
+	count, err := qb.WhereJsonLength("tags", ">=", 1).Count()
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 non-empty arrays, got %d (%v)", count, err)
+	}
+	qb, _ = Table("posts", connName)
+	rows, err := qb.WhereArrayContains("tags", "go", "db").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["id"] != int64(1) {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestWithCTE(t *testing.T) {
+	recent := newFakeBuilder("postgres", "orders").Select("user_id", "SUM(amount) as total").
+		Where("status", "=", "paid").GroupBy("user_id")
+	big := newFakeBuilder("postgres", "recent").Where("total", ">", 100)
+	sql, args, err := newFakeBuilder("postgres", "big").
+		With("recent", recent).
+		With("big", big).
+		Where("user_id", "!=", 1).
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	want := "WITH recent AS (SELECT user_id, SUM(amount) as total FROM orders WHERE status = $1 GROUP BY user_id), " +
+		"big AS (SELECT * FROM recent WHERE total > $2) SELECT * FROM big WHERE user_id != $3"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if len(args) != 3 || args[0] != "paid" || args[1] != 100 || args[2] != 1 {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	if _, _, err := newFakeBuilder("mysql", "t").With("bad name", recent).ToSQL(); err == nil {
+		t.Error("Expected error for invalid CTE name")
+	}
+
+	// 递归CTE遍历分类树
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE categories (id INTEGER PRIMARY KEY, parent_id INTEGER, name TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO categories (id, parent_id, name) VALUES
+		(1, NULL, 'root'), (2, 1, 'a'), (3, 2, 'a1'), (4, NULL, 'other'), (5, 4, 'b')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	anchor, _ := Table("categories", connName)
+	anchor.Select("id", "name").Where("id", "=", 1)
+	step, _ := Table("categories", connName)
+	step.Select("categories.id", "categories.name").Join("tree", "tree.id", "=", "categories.parent_id")
+	tree, _ := RawTable("tree", connName)
+	rows, err := tree.WithRecursive("tree", []string{"id", "name"}, anchor, step).OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 3 || rows[2]["name"] != "a1" {
+		t.Errorf("Unexpected tree rows: %v", rows)
+	}
+}
+
+type testMood int
+
+func TestRegisterScanConverter(t *testing.T) {
+	conn, connName := setupTestDB(t)
+	if _, err := conn.Exec("CREATE TABLE moods (id INTEGER PRIMARY KEY, mood MOOD(16), note TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO moods (id, mood, note) VALUES (1, 'happy', 'x'), (2, NULL, 'y')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	RegisterScanConverter("mood", func(raw []byte) (interface{}, error) {
+		switch string(raw) {
+		case "happy":
+			return testMood(1), nil
+		case "sad":
+			return testMood(2), nil
+		}
+		return nil, fmt.Errorf("unknown mood %q", raw)
+	})
+	t.Cleanup(func() { RegisterScanConverter("MOOD", nil) })
+
+	qb, _ := Table("moods", connName)
+	rows, err := qb.OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rows[0]["mood"] != testMood(1) || rows[1]["mood"] != nil || rows[0]["note"] != "x" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	if _, err := conn.Exec("INSERT INTO moods (id, mood) VALUES (3, 'bored')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	qb, _ = Table("moods", connName)
+	if _, err := qb.Get(); err == nil {
+		t.Error("Expected converter error to be returned")
+	}
+
+	RegisterScanConverter("MOOD", nil)
+	qb, _ = Table("moods", connName)
+	row, err := qb.Where("id", "=", 1).First()
+	if err != nil || row["mood"] != "happy" {
+		t.Errorf("Expected default conversion after unregister, got %v (%v)", row, err)
+	}
+}
+
+func TestFreshCacheAndWithoutCache(t *testing.T) {
+	newQuery := setupTestTable(t, testUsers)
+	key := "test_fresh_cache_" + t.Name()
+	defer GetDefaultCache().Delete(key)
+
+	count := func(qb *QueryBuilder) int {
+		rows, err := qb.Where("status", "=", "active").Get()
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		return len(rows)
+	}
+
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key)); n != 2 {
+		t.Fatalf("Expected 2 rows, got %d", n)
+	}
+	if _, err := newQuery().Where("name", "=", "dave").Update(map[string]interface{}{"status": "active"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// 缓存命中返回旧数据
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key)); n != 2 {
+		t.Errorf("Expected cached 2 rows, got %d", n)
+	}
+	// WithoutCache 直接查询数据库且不写入缓存
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key).WithoutCache()); n != 3 {
+		t.Errorf("Expected 3 rows without cache, got %d", n)
+	}
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key)); n != 2 {
+		t.Errorf("Expected cache to be untouched, got %d rows", n)
+	}
+	// FreshCache 跳过旧缓存并写入新结果
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key).FreshCache()); n != 3 {
+		t.Errorf("Expected 3 fresh rows, got %d", n)
+	}
+	if n := count(newQuery().Cache(time.Minute).CacheKey(key)); n != 3 {
+		t.Errorf("Expected refreshed cache with 3 rows, got %d", n)
+	}
+}
+
+// failingCache 始终返回错误的缓存，模拟缓存后端故障
+type failingCache struct {
+	CacheInterface
+	gets int
+	sets int
+}
+
+func (c *failingCache) Get(key string) (interface{}, error) {
+	c.gets++
+	return nil, fmt.Errorf("connection refused")
+}
+
+func (c *failingCache) Set(key string, value interface{}, ttl time.Duration) error {
+	c.sets++
+	return fmt.Errorf("connection refused")
+}
+
+func TestCacheFailureDegradation(t *testing.T) {
+	newQuery := setupTestTable(t, testUsers)
+	cache := &failingCache{}
+	SetDefaultCache(cache)
+	defer SetDefaultCache(nil)
+	defer queryCacheBreaker.recordSuccess()
+
+	threshold := CacheFailureThreshold
+	for i := 0; i < threshold+2; i++ {
+		rows, err := newQuery().Cache(time.Minute).Where("status", "=", "active").Get()
+		if err != nil {
+			t.Fatalf("Get should not fail when cache is down: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+	}
+
+	// 读写各计一次失败，达到阈值后停止读取
+	if cache.gets >= threshold+2 {
+		t.Errorf("Expected cache reads to stop after %d failures, got %d reads", threshold, cache.gets)
+	}
+	// 熔断期间同样不写入
+	if cache.gets+cache.sets != threshold {
+		t.Errorf("Expected cache access to stop after %d failures, got %d reads and %d writes", threshold, cache.gets, cache.sets)
+	}
+	if queryCacheBreaker.allow() {
+		t.Error("Expected breaker to be open")
+	}
+
+	queryCacheBreaker.recordSuccess()
+	if !queryCacheBreaker.allow() {
+		t.Error("Expected breaker to close after success")
+	}
+
+	// 未命中按错误值判断，与错误信息无关
+	if !isCacheMiss(ErrCacheKeyNotFound) || !isCacheMiss(ErrCacheExpired) || !isCacheMiss(WrapError(ErrCacheKeyNotFound, ErrCodeCacheFailed, "读取失败")) {
+		t.Error("Expected sentinel errors to be treated as cache misses")
+	}
+	if isCacheMiss(fmt.Errorf("dial tcp: cache miss handler unavailable")) {
+		t.Error("Expected backend errors mentioning a miss to count as failures")
+	}
+	memory := NewHighConcurrencyMemoryCache(nil)
+	defer memory.Close()
+	if _, err := memory.Get("missing"); !isCacheMiss(err) {
+		t.Errorf("Expected memory cache miss, got %v", err)
+	}
+}
+
+func TestOrderByRawBindings(t *testing.T) {
+	sql, args, err := newFakeBuilder("postgres", "users").
+		Select("status", "COUNT(*) as count").
+		Where("age", ">", 18).
+		GroupBy("status").
+		HavingRaw("COUNT(*) > ?", 1).
+		OrderByRaw("CASE WHEN status = ? THEN 0 WHEN status = ? THEN 1 ELSE 2 END", "active", "pending").
+		OrderBy("status", "asc").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	want := "SELECT status, COUNT(*) as count FROM users WHERE age > $1 GROUP BY status HAVING COUNT(*) > $2 " +
+		"ORDER BY CASE WHEN status = $3 THEN 0 WHEN status = $4 THEN 1 ELSE 2 END, status ASC"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{18, 1, "active", "pending"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// 原生排序的方向按原样保留
+	sql, _, _ = newFakeBuilder("mysql", "users").OrderByRaw("LENGTH(name) DESC").ToSQL()
+	if sql != "SELECT * FROM users ORDER BY LENGTH(name) DESC" {
+		t.Errorf("unexpected SQL: %s", sql)
+	}
+
+	newQuery := setupTestTable(t, testUsers)
+	rows, err := newQuery().OrderByRaw("CASE WHEN name = ? THEN 0 ELSE 1 END, id", "carol").Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 4 || rows[0]["name"] != "carol" || rows[1]["name"] != "alice" {
+		t.Errorf("Unexpected order: %v", rows)
+	}
+}
+
+func TestSelectRawBindings(t *testing.T) {
+	sql, args, err := newFakeBuilder("postgres", "orders").
+		Select("id").
+		SelectRaw("price * ? AS total", 2).
+		FieldRaw("COALESCE(note, :fallback) AS note", map[string]interface{}{"fallback": "-"}).
+		Where("status", "=", "paid").
+		ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	want := "SELECT id, price * $1 AS total, COALESCE(note, $2) AS note FROM orders WHERE status = $3"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{2, "-", "paid"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	newQuery := setupTestTable(t, testUsers)
+	row, err := newQuery().Select("name").SelectRaw("age + ? AS next_age", 10).Where("name", "=", "bob").First()
+	if err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+	if row["next_age"] != int64(35) {
+		t.Errorf("Expected next_age 35, got %v", row["next_age"])
+	}
+}
+
+// 测试分组计数和分组聚合
+func TestGroupedAggregates(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	counts, err := table().GroupedCounts("status")
+	if err != nil {
+		t.Fatalf("GroupedCounts failed: %v", err)
+	}
+	expected := map[interface{}]int64{"active": 2, "banned": 1, "pending": 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+
+	base := table().Where("age", ">", 20).OrderBy("name", "ASC").Limit(1)
+	counts, err = base.GroupedCounts("age")
+	if err != nil {
+		t.Fatalf("GroupedCounts with conditions failed: %v", err)
+	}
+	if len(counts) != 2 || counts[int64(25)] != 2 || counts[int64(30)] != 1 {
+		t.Errorf("Unexpected age counts: %v", counts)
+	}
+	if sql, _, _ := base.ToSQL(); sql != "SELECT * FROM users WHERE age > ? ORDER BY name ASC LIMIT 1" {
+		t.Errorf("Expected builder to be unchanged, got %s", sql)
+	}
+
+	totals, err := table().Aggregate("status", "SUM(age)")
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if totals["active"] != int64(45) || totals["banned"] != int64(30) {
+		t.Errorf("Unexpected totals: %v", totals)
+	}
+
+	if _, err := table().Aggregate("status", "SUM(age); DROP TABLE users"); err == nil {
+		t.Error("Expected error for dangerous aggregate expression")
+	}
+	if _, err := table().GroupedCounts("status; --"); err == nil {
+		t.Error("Expected error for invalid group column")
+	}
+}
+
+// 测试 INSERT ... SELECT
+func TestInsertUsing(t *testing.T) {
+	sub := newFakeBuilder("postgres", "orders").Select("id", "user_id").Where("status", "=", "done").Where("amount", ">", 10)
+	sql, args := newFakeBuilder("postgres", "archive").Where("ignored", "=", 1).buildInsertUsingSQL([]string{"id", "user_id"}, sub)
+	if sql != "INSERT INTO archive (id, user_id) SELECT id, user_id FROM orders WHERE status = $1 AND amount > $2" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if len(args) != 2 || args[0] != "done" || args[1] != 10 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	if n := newFakeBuilder("mysql", "orders").Select("id, COALESCE(a, b)").SelectRaw("amount * 2").selectFieldCount(); n != 3 {
+		t.Errorf("Expected 3 selected fields, got %d", n)
+	}
+	if n := newFakeBuilder("mysql", "orders").Select("orders.*").selectFieldCount(); n != 0 {
+		t.Errorf("Expected unknown field count for wildcard, got %d", n)
+	}
+
+	table := setupTestTable(t, testUsers)
+	conn, err := table().getConnection()
+	if err != nil {
+		t.Fatalf("getConnection failed: %v", err)
+	}
+	if _, err := conn.Exec("CREATE TABLE archived_users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	archive := func() *QueryBuilder { return table().From("archived_users") }
+	inserted, err := archive().InsertUsing([]string{"id", "name"}, table().Select("id", "name").Where("status", "=", "active"))
+	if err != nil {
+		t.Fatalf("InsertUsing failed: %v", err)
+	}
+	if inserted != 2 {
+		t.Errorf("Expected 2 inserted rows, got %d", inserted)
+	}
+	if count, _ := archive().Count(); count != 2 {
+		t.Errorf("Expected 2 archived rows, got %d", count)
+	}
+
+	if _, err := archive().InsertUsing([]string{"id"}, table().Select("id", "name")); err == nil {
+		t.Error("Expected column count mismatch error")
+	}
+	if _, err := archive().InsertUsing(nil, nil); err == nil {
+		t.Error("Expected error for missing subquery")
+	}
+}
+
+// 测试列名中的SQL关键字按完整单词匹配
+func TestColumnNameKeywordMatching(t *testing.T) {
+	qb := newFakeBuilder("mysql", "users")
+
+	for _, column := range []string{"created_at", "updated_by", "insert_time", "selection", "users.deleted_at", "execution_id"} {
+		if err := qb.validateColumnName(column); err != nil {
+			t.Errorf("Expected %q to be accepted, got %v", column, err)
+		}
+	}
+
+	for _, column := range []string{"drop", "users.delete", "id UNION SELECT 1", "name; DROP TABLE users", "id -- comment", "Update"} {
+		if err := qb.validateColumnName(column); err == nil {
+			t.Errorf("Expected %q to be rejected", column)
+		}
+	}
+
+	sql, _, err := newFakeBuilder("mysql", "users").Where("updated_by", "=", 1).OrderBy("created_at", "desc").ToSQL()
+	if err != nil || sql != "SELECT * FROM users WHERE updated_by = ? ORDER BY created_at DESC" {
+		t.Errorf("Unexpected SQL: %s (%v)", sql, err)
+	}
+}
+
+func TestWhereOperatorValidation(t *testing.T) {
+	injected := "; DROP TABLE users"
+	builders := map[string]*QueryBuilder{
+		"Where":    newFakeBuilder("mysql", "users").Where("age", injected, 1),
+		"OrWhere":  newFakeBuilder("mysql", "users").Where("id", ">", 0).OrWhere("age", injected, 1),
+		"Having":   newFakeBuilder("mysql", "users").GroupBy("status").Having("status", injected, "active"),
+		"OrHaving": newFakeBuilder("mysql", "users").GroupBy("status").OrHaving("status", injected, "active"),
+	}
+	for name, qb := range builders {
+		sql, _, err := qb.ToSQL()
+		if err == nil {
+			t.Errorf("%s: expected error for invalid operator", name)
+		}
+		if strings.Contains(sql, "DROP") {
+			t.Errorf("%s: invalid operator reached SQL: %s", name, sql)
+		}
+	}
+
+	sql, _, err := newFakeBuilder("mysql", "users").Where("name", "like", "a%").ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if !strings.Contains(sql, "LIKE ?") {
+		t.Errorf("Expected normalized LIKE operator, got: %s", sql)
+	}
+
+	// IN / BETWEEN 的切片值展开为多个占位符
+	sql, args, err := newFakeBuilder("mysql", "users").Where("id", "in", []int{1, 2}).Where("age", "NOT BETWEEN", []int{20, 30}).ToSQL()
+	if err != nil || sql != "SELECT * FROM users WHERE id IN (?, ?) AND age NOT BETWEEN ? AND ?" || len(args) != 4 {
+		t.Errorf("Unexpected expanded SQL: %s %v, %v", sql, args, err)
+	}
+	sql, args, _ = newFakeBuilder("mysql", "users").GroupBy("age").Having("COUNT(*)", "IN", []int{1, 2}).ToSQL()
+	if !strings.HasSuffix(sql, "HAVING COUNT(*) IN (?, ?)") || len(args) != 2 {
+		t.Errorf("Unexpected expanded HAVING: %s %v", sql, args)
+	}
+	if _, _, err := newFakeBuilder("mysql", "users").Where("age", "BETWEEN", []int{20}).ToSQL(); err == nil {
+		t.Error("Expected error for BETWEEN without exactly two values")
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().Where("id", "IN", []int{1, 2}).Get()
+	if err != nil || len(rows) != 2 {
+		t.Errorf("Expected 2 rows for IN, got %v, %v", rows, err)
+	}
+	if count, err := table().Where("age", "BETWEEN", []int{21, 29}).Count(); err != nil || count != 2 {
+		t.Errorf("Expected 2 rows for BETWEEN, got %d, %v", count, err)
+	}
+	if count, err := table().Where("id", "IN", []int{}).Count(); err != nil || count != 0 {
+		t.Errorf("Expected empty IN to match nothing, got %d, %v", count, err)
+	}
+	if count, err := table().Where("id", "NOT IN", []int{}).Count(); err != nil || count != 4 {
+		t.Errorf("Expected empty NOT IN to match everything, got %d, %v", count, err)
+	}
+}
+
+func TestRecordNotFoundSentinel(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	_, err := table().Where("name", "=", "nobody").First()
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("Expected ErrRecordNotFound, got %v", err)
+	}
+	wrapped := fmt.Errorf("load user: %w", err)
+	if !errors.Is(wrapped, ErrRecordNotFound) || !IsNotFoundError(wrapped) {
+		t.Errorf("Expected wrapped error to match ErrRecordNotFound, got %v", wrapped)
+	}
+	if _, err := table().Find(99); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound from Find, got %v", err)
+	}
+	if len(ErrRecordNotFound.Context) != 0 {
+		t.Errorf("Shared ErrRecordNotFound should not collect context: %v", ErrRecordNotFound.Context)
+	}
+}
+
+func TestGetKeyedAndGroupedBy(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	keyed, err := table().OrderBy("id", "asc").GetKeyedBy("name")
+	if err != nil {
+		t.Fatalf("GetKeyedBy failed: %v", err)
+	}
+	if len(keyed) != 4 || keyed["carol"]["status"] != "banned" {
+		t.Errorf("Unexpected keyed result: %v", keyed)
+	}
+
+	// 重复键保留最后一条
+	byAge, err := table().OrderBy("id", "asc").GetKeyedBy("age")
+	if err != nil {
+		t.Fatalf("GetKeyedBy failed: %v", err)
+	}
+	if len(byAge) != 3 || byAge[int64(25)]["name"] != "dave" {
+		t.Errorf("Expected last row to win for duplicate keys, got %v", byAge)
+	}
+
+	grouped, err := table().OrderBy("id", "asc").GetGroupedBy("status")
+	if err != nil {
+		t.Fatalf("GetGroupedBy failed: %v", err)
+	}
+	active := grouped["active"]
+	if len(grouped) != 3 || len(active) != 2 || active[0]["name"] != "alice" || active[1]["name"] != "bob" {
+		t.Errorf("Unexpected grouped result: %v", grouped)
+	}
+
+	if _, err := table().Select("id").GetKeyedBy("name"); err == nil {
+		t.Error("Expected error for column missing from result")
+	}
+	if _, err := table().GetGroupedBy("name; DROP TABLE users"); err == nil {
+		t.Error("Expected error for invalid column")
+	}
+}
+
+func TestHavingAggregates(t *testing.T) {
+	sql, args, err := newFakeBuilder("postgres", "orders").Select("user_id").GroupBy("user_id").
+		HavingCount(">", 5).HavingSum("amount", ">=", 1000).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL failed: %v", err)
+	}
+	if !strings.Contains(sql, "HAVING COUNT(*) > $1 AND SUM(") || !strings.Contains(sql, ">= $2") {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	if len(args) != 2 || args[0] != 5 || args[1] != 1000 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	for name, qb := range map[string]*QueryBuilder{
+		"operator": newFakeBuilder("mysql", "orders").GroupBy("user_id").HavingCount("> 0 OR 1=1 --", 5),
+		"column":   newFakeBuilder("mysql", "orders").GroupBy("user_id").HavingMax("amount) FROM users --", ">", 1),
+		"nil":      newFakeBuilder("mysql", "orders").GroupBy("user_id").HavingAvg("amount", ">", nil),
+	} {
+		if _, _, err := qb.ToSQL(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	table := setupTestTable(t, testUsers)
+	rows, err := table().Select("status").GroupBy("status").HavingCount(">=", 2).HavingMin("age", "<", 25).Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["status"] != "active" {
+		t.Errorf("Expected only the active group, got %v", rows)
+	}
+}
+
+// recordingLogger 记录警告消息的测试日志记录器
+type recordingLogger struct {
+	mutex    sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Info(msg string, fields ...interface{})  {}
+func (l *recordingLogger) Error(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Fatal(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Warn(msg string, fields ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprint(append([]interface{}{msg}, fields...)...))
+}
+
+func TestProfilerDetectsRepeatedQueries(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	log := &recordingLogger{}
+	profiler := EnableProfiler(3).SetLogger(log)
+	defer DisableProfiler()
+
+	ctx := WithProfileScope(context.Background(), "GET /orders")
+	for id := 1; id <= 4; id++ {
+		if _, err := table().WithContext(ctx).Where("id", "=", id).Get(); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+	if _, err := table().WhereIn("id", []interface{}{1, 2, 3}).Get(); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	report := profiler.Report()
+	shape := "SELECT * FROM users WHERE id = ?"
+	if report["GET /orders"][shape] != 4 {
+		t.Errorf("Expected 4 executions of %q, got %v", shape, report)
+	}
+	if report[""]["SELECT * FROM users WHERE id IN (?)"] != 1 {
+		t.Errorf("Expected unscoped IN query to be recorded once, got %v", report[""])
+	}
+	if len(log.warnings) != 1 || !strings.Contains(log.warnings[0], shape) {
+		t.Errorf("Expected a single warning for the repeated query, got %v", log.warnings)
+	}
+
+	if got := normalizeSQLShape("SELECT * FROM t WHERE name = 'it''s'  AND id IN ($1, $2) LIMIT 10"); got != "SELECT * FROM t WHERE name = ? AND id IN (?) LIMIT ?" {
+		t.Errorf("Unexpected normalized shape: %s", got)
+	}
+
+	profiler.Reset("GET /orders")
+	if _, ok := profiler.Report()["GET /orders"]; ok {
+		t.Error("Expected scope to be cleared")
+	}
+	DisableProfiler()
+	if _, err := table().Get(); err != nil || CurrentProfiler() != nil {
+		t.Errorf("Expected profiler to be disabled, err: %v", err)
+	}
+}
+
+func TestInsertIgnoreBatchIDs(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	ids, err := table().SetInsertChunkSize(2).InsertIgnoreBatchIDs([]map[string]interface{}{
+		{"id": 1, "name": "alice-again"},
+		{"id": 10, "name": "eve"},
+		{"id": 2, "name": "bob-again"},
+		{"id": 11, "name": "frank"},
+	})
+	if err != nil {
+		t.Fatalf("InsertIgnoreBatchIDs failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+		t.Errorf("Expected only new ids [10 11], got %v", ids)
+	}
+	if count, _ := table().Count(); count != 6 {
+		t.Errorf("Expected 6 rows, got %d", count)
+	}
+
+	qb := newFakeBuilder("mysql", "tags").DryRun()
+	if _, err := qb.InsertIgnoreBatchIDs([]map[string]interface{}{{"name": "go"}, {"name": "sql"}}); err != nil {
+		t.Fatalf("Dry-run InsertIgnoreBatchIDs failed: %v", err)
+	}
+	if sql, args := qb.LastSQL(); sql != "INSERT IGNORE INTO tags (name) VALUES (?); INSERT IGNORE INTO tags (name) VALUES (?)" ||
+		len(args) != 2 || args[0] != "go" || args[1] != "sql" {
+		t.Errorf("Expected per-row INSERT IGNORE on MySQL, got %s %v", sql, args)
+	}
+
+	if _, err := newFakeBuilder("sqlserver", "tags").InsertIgnoreBatchIDs([]map[string]interface{}{{"name": "go"}}); err == nil {
+		t.Error("Expected error for unsupported driver")
+	}
+}
+
+func TestScanWithoutRelationLoader(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+
+	var u struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := table().Where("name", "=", "bob").ScanWith(&u); err != nil || u.ID != 2 || u.Name != "bob" {
+		t.Fatalf("Expected bob, got %+v, %v", u, err)
+	}
+	// db 包测试中没有注册关联加载器
+	if err := table().Where("id", "=", 1).ScanWith(&u, "Posts"); err == nil {
+		t.Error("Expected error when no relation loader is registered")
+	}
+	if err := table().Where("id", "=", 99).ScanWith(&u); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+}
@@ -151,7 +151,7 @@ func (c *HighConcurrencyMemoryCache) Get(key string) (interface{}, error) {
 		shard.mutex.RUnlock()
 		atomic.AddInt64(&shard.misses, 1)
 		atomic.AddInt64(&c.totalMisses, 1)
-		return nil, fmt.Errorf("cache miss")
+		return nil, ErrCacheKeyNotFound
 	}
 
 	if item.IsExpired() {
@@ -160,7 +160,7 @@ func (c *HighConcurrencyMemoryCache) Get(key string) (interface{}, error) {
 		go c.deleteExpiredItem(key)
 		atomic.AddInt64(&shard.expired, 1)
 		atomic.AddInt64(&c.totalExpired, 1)
-		return nil, fmt.Errorf("cache expired")
+		return nil, ErrCacheExpired
 	}
 
 	// 更新访问统计（原子操作，无需写锁）
@@ -834,7 +834,10 @@ func GenerateCacheKey(prefix string, data interface{}) string {
 }
 
 // 默认缓存实例
-var defaultCache *MemoryCache
+var (
+	defaultCache      CacheInterface
+	defaultCacheMutex sync.RWMutex
+)
 
 func init() {
 	defaultCache = NewMemoryCache()
@@ -842,5 +845,17 @@ func init() {
 
 // GetDefaultCache 获取默认缓存实例
 func GetDefaultCache() CacheInterface {
+	defaultCacheMutex.RLock()
+	defer defaultCacheMutex.RUnlock()
 	return defaultCache
 }
+
+// SetDefaultCache 替换查询缓存使用的默认缓存实例（如 Redis 缓存），nil 时恢复为新的内存缓存
+func SetDefaultCache(cache CacheInterface) {
+	if cache == nil {
+		cache = NewMemoryCache()
+	}
+	defaultCacheMutex.Lock()
+	defaultCache = cache
+	defaultCacheMutex.Unlock()
+}
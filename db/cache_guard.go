@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// CacheFailureThreshold 查询缓存连续失败多少次后暂停读取缓存
+var CacheFailureThreshold = 5
+
+// CacheBreakerCooldown 查询缓存熔断后暂停读取的时长，到期后允许再次尝试
+var CacheBreakerCooldown = 30 * time.Second
+
+// cacheBreaker 查询缓存熔断器，缓存后端连续失败时暂停读取，避免每次查询都等待故障的缓存
+type cacheBreaker struct {
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// queryCacheBreaker 查询构建器使用的全局缓存熔断器
+var queryCacheBreaker = &cacheBreaker{}
+
+// allow 熔断期间返回 false，查询缓存的读取和写入都跳过
+func (b *cacheBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return time.Now().After(b.openUntil)
+}
+
+// recordSuccess 缓存后端正常响应（包括未命中）时重置失败计数
+func (b *cacheBreaker) recordSuccess() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// recordFailure 记录一次失败，达到阈值时熔断；熔断到期后再次失败会立即重新熔断
+func (b *cacheBreaker) recordFailure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures++
+	if CacheFailureThreshold > 0 && b.failures >= CacheFailureThreshold {
+		b.openUntil = time.Now().Add(CacheBreakerCooldown)
+	}
+}
+
+// isCacheMiss 判断缓存错误是否只是未命中或已过期，而不是后端故障
+func isCacheMiss(err error) bool {
+	return errors.Is(err, ErrCacheKeyNotFound) || errors.Is(err, ErrCacheExpired)
+}
+
+// readQueryCache 读取查询缓存，缓存故障只记录日志，不影响查询
+func (qb *QueryBuilder) readQueryCache(key string) ([]map[string]interface{}, bool) {
+	if !queryCacheBreaker.allow() {
+		return nil, false
+	}
+
+	cached, err := GetDefaultCache().Get(key)
+	if err != nil {
+		if isCacheMiss(err) {
+			queryCacheBreaker.recordSuccess()
+			return nil, false
+		}
+		queryCacheBreaker.recordFailure()
+		LogError(WrapError(err, ErrCodeCacheFailed, "读取查询缓存失败").
+			WithContext("key", key).
+			WithContext("table", qb.tableName))
+		return nil, false
+	}
+	queryCacheBreaker.recordSuccess()
+
+	result, ok := cached.([]map[string]interface{})
+	return result, ok
+}
+
+// writeQueryCache 写入查询缓存，写入失败只记录日志，查询结果照常返回；熔断期间不写入
+func (qb *QueryBuilder) writeQueryCache(key string, result []map[string]interface{}) {
+	if !queryCacheBreaker.allow() {
+		return
+	}
+
+	cache := GetDefaultCache()
+	var err error
+	if len(qb.cacheTags) > 0 {
+		tagged, ok := cache.(CacheWithTagsInterface)
+		if !ok {
+			return
+		}
+		err = tagged.SetWithTags(key, result, qb.cacheTTL, qb.cacheTags)
+	} else {
+		err = cache.Set(key, result, qb.cacheTTL)
+	}
+
+	if err != nil {
+		queryCacheBreaker.recordFailure()
+		LogError(WrapError(err, ErrCodeCacheFailed, "写入查询缓存失败").
+			WithContext("key", key).
+			WithContext("table", qb.tableName))
+		return
+	}
+	queryCacheBreaker.recordSuccess()
+}
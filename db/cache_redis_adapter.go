@@ -202,14 +202,14 @@ func (c *RedisCache) Get(key string) (interface{}, error) {
 		atomic.AddInt64(&c.errors, 1)
 		if strings.Contains(err.Error(), "nil") || strings.Contains(err.Error(), "not found") {
 			atomic.AddInt64(&c.misses, 1)
-			return nil, fmt.Errorf("cache miss")
+			return nil, ErrCacheKeyNotFound
 		}
 		return nil, err
 	}
 
 	if result == "" {
 		atomic.AddInt64(&c.misses, 1)
-		return nil, fmt.Errorf("cache miss")
+		return nil, ErrCacheKeyNotFound
 	}
 
 	atomic.AddInt64(&c.hits, 1)
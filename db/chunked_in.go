@@ -0,0 +1,170 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WhereInChunkSize WhereInChunks 每个 IN 列表默认包含的最大值数量
+var WhereInChunkSize = 1000
+
+// chunkedInCondition WhereInChunks 添加的条件，Get 时用于按块拆分查询
+type chunkedInCondition struct {
+	raw    string
+	column string
+	chunks [][]interface{}
+}
+
+// WhereInChunks 适用于超大 IN 列表的 WHERE IN 条件，chunkSize <= 0 时使用 WhereInChunkSize
+// 条件生成为 (col IN (...) OR col IN (...))，避免单个 IN 列表过长
+// Get 时如果查询没有 OR 条件、排序、分组、聚合、DISTINCT 和 LIMIT/OFFSET，会按块分别查询并合并结果，
+// 使每条语句的参数数量不超过 chunkSize；其他情况以及 Count、Update 等操作仍在一条语句中执行。
+// 重复的值只保留一个，保证分块查询不会返回重复的行；多次调用时按所有条件的块组合分别查询
+func (qb *QueryBuilder) WhereInChunks(field string, values []interface{}, chunkSize int) *QueryBuilder {
+	if len(values) == 0 {
+		return qb
+	}
+	if err := qb.validateColumnName(field); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	values = uniqueValues(values)
+	if chunkSize <= 0 {
+		chunkSize = WhereInChunkSize
+	}
+
+	column := qb.quoteIdentifier(field)
+	chunks := make([][]interface{}, 0, (len(values)+chunkSize-1)/chunkSize)
+	parts := make([]string, 0, cap(chunks))
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+		chunks = append(chunks, values[start:end])
+		parts = append(parts, inListSQL(column, end-start))
+	}
+
+	raw := parts[0]
+	if len(parts) > 1 {
+		raw = "(" + strings.Join(parts, " OR ") + ")"
+	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    raw,
+		Values: values,
+		Logic:  "AND",
+	})
+	if len(chunks) > 1 {
+		chunked := make([]*chunkedInCondition, len(qb.chunkedIn), len(qb.chunkedIn)+1)
+		copy(chunked, qb.chunkedIn)
+		qb.chunkedIn = append(chunked, &chunkedInCondition{raw: raw, column: column, chunks: chunks})
+	}
+	return qb
+}
+
+// uniqueValues 去除重复的值并保持原有顺序，字节数组按内容比较
+func uniqueValues(values []interface{}) []interface{} {
+	seen := make(map[string]bool, len(values))
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		key := fmt.Sprintf("%T:%v", value, value)
+		if data, ok := value.([]byte); ok {
+			key = "bytes:" + string(data)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+// inListSQL 生成 col IN (?, ?, ...)
+func inListSQL(column string, count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+}
+
+// chunkedInIndexes 返回可按块拆分执行的 WhereInChunks 条件位置，与 qb.chunkedIn 一一对应，不能拆分时返回 nil
+// 分块查询的结果直接拼接，因此要求各块结果互不重叠且不依赖整体排序或分页
+func (qb *QueryBuilder) chunkedInIndexes() []int {
+	if len(qb.chunkedIn) == 0 || qb.limitCount > 0 || qb.offsetCount > 0 ||
+		len(qb.orderByColumns) > 0 || len(qb.groupByColumns) > 0 ||
+		len(qb.havingConditions) > 0 || len(qb.selectRaw) > 0 || qb.distinct {
+		return nil
+	}
+	for _, column := range qb.selectColumns {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(column)), "DISTINCT") {
+			return nil
+		}
+	}
+
+	indexes := make([]int, len(qb.chunkedIn))
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i, condition := range qb.whereConditions {
+		if i > 0 && strings.EqualFold(condition.Logic, "OR") {
+			return nil
+		}
+		for j, chunked := range qb.chunkedIn {
+			if condition.Raw == chunked.raw {
+				indexes[j] = i
+			}
+		}
+	}
+	for _, index := range indexes {
+		if index < 0 {
+			return nil
+		}
+	}
+	return indexes
+}
+
+// getInChunks 对所有 WhereInChunks 条件的每种块组合分别执行查询并按顺序合并结果
+// 每个值只属于一个块，因此各组合的结果互不重叠；设置了缓存键时每个组合使用带序号后缀的键
+func (qb *QueryBuilder) getInChunks(indexes []int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	selected := make([][]interface{}, len(qb.chunkedIn))
+	combination := 0
+
+	var run func(depth int) error
+	run = func(depth int) error {
+		if depth < len(qb.chunkedIn) {
+			for _, chunk := range qb.chunkedIn[depth].chunks {
+				selected[depth] = chunk
+				if err := run(depth + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		chunkQuery := qb.Clone()
+		chunkQuery.chunkedIn = nil
+		if qb.cacheKey != "" {
+			chunkQuery.cacheKey = fmt.Sprintf("%s:chunk:%d", qb.cacheKey, combination)
+		}
+		combination++
+		for i, index := range indexes {
+			chunkQuery.whereConditions[index].Raw = inListSQL(qb.chunkedIn[i].column, len(selected[i]))
+			chunkQuery.whereConditions[index].Values = selected[i]
+		}
+
+		rows, err := chunkQuery.Get()
+		if err != nil {
+			return err
+		}
+		results = append(results, rows...)
+		return nil
+	}
+
+	if err := run(0); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -1,9 +1,11 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -40,6 +42,102 @@ type Config struct {
 	Options    map[string]string `json:"options" yaml:"options"`         // 其他选项
 	Debug      bool              `json:"debug" yaml:"debug"`             // 是否开启调试
 	LogQueries bool              `json:"log_queries" yaml:"log_queries"` // 是否记录查询日志
+
+	// 查询配置
+	QueryTimeout         time.Duration `json:"query_timeout" yaml:"query_timeout"`                     // 默认查询超时，查询未设置截止时间时使用，0 表示不限制
+	PrepareStmtCacheSize int           `json:"prepare_stmt_cache_size" yaml:"prepare_stmt_cache_size"` // 预处理语句缓存容量，按SQL复用预处理语句，0 表示不开启
+	QuoteIdentifiers     bool          `json:"quote_identifiers" yaml:"quote_identifiers"`             // 为生成SQL中的表名和列名加引号，允许使用 order、group 等保留字作为列名
+
+	// 事务配置，BeginTx 未指定选项时使用
+	TxIsolation sql.IsolationLevel `json:"tx_isolation" yaml:"tx_isolation"` // 默认事务隔离级别，0 表示使用驱动默认值
+	TxReadOnly  bool               `json:"tx_read_only" yaml:"tx_read_only"` // 默认开启只读事务
+
+	// Location 解析和格式化时间使用的时区，为空时使用 Timezone，均未设置时为服务器本地时区
+	Location *time.Location `json:"-" yaml:"-"`
+}
+
+// locationCache 时区名称到 *time.Location 的缓存，避免每次解析时间都加载时区数据
+var locationCache sync.Map
+
+// SetTimezone 设置解析和格式化时间使用的时区
+func (c *Config) SetTimezone(loc *time.Location) *Config {
+	c.Location = loc
+	return c
+}
+
+// GetLocation 获取解析和格式化时间使用的时区
+func (c *Config) GetLocation() *time.Location {
+	if c.Location != nil {
+		return c.Location
+	}
+	if c.Timezone != "" {
+		if cached, ok := locationCache.Load(c.Timezone); ok {
+			return cached.(*time.Location)
+		}
+		if loc, err := time.LoadLocation(c.Timezone); err == nil {
+			locationCache.Store(c.Timezone, loc)
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// SetTablePrefix 设置表前缀
+func (c *Config) SetTablePrefix(prefix string) *Config {
+	c.Prefix = prefix
+	return c
+}
+
+// PrefixTable 为表名加上表前缀
+// 带模式名时前缀加在表名部分（public.users → public.app_users），别名保持不变（users u → app_users u）
+// 已经带有前缀的表名（如模型 TableName 返回 app_users）不会重复添加
+func (c *Config) PrefixTable(table string) string {
+	if c == nil || c.Prefix == "" || table == "" {
+		return table
+	}
+
+	name, alias := table, ""
+	if index := strings.IndexAny(table, " \t"); index > 0 {
+		name, alias = table[:index], table[index:]
+	}
+	schema := ""
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		schema, name = name[:index+1], name[index+1:]
+	}
+	if strings.HasPrefix(name, c.Prefix) {
+		return table
+	}
+	return schema + c.Prefix + name + alias
+}
+
+// SetQueryTimeout 设置连接默认的查询超时，0 表示不限制
+func (c *Config) SetQueryTimeout(timeout time.Duration) *Config {
+	c.QueryTimeout = timeout
+	return c
+}
+
+// SetQuoteIdentifiers 设置是否为生成SQL中的表名和列名加引号
+func (c *Config) SetQuoteIdentifiers(quote bool) *Config {
+	c.QuoteIdentifiers = quote
+	return c
+}
+
+// SetTxOptions 设置连接默认的事务隔离级别和只读模式
+func (c *Config) SetTxOptions(opts TxOptions) *Config {
+	c.TxIsolation = opts.Isolation
+	c.TxReadOnly = opts.ReadOnly
+	return c
+}
+
+// txOptions 将事务选项转换为 database/sql 的选项，opts 为空时使用连接配置的默认值
+func (c *Config) txOptions(opts *TxOptions) *sql.TxOptions {
+	if opts == nil {
+		if c == nil || (c.TxIsolation == sql.LevelDefault && !c.TxReadOnly) {
+			return nil
+		}
+		return &sql.TxOptions{Isolation: c.TxIsolation, ReadOnly: c.TxReadOnly}
+	}
+	return &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
 }
 
 // DSN 构建数据源名称
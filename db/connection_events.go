@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ConnectionListener 连接生命周期监听器，用于监控数据库连通性
+// OnConnect 在连接实际建立时调用（首次使用、预热或断开后重连成功），
+// OnError 在建立连接失败、健康检查失败或执行SQL时驱动返回连接级错误时调用
+type ConnectionListener interface {
+	OnConnect(name string)
+	OnError(name string, err error)
+}
+
+// ConnectionDisconnectListener 可选接口，监听器实现后在连接关闭并移除时调用 OnDisconnect
+type ConnectionDisconnectListener interface {
+	OnDisconnect(name string)
+}
+
+var (
+	connectionListeners      []ConnectionListener
+	connectionListenersMutex sync.RWMutex
+)
+
+// AddConnectionListener 注册连接生命周期监听器，监听器按注册顺序同步调用
+func AddConnectionListener(listener ConnectionListener) {
+	if listener == nil {
+		return
+	}
+	connectionListenersMutex.Lock()
+	defer connectionListenersMutex.Unlock()
+	connectionListeners = append(connectionListeners, listener)
+}
+
+// ClearConnectionListeners 移除所有连接生命周期监听器
+func ClearConnectionListeners() {
+	connectionListenersMutex.Lock()
+	defer connectionListenersMutex.Unlock()
+	connectionListeners = nil
+}
+
+// registeredConnectionListeners 返回当前注册的监听器快照，调用监听器时不持有锁
+func registeredConnectionListeners() []ConnectionListener {
+	connectionListenersMutex.RLock()
+	defer connectionListenersMutex.RUnlock()
+	return connectionListeners
+}
+
+// notifyConnect 通知连接已建立
+func notifyConnect(name string) {
+	for _, listener := range registeredConnectionListeners() {
+		listener.OnConnect(name)
+	}
+}
+
+// notifyConnectionError 通知连接错误
+func notifyConnectionError(name string, err error) {
+	for _, listener := range registeredConnectionListeners() {
+		listener.OnError(name, err)
+	}
+}
+
+// notifyDisconnect 通知连接已关闭并移除
+func notifyDisconnect(name string) {
+	for _, listener := range registeredConnectionListeners() {
+		if disconnectListener, ok := listener.(ConnectionDisconnectListener); ok {
+			disconnectListener.OnDisconnect(name)
+		}
+	}
+}
+
+// reportExecutionError 执行SQL时驱动返回连接级错误则通知监听器，连接名从默认管理器中查找
+func reportExecutionError(conn ConnectionInterface, err error) {
+	if err == nil || !isConnectionLevelError(err) || len(registeredConnectionListeners()) == 0 {
+		return
+	}
+	notifyConnectionError(defaultManager.connectionName(conn), err)
+}
+
+// connectionTransaction 可以返回所属连接的事务
+type connectionTransaction interface {
+	connection() ConnectionInterface
+}
+
+// reportTxExecutionError 在事务中执行SQL时驱动返回连接级错误则通知监听器，连接名取自开启事务的连接
+func reportTxExecutionError(tx TransactionInterface, err error) {
+	if bound, ok := tx.(connectionTransaction); ok && bound.connection() != nil {
+		reportExecutionError(bound.connection(), err)
+	}
+}
+
+// isConnectionLevelError 判断错误是否来自连接本身（连接断开、网络错误等），而不是SQL错误
+func isConnectionLevelError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var tormErr *TormError
+	if errors.As(err, &tormErr) && IsConnectionError(tormErr) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"database is closed",
+		"connection is not established",
+		"connection refused",
+		"connection reset",
+		"broken pipe",
+		"bad connection",
+	} {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// smartConversion 是否对未知类型的文本值启用智能类型推断
+var smartConversion int32
+
+// SetSmartConversion 设置是否启用智能类型推断（默认关闭）
+// 开启后，无法从列类型判断的文本值会被尝试解析为整数、浮点数、布尔、时间、JSON或Base64解码；
+// 文本类型（CHAR/VARCHAR/TEXT等）的列始终保持为字符串
+func SetSmartConversion(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&smartConversion, value)
+}
+
+// IsSmartConversionEnabled 是否启用了智能类型推断
+func IsSmartConversionEnabled() bool {
+	return atomic.LoadInt32(&smartConversion) == 1
+}
+
+var (
+	// scanConverters 按数据库类型名注册的扫描转换函数，键为大写类型名
+	scanConverters      = make(map[string]func([]byte) (interface{}, error))
+	scanConvertersMutex sync.RWMutex
+)
+
+// RegisterScanConverter 为数据库类型注册扫描转换函数，查询结果中该类型列的值交给 fn 转换
+// dbTypeName 与列的 DatabaseTypeName() 比较，不区分大小写并忽略长度参数，如 "INET"、"VARCHAR(32)"
+// fn 只处理驱动返回的文本或字节值，NULL 保持为 nil；fn 为 nil 时取消注册
+func RegisterScanConverter(dbTypeName string, fn func([]byte) (interface{}, error)) {
+	key := normalizeTypeName(dbTypeName)
+
+	scanConvertersMutex.Lock()
+	defer scanConvertersMutex.Unlock()
+	if fn == nil {
+		delete(scanConverters, key)
+		return
+	}
+	scanConverters[key] = fn
+}
+
+// scanConverterFor 查找列类型注册的扫描转换函数
+func scanConverterFor(columnType *sql.ColumnType) func([]byte) (interface{}, error) {
+	scanConvertersMutex.RLock()
+	defer scanConvertersMutex.RUnlock()
+	if len(scanConverters) == 0 {
+		return nil
+	}
+	return scanConverters[normalizeTypeName(columnType.DatabaseTypeName())]
+}
+
+// convertWithScanConverter 使用注册的扫描转换函数转换值，列类型没有注册或值不是文本时 ok 为 false
+func convertWithScanConverter(value interface{}, columnType *sql.ColumnType) (converted interface{}, ok bool, err error) {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return nil, false, nil
+	}
+	converter := scanConverterFor(columnType)
+	if converter == nil {
+		return nil, false, nil
+	}
+	converted, err = converter(raw)
+	return converted, true, err
+}
+
+// normalizeTypeName 类型名转为大写并去掉长度参数，如 varchar(32) -> VARCHAR
+func normalizeTypeName(typeName string) string {
+	typeName = strings.ToUpper(strings.TrimSpace(typeName))
+	if idx := strings.Index(typeName, "("); idx >= 0 {
+		typeName = strings.TrimSpace(typeName[:idx])
+	}
+	return typeName
+}
+
+// columnKind 列的数据库类型分类
+type columnKind int
+
+const (
+	columnKindUnknown columnKind = iota
+	columnKindText
+	columnKindInteger
+	columnKindFloat
+	columnKindBool
+	columnKindTime
+	columnKindBinary
+)
+
+// classifyColumn 根据 DatabaseTypeName 对列分类，如 VARCHAR(255)、INT UNSIGNED、CHARACTER VARYING
+func classifyColumn(columnType *sql.ColumnType) columnKind {
+	typeName := strings.ToUpper(strings.TrimSpace(columnType.DatabaseTypeName()))
+	if idx := strings.Index(typeName, "("); idx >= 0 {
+		typeName = strings.TrimSpace(typeName[:idx])
+	}
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+	typeName = strings.TrimSuffix(typeName, " UNSIGNED")
+
+	switch typeName {
+	case "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "TEXT", "NTEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT",
+		"CHARACTER", "CHARACTER VARYING", "BPCHAR", "CITEXT", "CLOB", "STRING", "ENUM", "SET", "UUID", "UNIQUEIDENTIFIER":
+		return columnKindText
+	case "INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "INT2", "INT4", "INT8",
+		"SERIAL", "SMALLSERIAL", "BIGSERIAL", "YEAR":
+		return columnKindInteger
+	case "FLOAT", "DOUBLE", "DOUBLE PRECISION", "REAL", "FLOAT4", "FLOAT8", "DECIMAL", "NUMERIC", "NEWDECIMAL":
+		return columnKindFloat
+	case "BOOL", "BOOLEAN", "BIT":
+		return columnKindBool
+	case "DATE", "DATETIME", "DATETIME2", "TIMESTAMP", "TIMESTAMPTZ", "TIME", "TIMETZ":
+		return columnKindTime
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA", "IMAGE":
+		return columnKindBinary
+	}
+	return columnKindUnknown
+}
+
+// convertTypedText 按列类型转换驱动返回的文本值（[]byte或string），无法按类型转换时返回 false
+func (qb *QueryBuilder) convertTypedText(value interface{}, columnType *sql.ColumnType) (interface{}, bool) {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return nil, false
+	}
+
+	kind := classifyColumn(columnType)
+	if kind == columnKindBinary {
+		return value, true
+	}
+
+	str := string(raw)
+	switch kind {
+	case columnKindText:
+		return str, true
+	case columnKindInteger:
+		if i, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil {
+			return i, true
+		}
+		if u, err := strconv.ParseUint(strings.TrimSpace(str), 10, 64); err == nil {
+			return u, true
+		}
+	case columnKindFloat:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+			return f, true
+		}
+	case columnKindBool:
+		if b, ok := qb.tryParseBool(str); ok {
+			return b, true
+		}
+		if len(raw) == 1 && raw[0] <= 1 { // MySQL BIT(1)
+			return raw[0] == 1, true
+		}
+	case columnKindTime:
+		if t, ok := qb.tryParseTime(str); ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// plainByteValue 关闭智能推断时的字节数组转换：有效UTF-8转为字符串，否则保持为字节数组
+func plainByteValue(data []byte) interface{} {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return data
+}
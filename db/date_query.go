@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// WhereToday 列值在今天之内，按连接配置的时区计算当天的起止时间
+// 生成：column >= ? AND column < ?，区间左闭右开
+func (qb *QueryBuilder) WhereToday(column string) *QueryBuilder {
+	start := startOfDay(time.Now().In(qb.location()))
+	return qb.whereTimeRange(column, start, start.AddDate(0, 0, 1))
+}
+
+// WhereThisWeek 列值在本周之内，每周从周一开始
+func (qb *QueryBuilder) WhereThisWeek(column string) *QueryBuilder {
+	today := startOfDay(time.Now().In(qb.location()))
+	// time.Weekday 以周日为0，换算为距离周一的天数
+	offset := (int(today.Weekday()) + 6) % 7
+	start := today.AddDate(0, 0, -offset)
+	return qb.whereTimeRange(column, start, start.AddDate(0, 0, 7))
+}
+
+// WhereThisMonth 列值在本月之内
+func (qb *QueryBuilder) WhereThisMonth(column string) *QueryBuilder {
+	now := time.Now().In(qb.location())
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return qb.whereTimeRange(column, start, start.AddDate(0, 1, 0))
+}
+
+// WhereDateRange 列值在 start 所在日期到 end 所在日期之间（包含两端的整天）
+// 日期按连接配置的时区计算，生成 column >= <start当天0点> AND column < <end次日0点>，
+// 例如 WhereDateRange("created_at", jan1, jan31) 包含1月31日全天的记录
+func (qb *QueryBuilder) WhereDateRange(column string, start, end time.Time) *QueryBuilder {
+	loc := qb.location()
+	from := startOfDay(start.In(loc))
+	to := startOfDay(end.In(loc)).AddDate(0, 0, 1)
+	if !to.After(from) {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "WhereDateRange 结束日期 %s 早于开始日期 %s",
+			end.In(loc).Format("2006-01-02"), start.In(loc).Format("2006-01-02")).WithContext("column", column))
+		return qb
+	}
+	return qb.whereTimeRange(column, from, to)
+}
+
+// whereTimeRange 添加左闭右开的时间区间条件，边界值转换到连接时区后绑定，见 bindTimeValue
+func (qb *QueryBuilder) whereTimeRange(column string, start, end time.Time) *QueryBuilder {
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	quoted := qb.quoteIdentifier(column)
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    fmt.Sprintf("%s >= ? AND %s < ?", quoted, quoted),
+		Values: []interface{}{qb.bindTimeValue(start), qb.bindTimeValue(end)},
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// startOfDay 返回 t 所在时区当天的0点
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
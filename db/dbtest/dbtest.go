@@ -0,0 +1,84 @@
+package dbtest
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zhoudm1743/torm/db"
+)
+
+// connectionSeq 生成唯一的测试连接名
+var connectionSeq int64
+
+// OpenSQLite 注册一个SQLite测试连接并返回连接名，测试结束时自动关闭并移除
+// database 为 ":memory:" 时连接池限制为单个连接，保证所有查询访问同一个内存数据库
+func OpenSQLite(t testing.TB, database string) string {
+	t.Helper()
+
+	name := fmt.Sprintf("dbtest_%d", atomic.AddInt64(&connectionSeq, 1))
+	config := &db.Config{
+		Driver:   "sqlite",
+		Database: database,
+	}
+	if database == ":memory:" {
+		config.MaxOpenConns = 1
+	}
+	if err := db.AddConnection(name, config); err != nil {
+		t.Fatalf("dbtest: 注册连接失败: %v", err)
+	}
+	t.Cleanup(func() {
+		db.RemoveConnection(name)
+	})
+
+	// 立即建立连接，配置错误在这里暴露而不是在第一次查询时
+	if _, err := db.DB(name); err != nil {
+		t.Fatalf("dbtest: 连接数据库失败: %v", err)
+	}
+	return name
+}
+
+// LoadFixtures 将种子数据批量插入到对应的表中，表需要已经存在
+// 按表名排序依次插入，每张表使用 InsertBatch 写入
+func LoadFixtures(conn string, fixtures map[string][]map[string]interface{}) error {
+	tables := make([]string, 0, len(fixtures))
+	for table := range fixtures {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		rows := fixtures[table]
+		if len(rows) == 0 {
+			continue
+		}
+		query, err := db.Table(table, conn)
+		if err != nil {
+			return err
+		}
+		if _, err := query.InsertBatch(rows); err != nil {
+			return db.WrapError(err, db.ErrCodeQueryFailed, "加载测试数据失败").WithContext("table", table)
+		}
+	}
+	return nil
+}
+
+// AssertTableCount 断言表中的记录数，不相等时报告测试失败
+func AssertTableCount(t testing.TB, conn, table string, n int64) {
+	t.Helper()
+
+	query, err := db.Table(table, conn)
+	if err != nil {
+		t.Errorf("dbtest: 创建查询失败: %v", err)
+		return
+	}
+	count, err := query.Count()
+	if err != nil {
+		t.Errorf("dbtest: 统计表 %s 记录数失败: %v", table, err)
+		return
+	}
+	if count != n {
+		t.Errorf("dbtest: 表 %s 期望 %d 条记录，实际 %d 条", table, n, count)
+	}
+}
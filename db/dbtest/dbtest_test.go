@@ -0,0 +1,39 @@
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/zhoudm1743/torm/db"
+)
+
+func TestFixtures(t *testing.T) {
+	var name string
+	t.Run("load", func(t *testing.T) {
+		name = OpenSQLite(t, ":memory:")
+		if _, err := db.DefaultManager().Exec(name, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+			t.Fatalf("create table failed: %v", err)
+		}
+		if _, err := db.DefaultManager().Exec(name, "CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER)"); err != nil {
+			t.Fatalf("create table failed: %v", err)
+		}
+
+		err := LoadFixtures(name, map[string][]map[string]interface{}{
+			"users": {{"id": 1, "name": "alice"}, {"id": 2, "name": "bob"}},
+			"posts": {{"id": 1, "user_id": 1}},
+		})
+		if err != nil {
+			t.Fatalf("LoadFixtures failed: %v", err)
+		}
+		AssertTableCount(t, name, "users", 2)
+		AssertTableCount(t, name, "posts", 1)
+
+		if err := LoadFixtures(name, map[string][]map[string]interface{}{"missing": {{"id": 1}}}); err == nil {
+			t.Error("Expected error for missing table")
+		}
+	})
+
+	// 子测试结束后连接被移除
+	if _, err := db.DB(name); err == nil {
+		t.Errorf("Expected connection %s to be removed after cleanup", name)
+	}
+}
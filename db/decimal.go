@@ -0,0 +1,91 @@
+package db
+
+import (
+	"database/sql"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// DecimalMode DECIMAL/NUMERIC 列的返回类型
+type DecimalMode int32
+
+const (
+	// DecimalAsFloat 转换为 float64（默认，兼容旧版本，可能丢失精度）
+	DecimalAsFloat DecimalMode = iota
+	// DecimalAsString 保持为字符串，如 "19.99"
+	DecimalAsString
+	// DecimalAsRat 转换为 *big.Rat，可精确计算
+	DecimalAsRat
+)
+
+// decimalMode 当前的DECIMAL返回模式
+var decimalMode int32
+
+// SetDecimalMode 设置DECIMAL/NUMERIC/MONEY列的返回类型
+// 金额等需要精确比较的场景建议使用 DecimalAsString 或 DecimalAsRat
+func SetDecimalMode(mode DecimalMode) {
+	atomic.StoreInt32(&decimalMode, int32(mode))
+}
+
+// GetDecimalMode 获取DECIMAL/NUMERIC/MONEY列的返回类型
+func GetDecimalMode() DecimalMode {
+	return DecimalMode(atomic.LoadInt32(&decimalMode))
+}
+
+// moneyReplacer 去掉 MONEY 值中的货币符号和千分位
+var moneyReplacer = strings.NewReplacer("$", "", ",", "")
+
+// isDecimalColumn 根据列的数据库类型判断是否为定点数列，如 DECIMAL(10,2)、NUMERIC、MONEY
+func isDecimalColumn(columnType *sql.ColumnType) bool {
+	typeName := strings.ToUpper(strings.TrimSpace(columnType.DatabaseTypeName()))
+	if idx := strings.Index(typeName, "("); idx >= 0 {
+		typeName = strings.TrimSpace(typeName[:idx])
+	}
+	switch typeName {
+	case "DECIMAL", "NUMERIC", "NEWDECIMAL", "MONEY", "SMALLMONEY":
+		return true
+	}
+	return false
+}
+
+// convertDecimalValue 按模式转换定点数值，与是否启用智能类型推断无关，无法识别时返回 false
+func convertDecimalValue(value interface{}, mode DecimalMode) (interface{}, bool) {
+	var str string
+	switch v := value.(type) {
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		str = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int64:
+		str = strconv.FormatInt(v, 10)
+	default:
+		return nil, false
+	}
+	str = strings.TrimSpace(str)
+
+	switch mode {
+	case DecimalAsFloat:
+		// MONEY 类型可能带有货币符号和千分位
+		f, err := strconv.ParseFloat(moneyReplacer.Replace(str), 64)
+		if err != nil {
+			return nil, false
+		}
+		return f, true
+	case DecimalAsString:
+		return str, true
+	case DecimalAsRat:
+		// MONEY 类型可能带有货币符号和千分位
+		rat, ok := new(big.Rat).SetString(moneyReplacer.Replace(str))
+		if !ok {
+			return nil, false
+		}
+		return rat, true
+	}
+	return nil, false
+}
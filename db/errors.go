@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
@@ -301,11 +302,19 @@ var (
 	// 缓存错误
 	ErrCacheFailed      = NewError(ErrCodeCacheFailed, "缓存操作失败")
 	ErrCacheKeyNotFound = NewError(ErrCodeCacheKeyNotFound, "缓存键不存在")
+	ErrCacheExpired     = NewError(ErrCodeCacheExpired, "缓存已过期")
 )
 
+// newNotFoundError 创建记录不存在错误并附加上下文，每次返回新实例，不修改共享的 ErrRecordNotFound
+// 返回的错误与 ErrRecordNotFound 错误码相同，可以用 errors.Is(err, ErrRecordNotFound) 判断
+func newNotFoundError(key string, value interface{}) *TormError {
+	return NewError(ErrCodeRecordNotFound, ErrRecordNotFound.Message).WithContext(key, value)
+}
+
 // IsConnectionError 检查是否为连接错误
 func IsConnectionError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code >= 2000 && te.Code < 3000
 	}
 	return false
@@ -313,7 +322,8 @@ func IsConnectionError(err error) bool {
 
 // IsQueryError 检查是否为查询错误
 func IsQueryError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code >= 3000 && te.Code < 4000
 	}
 	return false
@@ -321,7 +331,8 @@ func IsQueryError(err error) bool {
 
 // IsTransactionError 检查是否为事务错误
 func IsTransactionError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code >= 4000 && te.Code < 5000
 	}
 	return false
@@ -329,7 +340,8 @@ func IsTransactionError(err error) bool {
 
 // IsModelError 检查是否为模型错误
 func IsModelError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code >= 5000 && te.Code < 6000
 	}
 	return false
@@ -337,7 +349,8 @@ func IsModelError(err error) bool {
 
 // IsNotFoundError 检查是否为记录不存在错误
 func IsNotFoundError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code == ErrCodeRecordNotFound
 	}
 	return false
@@ -345,7 +358,8 @@ func IsNotFoundError(err error) bool {
 
 // IsDuplicateError 检查是否为重复键错误
 func IsDuplicateError(err error) bool {
-	if te, ok := err.(*TormError); ok {
+	var te *TormError
+	if errors.As(err, &te) {
 		return te.Code == ErrCodeDuplicateKey
 	}
 	return false
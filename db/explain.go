@@ -0,0 +1,211 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberedPlaceholderRegex PostgreSQL（$1）和SQL Server（@p1）的编号占位符
+var numberedPlaceholderRegex = regexp.MustCompile(`\$(\d+)|@p(\d+)`)
+
+// dumpWriter Dump/DD 的输出目标
+var dumpWriter io.Writer = os.Stdout
+
+// DDSignal DD 输出SQL后触发的 panic 值，可通过 recover 捕获（如在测试中）
+type DDSignal struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Tap 以当前构建器调用 fn 并原样返回，用于在链式调用中途检查或记录查询
+func (qb *QueryBuilder) Tap(fn func(*QueryBuilder)) *QueryBuilder {
+	if fn != nil {
+		fn(qb)
+	}
+	return qb
+}
+
+// Dump 输出当前 ToSQL() 的SQL、参数和构建错误，返回构建器本身以便继续链式调用
+func (qb *QueryBuilder) Dump() *QueryBuilder {
+	sqlStr, args, err := qb.ToSQL()
+	fmt.Fprintf(dumpWriter, "[TORM DUMP] %s | 参数: %v\n", sqlStr, args)
+	if err != nil {
+		fmt.Fprintf(dumpWriter, "[TORM DUMP] 构建错误: %v\n", err)
+	}
+	return qb
+}
+
+// DD 输出当前SQL后以 DDSignal 触发 panic，中断后续执行；返回值仅为了能放在链式调用中间
+func (qb *QueryBuilder) DD() *QueryBuilder {
+	qb.Dump()
+	sqlStr, args, _ := qb.ToSQL()
+	panic(DDSignal{SQL: sqlStr, Args: args})
+}
+
+// DumpSQL 返回已将参数内联的SELECT语句，便于复制到数据库客户端中调试
+// 警告：参数只做了简单的引号转义，结果仅用于查看和调试，禁止用于执行
+func (qb *QueryBuilder) DumpSQL() string {
+	sqlStr, args := qb.buildSelectSQL()
+	return interpolateSQL(sqlStr, args)
+}
+
+// Explain 执行 EXPLAIN 并返回执行计划，每行计划占一行
+// SQLite 使用 EXPLAIN QUERY PLAN
+func (qb *QueryBuilder) Explain() (string, error) {
+	switch qb.getDriverName() {
+	case "sqlite", "sqlite3":
+		return qb.runExplain("EXPLAIN QUERY PLAN ")
+	case "sqlserver", "mssql":
+		return "", NewError(ErrCodeNotImplemented, "SQL Server 不支持 EXPLAIN，请使用 SET SHOWPLAN_TEXT")
+	default:
+		return qb.runExplain("EXPLAIN ")
+	}
+}
+
+// ExplainAnalyze 执行 EXPLAIN ANALYZE 并返回实际执行计划
+// 注意：查询会被真正执行，仅支持 PostgreSQL 和 MySQL 8.0.18+
+func (qb *QueryBuilder) ExplainAnalyze() (string, error) {
+	switch qb.getDriverName() {
+	case "postgres", "postgresql", "pq", "mysql":
+		return qb.runExplain("EXPLAIN ANALYZE ")
+	default:
+		return "", NewErrorf(ErrCodeNotImplemented, "数据库驱动 %s 不支持 EXPLAIN ANALYZE", qb.getDriverName())
+	}
+}
+
+// runExplain 以指定前缀执行当前查询并格式化执行计划
+func (qb *QueryBuilder) runExplain(prefix string) (string, error) {
+	if err := qb.beginExecution(); err != nil {
+		return "", err
+	}
+	defer qb.endExecution()
+
+	qb.checkLockTransaction()
+	if qb.err != nil {
+		return "", qb.err
+	}
+
+	sqlStr, args := qb.buildSelectSQL()
+	sqlStr = prefix + sqlStr
+
+	var rows *sql.Rows
+	var err error
+	if qb.transaction != nil {
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return "", connErr
+		}
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return "", WrapError(err, ErrCodeQueryFailed, "执行计划查询失败").
+			WithContext("sql", sqlStr).
+			WithContext("args", args)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", WrapError(err, ErrCodeQueryFailed, "获取执行计划列失败")
+	}
+
+	var lines []string
+	for rows.Next() {
+		row, err := qb.scanRow(rows, columns, nil)
+		if err != nil {
+			return "", WrapError(err, ErrCodeQueryFailed, "扫描执行计划失败")
+		}
+		lines = append(lines, formatPlanRow(columns, row))
+	}
+	if err := rows.Err(); err != nil {
+		return "", WrapError(err, ErrCodeQueryFailed, "读取执行计划失败")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatPlanRow 格式化一行执行计划
+// 单列（PostgreSQL）或带detail列（SQLite）时只输出计划文本，否则输出 列=值 列表（MySQL）
+func formatPlanRow(columns []string, row map[string]interface{}) string {
+	if len(columns) == 1 {
+		return fmt.Sprint(row[columns[0]])
+	}
+	if detail, ok := row["detail"]; ok {
+		return fmt.Sprint(detail)
+	}
+
+	parts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if value := row[column]; value != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", column, value))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// interpolateSQL 将参数内联到SQL中，支持 ?、$n 和 @pn 占位符
+func interpolateSQL(sqlStr string, args []interface{}) string {
+	if len(args) == 0 {
+		return sqlStr
+	}
+
+	if numberedPlaceholderRegex.MatchString(sqlStr) {
+		return numberedPlaceholderRegex.ReplaceAllStringFunc(sqlStr, func(placeholder string) string {
+			index, err := strconv.Atoi(strings.TrimLeft(placeholder, "$@p"))
+			if err != nil || index < 1 || index > len(args) {
+				return placeholder
+			}
+			return formatSQLLiteral(args[index-1])
+		})
+	}
+
+	var result strings.Builder
+	argIndex := 0
+	for _, ch := range sqlStr {
+		if ch == '?' && argIndex < len(args) {
+			result.WriteString(formatSQLLiteral(args[argIndex]))
+			argIndex++
+			continue
+		}
+		result.WriteRune(ch)
+	}
+	return result.String()
+}
+
+// formatSQLLiteral 将参数格式化为SQL字面量
+func formatSQLLiteral(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "NULL"
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "'" + strings.ReplaceAll(string(v), "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05") + "'"
+	default:
+		return fmt.Sprint(v)
+	}
+}
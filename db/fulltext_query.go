@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 全文搜索模式
+const (
+	FulltextNatural   = "natural"   // 自然语言模式（默认）
+	FulltextBoolean   = "boolean"   // 布尔模式，支持 +、-、"短语" 等运算符
+	FulltextExpansion = "expansion" // 查询扩展模式，仅MySQL支持
+)
+
+// WhereFulltext 全文搜索条件，搜索词作为参数绑定，term 为空时不添加条件
+// MySQL使用 MATCH(col, ...) AGAINST (? IN ... MODE)，columns 需与FULLTEXT索引的列一致
+// PostgreSQL使用 to_tsvector('english', col) @@ plainto_tsquery('english', ?)，与迁移创建的GIN索引表达式一致，布尔模式使用 websearch_to_tsquery
+// SQLite要求当前表为FTS5虚拟表，使用 MATCH 并以 {col ...} 限定搜索的列，搜索词按FTS5查询语法解析
+func (qb *QueryBuilder) WhereFulltext(columns []string, term string, mode string) *QueryBuilder {
+	if term == "" {
+		return qb
+	}
+	if len(columns) == 0 {
+		qb.setErr(NewError(ErrCodeInvalidParameter, "全文搜索的列不能为空"))
+		return qb
+	}
+	for _, column := range columns {
+		if err := qb.validateColumnName(column); err != nil {
+			qb.setErr(err)
+			return qb
+		}
+	}
+
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = FulltextNatural
+	}
+	if mode != FulltextNatural && mode != FulltextBoolean && mode != FulltextExpansion {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "不支持的全文搜索模式: %s", mode))
+		return qb
+	}
+
+	condition, err := qb.buildFulltextCondition(columns, term, mode)
+	if err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	condition.Logic = "AND"
+	qb.whereConditions = append(qb.whereConditions, condition)
+	return qb
+}
+
+// buildFulltextCondition 根据数据库驱动构建全文搜索条件
+func (qb *QueryBuilder) buildFulltextCondition(columns []string, term, mode string) (WhereCondition, error) {
+	driver := qb.getDriverName()
+	switch driver {
+	case "mysql":
+		modifier := "IN NATURAL LANGUAGE MODE"
+		switch mode {
+		case FulltextBoolean:
+			modifier = "IN BOOLEAN MODE"
+		case FulltextExpansion:
+			modifier = "WITH QUERY EXPANSION"
+		}
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = qb.quoteIdentifier(column)
+		}
+		return WhereCondition{
+			Raw:    fmt.Sprintf("MATCH(%s) AGAINST (? %s)", strings.Join(quoted, ", "), modifier),
+			Values: []interface{}{term},
+		}, nil
+
+	case "postgres", "postgresql", "pq":
+		if mode == FulltextExpansion {
+			return WhereCondition{}, NewError(ErrCodeInvalidParameter, "PostgreSQL不支持查询扩展模式")
+		}
+		function := "plainto_tsquery"
+		if mode == FulltextBoolean {
+			function = "websearch_to_tsquery"
+		}
+		// 每列单独匹配，以便使用迁移为各列创建的GIN索引
+		parts := make([]string, 0, len(columns))
+		values := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			parts = append(parts, fmt.Sprintf("to_tsvector('english', %s) @@ %s('english', ?)", qb.quoteIdentifier(column), function))
+			values = append(values, term)
+		}
+		raw := strings.Join(parts, " OR ")
+		if len(parts) > 1 {
+			raw = "(" + raw + ")"
+		}
+		return WhereCondition{Raw: raw, Values: values}, nil
+
+	case "sqlite", "sqlite3":
+		if mode == FulltextExpansion {
+			return WhereCondition{}, NewError(ErrCodeInvalidParameter, "SQLite不支持查询扩展模式")
+		}
+		if qb.tableName == "" {
+			return WhereCondition{}, NewError(ErrCodeInvalidParameter, "SQLite全文搜索需要先指定FTS5表")
+		}
+		// FTS5 的表级 MATCH 不能通过别名引用，加了前缀的表在 FROM 中带别名，因此通过 rowid 子查询匹配
+		table := qb.quoteIdentifier(strings.Fields(qb.fullTableName())[0])
+		return WhereCondition{
+			Raw:    fmt.Sprintf("rowid IN (SELECT rowid FROM %s WHERE %s MATCH ?)", table, table),
+			Values: []interface{}{fmt.Sprintf("{%s} : (%s)", strings.Join(columns, " "), term)},
+		}, nil
+	}
+
+	return WhereCondition{}, NewErrorf(ErrCodeInvalidParameter, "数据库驱动 %s 不支持全文搜索", driver)
+}
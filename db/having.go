@@ -0,0 +1,61 @@
+package db
+
+import "strings"
+
+// HavingCount 添加 HAVING COUNT(*) <operator> ? 条件，值以参数绑定
+// 例如：db.Table("orders").Select("user_id").GroupBy("user_id").HavingCount(">", 5)
+func (qb *QueryBuilder) HavingCount(operator string, value interface{}) *QueryBuilder {
+	return qb.havingAggregate("COUNT", "*", operator, value)
+}
+
+// HavingSum 添加 HAVING SUM(column) <operator> ? 条件
+// 例如：db.Table("orders").GroupBy("user_id").HavingSum("amount", ">=", 1000)
+func (qb *QueryBuilder) HavingSum(column, operator string, value interface{}) *QueryBuilder {
+	return qb.havingAggregate("SUM", column, operator, value)
+}
+
+// HavingAvg 添加 HAVING AVG(column) <operator> ? 条件
+func (qb *QueryBuilder) HavingAvg(column, operator string, value interface{}) *QueryBuilder {
+	return qb.havingAggregate("AVG", column, operator, value)
+}
+
+// HavingMin 添加 HAVING MIN(column) <operator> ? 条件
+func (qb *QueryBuilder) HavingMin(column, operator string, value interface{}) *QueryBuilder {
+	return qb.havingAggregate("MIN", column, operator, value)
+}
+
+// HavingMax 添加 HAVING MAX(column) <operator> ? 条件
+func (qb *QueryBuilder) HavingMax(column, operator string, value interface{}) *QueryBuilder {
+	return qb.havingAggregate("MAX", column, operator, value)
+}
+
+// havingAggregate 添加聚合函数的HAVING条件，列名经过校验并加引号，操作符只允许比较操作符
+func (qb *QueryBuilder) havingAggregate(function, column, operator string, value interface{}) *QueryBuilder {
+	expr := "*"
+	if column != "*" {
+		if err := qb.validateColumnName(column); err != nil {
+			qb.setErr(err)
+			return qb
+		}
+		expr = qb.quoteIdentifier(column)
+	}
+
+	operator = strings.ToUpper(strings.TrimSpace(operator))
+	switch operator {
+	case "=", "!=", "<>", "<", ">", "<=", ">=":
+	default:
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "HAVING %s 不支持的操作符: %s", function, operator))
+		return qb
+	}
+	if isNilValue(value) {
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "%s(%s) 的比较值不能为空", function, column))
+		return qb
+	}
+
+	qb.havingConditions = append(qb.havingConditions, WhereCondition{
+		Raw:    function + "(" + expr + ") " + operator + " ?",
+		Values: []interface{}{value},
+		Logic:  "AND",
+	})
+	return qb
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// InsertIgnore 插入数据，违反唯一约束时跳过而不是报错，返回实际插入的行数（跳过时为0）
+// 与 Upsert 不同，已存在的记录不会被修改
+func (qb *QueryBuilder) InsertIgnore(data map[string]interface{}) (int64, error) {
+	if len(data) == 0 {
+		qb.releaseTimeout()
+		return 0, NewError(ErrCodeInvalidParameter, "插入数据不能为空")
+	}
+	return qb.InsertIgnoreBatch([]map[string]interface{}{data})
+}
+
+// InsertIgnoreBatch 批量插入数据，跳过违反唯一约束的行，返回实际插入的行数
+// MySQL 使用 INSERT IGNORE（同时会忽略其他可降级为警告的错误），PostgreSQL 和 SQLite 使用 ON CONFLICT DO NOTHING，
+// SQL Server 逐行插入并忽略重复键错误
+func (qb *QueryBuilder) InsertIgnoreBatch(data []map[string]interface{}) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	columns := qb.prepareBatchInsert(data)
+	chunkSize := qb.insertChunkRows(len(columns))
+	switch qb.getDriverName() {
+	case "sqlserver", "mssql":
+		// 多行INSERT中任一行重复会使整条语句失败，只能逐行插入
+		chunkSize = 1
+	}
+	return qb.insertChunks(columns, data, chunkSize, true)
+}
+
+// InsertIgnoreBatchIDs 批量插入数据，跳过违反唯一约束的行，返回新插入行的 id 列的值，被跳过的行不包含在结果中
+// PostgreSQL 和 SQLite 按块使用 ON CONFLICT DO NOTHING RETURNING id，MySQL 逐行 INSERT IGNORE 并读取自增ID，
+// SQL Server 不支持；多块或逐行插入且不在事务中时在事务中执行，任一块失败则全部回滚
+// 例如：ids, err := db.Table("tags").InsertIgnoreBatchIDs(rows)
+func (qb *QueryBuilder) InsertIgnoreBatchIDs(data []map[string]interface{}) ([]int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	switch qb.getDriverName() {
+	case "sqlserver", "mssql":
+		return nil, NewError(ErrCodeNotImplemented, "当前数据库不支持 InsertIgnoreBatchIDs").
+			WithContext("driver", qb.getDriverName())
+	}
+
+	columns := qb.prepareBatchInsert(data)
+	returning := qb.SupportsReturning()
+	chunkSize := 1
+	if returning {
+		chunkSize = qb.insertChunkRows(len(columns))
+	}
+
+	ids := make([]int64, 0, len(data))
+	err := qb.eachInsertChunk(len(data), chunkSize, func(tx TransactionInterface, start, end int) error {
+		var chunkIDs []int64
+		var err error
+		if returning {
+			chunkIDs, err = qb.insertIgnoreReturningIDs(tx, columns, data[start:end])
+		} else {
+			chunkIDs, err = qb.insertIgnoreRowID(tx, columns, data[start])
+		}
+		ids = append(ids, chunkIDs...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// insertIgnoreReturningIDs 用一条 INSERT ... ON CONFLICT DO NOTHING RETURNING id 插入一块数据
+func (qb *QueryBuilder) insertIgnoreReturningIDs(tx TransactionInterface, columns []string, data []map[string]interface{}) ([]int64, error) {
+	sqlStr, args := qb.buildBatchInsertSQL(columns, data, true)
+	sqlStr += " RETURNING " + qb.quoteIdentifier("id")
+	if qb.recordSQL(sqlStr, args) {
+		return nil, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = queryTxWithContext(qb.context(), tx, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return nil, connErr
+		}
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "批量插入失败").
+			WithContext("table", qb.tableName).
+			WithContext("rows", len(data))
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, WrapError(err, ErrCodeQueryFailed, "读取插入的ID失败").WithContext("table", qb.tableName)
+		}
+		id, err := castToInt(value)
+		if err != nil {
+			return nil, WrapError(err, ErrCodeQueryFailed, "插入的ID不是整数").WithContext("table", qb.tableName)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "读取插入的ID失败").WithContext("table", qb.tableName)
+	}
+	return ids, nil
+}
+
+// insertIgnoreRowID 插入一行并跳过重复键，实际插入时返回自增ID
+func (qb *QueryBuilder) insertIgnoreRowID(tx TransactionInterface, columns []string, row map[string]interface{}) ([]int64, error) {
+	sqlStr, args := qb.buildBatchInsertSQL(columns, []map[string]interface{}{row}, true)
+	if qb.recordSQL(sqlStr, args) {
+		return nil, nil
+	}
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = execTxWithContext(qb.context(), tx, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return nil, connErr
+		}
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "插入数据失败").WithContext("table", qb.tableName)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "获取影响行数失败")
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "获取插入ID失败").WithContext("table", qb.tableName)
+	}
+	return []int64{id}, nil
+}
+
+// insertIgnoreSQL 将INSERT语句改写为对应数据库跳过重复键的形式
+func (qb *QueryBuilder) insertIgnoreSQL(sqlStr string) string {
+	switch qb.getDriverName() {
+	case "mysql":
+		return strings.Replace(sqlStr, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	case "sqlserver", "mssql":
+		// 2601 和 2627 为唯一索引和唯一约束冲突
+		return "BEGIN TRY " + sqlStr + " END TRY BEGIN CATCH IF ERROR_NUMBER() NOT IN (2601, 2627) THROW; END CATCH"
+	default:
+		return sqlStr + " ON CONFLICT DO NOTHING"
+	}
+}
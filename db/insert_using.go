@@ -0,0 +1,133 @@
+package db
+
+import "strings"
+
+// InsertUsing 将子查询的结果插入当前表，生成 INSERT INTO table (columns) SELECT ...，数据不经过应用程序
+// 子查询的参数合并到插入语句中；columns 为空时省略列列表，此时子查询的列需要与表的列一一对应。
+// 子查询显式选择了列时校验列数与 columns 一致，返回插入的行数
+// 例如归档订单：
+//
+//	archive.InsertUsing([]string{"id", "user_id", "amount"},
+//		orders.Select("id", "user_id", "amount").Where("created_at", "<", cutoff))
+func (qb *QueryBuilder) InsertUsing(columns []string, sub *QueryBuilder) (int64, error) {
+	if err := qb.beginExecution(); err != nil {
+		return 0, err
+	}
+	defer qb.endExecution()
+
+	if qb.err != nil {
+		return 0, qb.err
+	}
+	if sub == nil {
+		return 0, NewError(ErrCodeInvalidParameter, "InsertUsing 缺少子查询")
+	}
+	for _, column := range columns {
+		if err := qb.validateColumnName(column); err != nil {
+			return 0, err
+		}
+	}
+	if fields := sub.selectFieldCount(); len(columns) > 0 && fields > 0 && fields != len(columns) {
+		return 0, NewErrorf(ErrCodeInvalidParameter, "InsertUsing 的列数 %d 与子查询选择的列数 %d 不一致", len(columns), fields).
+			WithContext("table", qb.tableName)
+	}
+
+	sqlStr, args := qb.buildInsertUsingSQL(columns, sub)
+	if sub.err != nil {
+		return 0, sub.err
+	}
+	if qb.recordSQL(sqlStr, args) {
+		return 0, nil
+	}
+
+	var result interface{}
+	var err error
+	if qb.transaction != nil {
+		result, err = execTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return 0, connErr
+		}
+		result, err = execWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return 0, WrapError(err, ErrCodeQueryFailed, "插入子查询结果失败").
+			WithContext("sql", sqlStr).
+			WithContext("args", args).
+			WithContext("table", qb.tableName)
+	}
+
+	if sqlResult, ok := result.(interface{ RowsAffected() (int64, error) }); ok {
+		affected, err := sqlResult.RowsAffected()
+		if err != nil {
+			return 0, WrapError(err, ErrCodeQueryFailed, "获取影响行数失败")
+		}
+		return affected, nil
+	}
+	return 0, NewError(ErrCodeQueryFailed, "无法获取影响行数").
+		WithContext("table", qb.tableName)
+}
+
+// buildInsertUsingSQL 构建 INSERT INTO ... SELECT 语句，子查询按当前连接的占位符格式统一编号
+func (qb *QueryBuilder) buildInsertUsingSQL(columns []string, sub *QueryBuilder) (string, []interface{}) {
+	subSQL, args := sub.buildSubquerySQL()
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(qb.quoteIdentifier(qb.fullTableName()))
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = qb.quoteIdentifier(column)
+		}
+		sql.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+	sql.WriteString(" ")
+	sql.WriteString(subSQL)
+
+	return qb.processPlaceholders(sql.String(), 0), args
+}
+
+// selectFieldCount 统计显式选择的列数，未指定选择列或包含 * 时无法确定，返回 0
+func (qb *QueryBuilder) selectFieldCount() int {
+	count := 0
+	for _, column := range qb.selectColumns {
+		for _, field := range splitTopLevelCommas(column) {
+			field = strings.TrimSpace(field)
+			if field == "*" || strings.HasSuffix(field, ".*") {
+				return 0
+			}
+			count++
+		}
+	}
+	for _, raw := range qb.selectRaw {
+		count += len(splitTopLevelCommas(raw.SQL))
+	}
+	return count
+}
+
+// splitTopLevelCommas 按不在括号和引号内的逗号拆分表达式
+func splitTopLevelCommas(expr string) []string {
+	var parts []string
+	depth := 0
+	var quote rune
+	start := 0
+	for i, r := range expr {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, expr[start:])
+}
@@ -21,7 +21,7 @@ type ConnectionInterface interface {
 
 	// 事务操作
 	Begin() (TransactionInterface, error)
-	BeginTx(opts *sql.TxOptions) (TransactionInterface, error)
+	BeginTx(ctx context.Context, opts *TxOptions) (TransactionInterface, error)
 
 	// 连接信息
 	GetConfig() *Config
@@ -186,7 +186,7 @@ type ModelInterface interface {
 
 // CacheInterface 基础缓存接口
 type CacheInterface interface {
-	// 基础操作
+	// 基础操作，Get 未命中时返回 ErrCacheKeyNotFound，已过期时返回 ErrCacheExpired
 	Get(key string) (interface{}, error)
 	Set(key string, value interface{}, ttl time.Duration) error
 	Delete(key string) error
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// RowIterator 逐行读取查询结果的迭代器，不会一次性加载全部数据
+// 读取完毕或出错时自动关闭，提前结束时必须调用 Close；关闭之前查询构建器处于执行状态，不能执行其他查询
+type RowIterator struct {
+	qb      *QueryBuilder
+	rows    *sql.Rows
+	columns []string
+	types   []*sql.ColumnType
+	closed  bool
+}
+
+// Rows 执行查询并返回逐行迭代器，适用于大结果集的导出、ETL等场景
+//
+//	it, err := query.Rows()
+//	if err != nil { ... }
+//	defer it.Close()
+//	for {
+//		row, ok, err := it.Next()
+//		if err != nil || !ok { break }
+//		...
+//	}
+func (qb *QueryBuilder) Rows() (*RowIterator, error) {
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	qb.checkLockTransaction()
+	if qb.err != nil {
+		qb.endExecution()
+		return nil, qb.err
+	}
+
+	sqlStr, args := qb.buildSelectSQL()
+
+	var rows *sql.Rows
+	var err error
+
+	if qb.transaction != nil {
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			qb.endExecution()
+			return nil, connErr
+		}
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
+	}
+
+	if err != nil {
+		qb.endExecution()
+		wrappedErr := WrapError(err, ErrCodeQueryFailed, "查询执行失败").
+			WithContext("sql", sqlStr).
+			WithContext("args", args).
+			WithContext("table", qb.tableName).
+			WithContext("operation", "SELECT_ROWS").
+			WithDetails(fmt.Sprintf("数据库查询错误: %v", err))
+		LogError(wrappedErr)
+		return nil, wrappedErr
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		qb.endExecution()
+		return nil, WrapError(err, ErrCodeQueryFailed, "获取结果列失败").
+			WithContext("sql", sqlStr)
+	}
+
+	types, _ := rows.ColumnTypes()
+	return &RowIterator{qb: qb, rows: rows, columns: columns, types: types}, nil
+}
+
+// Next 读取下一行，没有更多数据时返回 ok=false
+func (it *RowIterator) Next() (map[string]interface{}, bool, error) {
+	if it.closed {
+		return nil, false, nil
+	}
+
+	if !it.rows.Next() {
+		err := it.rows.Err()
+		it.Close()
+		if err != nil {
+			return nil, false, WrapError(err, ErrCodeQueryFailed, "读取查询结果失败")
+		}
+		return nil, false, nil
+	}
+
+	row, err := it.qb.scanRow(it.rows, it.columns, it.types)
+	if err != nil {
+		it.Close()
+		return nil, false, WrapError(err, ErrCodeQueryFailed, "扫描查询结果失败")
+	}
+	return row, true, nil
+}
+
+// Columns 获取结果列名
+func (it *RowIterator) Columns() []string {
+	return it.columns
+}
+
+// Close 关闭迭代器并释放数据库连接，可重复调用
+func (it *RowIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	err := it.rows.Close()
+	it.qb.endExecution()
+	return err
+}
+
+// Each 逐行处理查询结果，fn 返回 false 时提前结束，结束后自动关闭结果集
+func (qb *QueryBuilder) Each(fn func(row map[string]interface{}) bool) error {
+	it, err := qb.Rows()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for {
+		row, ok, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if !ok || !fn(row) {
+			return nil
+		}
+	}
+}
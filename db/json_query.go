@@ -0,0 +1,238 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jsonPathSegmentRegex JSON路径片段：键名或数组下标
+var jsonPathSegmentRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)?((\[\d+\])*)$`)
+
+// jsonPathIndexRegex 数组下标
+var jsonPathIndexRegex = regexp.MustCompile(`\[(\d+)\]`)
+
+// WhereJsonContains JSON列包含条件
+// path 为JSON路径，支持 "a.b"、"$.a.b"、"tags[0]" 等形式，空路径表示整个JSON文档
+// MySQL使用 JSON_CONTAINS，PostgreSQL使用 jsonb @>，SQLite使用 json_each
+func (qb *QueryBuilder) WhereJsonContains(column, path string, value interface{}) *QueryBuilder {
+	condition, err := qb.buildJsonContainsCondition(column, path, value)
+	if err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	condition.Logic = "AND"
+	qb.whereConditions = append(qb.whereConditions, condition)
+	return qb
+}
+
+// WhereJsonLength JSON数组长度条件，如 WhereJsonLength("tags", ">", 2)
+// MySQL使用 JSON_LENGTH，PostgreSQL使用 jsonb_array_length，SQLite使用 json_array_length
+func (qb *QueryBuilder) WhereJsonLength(column, operator string, length int) *QueryBuilder {
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	operator = strings.TrimSpace(operator)
+	switch operator {
+	case "=", "!=", "<>", ">", ">=", "<", "<=":
+	default:
+		qb.setErr(NewErrorf(ErrCodeInvalidParameter, "WhereJsonLength 不支持的操作符: %s", operator))
+		return qb
+	}
+
+	column = qb.quoteIdentifier(column)
+	var function string
+	switch qb.getDriverName() {
+	case "postgres", "postgresql":
+		function = "jsonb_array_length(" + column + "::jsonb)"
+	case "sqlite", "sqlite3":
+		function = "json_array_length(" + column + ")"
+	default:
+		function = "JSON_LENGTH(" + column + ")"
+	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    fmt.Sprintf("%s %s ?", function, operator),
+		Values: []interface{}{length},
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// WhereArrayContains JSON数组列包含所有给定值，values 为空时不添加条件
+// MySQL使用 JSON_CONTAINS，PostgreSQL使用 jsonb @>，值编码为JSON数组后绑定；SQLite对每个值使用 json_each
+func (qb *QueryBuilder) WhereArrayContains(column string, values ...interface{}) *QueryBuilder {
+	if len(values) == 0 {
+		return qb
+	}
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	column = qb.quoteIdentifier(column)
+
+	driver := qb.getDriverName()
+	if driver == "sqlite" || driver == "sqlite3" {
+		parts := make([]string, len(values))
+		for i := range values {
+			parts[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value = ?)", column)
+		}
+		qb.whereConditions = append(qb.whereConditions, WhereCondition{
+			Raw:    "(" + strings.Join(parts, " AND ") + ")",
+			Values: values,
+			Logic:  "AND",
+		})
+		return qb
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		qb.setErr(WrapError(err, ErrCodeInvalidParameter, "WhereArrayContains 的值无法编码为JSON").
+			WithContext("column", column))
+		return qb
+	}
+	raw := fmt.Sprintf("JSON_CONTAINS(%s, ?)", column)
+	if driver == "postgres" || driver == "postgresql" {
+		raw = fmt.Sprintf("%s::jsonb @> ?::jsonb", column)
+	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    raw,
+		Values: []interface{}{string(encoded)},
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// SelectJson 选择JSON列中指定路径的值
+func (qb *QueryBuilder) SelectJson(column, path, alias string) *QueryBuilder {
+	segments, err := qb.checkedJsonPath(column, path)
+	if err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	if alias != "" {
+		if err := qb.validateColumnName(alias); err != nil {
+			qb.setErr(err)
+			return qb
+		}
+	}
+
+	column = qb.quoteIdentifier(column)
+	var expr RawExpression
+	switch qb.getDriverName() {
+	case "postgres", "postgresql":
+		expr = RawExpression{SQL: column + " #>> ?", Values: []interface{}{buildPostgresJsonPath(segments)}}
+	case "sqlite", "sqlite3":
+		expr = RawExpression{SQL: "json_extract(" + column + ", ?)", Values: []interface{}{buildJsonPath(segments)}}
+	default:
+		expr = RawExpression{SQL: "JSON_UNQUOTE(JSON_EXTRACT(" + column + ", ?))", Values: []interface{}{buildJsonPath(segments)}}
+	}
+
+	if alias != "" {
+		expr.SQL += " AS " + qb.quoteIdentifier(alias)
+	}
+	qb.selectRaw = append(qb.selectRaw, expr)
+	return qb
+}
+
+// buildJsonContainsCondition 根据数据库驱动构建JSON包含条件
+func (qb *QueryBuilder) buildJsonContainsCondition(column, path string, value interface{}) (WhereCondition, error) {
+	segments, err := qb.checkedJsonPath(column, path)
+	if err != nil {
+		return WhereCondition{}, err
+	}
+	quoted := qb.quoteIdentifier(column)
+
+	switch qb.getDriverName() {
+	case "sqlite", "sqlite3":
+		return WhereCondition{
+			Raw:    fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s, ?) WHERE json_each.value = ?)", quoted),
+			Values: []interface{}{buildJsonPath(segments), value},
+		}, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return WhereCondition{}, WrapError(err, ErrCodeInvalidParameter, "WhereJsonContains 的值无法编码为JSON").
+			WithContext("column", column)
+	}
+
+	switch qb.getDriverName() {
+	case "postgres", "postgresql":
+		if len(segments) == 0 {
+			return WhereCondition{
+				Raw:    fmt.Sprintf("%s::jsonb @> ?::jsonb", quoted),
+				Values: []interface{}{string(encoded)},
+			}, nil
+		}
+		return WhereCondition{
+			Raw:    fmt.Sprintf("(%s::jsonb #> ?) @> ?::jsonb", quoted),
+			Values: []interface{}{buildPostgresJsonPath(segments), string(encoded)},
+		}, nil
+	default:
+		return WhereCondition{
+			Raw:    fmt.Sprintf("JSON_CONTAINS(%s, ?, ?)", quoted),
+			Values: []interface{}{string(encoded), buildJsonPath(segments)},
+		}, nil
+	}
+}
+
+// checkedJsonPath 校验列名并解析JSON路径
+func (qb *QueryBuilder) checkedJsonPath(column, path string) ([]string, error) {
+	if err := qb.validateColumnName(column); err != nil {
+		return nil, err
+	}
+	segments, ok := parseJsonPath(path)
+	if !ok {
+		return nil, NewErrorf(ErrCodeInvalidParameter, "无效的JSON路径: %s", path).WithContext("column", column)
+	}
+	return segments, nil
+}
+
+// parseJsonPath 解析并校验JSON路径，仅允许键名（字母、数字、下划线）和数组下标
+func parseJsonPath(path string) ([]string, bool) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return []string{}, true
+	}
+
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		matches := jsonPathSegmentRegex.FindStringSubmatch(part)
+		if matches == nil || part == "" {
+			return nil, false
+		}
+		if matches[1] != "" {
+			segments = append(segments, matches[1])
+		}
+		for _, index := range jsonPathIndexRegex.FindAllStringSubmatch(matches[2], -1) {
+			segments = append(segments, "["+index[1]+"]")
+		}
+	}
+	return segments, true
+}
+
+// buildJsonPath 构建MySQL/SQLite格式的JSON路径，如 $.a.b[0]
+func buildJsonPath(segments []string) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment, "[") {
+			path.WriteString(".")
+		}
+		path.WriteString(segment)
+	}
+	return path.String()
+}
+
+// buildPostgresJsonPath 构建PostgreSQL格式的JSON路径数组，如 {a,b,0}
+func buildPostgresJsonPath(segments []string) string {
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		parts[i] = strings.Trim(segment, "[]")
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,69 @@
+package db
+
+import "strings"
+
+// GetKeyedBy 执行查询并按列值索引结果，返回 列值 → 记录
+// 列值重复时保留最后一条记录，需要保留全部记录时使用 GetGroupedBy；
+// 列值为NULL的记录键为 nil，字节数组转为字符串，列必须出现在查询结果中
+// 例如：users, err := db.Table("users").WhereIn("id", ids).GetKeyedBy("id")
+func (qb *QueryBuilder) GetKeyedBy(column string) (map[interface{}]map[string]interface{}, error) {
+	rows, key, err := qb.getForKeying(column)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[interface{}]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		value, ok := row[key]
+		if !ok {
+			return nil, missingResultColumnError(column)
+		}
+		result[aggregateValue(value)] = row
+	}
+	return result, nil
+}
+
+// GetGroupedBy 执行查询并按列值分组结果，返回 列值 → 记录列表，组内记录保持查询顺序
+// 例如：orders, err := db.Table("orders").WhereIn("user_id", userIDs).GetGroupedBy("user_id")
+func (qb *QueryBuilder) GetGroupedBy(column string) (map[interface{}][]map[string]interface{}, error) {
+	rows, key, err := qb.getForKeying(column)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[interface{}][]map[string]interface{})
+	for _, row := range rows {
+		value, ok := row[key]
+		if !ok {
+			return nil, missingResultColumnError(column)
+		}
+		group := aggregateValue(value)
+		result[group] = append(result[group], row)
+	}
+	return result, nil
+}
+
+// getForKeying 校验列名并执行查询，返回结果和列在结果中的键
+// 带表前缀的列（如 users.id）在结果中的键为去掉前缀后的列名
+func (qb *QueryBuilder) getForKeying(column string) ([]map[string]interface{}, string, error) {
+	if err := qb.validateColumnName(column); err != nil {
+		return nil, "", err
+	}
+
+	rows, err := qb.Get()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := column
+	if index := strings.LastIndex(column, "."); index >= 0 {
+		key = column[index+1:]
+	}
+	return rows, key, nil
+}
+
+// missingResultColumnError 查询结果中不包含用于索引的列
+func missingResultColumnError(column string) error {
+	return NewErrorf(ErrCodeInvalidParameter, "查询结果中不存在列: %s", column).
+		WithContext("column", column)
+}
@@ -0,0 +1,77 @@
+package db
+
+import "strings"
+
+// likeEscapeReplacer 转义LIKE通配符，转义字符为反斜杠
+var likeEscapeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike 转义LIKE模式中的通配符 % 和 _ 以及转义字符本身
+func EscapeLike(value string) string {
+	return likeEscapeReplacer.Replace(value)
+}
+
+// WhereLike 包含匹配，value中的通配符会被转义，等价于 column LIKE '%value%'
+func (qb *QueryBuilder) WhereLike(column, value string) *QueryBuilder {
+	return qb.addLikeCondition(column, "LIKE", "%"+EscapeLike(value)+"%")
+}
+
+// WhereNotLike 不包含匹配，value中的通配符会被转义
+func (qb *QueryBuilder) WhereNotLike(column, value string) *QueryBuilder {
+	return qb.addLikeCondition(column, "NOT LIKE", "%"+EscapeLike(value)+"%")
+}
+
+// WhereLikeStart 前缀匹配，等价于 column LIKE 'value%'
+func (qb *QueryBuilder) WhereLikeStart(column, value string) *QueryBuilder {
+	return qb.addLikeCondition(column, "LIKE", EscapeLike(value)+"%")
+}
+
+// WhereLikeEnd 后缀匹配，等价于 column LIKE '%value'
+func (qb *QueryBuilder) WhereLikeEnd(column, value string) *QueryBuilder {
+	return qb.addLikeCondition(column, "LIKE", "%"+EscapeLike(value))
+}
+
+// WhereILike 不区分大小写的包含匹配
+// PostgreSQL使用 ILIKE，其他数据库使用 LOWER(column) LIKE LOWER(?)
+func (qb *QueryBuilder) WhereILike(column, value string) *QueryBuilder {
+	pattern := "%" + EscapeLike(value) + "%"
+	switch qb.getDriverName() {
+	case "postgres", "postgresql", "pq":
+		return qb.addLikeCondition(column, "ILIKE", pattern)
+	}
+
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    "LOWER(" + qb.quoteIdentifier(column) + ") LIKE LOWER(?) " + qb.likeEscapeClause(),
+		Values: []interface{}{pattern},
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// addLikeCondition 追加带ESCAPE子句的LIKE条件
+func (qb *QueryBuilder) addLikeCondition(column, operator, pattern string) *QueryBuilder {
+	if err := qb.validateColumnName(column); err != nil {
+		qb.setErr(err)
+		return qb
+	}
+	qb.whereConditions = append(qb.whereConditions, WhereCondition{
+		Raw:    qb.quoteIdentifier(column) + " " + operator + " ? " + qb.likeEscapeClause(),
+		Values: []interface{}{pattern},
+		Logic:  "AND",
+	})
+	return qb
+}
+
+// likeEscapeClause 根据数据库驱动生成ESCAPE子句
+// MySQL字符串字面量中反斜杠本身需要转义
+func (qb *QueryBuilder) likeEscapeClause() string {
+	switch qb.getDriverName() {
+	case "mysql":
+		return `ESCAPE '\\'`
+	default:
+		return `ESCAPE '\'`
+	}
+}
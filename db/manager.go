@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"sync"
 	"time"
@@ -200,22 +202,26 @@ func (m *Manager) handleUnhealthyConnection(name string, conn ConnectionInterfac
 	if m.logger != nil {
 		m.logger.Error("连接健康检查失败", "connection", name, "error", err)
 	}
+	notifyConnectionError(name, err)
 
 	// 尝试重新连接
 	if reconnErr := conn.Connect(); reconnErr != nil {
 		if m.logger != nil {
 			m.logger.Error("连接重连失败", "connection", name, "error", reconnErr)
 		}
+		notifyConnectionError(name, reconnErr)
 
 		// 如果重连失败，移除这个连接
 		m.mutex.Lock()
 		delete(m.connections, name)
 		delete(m.connectionStats, name)
 		m.mutex.Unlock()
+		notifyDisconnect(name)
 	} else {
 		if m.logger != nil {
 			m.logger.Info("连接重连成功", "connection", name)
 		}
+		notifyConnect(name)
 	}
 }
 
@@ -228,6 +234,32 @@ func (m *Manager) AddConfig(name string, config *Config) error {
 	return nil
 }
 
+// SetTimezone 设置指定连接解析和格式化时间使用的时区
+func (m *Manager) SetTimezone(name string, loc *time.Location) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	config, exists := m.configs[name]
+	if !exists {
+		return NewErrorf(ErrCodeInvalidParameter, "连接配置 '%s' 不存在", name)
+	}
+	config.SetTimezone(loc)
+	return nil
+}
+
+// SetTablePrefix 设置连接的表前缀，之后构建的查询自动为表名加上前缀
+func (m *Manager) SetTablePrefix(name, prefix string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	config, exists := m.configs[name]
+	if !exists {
+		return NewErrorf(ErrCodeInvalidParameter, "连接配置 '%s' 不存在", name)
+	}
+	config.SetTablePrefix(prefix)
+	return nil
+}
+
 // Connection 获取数据库连接 - 优化版本
 func (m *Manager) Connection(name string) (ConnectionInterface, error) {
 	// 先检查连接数量限制
@@ -249,23 +281,19 @@ func (m *Manager) Connection(name string) (ConnectionInterface, error) {
 		}
 	}
 
-	// 先检查是否已有连接（读锁）
-	m.mutex.RLock()
+	// 先检查是否已有连接，更新使用统计需要写锁
+	m.mutex.Lock()
 	if conn, exists := m.connections[name]; exists {
 		// 更新使用时间
-		if stats, exists := m.connectionStats[name]; exists {
+		stats, hasStats := m.connectionStats[name]
+		if hasStats {
 			stats.LastUsed = time.Now()
 			stats.TotalQueries++
 		}
-		m.mutex.RUnlock()
-
-		// 简化健康检查，减少Ping频率
-		m.mutex.RLock()
-		stats, hasStats := m.connectionStats[name]
-		m.mutex.RUnlock()
 
 		// 只有在连接明确不健康或很久没检查时才进行ping
 		shouldPing := !hasStats || (!stats.IsHealthy && time.Since(stats.LastCheck) > 5*time.Second)
+		m.mutex.Unlock()
 
 		if shouldPing {
 			// 快速健康检查（更短超时）
@@ -280,6 +308,7 @@ func (m *Manager) Connection(name string) (ConnectionInterface, error) {
 					if m.logger != nil {
 						m.logger.Warn("连接不健康，移除连接", "connection", name, "error", err)
 					}
+					notifyConnectionError(name, err)
 					// 连接不健康，移除
 					m.removeConnection(name)
 					return m.createNewConnection(name)
@@ -296,6 +325,7 @@ func (m *Manager) Connection(name string) (ConnectionInterface, error) {
 				if m.logger != nil {
 					m.logger.Warn("连接ping超时，移除连接", "connection", name)
 				}
+				notifyConnectionError(name, NewError(ErrCodeConnectionTimeout, ErrConnectionTimeout.Message).WithContext("connection", name))
 				// Ping超时，移除
 				m.removeConnection(name)
 				return m.createNewConnection(name)
@@ -307,7 +337,7 @@ func (m *Manager) Connection(name string) (ConnectionInterface, error) {
 
 	// 获取配置
 	_, exists := m.configs[name]
-	m.mutex.RUnlock()
+	m.mutex.Unlock()
 	if !exists {
 		return nil, fmt.Errorf("连接配置 '%s' 不存在", name)
 	}
@@ -350,11 +380,13 @@ func (m *Manager) createNewConnection(name string) (ConnectionInterface, error)
 	}
 
 	if err != nil {
+		notifyConnectionError(name, err)
 		return nil, fmt.Errorf("创建数据库连接失败: %w", err)
 	}
 
 	// 连接数据库
 	if err := conn.Connect(); err != nil {
+		notifyConnectionError(name, err)
 		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
 
@@ -378,6 +410,7 @@ func (m *Manager) createNewConnection(name string) (ConnectionInterface, error)
 	if m.logger != nil {
 		m.logger.Info("数据库连接创建成功", "connection", name, "driver", config.Driver, "duration", duration)
 	}
+	notifyConnect(name)
 
 	return conn, nil
 }
@@ -401,6 +434,121 @@ func DB(name ...string) (ConnectionInterface, error) {
 	return defaultManager.Connection(connectionName)
 }
 
+// Exec 在指定连接上执行原生SQL（如建表、数据修复脚本）
+func (m *Manager) Exec(connName string, query string, args ...interface{}) (sql.Result, error) {
+	return m.ExecContext(context.Background(), connName, query, args...)
+}
+
+// ExecContext 在指定连接上执行原生SQL，支持上下文取消和超时
+func (m *Manager) ExecContext(ctx context.Context, connName string, query string, args ...interface{}) (sql.Result, error) {
+	conn, err := m.Connection(connName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := execWithContext(ctx, conn, query, args...)
+	if err != nil {
+		wrappedErr := WrapError(err, ErrCodeQueryFailed, "SQL执行失败").
+			WithContext("sql", query).
+			WithContext("args", args).
+			WithContext("connection", connName)
+		LogError(wrappedErr)
+		return nil, wrappedErr
+	}
+	return result, nil
+}
+
+// Query 在指定连接上执行原生查询，调用方负责关闭返回的 *sql.Rows
+func (m *Manager) Query(connName string, query string, args ...interface{}) (*sql.Rows, error) {
+	return m.QueryContext(context.Background(), connName, query, args...)
+}
+
+// QueryContext 在指定连接上执行原生查询，支持上下文取消和超时
+func (m *Manager) QueryContext(ctx context.Context, connName string, query string, args ...interface{}) (*sql.Rows, error) {
+	conn, err := m.Connection(connName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryWithContext(ctx, conn, query, args...)
+	if err != nil {
+		wrappedErr := WrapError(err, ErrCodeQueryFailed, "查询执行失败").
+			WithContext("sql", query).
+			WithContext("args", args).
+			WithContext("connection", connName)
+		LogError(wrappedErr)
+		return nil, wrappedErr
+	}
+	return rows, nil
+}
+
+// execWithContext 执行SQL，上下文不可取消时走连接自身的Exec以保留SQL日志
+// 驱动返回连接级错误时通知连接监听器
+func execWithContext(ctx context.Context, conn ConnectionInterface, query string, args ...interface{}) (result sql.Result, err error) {
+	defer func() { reportExecutionError(conn, err) }()
+	profileQuery(ctx, query)
+
+	if ctx.Done() == nil {
+		return conn.Exec(query, args...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sqlDB := conn.GetDB()
+	if sqlDB == nil {
+		return nil, ErrConnectionClosed
+	}
+	return execDB(ctx, sqlDB, conn.GetConfig(), query, args...)
+}
+
+// queryWithContext 执行查询，上下文不可取消时走连接自身的Query以保留SQL日志
+// 驱动返回连接级错误时通知连接监听器
+func queryWithContext(ctx context.Context, conn ConnectionInterface, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { reportExecutionError(conn, err) }()
+	profileQuery(ctx, query)
+
+	if ctx.Done() == nil {
+		return conn.Query(query, args...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sqlDB := conn.GetDB()
+	if sqlDB == nil {
+		return nil, ErrConnectionClosed
+	}
+	return queryDB(ctx, sqlDB, conn.GetConfig(), query, args...)
+}
+
+// Exec 在指定连接上执行原生SQL（便捷函数）
+func Exec(connName string, query string, args ...interface{}) (sql.Result, error) {
+	return defaultManager.Exec(connName, query, args...)
+}
+
+// Query 在指定连接上执行原生查询（便捷函数）
+func Query(connName string, query string, args ...interface{}) (*sql.Rows, error) {
+	return defaultManager.Query(connName, query, args...)
+}
+
+// SetTimezone 设置连接解析和格式化时间使用的时区（便捷函数），默认连接为 default
+// 未设置时区的连接按服务器本地时区生成时间戳和解析时间字符串，需要UTC时传入 time.UTC
+func SetTimezone(loc *time.Location, connectionName ...string) error {
+	connName := "default"
+	if len(connectionName) > 0 {
+		connName = connectionName[0]
+	}
+	return defaultManager.SetTimezone(connName, loc)
+}
+
+// SetTablePrefix 设置连接的表前缀（便捷函数），默认连接为 default
+func SetTablePrefix(prefix string, connectionName ...string) error {
+	connName := "default"
+	if len(connectionName) > 0 {
+		connName = connectionName[0]
+	}
+	return defaultManager.SetTablePrefix(connName, prefix)
+}
+
 // Table 创建表查询构建器（便捷函数）
 func Table(tableName string, connectionName ...string) (*QueryBuilder, error) {
 	connName := "default"
@@ -417,6 +565,16 @@ func Table(tableName string, connectionName ...string) (*QueryBuilder, error) {
 	return builder, nil
 }
 
+// RawTable 创建不加表前缀的表查询构建器（便捷函数），表名按原样使用
+func RawTable(tableName string, connectionName ...string) (*QueryBuilder, error) {
+	builder, err := Table(tableName, connectionName...)
+	if err != nil {
+		return nil, err
+	}
+	builder.rawTable = true
+	return builder, nil
+}
+
 // Model 从模型创建查询构建器（便捷函数）
 func Model(model interface{}, connectionName ...string) (*QueryBuilder, error) {
 	connName := "default"
@@ -522,6 +680,17 @@ func WarmUpConnections() error {
 
 // CloseAllConnections 关闭所有连接
 func (m *Manager) CloseAllConnections() error {
+	closed, err := m.closeAllConnections()
+
+	// 监听器可能访问管理器，释放锁后再通知
+	for _, name := range closed {
+		notifyDisconnect(name)
+	}
+	return err
+}
+
+// closeAllConnections 关闭并清空所有连接，返回已关闭的连接名
+func (m *Manager) closeAllConnections() ([]string, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -535,10 +704,12 @@ func (m *Manager) CloseAllConnections() error {
 	}
 
 	var errors []string
+	closed := make([]string, 0, len(m.connections))
 	for name, conn := range m.connections {
 		if err := conn.Close(); err != nil {
 			errors = append(errors, fmt.Sprintf("关闭连接 '%s' 失败: %v", name, err))
 		}
+		closed = append(closed, name)
 	}
 
 	// 清空连接和统计
@@ -546,14 +717,14 @@ func (m *Manager) CloseAllConnections() error {
 	m.connectionStats = make(map[string]*ConnectionStats)
 
 	if len(errors) > 0 {
-		return fmt.Errorf("关闭连接时发生错误: %v", errors)
+		return closed, fmt.Errorf("关闭连接时发生错误: %v", errors)
 	}
 
 	if m.logger != nil {
 		m.logger.Info("所有数据库连接已关闭")
 	}
 
-	return nil
+	return closed, nil
 }
 
 // CloseAllConnections 关闭所有连接（便捷函数）
@@ -561,6 +732,35 @@ func CloseAllConnections() error {
 	return defaultManager.CloseAllConnections()
 }
 
+// Close 关闭管理器，用于程序退出时确定性地释放数据库资源
+// 关闭所有连接（底层 *sql.DB）、停止健康检查和空闲连接清理协程；
+// 默认管理器还会关闭默认查询缓存并换上新的空内存缓存，之前缓存的查询结果全部失效
+// 连接配置保留，关闭后再次使用连接会重新建立
+func (m *Manager) Close() error {
+	select {
+	case m.stopCleanup <- true:
+	default:
+	}
+	m.DisableHealthCheck()
+
+	err := m.CloseAllConnections()
+
+	if m == defaultManager {
+		cache := GetDefaultCache()
+		SetDefaultCache(nil)
+		if cacheErr := cache.Close(); cacheErr != nil && err == nil {
+			err = WrapError(cacheErr, ErrCodeCacheFailed, "关闭默认缓存失败")
+		}
+	}
+	return err
+}
+
+// Close 关闭默认管理器的所有连接和默认缓存（便捷函数），通常在服务优雅退出时调用
+// 默认缓存关闭后会换上新的空内存缓存，之后的查询缓存仍可使用
+func Close() error {
+	return defaultManager.Close()
+}
+
 // GetHealthyConnections 获取健康的连接数量
 func (m *Manager) GetHealthyConnections() (healthy, total int) {
 	m.mutex.RLock()
@@ -580,12 +780,25 @@ func GetHealthyConnections() (healthy, total int) {
 	return defaultManager.GetHealthyConnections()
 }
 
+// RemoveConnection 关闭并移除连接及其配置，之后需要重新 AddConfig 才能使用该连接名
+func (m *Manager) RemoveConnection(name string) {
+	m.removeConnection(name)
+
+	m.mutex.Lock()
+	delete(m.configs, name)
+	m.mutex.Unlock()
+}
+
+// RemoveConnection 关闭并移除连接及其配置（便捷函数）
+func RemoveConnection(name string) {
+	defaultManager.RemoveConnection(name)
+}
+
 // removeConnection 安全移除连接
 func (m *Manager) removeConnection(name string) {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	if conn, exists := m.connections[name]; exists {
+	conn, exists := m.connections[name]
+	if exists {
 		// 尝试关闭连接
 		if err := conn.Close(); err != nil && m.logger != nil {
 			m.logger.Warn("关闭连接失败", "connection", name, "error", err)
@@ -598,6 +811,24 @@ func (m *Manager) removeConnection(name string) {
 			m.logger.Debug("连接已移除", "connection", name)
 		}
 	}
+	m.mutex.Unlock()
+
+	if exists {
+		notifyDisconnect(name)
+	}
+}
+
+// connectionName 查找连接实例对应的连接名，找不到时返回空字符串
+func (m *Manager) connectionName(conn ConnectionInterface) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for name, candidate := range m.connections {
+		if candidate == conn {
+			return name
+		}
+	}
+	return ""
 }
 
 // cleanupIdleConnections 清理空闲连接
@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试Manager原生Exec/Query
+func TestManagerExecAndQuery(t *testing.T) {
+	_, connName := setupTestDB(t)
+	m := DefaultManager()
+
+	if _, err := m.Exec(connName, "CREATE TABLE settings (k TEXT, v TEXT)"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	result, err := m.Exec(connName, "INSERT INTO settings (k, v) VALUES (?, ?), (?, ?)", "a", "1", "b", "2")
+	if err != nil {
+		t.Fatalf("Exec insert failed: %v", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 2 {
+		t.Errorf("Expected 2 rows affected, got %d", affected)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rows, err := m.QueryContext(ctx, connName, "SELECT v FROM settings WHERE k = ?", "b")
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		values = append(values, v)
+	}
+	if len(values) != 1 || values[0] != "2" {
+		t.Errorf("Expected [2], got %v", values)
+	}
+
+	if _, err := m.Exec(connName, "INSERT INTO missing_table VALUES (1)"); err == nil {
+		t.Error("Expected error for invalid SQL")
+	} else if tormErr, ok := err.(*TormError); !ok || tormErr.Code != ErrCodeQueryFailed {
+		t.Errorf("Expected TormError with ErrCodeQueryFailed, got %v", err)
+	}
+
+	if _, err := m.Query("no_such_connection", "SELECT 1"); err == nil {
+		t.Error("Expected error for unknown connection")
+	}
+}
+
+func TestRawQuery(t *testing.T) {
+	table := setupTestTable(t, testUsers)
+	connName := table().GetConnection()
+
+	query := "SELECT name, age, RANK() OVER (ORDER BY age DESC) AS age_rank FROM users WHERE status = ? ORDER BY age DESC"
+	rows, err := Raw(connName, query, "active")
+	if err != nil {
+		t.Fatalf("Raw failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["name"] != "bob" || rows[0]["age_rank"] != int64(1) {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	// 结果与查询构建器一致
+	built, err := table().Where("name", "=", "bob").First()
+	if err != nil {
+		t.Fatalf("First failed: %v", err)
+	}
+	raw, err := RawFirst(connName, "SELECT * FROM users WHERE name = ?", "bob")
+	if err != nil {
+		t.Fatalf("RawFirst failed: %v", err)
+	}
+	if !reflect.DeepEqual(built, raw) {
+		t.Errorf("Expected raw result %v to match builder result %v", raw, built)
+	}
+
+	if _, err := RawFirst(connName, "SELECT * FROM users WHERE name = ?", "nobody"); !IsNotFoundError(err) {
+		t.Errorf("Expected record not found, got %v", err)
+	}
+
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	var users []user
+	if err := RawInto(connName, &users, "SELECT name, age FROM users WHERE age = ? ORDER BY name", 25); err != nil {
+		t.Fatalf("RawInto failed: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "bob" || users[1].Name != "dave" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+	var one user
+	if err := RawInto(connName, &one, "SELECT name, age FROM users WHERE id = ?", 3); err != nil {
+		t.Fatalf("RawInto struct failed: %v", err)
+	}
+	if one.Name != "carol" || one.Age != 30 {
+		t.Errorf("Unexpected user: %+v", one)
+	}
+}
+
+// recordingConnectionListener 记录收到的连接事件
+type recordingConnectionListener struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *recordingConnectionListener) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *recordingConnectionListener) OnConnect(name string) { l.record("connect:" + name) }
+
+func (l *recordingConnectionListener) OnError(name string, err error) { l.record("error:" + name) }
+
+func (l *recordingConnectionListener) OnDisconnect(name string) { l.record("disconnect:" + name) }
+
+// 测试连接生命周期事件
+func TestConnectionListener(t *testing.T) {
+	listener := &recordingConnectionListener{}
+	AddConnectionListener(listener)
+	defer ClearConnectionListeners()
+
+	connName := fmt.Sprintf("test_%s", t.Name())
+	if err := AddConnection(connName, &Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	conn, err := DB(connName)
+	if err != nil {
+		t.Fatalf("DB failed: %v", err)
+	}
+
+	// SQL错误不是连接错误
+	if _, err := Exec(connName, "SELECT * FROM missing_table"); err == nil {
+		t.Fatal("Expected error for invalid SQL")
+	}
+
+	// 底层连接被关闭后执行SQL
+	conn.GetDB().Close()
+	if _, err := Exec(connName, "SELECT 1"); err == nil {
+		t.Fatal("Expected error on closed connection")
+	}
+	RemoveConnection(connName)
+
+	// 建立连接失败
+	badName := connName + "_bad"
+	badPath := filepath.Join(t.TempDir(), "missing", "dir", "test.db")
+	if err := AddConnection(badName, &Config{Driver: "sqlite", Database: badPath + "?mode=ro"}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	defer RemoveConnection(badName)
+	if _, err := DB(badName); err == nil {
+		t.Fatal("Expected error connecting to a missing database")
+	}
+
+	want := []string{"connect:" + connName, "error:" + connName, "disconnect:" + connName, "error:" + badName}
+	if !reflect.DeepEqual(listener.events, want) {
+		t.Errorf("Expected events %v, got %v", want, listener.events)
+	}
+}
+
+// badConnTransaction 连接已断开的事务，执行SQL时返回 driver.ErrBadConn
+type badConnTransaction struct {
+	TransactionInterface
+	conn ConnectionInterface
+}
+
+func (t *badConnTransaction) connection() ConnectionInterface { return t.conn }
+
+func (t *badConnTransaction) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, driver.ErrBadConn
+}
+
+func (t *badConnTransaction) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, driver.ErrBadConn
+}
+
+// 测试事务中的连接错误同样通知监听器
+func TestConnectionListenerInTransaction(t *testing.T) {
+	listener := &recordingConnectionListener{}
+	AddConnectionListener(listener)
+	defer ClearConnectionListeners()
+
+	connName := fmt.Sprintf("test_%s", t.Name())
+	if err := AddConnection(connName, &Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	defer RemoveConnection(connName)
+	conn, err := DB(connName)
+	if err != nil {
+		t.Fatalf("DB failed: %v", err)
+	}
+
+	// 事务中的SQL错误不是连接错误
+	err = RunInTransaction(conn, func(tx TransactionInterface) error {
+		query, _ := Table("missing_table", connName)
+		_, err := query.InTransaction(tx).Get()
+		return err
+	})
+	if err == nil {
+		t.Fatal("Expected error for missing table")
+	}
+
+	tx := &badConnTransaction{conn: conn}
+	query, _ := Table("users", connName)
+	if _, err := query.InTransaction(tx).Where("id", "=", 1).Update(map[string]interface{}{"name": "x"}); err == nil {
+		t.Fatal("Expected error on broken transaction update")
+	}
+	query, _ = Table("users", connName)
+	if _, err := query.InTransaction(tx).Get(); err == nil {
+		t.Fatal("Expected error on broken transaction query")
+	}
+
+	want := []string{"connect:" + connName, "error:" + connName, "error:" + connName}
+	if !reflect.DeepEqual(listener.events, want) {
+		t.Errorf("Expected events %v, got %v", want, listener.events)
+	}
+
+	dbTx, err := NewTransaction(conn)
+	if err != nil {
+		t.Fatalf("NewTransaction failed: %v", err)
+	}
+	defer dbTx.Rollback()
+	if dbTx.connection() != conn {
+		t.Error("Expected transaction to keep its connection")
+	}
+}
+
+// 测试关闭管理器释放所有连接，配置保留
+func TestManagerClose(t *testing.T) {
+	m := NewManager()
+	if err := m.AddConfig("close_test", &Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConfig failed: %v", err)
+	}
+	conn, err := m.Connection("close_test")
+	if err != nil {
+		t.Fatalf("Connection failed: %v", err)
+	}
+
+	m.EnableHealthCheck(time.Hour)
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if m.healthCheckEnabled {
+		t.Error("Expected Close to stop the health check")
+	}
+	if conn.GetDB() != nil {
+		t.Error("Expected underlying *sql.DB to be closed")
+	}
+	if _, total := m.GetHealthyConnections(); total != 0 {
+		t.Errorf("Expected no connections after Close, got %d", total)
+	}
+
+	// 关闭后再次使用会重新建立连接
+	if _, err := m.Exec("close_test", "SELECT 1"); err != nil {
+		t.Errorf("Exec after Close failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
@@ -0,0 +1,636 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	ratType     = reflect.TypeOf(big.Rat{})
+)
+
+// LoadModel 将一行查询结果填充到结构体指针中
+// 列名与字段的对应规则与模型一致：torm标签的column/db > json标签 > db标签 > 字段名蛇形命名
+func LoadModel(data map[string]interface{}, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		return NewError(ErrCodeInvalidParameter, "目标必须是非空的结构体指针").
+			WithContext("type", fmt.Sprintf("%T", dest))
+	}
+
+	structValue := destValue.Elem()
+	if structValue.Kind() != reflect.Struct {
+		return NewError(ErrCodeInvalidParameter, "目标必须是非空的结构体指针").
+			WithContext("type", fmt.Sprintf("%T", dest))
+	}
+
+	return loadStruct(data, structValue)
+}
+
+// LoadModels 将多行查询结果填充到结构体切片指针中，支持 *[]T 和 *[]*T
+func LoadModels(rows []map[string]interface{}, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		return NewError(ErrCodeInvalidParameter, "目标必须是切片指针").
+			WithContext("type", fmt.Sprintf("%T", dest))
+	}
+
+	sliceValue := destValue.Elem()
+	elemType := sliceValue.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return NewError(ErrCodeInvalidParameter, "切片元素必须是结构体或结构体指针").
+			WithContext("type", fmt.Sprintf("%T", dest))
+	}
+
+	result := reflect.MakeSlice(sliceValue.Type(), 0, len(rows))
+	for i, row := range rows {
+		item := reflect.New(elemType)
+		if err := loadStruct(row, item.Elem()); err != nil {
+			return WrapError(err, ErrCodeInvalidParameter, "填充结构体失败").WithContext("row", i)
+		}
+		if isPtr {
+			result = reflect.Append(result, item)
+		} else {
+			result = reflect.Append(result, item.Elem())
+		}
+	}
+
+	sliceValue.Set(result)
+	return nil
+}
+
+// loadStruct 按列名填充结构体字段，包括匿名嵌入的结构体
+func loadStruct(data map[string]interface{}, structValue reflect.Value) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			if err := loadStruct(data, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() || field.Tag.Get("torm") == "-" {
+			continue
+		}
+
+		value, exists := data[columnNameFromField(field)]
+		if !exists {
+			continue
+		}
+
+		var err error
+		if cast := castFromField(field); cast != "" {
+			err = assignCastValue(fieldValue, value, cast)
+		} else {
+			err = assignValue(fieldValue, value)
+		}
+		if err != nil {
+			return NewErrorf(ErrCodeInvalidParameter, "字段 %s 赋值失败: %v", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// columnNameFromField 获取字段对应的列名
+func columnNameFromField(field reflect.StructField) string {
+	if tormTag := field.Tag.Get("torm"); tormTag != "" {
+		for _, part := range strings.Split(tormTag, ",") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "column:") {
+				return strings.TrimPrefix(part, "column:")
+			}
+			if strings.HasPrefix(part, "db:") {
+				return strings.TrimPrefix(part, "db:")
+			}
+		}
+	}
+
+	// db 标签专门指定列名，优先于 json 标签
+	if dbTag := field.Tag.Get("db"); dbTag != "" {
+		if name := strings.Split(dbTag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+		if name := strings.Split(jsonTag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return camelToSnake(field.Name)
+}
+
+// castFromField 获取字段torm标签中的cast类型，如 torm:"cast:json"
+func castFromField(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("torm"), ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "cast:") {
+			return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(part, "cast:")))
+		}
+	}
+	return ""
+}
+
+// assignCastValue 按显式cast类型转换数据库值后赋给字段，支持指针字段
+func assignCastValue(field reflect.Value, value interface{}, cast string) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if bytes, ok := value.([]byte); ok {
+		value = string(bytes)
+	}
+
+	switch cast {
+	case "json":
+		var raw []byte
+		if s, ok := value.(string); ok {
+			if strings.TrimSpace(s) == "" {
+				field.Set(reflect.Zero(field.Type()))
+				return nil
+			}
+			raw = []byte(s)
+		} else {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			raw = encoded
+		}
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal(raw, target.Interface()); err != nil {
+			return err
+		}
+		field.Set(target.Elem())
+		return nil
+	case "int", "integer":
+		i, err := castToInt(value)
+		if err != nil {
+			return err
+		}
+		return assignValue(field, i)
+	case "float", "double":
+		f, err := castToFloat(value)
+		if err != nil {
+			return err
+		}
+		return assignValue(field, f)
+	case "bool", "boolean":
+		b, err := castToBool(value)
+		if err != nil {
+			return err
+		}
+		return assignValue(field, b)
+	case "time", "datetime":
+		parsed := NewTimeFieldManager().ParseTimeValue(value, timeType)
+		t, ok := parsed.(time.Time)
+		if !ok {
+			return fmt.Errorf("无法将 %v 转换为 time.Time", value)
+		}
+		return assignValue(field, t)
+	case "string":
+		return assignValue(field, fmt.Sprint(value))
+	case "decimal":
+		return assignDecimalValue(field, value)
+	}
+	return fmt.Errorf("不支持的cast类型: %s", cast)
+}
+
+// assignDecimalValue 将定点数赋值给 string、big.Rat、*big.Rat 或浮点字段
+// 字符串字段保留数据库返回的原始精度，如 "19.90"
+func assignDecimalValue(field reflect.Value, value interface{}) error {
+	var str string
+	rat, ok := value.(*big.Rat)
+	if ok {
+		str = strings.TrimRight(strings.TrimRight(rat.FloatString(18), "0"), ".")
+	} else {
+		converted, ok := convertDecimalValue(value, DecimalAsString)
+		if !ok {
+			return fmt.Errorf("无法将 %T 转换为decimal", value)
+		}
+		str = converted.(string)
+		if rat, ok = new(big.Rat).SetString(str); !ok {
+			return fmt.Errorf("无法将 %q 转换为decimal", str)
+		}
+	}
+
+	switch {
+	case field.Type() == ratType:
+		field.Set(reflect.ValueOf(*rat))
+		return nil
+	case field.Type() == reflect.PtrTo(ratType):
+		field.Set(reflect.ValueOf(rat))
+		return nil
+	case field.Kind() == reflect.String:
+		field.SetString(str)
+		return nil
+	}
+	f, _ := rat.Float64()
+	return assignValue(field, f)
+}
+
+// castToInt 将值转换为int64
+func castToInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 转换为整数", v)
+		}
+		return int64(f), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	source := reflect.ValueOf(value)
+	if !isNumericKind(source.Kind()) {
+		return 0, fmt.Errorf("无法将 %T 转换为整数", value)
+	}
+	return source.Convert(reflect.TypeOf(int64(0))).Int(), nil
+}
+
+// castToFloat 将值转换为float64
+func castToFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("无法将 %q 转换为浮点数", v)
+		}
+		return f, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	source := reflect.ValueOf(value)
+	if !isNumericKind(source.Kind()) {
+		return 0, fmt.Errorf("无法将 %T 转换为浮点数", value)
+	}
+	return source.Convert(reflect.TypeOf(float64(0))).Float(), nil
+}
+
+// castToBool 将值转换为bool，支持 1/0、true/false、yes/no、on/off
+func castToBool(value interface{}) (bool, error) {
+	if v, ok := value.(bool); ok {
+		return v, nil
+	}
+	if v, ok := value.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "t", "true", "y", "yes", "on":
+			return true, nil
+		case "0", "f", "false", "n", "no", "off", "":
+			return false, nil
+		}
+		return false, fmt.Errorf("无法将 %q 转换为bool", v)
+	}
+	f, err := castToFloat(value)
+	if err != nil {
+		return false, fmt.Errorf("无法将 %T 转换为bool", value)
+	}
+	return f != 0, nil
+}
+
+// assignValue 将数据库值转换并赋给字段
+func assignValue(field reflect.Value, value interface{}) error {
+	// 实现sql.Scanner的类型（如sql.NullString）交给类型自身处理，
+	// 驱动返回的类型无法直接扫描时（如SQLite以字符串返回的时间扫描到sql.NullTime）按值字段转换
+	if field.CanAddr() && field.Addr().Type().Implements(scannerType) {
+		err := field.Addr().Interface().(sql.Scanner).Scan(value)
+		if err != nil && value != nil && assignNullStruct(field, value) == nil {
+			return nil
+		}
+		return err
+	}
+
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if bytes, ok := value.([]byte); ok && field.Kind() != reflect.Slice {
+		value = string(bytes)
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(field.Type()) {
+		field.Set(source)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		parsed := NewTimeFieldManager().ParseTimeValue(value, timeType)
+		if t, ok := parsed.(time.Time); ok {
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return fmt.Errorf("无法将 %T 转换为 time.Time", value)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if t, ok := value.(time.Time); ok {
+			field.SetString(t.Format("2006-01-02 15:04:05"))
+		} else {
+			field.SetString(fmt.Sprint(value))
+		}
+		return nil
+	case reflect.Bool:
+		switch v := value.(type) {
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			if !isNumericKind(source.Kind()) {
+				return fmt.Errorf("无法将 %T 转换为 bool", value)
+			}
+			field.SetBool(source.Convert(reflect.TypeOf(float64(0))).Float() != 0)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case string:
+			v = strings.TrimSpace(v)
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				source = reflect.ValueOf(i)
+				break
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			source = reflect.ValueOf(f)
+		case bool:
+			if v {
+				source = reflect.ValueOf(1)
+			} else {
+				source = reflect.ValueOf(0)
+			}
+		case time.Time:
+			source = reflect.ValueOf(v.Unix())
+		}
+		if !isNumericKind(source.Kind()) {
+			return fmt.Errorf("无法将 %T 转换为 %s", value, field.Type())
+		}
+		if numericOverflows(source, field) {
+			return fmt.Errorf("值 %v 超出 %s 的范围", source.Interface(), field.Type())
+		}
+		field.Set(source.Convert(field.Type()))
+		return nil
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		// JSON列：字符串或已解析的JSON值
+		var raw []byte
+		if s, ok := value.(string); ok {
+			raw = []byte(s)
+		} else if b, ok := value.([]byte); ok {
+			raw = b
+		} else {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			raw = encoded
+		}
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal(raw, target.Interface()); err != nil {
+			return err
+		}
+		field.Set(target.Elem())
+		return nil
+	}
+
+	if source.Type().ConvertibleTo(field.Type()) {
+		field.Set(source.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("无法将 %T 转换为 %s", value, field.Type())
+}
+
+// assignNullStruct 按 sql.Null* 的结构（一个值字段和 Valid 字段）赋值，
+// 用于Scan无法处理的驱动值类型，值字段按 assignValue 的规则转换
+func assignNullStruct(field reflect.Value, value interface{}) error {
+	if field.Kind() != reflect.Struct || field.NumField() != 2 {
+		return fmt.Errorf("%s 不是 sql.Null* 类型", field.Type())
+	}
+	valid := field.FieldByName("Valid")
+	if !valid.IsValid() || valid.Kind() != reflect.Bool || field.Type().Field(1).Name != "Valid" {
+		return fmt.Errorf("%s 不是 sql.Null* 类型", field.Type())
+	}
+
+	target := reflect.New(field.Type()).Elem()
+	if err := assignValue(target.Field(0), value); err != nil {
+		return err
+	}
+	target.Field(1).SetBool(true)
+	field.Set(target)
+	return nil
+}
+
+// numericOverflows 数值转换为字段类型时是否溢出（包括负数赋给无符号类型）
+func numericOverflows(source, field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch source.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return source.Uint() > math.MaxInt64 || field.OverflowInt(int64(source.Uint()))
+		case reflect.Float32, reflect.Float64:
+			f := source.Float()
+			return f < math.MinInt64 || f >= math.MaxInt64 || field.OverflowInt(int64(f))
+		}
+		return field.OverflowInt(source.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return source.Int() < 0 || field.OverflowUint(uint64(source.Int()))
+		case reflect.Float32, reflect.Float64:
+			f := source.Float()
+			return f < 0 || f >= math.MaxUint64 || field.OverflowUint(uint64(f))
+		}
+		return field.OverflowUint(source.Uint())
+	case reflect.Float32:
+		switch source.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return field.OverflowFloat(source.Float())
+		}
+	}
+	return false
+}
+
+// isNumericKind 是否为数字类型
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// modelStructValue 获取模型的结构体值，要求为非空结构体指针或结构体
+func modelStructValue(model interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(model)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, NewError(ErrCodeInvalidParameter, "模型不能为空指针")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, NewError(ErrCodeInvalidParameter, "模型必须是结构体或结构体指针").
+			WithContext("type", fmt.Sprintf("%T", model))
+	}
+	return value, nil
+}
+
+// modelToMap 将模型结构体转换为列名到值的映射
+// 跳过嵌入的BaseModel、torm:"-" 字段和零值字段，结构体/切片/map字段序列化为JSON
+func modelToMap(structValue reflect.Value) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	if err := collectModelFields(structValue, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// collectModelFields 收集结构体字段值，包括匿名嵌入的结构体
+func collectModelFields(structValue reflect.Value, data map[string]interface{}) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			if field.Type.Name() == "BaseModel" {
+				continue
+			}
+			if err := collectModelFields(fieldValue, data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() || field.Tag.Get("torm") == "-" || fieldValue.IsZero() {
+			continue
+		}
+
+		value, err := fieldToColumnValue(fieldValue)
+		if err != nil {
+			return NewErrorf(ErrCodeInvalidParameter, "字段 %s 转换失败: %v", field.Name, err)
+		}
+		data[columnNameFromField(field)] = value
+	}
+
+	return nil
+}
+
+// fieldToColumnValue 将字段值转换为可写入数据库的值
+func fieldToColumnValue(fieldValue reflect.Value) (interface{}, error) {
+	if fieldValue.Type().Implements(valuerType) {
+		return fieldValue.Interface(), nil
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		fieldValue = fieldValue.Elem()
+	}
+	if fieldValue.Type() == timeType {
+		return fieldValue.Interface(), nil
+	}
+
+	if fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.Uint8 {
+		return fieldValue.Bytes(), nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		encoded, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	}
+	return fieldValue.Interface(), nil
+}
+
+// modelPrimaryKeys 获取模型结构体中标记为主键的列，未标记时默认为 id
+func modelPrimaryKeys(structType reflect.Type) []string {
+	var keys []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Type.Name() != "BaseModel" {
+			keys = append(keys, modelPrimaryKeys(field.Type)...)
+			continue
+		}
+		for _, part := range strings.Split(field.Tag.Get("torm"), ",") {
+			switch strings.ToLower(strings.TrimSpace(part)) {
+			case "primary_key", "pk", "primary", "primarykey":
+				keys = append(keys, columnNameFromField(field))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return []string{"id"}
+	}
+	return keys
+}
+
+// setModelPrimaryKey 插入成功后将自增ID回写到模型的主键字段
+func setModelPrimaryKey(structValue reflect.Value, column string, id int64) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Type.Name() != "BaseModel" {
+			setModelPrimaryKey(fieldValue, column, id)
+			continue
+		}
+		if !field.IsExported() || columnNameFromField(field) != column || !fieldValue.CanSet() || !fieldValue.IsZero() {
+			continue
+		}
+		_ = assignValue(fieldValue, id)
+		return
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"time"
 
@@ -20,7 +21,7 @@ type MongoQueryBuilder struct {
 	// MongoDB特有的查询条件
 	filter     bson.M
 	projection bson.M
-	sort       bson.M
+	sort       bson.D // 有序，保证多字段排序的先后顺序
 	skip       int64
 	limit      int64
 
@@ -34,6 +35,9 @@ type MongoQueryBuilder struct {
 	timeManager *TimeFieldManager
 	timeFields  []TimeFieldInfo
 
+	// 构建过程中记录的错误，在执行时返回，见 Err
+	err error
+
 	// 上下文
 	ctx context.Context
 }
@@ -44,7 +48,7 @@ func NewMongoQueryBuilder(conn *MongoConnection) *MongoQueryBuilder {
 		connection:  conn,
 		filter:      bson.M{},
 		projection:  bson.M{},
-		sort:        bson.M{},
+		sort:        bson.D{},
 		timeManager: NewTimeFieldManager(),
 		timeFields:  make([]TimeFieldInfo, 0),
 		ctx:         context.Background(),
@@ -71,14 +75,49 @@ func (m *MongoQueryBuilder) GetModel() interface{} {
 	return m.model
 }
 
-// Where 添加查询条件
-func (m *MongoQueryBuilder) Where(field string, value interface{}) *MongoQueryBuilder {
-	m.filter[field] = m.parseValue(field, value)
+// Where 添加查询条件，与SQL查询构建器的调用方式保持一致
+// Where(bson.M{...})、Where("status", "active")、Where("age", ">", 18)
+func (m *MongoQueryBuilder) Where(args ...interface{}) *MongoQueryBuilder {
+	switch len(args) {
+	case 1:
+		if condition, ok := args[0].(bson.M); ok {
+			return m.AndWhere(condition)
+		}
+	case 2:
+		if field, ok := args[0].(string); ok {
+			m.filter[field] = m.parseValue(field, args[1])
+		}
+	case 3:
+		if field, ok := args[0].(string); ok {
+			if operator, ok := args[1].(string); ok {
+				return m.WhereOp(field, operator, args[2])
+			}
+		}
+	}
 	return m
 }
 
 // WhereOp 添加操作符查询条件
+// 同一字段的多个操作符会合并，例如 age > 18 且 age < 30
 func (m *MongoQueryBuilder) WhereOp(field string, operator string, value interface{}) *MongoQueryBuilder {
+	switch strings.ToLower(strings.TrimSpace(operator)) {
+	case "between", "not between":
+		values := mongoValues(value)
+		if len(values) != 2 {
+			m.setErr(NewErrorf(ErrCodeInvalidParameter, "%s 需要恰好两个值，实际为 %d 个", strings.ToUpper(strings.TrimSpace(operator)), len(values)).
+				WithContext("field", field))
+			return m
+		}
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(operator)), "not") {
+			return m.WhereNotBetween(field, values[0], values[1])
+		}
+		return m.WhereBetween(field, values[0], values[1])
+	case "in":
+		return m.WhereIn(field, mongoValues(value))
+	case "not in", "nin":
+		return m.WhereNotIn(field, mongoValues(value))
+	}
+
 	mongoOp := m.parseOperator(operator)
 	if mongoOp == "" {
 		// 如果操作符无效，使用等于
@@ -86,17 +125,43 @@ func (m *MongoQueryBuilder) WhereOp(field string, operator string, value interfa
 		return m
 	}
 
-	m.filter[field] = bson.M{mongoOp: m.parseValue(field, value)}
+	m.mergeOperator(field, mongoOp, m.parseValue(field, value))
 	return m
 }
 
+// mergeOperator 向字段条件追加操作符，已有操作符条件时合并而不是覆盖
+// 合并时复制操作符文档，克隆出的构建器与原构建器共享的文档不会被修改
+func (m *MongoQueryBuilder) mergeOperator(field, mongoOp string, value interface{}) {
+	merged := bson.M{mongoOp: value}
+	if existing, ok := m.filter[field].(bson.M); ok && isOperatorDocument(existing) {
+		for op, v := range existing {
+			if op != mongoOp {
+				merged[op] = v
+			}
+		}
+	}
+	m.filter[field] = merged
+}
+
+// Err 返回构建查询时记录的错误，存在错误时执行方法直接返回该错误
+func (m *MongoQueryBuilder) Err() error {
+	return m.err
+}
+
+// setErr 记录构建查询时的错误，只保留第一个错误
+func (m *MongoQueryBuilder) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
 // WhereIn 添加IN查询条件
 func (m *MongoQueryBuilder) WhereIn(field string, values []interface{}) *MongoQueryBuilder {
 	parsedValues := make([]interface{}, len(values))
 	for i, v := range values {
 		parsedValues[i] = m.parseValue(field, v)
 	}
-	m.filter[field] = bson.M{"$in": parsedValues}
+	m.mergeOperator(field, "$in", parsedValues)
 	return m
 }
 
@@ -106,19 +171,25 @@ func (m *MongoQueryBuilder) WhereNotIn(field string, values []interface{}) *Mong
 	for i, v := range values {
 		parsedValues[i] = m.parseValue(field, v)
 	}
-	m.filter[field] = bson.M{"$nin": parsedValues}
+	m.mergeOperator(field, "$nin", parsedValues)
 	return m
 }
 
 // WhereBetween 添加BETWEEN查询条件
 func (m *MongoQueryBuilder) WhereBetween(field string, min, max interface{}) *MongoQueryBuilder {
-	m.filter[field] = bson.M{
-		"$gte": m.parseValue(field, min),
-		"$lte": m.parseValue(field, max),
-	}
+	m.mergeOperator(field, "$gte", m.parseValue(field, min))
+	m.mergeOperator(field, "$lte", m.parseValue(field, max))
 	return m
 }
 
+// WhereNotBetween 添加NOT BETWEEN查询条件
+func (m *MongoQueryBuilder) WhereNotBetween(field string, min, max interface{}) *MongoQueryBuilder {
+	return m.AndWhere(bson.M{"$or": []bson.M{
+		{field: bson.M{"$lt": m.parseValue(field, min)}},
+		{field: bson.M{"$gt": m.parseValue(field, max)}},
+	}})
+}
+
 // WhereRegex 添加正则表达式查询条件
 func (m *MongoQueryBuilder) WhereRegex(field string, pattern string, options string) *MongoQueryBuilder {
 	regex := primitive.Regex{Pattern: pattern, Options: options}
@@ -210,7 +281,13 @@ func (m *MongoQueryBuilder) OrderBy(field string, direction string) *MongoQueryB
 	if strings.ToLower(direction) == "desc" {
 		order = -1
 	}
-	m.sort[field] = order
+	for i := range m.sort {
+		if m.sort[i].Key == field {
+			m.sort[i].Value = order
+			return m
+		}
+	}
+	m.sort = append(m.sort, bson.E{Key: field, Value: order})
 	return m
 }
 
@@ -230,6 +307,11 @@ func (m *MongoQueryBuilder) Skip(n int64) *MongoQueryBuilder {
 	return m
 }
 
+// Offset 设置跳过数量，与SQL查询构建器保持一致
+func (m *MongoQueryBuilder) Offset(n int64) *MongoQueryBuilder {
+	return m.Skip(n)
+}
+
 // Limit 设置限制数量
 func (m *MongoQueryBuilder) Limit(n int64) *MongoQueryBuilder {
 	m.limit = n
@@ -255,8 +337,16 @@ func (m *MongoQueryBuilder) WithSession(session mongo.Session) *MongoQueryBuilde
 	return m
 }
 
+// Get 查找多个文档，与SQL查询构建器保持一致
+func (m *MongoQueryBuilder) Get() ([]map[string]interface{}, error) {
+	return m.Find()
+}
+
 // Find 查找多个文档
 func (m *MongoQueryBuilder) Find() ([]map[string]interface{}, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	if m.collectionName == "" {
 		return nil, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -266,24 +356,7 @@ func (m *MongoQueryBuilder) Find() ([]map[string]interface{}, error) {
 		return nil, NewError(ErrCodeConnectionClosed, "MongoDB连接未建立")
 	}
 
-	// 构建查询选项
-	opts := options.Find()
-
-	if len(m.projection) > 0 {
-		opts.SetProjection(m.projection)
-	}
-
-	if len(m.sort) > 0 {
-		opts.SetSort(m.sort)
-	}
-
-	if m.skip > 0 {
-		opts.SetSkip(m.skip)
-	}
-
-	if m.limit > 0 {
-		opts.SetLimit(m.limit)
-	}
+	opts := m.FindOptions()
 
 	// 执行查询
 	var cursor *mongo.Cursor
@@ -316,6 +389,9 @@ func (m *MongoQueryBuilder) Find() ([]map[string]interface{}, error) {
 
 // First 查找第一个文档
 func (m *MongoQueryBuilder) First() (map[string]interface{}, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	if m.collectionName == "" {
 		return nil, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -352,7 +428,7 @@ func (m *MongoQueryBuilder) First() (map[string]interface{}, error) {
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, ErrRecordNotFound.WithContext("collection", m.collectionName)
+			return nil, newNotFoundError("collection", m.collectionName)
 		}
 		return nil, WrapError(err, ErrCodeQueryFailed, "MongoDB查询失败").
 			WithContext("collection", m.collectionName).
@@ -370,6 +446,9 @@ func (m *MongoQueryBuilder) First() (map[string]interface{}, error) {
 
 // Count 计算文档数量
 func (m *MongoQueryBuilder) Count() (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
 	if m.collectionName == "" {
 		return 0, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -400,6 +479,9 @@ func (m *MongoQueryBuilder) Count() (int64, error) {
 
 // Insert 插入文档
 func (m *MongoQueryBuilder) Insert(data map[string]interface{}) (interface{}, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	if m.collectionName == "" {
 		return nil, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -439,6 +521,9 @@ func (m *MongoQueryBuilder) Insert(data map[string]interface{}) (interface{}, er
 
 // InsertMany 插入多个文档
 func (m *MongoQueryBuilder) InsertMany(documents []interface{}) ([]interface{}, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	if m.collectionName == "" {
 		return nil, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -482,6 +567,9 @@ func (m *MongoQueryBuilder) InsertMany(documents []interface{}) ([]interface{},
 
 // Update 更新文档
 func (m *MongoQueryBuilder) Update(data map[string]interface{}) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
 	if m.collectionName == "" {
 		return 0, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -525,6 +613,9 @@ func (m *MongoQueryBuilder) Update(data map[string]interface{}) (int64, error) {
 
 // Delete 删除文档
 func (m *MongoQueryBuilder) Delete() (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
 	if m.collectionName == "" {
 		return 0, NewError(ErrCodeInvalidParameter, "集合名称不能为空")
 	}
@@ -623,6 +714,29 @@ func (m *MongoQueryBuilder) ToFilter() bson.M {
 	return m.filter
 }
 
+// FindOptions 根据投影、排序和分页设置构建查询选项
+func (m *MongoQueryBuilder) FindOptions() *options.FindOptions {
+	opts := options.Find()
+
+	if len(m.projection) > 0 {
+		opts.SetProjection(m.projection)
+	}
+
+	if len(m.sort) > 0 {
+		opts.SetSort(m.sort)
+	}
+
+	if m.skip > 0 {
+		opts.SetSkip(m.skip)
+	}
+
+	if m.limit > 0 {
+		opts.SetLimit(m.limit)
+	}
+
+	return opts
+}
+
 // Clone 克隆查询构建器
 func (m *MongoQueryBuilder) Clone() *MongoQueryBuilder {
 	clone := &MongoQueryBuilder{
@@ -631,15 +745,23 @@ func (m *MongoQueryBuilder) Clone() *MongoQueryBuilder {
 		model:          m.model,
 		filter:         bson.M{},
 		projection:     bson.M{},
-		sort:           bson.M{},
+		sort:           make(bson.D, len(m.sort)),
 		skip:           m.skip,
 		limit:          m.limit,
 		session:        m.session,
+		err:            m.err,
 		ctx:            m.ctx,
 	}
 
-	// 深拷贝过滤条件
+	// 深拷贝过滤条件，操作符文档单独复制
 	for k, v := range m.filter {
+		if doc, ok := v.(bson.M); ok && isOperatorDocument(doc) {
+			copied := make(bson.M, len(doc))
+			for op, opValue := range doc {
+				copied[op] = opValue
+			}
+			v = copied
+		}
 		clone.filter[k] = v
 	}
 
@@ -649,9 +771,36 @@ func (m *MongoQueryBuilder) Clone() *MongoQueryBuilder {
 	}
 
 	// 深拷贝排序
-	for k, v := range m.sort {
-		clone.sort[k] = v
-	}
+	copy(clone.sort, m.sort)
 
 	return clone
 }
+
+// isOperatorDocument 判断条件是否为操作符文档，如 {"$gt": 1}
+func isOperatorDocument(doc bson.M) bool {
+	if len(doc) == 0 {
+		return false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// mongoValues 将切片或数组转换为 []interface{}，单个值视为只有一个元素
+func mongoValues(value interface{}) []interface{} {
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 测试MongoDB构建器与SQL构建器一致的链式调用
+func TestMongoQueryBuilderParity(t *testing.T) {
+	m := NewMongoQueryBuilder(nil).Collection("users").
+		Where("status", "active").
+		Where("age", ">", 18).
+		Where("age", "<=", 60).
+		Where("role", "IN", []string{"admin", "editor"}).
+		Where("score", "BETWEEN", []int{10, 20}).
+		OrderBy("age", "desc").
+		OrderBy("name", "asc").
+		Limit(10).
+		Offset(20)
+
+	expected := bson.M{
+		"status": "active",
+		"age":    bson.M{"$gt": 18, "$lte": 60},
+		"role":   bson.M{"$in": []interface{}{"admin", "editor"}},
+		"score":  bson.M{"$gte": 10, "$lte": 20},
+	}
+	if !reflect.DeepEqual(m.ToFilter(), expected) {
+		t.Errorf("Unexpected filter: %v", m.ToFilter())
+	}
+
+	opts := m.FindOptions()
+	if *opts.Limit != 10 || *opts.Skip != 20 {
+		t.Errorf("Unexpected limit/skip: %d/%d", *opts.Limit, *opts.Skip)
+	}
+	sort, ok := opts.Sort.(bson.D)
+	if !ok || len(sort) != 2 || sort[0].Key != "age" || sort[0].Value != -1 || sort[1].Key != "name" {
+		t.Errorf("Unexpected sort: %v", opts.Sort)
+	}
+
+	clone := m.Clone().OrderBy("id", "asc")
+	if len(m.sort) != 2 || len(clone.sort) != 3 {
+		t.Errorf("Clone should not share sort fields")
+	}
+
+	// 克隆后追加同一字段的操作符不影响原构建器
+	m.Clone().Where("age", "!=", 30)
+	if !reflect.DeepEqual(m.ToFilter()["age"], bson.M{"$gt": 18, "$lte": 60}) {
+		t.Errorf("Clone should not share operator documents, got %v", m.ToFilter()["age"])
+	}
+
+	// BETWEEN 的值数量错误时记录错误并在执行时返回
+	invalid := NewMongoQueryBuilder(nil).Collection("users").Where("score", "BETWEEN", []int{10})
+	if invalid.Err() == nil {
+		t.Errorf("Expected error for BETWEEN with one value")
+	}
+	if _, err := invalid.Count(); err != invalid.Err() {
+		t.Errorf("Expected Count to return the recorded error, got %v", err)
+	}
+}
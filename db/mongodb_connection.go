@@ -141,11 +141,11 @@ func (m *MongoConnection) Exec(query string, args ...interface{}) (sql.Result, e
 
 // Begin 开始事务（适配SQL接口）
 func (m *MongoConnection) Begin() (TransactionInterface, error) {
-	return m.BeginTx(nil)
+	return m.BeginTx(context.Background(), nil)
 }
 
-// BeginTx 开始事务
-func (m *MongoConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, error) {
+// BeginTx 开始事务，MongoDB 不支持 SQL 隔离级别，opts 被忽略
+func (m *MongoConnection) BeginTx(ctx context.Context, opts *TxOptions) (TransactionInterface, error) {
 	if !m.connected {
 		return nil, NewError(ErrCodeConnectionClosed, "MongoDB连接未建立")
 	}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
@@ -69,6 +70,7 @@ func (c *MySQLConnection) Close() error {
 		return nil
 	}
 
+	releaseStmtCache(c.db)
 	err := c.db.Close()
 	c.db = nil
 	c.connected = false
@@ -113,7 +115,7 @@ func (c *MySQLConnection) Query(query string, args ...interface{}) (*sql.Rows, e
 	}
 
 	start := time.Now()
-	rows, err := c.db.Query(query, args...)
+	rows, err := queryDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -168,7 +170,7 @@ func (c *MySQLConnection) Exec(query string, args ...interface{}) (sql.Result, e
 	}
 
 	start := time.Now()
-	result, err := c.db.Exec(query, args...)
+	result, err := execDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -196,11 +198,11 @@ func (c *MySQLConnection) Exec(query string, args ...interface{}) (sql.Result, e
 
 // Begin 开始事务
 func (c *MySQLConnection) Begin() (TransactionInterface, error) {
-	return c.BeginTx(nil)
+	return c.BeginTx(context.Background(), nil)
 }
 
-// BeginTx 开始事务（带选项）
-func (c *MySQLConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, error) {
+// BeginTx 开始事务（带选项），opts 为空时使用连接配置的默认隔离级别和只读模式
+func (c *MySQLConnection) BeginTx(ctx context.Context, opts *TxOptions) (TransactionInterface, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -208,7 +210,7 @@ func (c *MySQLConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, er
 		return nil, fmt.Errorf("connection not established")
 	}
 
-	tx, err := c.db.Begin()
+	tx, err := c.db.BeginTx(txContext(ctx), c.config.txOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -217,6 +219,7 @@ func (c *MySQLConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, er
 		tx:     tx,
 		logger: c.logger,
 		config: c.config,
+		conn:   c,
 	}, nil
 }
 
@@ -247,6 +250,7 @@ type MySQLTransaction struct {
 	tx     *sql.Tx
 	logger LoggerInterface
 	config *Config
+	conn   ConnectionInterface
 }
 
 // Query 在事务中执行查询
@@ -296,6 +300,11 @@ func (t *MySQLTransaction) Exec(query string, args ...interface{}) (sql.Result,
 	return result, err
 }
 
+// connection 返回开启事务的连接
+func (t *MySQLTransaction) connection() ConnectionInterface {
+	return t.conn
+}
+
 // Commit 提交事务
 func (t *MySQLTransaction) Commit() error {
 	err := t.tx.Commit()
@@ -0,0 +1,84 @@
+package db
+
+import "strings"
+
+// bindNamed 将原生SQL中的 :name 命名参数改写为 ? 占位符，并按出现顺序返回参数值
+// 切片参数展开为多个占位符，便于 IN (:ids)；字符串字面量、引号标识符和 PostgreSQL 的 :: 类型转换不做处理
+// 占位符最终由 processPlaceholders 转换为对应数据库的格式
+func (qb *QueryBuilder) bindNamed(sql string, params map[string]interface{}) (string, []interface{}, error) {
+	var result strings.Builder
+	var values []interface{}
+	var quote rune
+
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+
+		if quote != 0 {
+			result.WriteRune(ch)
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+			result.WriteRune(ch)
+		case ch == ':' && i+1 < len(runes) && isNameStart(runes[i+1]) && (i == 0 || runes[i-1] != ':'):
+			end := i + 1
+			for end < len(runes) && isNamePart(runes[end]) {
+				end++
+			}
+			name := string(runes[i+1 : end])
+			value, ok := params[name]
+			if !ok {
+				return "", nil, NewErrorf(ErrCodeInvalidParameter, "缺少命名参数 :%s", name).
+					WithContext("sql", sql)
+			}
+
+			if _, isBytes := value.([]byte); !isBytes && qb.isSliceOrArray(value) {
+				items := qb.convertToInterfaceSlice(value)
+				if len(items) == 0 {
+					return "", nil, NewErrorf(ErrCodeInvalidParameter, "命名参数 :%s 为空切片", name).
+						WithContext("sql", sql)
+				}
+				result.WriteString(strings.TrimSuffix(strings.Repeat("?, ", len(items)), ", "))
+				values = append(values, items...)
+			} else {
+				result.WriteRune('?')
+				values = append(values, value)
+			}
+			i = end - 1
+		default:
+			result.WriteRune(ch)
+		}
+	}
+
+	return result.String(), values, nil
+}
+
+// rawCondition 构建原生条件，绑定参数为单个 map[string]interface{} 时按命名参数处理
+// 命名参数解析失败时记录构建错误，执行方法直接返回该错误，不会静默放宽条件
+func (qb *QueryBuilder) rawCondition(sql string, bindings []interface{}, logic string) WhereCondition {
+	if len(bindings) == 1 {
+		if params, ok := bindings[0].(map[string]interface{}); ok {
+			bound, values, err := qb.bindNamed(sql, params)
+			if err != nil {
+				qb.setErr(err)
+				return WhereCondition{Raw: sql, Logic: logic}
+			}
+			return WhereCondition{Raw: bound, Values: values, Logic: logic}
+		}
+	}
+	return WhereCondition{Raw: sql, Values: bindings, Logic: logic}
+}
+
+func isNameStart(ch rune) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNamePart(ch rune) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
@@ -1,7 +1,11 @@
 package db
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"math"
+	"strings"
 )
 
 // PaginationResult 分页结果（向下兼容）
@@ -16,9 +20,10 @@ func (qb *QueryBuilder) Paginate(page, perPage int) (*SimplePagination, error) {
 		perPage = 15
 	}
 
-	// 获取总数（创建一个新的查询构建器副本用于计数）
-	countBuilder := *qb
+	// 获取总数（克隆查询构建器用于计数，克隆不共享执行标记和超时的取消函数）
+	countBuilder := qb.Clone()
 	countBuilder.selectColumns = []string{}
+	countBuilder.selectRaw = nil
 	countBuilder.orderByColumns = []OrderByClause{}
 	countBuilder.limitCount = 0
 	countBuilder.offsetCount = 0
@@ -55,6 +60,48 @@ func (qb *QueryBuilder) Paginate(page, perPage int) (*SimplePagination, error) {
 	}, nil
 }
 
+// Paginator 结构化分页结果
+type Paginator struct {
+	Data        []map[string]interface{} `json:"data"`
+	Total       int64                    `json:"total"`
+	PerPage     int                      `json:"per_page"`
+	CurrentPage int                      `json:"current_page"`
+	LastPage    int                      `json:"last_page"`
+	HasMore     bool                     `json:"has_more"`
+	HasPrev     bool                     `json:"has_prev"`
+}
+
+// PaginateStruct 分页查询并返回结构化分页结果
+func (qb *QueryBuilder) PaginateStruct(page, perPage int) (*Paginator, error) {
+	result, err := qb.Paginate(page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Paginator{
+		Data:        result.Data,
+		Total:       result.Total,
+		PerPage:     result.PerPage,
+		CurrentPage: result.CurrentPage,
+		LastPage:    result.LastPage,
+		HasMore:     result.CurrentPage < result.LastPage,
+		HasPrev:     result.CurrentPage > 1,
+	}, nil
+}
+
+// PaginateInto 分页查询并将当前页数据填充到 dest（*[]T 或 *[]*T）
+func (qb *QueryBuilder) PaginateInto(dest interface{}, page, perPage int) (*Paginator, error) {
+	paginator, err := qb.PaginateStruct(page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := LoadModels(paginator.Data, dest); err != nil {
+		return nil, err
+	}
+	return paginator, nil
+}
+
 // SimplePaginate 简单分页（不计算总数，适用于大数据集）
 func (qb *QueryBuilder) SimplePaginate(page, perPage int) ([]map[string]interface{}, bool, error) {
 	if page < 1 {
@@ -80,3 +127,111 @@ func (qb *QueryBuilder) SimplePaginate(page, perPage int) ([]map[string]interfac
 
 	return data, hasMore, nil
 }
+
+// CursorPage 游标分页结果
+type CursorPage struct {
+	Data       []map[string]interface{} `json:"data"`
+	PerPage    int                      `json:"per_page"`
+	NextCursor string                   `json:"next_cursor"`
+	HasMore    bool                     `json:"has_more"`
+}
+
+// CursorPaginate 游标（keyset）分页，按 column 升序读取 cursor 之后的 perPage 条记录
+// cursor 为空表示第一页，返回结果中的 NextCursor 用于获取下一页
+func (qb *QueryBuilder) CursorPaginate(column string, perPage int, cursor string) (CursorPage, error) {
+	if err := qb.validateColumnName(column); err != nil {
+		return CursorPage{}, err
+	}
+	if perPage < 1 {
+		perPage = 15
+	}
+
+	query := qb.Clone()
+	query.orderByColumns = []OrderByClause{{Column: column, Direction: "ASC"}}
+	query.offsetCount = 0
+
+	// 结果中的键不带表名前缀
+	key := column[strings.LastIndex(column, ".")+1:]
+
+	// 指定了查询列时补充游标列，否则无法生成下一页的游标
+	if (len(query.selectColumns) > 0 || len(query.selectRaw) > 0) && !selectsColumn(query.selectColumns, column, key) {
+		query.selectColumns = append(query.selectColumns, column)
+	}
+
+	if cursor != "" {
+		value, err := decodeCursor(cursor)
+		if err != nil {
+			return CursorPage{}, WrapError(err, ErrCodeInvalidParameter, "无效的分页游标")
+		}
+		query.Where(column, ">", value)
+	}
+
+	// 多查询一条记录来判断是否还有更多数据
+	data, err := query.Limit(perPage + 1).Get()
+	if err != nil {
+		return CursorPage{}, err
+	}
+
+	page := CursorPage{Data: data, PerPage: perPage}
+	if len(data) > perPage {
+		page.Data = data[:perPage]
+		page.HasMore = true
+
+		value, exists := page.Data[perPage-1][key]
+		if !exists {
+			return CursorPage{}, NewErrorf(ErrCodeInvalidParameter, "查询结果中缺少游标列: %s", key)
+		}
+		page.NextCursor, err = encodeCursor(value)
+		if err != nil {
+			return CursorPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// selectsColumn 判断查询列中是否已包含指定列（含 * 和 table.*）
+func selectsColumn(columns []string, column, key string) bool {
+	for _, selected := range columns {
+		selected = strings.TrimSpace(selected)
+		if selected == "*" || strings.HasSuffix(selected, ".*") ||
+			strings.EqualFold(selected, column) || strings.EqualFold(selected, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor 将游标值编码为base64字符串
+func encodeCursor(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(encoded), nil
+}
+
+// decodeCursor 解码base64游标值
+func decodeCursor(cursor string) (interface{}, error) {
+	decoded, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(decoded))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// 数字游标优先还原为整数
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return i, nil
+		}
+		return number.Float64()
+	}
+	return value, nil
+}
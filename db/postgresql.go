@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -76,6 +77,7 @@ func (c *PostgreSQLConnection) Close() error {
 		if c.logger != nil {
 			c.logger.Debug("Closing PostgreSQL connection")
 		}
+		releaseStmtCache(c.db)
 		err := c.db.Close()
 		c.db = nil
 		return err
@@ -106,7 +108,7 @@ func (c *PostgreSQLConnection) Query(query string, args ...interface{}) (*sql.Ro
 	}
 
 	start := time.Now()
-	rows, err := c.db.Query(query, args...)
+	rows, err := queryDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -159,7 +161,7 @@ func (c *PostgreSQLConnection) Exec(query string, args ...interface{}) (sql.Resu
 	}
 
 	start := time.Now()
-	result, err := c.db.Exec(query, args...)
+	result, err := execDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -190,16 +192,16 @@ func (c *PostgreSQLConnection) Exec(query string, args ...interface{}) (sql.Resu
 
 // Begin 开始事务
 func (c *PostgreSQLConnection) Begin() (TransactionInterface, error) {
-	return c.BeginTx(nil)
+	return c.BeginTx(context.Background(), nil)
 }
 
-// BeginTx 开始事务（带选项）
-func (c *PostgreSQLConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, error) {
+// BeginTx 开始事务（带选项），opts 为空时使用连接配置的默认隔离级别和只读模式
+func (c *PostgreSQLConnection) BeginTx(ctx context.Context, opts *TxOptions) (TransactionInterface, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("database connection is not established")
 	}
 
-	tx, err := c.db.Begin()
+	tx, err := c.db.BeginTx(txContext(ctx), c.config.txOptions(opts))
 	if err != nil {
 		if c.logger != nil {
 			c.logger.Error("Failed to begin PostgreSQL transaction", "error", err)
@@ -215,6 +217,7 @@ func (c *PostgreSQLConnection) BeginTx(opts *sql.TxOptions) (TransactionInterfac
 		tx:     tx,
 		logger: c.logger,
 		config: c.config,
+		conn:   c,
 	}, nil
 }
 
@@ -241,6 +244,7 @@ type PostgreSQLTransaction struct {
 	tx     *sql.Tx
 	logger LoggerInterface
 	config *Config
+	conn   ConnectionInterface
 }
 
 // Query 在事务中执行查询
@@ -309,6 +313,11 @@ func (t *PostgreSQLTransaction) Exec(query string, args ...interface{}) (sql.Res
 	return result, nil
 }
 
+// connection 返回开启事务的连接
+func (t *PostgreSQLTransaction) connection() ConnectionInterface {
+	return t.conn
+}
+
 // Commit 提交事务
 func (t *PostgreSQLTransaction) Commit() error {
 	if t.tx == nil {
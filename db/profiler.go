@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zhoudm1743/torm/logger"
+)
+
+// Profiler 查询分析器，按作用域统计相同结构的SQL执行次数，用于在开发环境发现N+1查询
+// SQL结构通过去掉字面量和参数得到，同一作用域内某个结构的执行次数达到阈值时输出一次警告
+type Profiler struct {
+	mutex     sync.Mutex
+	threshold int
+	logger    LoggerInterface
+	// counts 作用域 → SQL结构 → 执行次数
+	counts map[string]map[string]int
+}
+
+// profileScopeKey 上下文中保存分析作用域的键
+type profileScopeKey struct{}
+
+// activeProfiler 当前启用的分析器，未启用时为 nil
+var activeProfiler atomic.Pointer[Profiler]
+
+var (
+	profileStringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+	profileNumberRegex        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	profilePlaceholderRegex   = regexp.MustCompile(`\$\d+|:\w+|@p\d+`)
+	profileInListRegex        = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	profileWhitespaceRegex    = regexp.MustCompile(`\s+`)
+)
+
+// EnableProfiler 启用查询分析器并返回它，threshold 为同一作用域内相同结构SQL触发警告的执行次数，小于2时使用10
+// 查询的作用域由上下文决定，见 WithProfileScope，没有作用域的查询统计在空作用域下；
+// 分析器只统计，不影响查询执行，适合在开发环境中使用
+// 例如：
+//
+//	profiler := db.EnableProfiler(5)
+//	ctx := db.WithProfileScope(r.Context(), r.URL.Path)
+//	db.Table("users").WithContext(ctx).Get()
+func EnableProfiler(threshold int) *Profiler {
+	if threshold < 2 {
+		threshold = 10
+	}
+	profiler := &Profiler{
+		threshold: threshold,
+		logger:    logger.DefaultLogger(),
+		counts:    make(map[string]map[string]int),
+	}
+	activeProfiler.Store(profiler)
+	return profiler
+}
+
+// DisableProfiler 停用查询分析器
+func DisableProfiler() {
+	activeProfiler.Store(nil)
+}
+
+// CurrentProfiler 获取当前启用的查询分析器，未启用时返回 nil
+func CurrentProfiler() *Profiler {
+	return activeProfiler.Load()
+}
+
+// WithProfileScope 返回带分析作用域的上下文，通常每个请求一个作用域，如请求ID或路由
+func WithProfileScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, profileScopeKey{}, scope)
+}
+
+// SetLogger 设置输出警告的日志记录器
+func (p *Profiler) SetLogger(l LoggerInterface) *Profiler {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.logger = l
+	return p
+}
+
+// Report 返回统计结果：作用域 → SQL结构 → 执行次数，返回的是副本
+func (p *Profiler) Report() map[string]map[string]int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	report := make(map[string]map[string]int, len(p.counts))
+	for scope, shapes := range p.counts {
+		copied := make(map[string]int, len(shapes))
+		for shape, count := range shapes {
+			copied[shape] = count
+		}
+		report[scope] = copied
+	}
+	return report
+}
+
+// Reset 清空统计结果，scopes 为空时清空所有作用域，请求结束后可以清理该请求的作用域
+func (p *Profiler) Reset(scopes ...string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(scopes) == 0 {
+		p.counts = make(map[string]map[string]int)
+		return
+	}
+	for _, scope := range scopes {
+		delete(p.counts, scope)
+	}
+}
+
+// record 记录一次SQL执行，执行次数恰好达到阈值时输出警告
+func (p *Profiler) record(scope, query string) {
+	shape := normalizeSQLShape(query)
+
+	p.mutex.Lock()
+	shapes := p.counts[scope]
+	if shapes == nil {
+		shapes = make(map[string]int)
+		p.counts[scope] = shapes
+	}
+	shapes[shape]++
+	count := shapes[shape]
+	log := p.logger
+	p.mutex.Unlock()
+
+	if count == p.threshold && log != nil {
+		log.Warn("检测到重复执行的查询，可能存在N+1问题", "scope", scope, "count", count, "sql", shape)
+	}
+}
+
+// profileQuery 分析器启用时记录一次SQL执行
+func profileQuery(ctx context.Context, query string) {
+	profiler := activeProfiler.Load()
+	if profiler == nil {
+		return
+	}
+	scope := ""
+	if ctx != nil {
+		scope, _ = ctx.Value(profileScopeKey{}).(string)
+	}
+	profiler.record(scope, query)
+}
+
+// normalizeSQLShape 将SQL规范化为结构：字面量和各方言占位符替换为 ?，IN列表合并为一个 ?，压缩空白
+func normalizeSQLShape(query string) string {
+	shape := profileStringLiteralRegex.ReplaceAllString(query, "?")
+	shape = profilePlaceholderRegex.ReplaceAllString(shape, "?")
+	shape = profileNumberRegex.ReplaceAllString(shape, "?")
+	shape = profileInListRegex.ReplaceAllString(shape, "(?)")
+	shape = profileWhitespaceRegex.ReplaceAllString(shape, " ")
+	return strings.TrimSpace(shape)
+}
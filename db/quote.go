@@ -0,0 +1,79 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+)
+
+// quotableIdentifierRegex 可以加引号的标识符：列名、表.列、表.*，可带 AS 别名
+// 其他表达式（函数、DISTINCT、已加引号的名称等）保持原样
+var quotableIdentifierRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.(?:[A-Za-z_][A-Za-z0-9_]*|\*))*)(?:\s+(?i:AS)\s+([A-Za-z_][A-Za-z0-9_]*))?$`)
+
+// QuoteIdentifiers 为当前查询生成的表名和列名加引号，连接配置 QuoteIdentifiers 为 true 时默认开启
+// MySQL 使用反引号，PostgreSQL 和 SQLite 使用双引号，SQL Server 使用方括号
+// 需要在添加条件之前调用，WhereIn、WhereNull 等条件在添加时生成列引用
+func (qb *QueryBuilder) QuoteIdentifiers() *QueryBuilder {
+	qb.quoteIdents = true
+	return qb
+}
+
+// quotingEnabled 是否为标识符加引号
+func (qb *QueryBuilder) quotingEnabled() bool {
+	if qb.quoteIdents {
+		return true
+	}
+	conn, err := qb.getConnection()
+	if err != nil {
+		return false
+	}
+	config := conn.GetConfig()
+	return config != nil && config.QuoteIdentifiers
+}
+
+// quoteName 按驱动为单个名称加引号
+func quoteName(driver, name string) string {
+	switch driver {
+	case "postgres", "postgresql", "pq", "sqlite", "sqlite3":
+		return `"` + name + `"`
+	case "sqlserver", "mssql":
+		return "[" + name + "]"
+	default:
+		return "`" + name + "`"
+	}
+}
+
+// quoteIdentifier 开启引号时为标识符加引号，限定名（users.id）逐段加引号，* 和别名按规则处理
+func (qb *QueryBuilder) quoteIdentifier(identifier string) string {
+	if !qb.quotingEnabled() {
+		return identifier
+	}
+	match := quotableIdentifierRegex.FindStringSubmatch(strings.TrimSpace(identifier))
+	if match == nil {
+		return identifier
+	}
+
+	driver := qb.getDriverName()
+	parts := strings.Split(match[1], ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = quoteName(driver, part)
+		}
+	}
+	quoted := strings.Join(parts, ".")
+	if match[2] != "" {
+		quoted += " AS " + quoteName(driver, match[2])
+	}
+	return quoted
+}
+
+// quoteTableReference 为 "表名 别名" 形式的表引用逐项加引号
+func (qb *QueryBuilder) quoteTableReference(reference string) string {
+	if !qb.quotingEnabled() {
+		return reference
+	}
+	fields := strings.Fields(reference)
+	if len(fields) == 2 {
+		return qb.quoteIdentifier(fields[0]) + " " + qb.quoteIdentifier(fields[1])
+	}
+	return qb.quoteIdentifier(reference)
+}
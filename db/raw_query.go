@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Raw 在指定连接上执行原生查询并返回结果，用于查询构建器无法表达的SQL（窗口函数、CTE等）
+// 结果的扫描和类型转换与查询构建器一致；SQL按原样执行，占位符需使用数据库自身的格式
+func Raw(connName string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return defaultManager.Raw(connName, query, args...)
+}
+
+// RawFirst 执行原生查询并返回第一条记录，没有记录时返回 ErrRecordNotFound
+func RawFirst(connName string, query string, args ...interface{}) (map[string]interface{}, error) {
+	return defaultManager.RawFirst(connName, query, args...)
+}
+
+// RawInto 执行原生查询并将结果填充到 dest
+// dest 为结构体切片指针时填充全部记录，为结构体指针时填充第一条记录
+func RawInto(connName string, dest interface{}, query string, args ...interface{}) error {
+	return defaultManager.RawInto(connName, dest, query, args...)
+}
+
+// Raw 在指定连接上执行原生查询并返回结果
+func (m *Manager) Raw(connName string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := m.Query(connName, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 使用查询构建器的扫描逻辑，保证类型转换（时区、DECIMAL等）与构建器查询一致
+	builder, err := NewQueryBuilder(connName)
+	if err != nil {
+		return nil, err
+	}
+	defer builder.Release()
+
+	result, err := builder.scanRows(rows)
+	if err != nil {
+		wrappedErr := WrapError(err, ErrCodeQueryFailed, "扫描查询结果失败").
+			WithContext("sql", query).
+			WithContext("args", args).
+			WithContext("connection", connName).
+			WithDetails(fmt.Sprintf("结果扫描错误: %v", err))
+		LogError(wrappedErr)
+		return nil, wrappedErr
+	}
+	return result, nil
+}
+
+// RawFirst 执行原生查询并返回第一条记录
+func (m *Manager) RawFirst(connName string, query string, args ...interface{}) (map[string]interface{}, error) {
+	results, err := m.Raw(connName, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, newNotFoundError("sql", query)
+	}
+	return results[0], nil
+}
+
+// RawInto 执行原生查询并将结果填充到结构体切片指针或结构体指针
+func (m *Manager) RawInto(connName string, dest interface{}, query string, args ...interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() == reflect.Ptr && !destValue.IsNil() && destValue.Elem().Kind() == reflect.Struct {
+		row, err := m.RawFirst(connName, query, args...)
+		if err != nil {
+			return err
+		}
+		return LoadModel(row, dest)
+	}
+
+	results, err := m.Raw(connName, query, args...)
+	if err != nil {
+		return err
+	}
+	return LoadModels(results, dest)
+}
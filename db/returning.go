@@ -0,0 +1,178 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// UpdateReturning 更新数据并返回被更新的行，columns 为空时返回所有列
+// PostgreSQL 和 SQLite 使用 UPDATE ... RETURNING 一次往返完成；
+// MySQL 和 SQL Server 在事务中先锁定查询匹配的行再更新，返回的行是查询结果合并更新数据，不包含数据库触发的其他变化
+func (qb *QueryBuilder) UpdateReturning(data map[string]interface{}, columns ...string) ([]map[string]interface{}, error) {
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
+	if len(data) == 0 {
+		return nil, NewError(ErrCodeInvalidParameter, "更新数据不能为空")
+	}
+	returning, err := qb.returningClause(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// 处理时间字段
+	if qb.timeManager != nil && len(qb.timeFields) > 0 {
+		data = qb.timeFieldManager().ProcessUpdateData(data, qb.timeFields)
+	}
+
+	if qb.SupportsReturning() {
+		sqlStr, args := qb.buildUpdateSQL(data)
+		return qb.queryReturning(sqlStr+returning, args, "更新数据失败")
+	}
+
+	rows, err := qb.selectThenModify(columns, func(query *QueryBuilder) (int64, error) {
+		return query.Update(data)
+	})
+	if err != nil || rows == nil {
+		return rows, err
+	}
+
+	// 查询结果为更新前的值，合并本次更新的列
+	for _, row := range rows {
+		for column, value := range data {
+			if _, selected := row[column]; selected || len(columns) == 0 {
+				row[column] = value
+			}
+		}
+	}
+	return rows, nil
+}
+
+// DeleteReturning 删除数据并返回被删除的行，columns 为空时返回所有列
+// PostgreSQL 和 SQLite 使用 DELETE ... RETURNING，MySQL 和 SQL Server 在事务中先锁定查询再删除
+func (qb *QueryBuilder) DeleteReturning(columns ...string) ([]map[string]interface{}, error) {
+	if err := qb.beginExecution(); err != nil {
+		return nil, err
+	}
+	defer qb.endExecution()
+
+	returning, err := qb.returningClause(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	if qb.SupportsReturning() {
+		sqlStr, args := qb.buildDeleteSQL()
+		return qb.queryReturning(sqlStr+returning, args, "删除数据失败")
+	}
+
+	return qb.selectThenModify(columns, func(query *QueryBuilder) (int64, error) {
+		return query.Delete()
+	})
+}
+
+// returningClause 校验返回列并生成 RETURNING 子句，开启引号时列名加引号
+func (qb *QueryBuilder) returningClause(columns []string) (string, error) {
+	if len(columns) == 0 {
+		return " RETURNING *", nil
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if err := qb.validateColumnName(column); err != nil {
+			return "", err
+		}
+		quoted[i] = qb.quoteIdentifier(column)
+	}
+	return " RETURNING " + strings.Join(quoted, ", "), nil
+}
+
+// queryReturning 执行带 RETURNING 的写语句并扫描返回的行
+func (qb *QueryBuilder) queryReturning(sqlStr string, args []interface{}, message string) ([]map[string]interface{}, error) {
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	if qb.recordSQL(sqlStr, args) {
+		return nil, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if qb.transaction != nil {
+		rows, err = queryTxWithContext(qb.context(), qb.transaction, sqlStr, args...)
+	} else {
+		conn, connErr := qb.getConnection()
+		if connErr != nil {
+			return nil, connErr
+		}
+		rows, err = queryWithContext(qb.context(), conn, sqlStr, args...)
+	}
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, message).
+			WithContext("sql", sqlStr).
+			WithContext("args", args).
+			WithContext("table", qb.tableName)
+	}
+	defer rows.Close()
+
+	result, err := qb.scanRows(rows)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeQueryFailed, "扫描返回结果失败").
+			WithContext("sql", sqlStr).
+			WithContext("table", qb.tableName)
+	}
+	return result, nil
+}
+
+// selectThenModify 不支持 RETURNING 时的回退：在事务中锁定查询匹配的行，再执行修改
+func (qb *QueryBuilder) selectThenModify(columns []string, modify func(*QueryBuilder) (int64, error)) ([]map[string]interface{}, error) {
+	if qb.dryRun {
+		// 当前构建器已处于执行状态，在克隆上生成修改语句，再记录到当前构建器
+		modifier := qb.Clone()
+		modifier.timeFields = nil // 时间字段已处理
+		_, err := modify(modifier)
+		qb.recordSQL(modifier.LastSQL())
+		return nil, err
+	}
+
+	tx := qb.transaction
+	ownTx := tx == nil
+	if ownTx {
+		conn, err := qb.getConnection()
+		if err != nil {
+			return nil, err
+		}
+		tx, err = conn.Begin()
+		if err != nil {
+			return nil, WrapError(err, ErrCodeTransactionFailed, "开始事务失败")
+		}
+	}
+
+	query := qb.Clone()
+	query.transaction = tx
+	if len(columns) > 0 {
+		query.selectColumns = append(query.selectColumns[:0], columns...)
+	}
+	query.lockMode = "update"
+	rows, err := query.Get()
+	if err == nil {
+		modifier := qb.Clone()
+		modifier.transaction = tx
+		modifier.timeFields = nil // 时间字段已处理
+		_, err = modify(modifier)
+	}
+	if err != nil {
+		if ownTx {
+			tx.Rollback()
+		}
+		return nil, err
+	}
+
+	if ownTx {
+		if err := tx.Commit(); err != nil {
+			return nil, WrapError(err, ErrCodeTransactionCommitFailed, "提交事务失败")
+		}
+	}
+	return rows, nil
+}
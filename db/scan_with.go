@@ -0,0 +1,46 @@
+package db
+
+import "sync"
+
+// RelationLoader 关联加载器，将 row 对应的结构体 dest 的关联按名称加载到结构体的关联字段中
+// connName 为查询使用的连接，关联查询在同一连接上执行
+type RelationLoader func(dest interface{}, row map[string]interface{}, connName string, relations []string) error
+
+var (
+	relationLoader      RelationLoader
+	relationLoaderMutex sync.RWMutex
+)
+
+// RegisterRelationLoader 注册 ScanWith 使用的关联加载器，model 包在初始化时注册，loader 为 nil 时取消注册
+func RegisterRelationLoader(loader RelationLoader) {
+	relationLoaderMutex.Lock()
+	defer relationLoaderMutex.Unlock()
+	relationLoader = loader
+}
+
+// ScanWith 查询第一条记录填充到结构体指针中，并预加载指定的关联到结构体的关联字段
+// 关联的定义和字段匹配规则见 model 包的关联加载器，未找到记录时返回 ErrRecordNotFound
+// 例如：
+//
+//	var author Author
+//	err := db.Table("authors").Where("id", "=", 1).ScanWith(&author, "Posts", "Country")
+func (qb *QueryBuilder) ScanWith(dest interface{}, relations ...string) error {
+	row, err := qb.FirstRaw()
+	if err != nil {
+		return err
+	}
+	if err := LoadModel(row, dest); err != nil {
+		return err
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+
+	relationLoaderMutex.RLock()
+	loader := relationLoader
+	relationLoaderMutex.RUnlock()
+	if loader == nil {
+		return NewError(ErrCodeNotImplemented, "未注册关联加载器，请导入 model 包")
+	}
+	return loader(dest, row, qb.GetConnection(), relations)
+}
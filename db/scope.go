@@ -0,0 +1,135 @@
+package db
+
+import "strings"
+
+// SoftDeleteScope 软删除内置全局作用域名称
+const SoftDeleteScope = "soft_delete"
+
+// ScopeFunc 查询作用域，向查询构建器追加可复用的条件
+type ScopeFunc func(*QueryBuilder) *QueryBuilder
+
+// globalScope 命名的全局作用域
+type globalScope struct {
+	name string
+	fn   ScopeFunc
+}
+
+// Scope 应用局部作用域
+// 例如：query.Scope(Active, OfTenant(tid))
+func (qb *QueryBuilder) Scope(scopes ...ScopeFunc) *QueryBuilder {
+	for _, scope := range scopes {
+		if scope != nil {
+			scope(qb)
+		}
+	}
+	return qb
+}
+
+// When 条件为真时应用 fn，否则应用 elseFn（可选），用于在链式调用中按条件追加子句
+// 例如：query.When(status != "", func(q *QueryBuilder) *QueryBuilder { return q.Where("status", "=", status) })
+func (qb *QueryBuilder) When(condition bool, fn ScopeFunc, elseFn ...ScopeFunc) *QueryBuilder {
+	if condition {
+		return qb.Scope(fn)
+	}
+	return qb.Scope(elseFn...)
+}
+
+// WithGlobalScope 注册全局作用域，在构建SQL时自动应用，同名作用域会被替换
+func (qb *QueryBuilder) WithGlobalScope(name string, scope ScopeFunc) *QueryBuilder {
+	if scope == nil {
+		return qb
+	}
+	for i := range qb.globalScopes {
+		if qb.globalScopes[i].name == name {
+			qb.globalScopes[i].fn = scope
+			return qb
+		}
+	}
+	qb.globalScopes = append(qb.globalScopes, globalScope{name: name, fn: scope})
+	return qb
+}
+
+// WithoutGlobalScope 移除指定的全局作用域，不传名称时移除所有全局作用域
+func (qb *QueryBuilder) WithoutGlobalScope(names ...string) *QueryBuilder {
+	if len(names) == 0 {
+		qb.globalScopes = nil
+		return qb
+	}
+
+	scopes := make([]globalScope, 0, len(qb.globalScopes))
+	for _, scope := range qb.globalScopes {
+		removed := false
+		for _, name := range names {
+			if scope.name == name {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			scopes = append(scopes, scope)
+		}
+	}
+	qb.globalScopes = scopes
+	return qb
+}
+
+// HasGlobalScope 检查是否注册了指定的全局作用域
+func (qb *QueryBuilder) HasGlobalScope(name string) bool {
+	for _, scope := range qb.globalScopes {
+		if scope.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedBuilder 返回应用了全局作用域的副本，没有全局作用域时返回自身
+// 作用域在每次构建SQL时应用到副本上，不修改当前构建器，ToSQL、Count 等调用之后仍可以移除作用域；
+// 任一组条件包含OR时会加括号分组，避免作用域条件被OR绕过
+func (qb *QueryBuilder) scopedBuilder() *QueryBuilder {
+	if len(qb.globalScopes) == 0 {
+		return qb
+	}
+
+	scoped := qb.Clone()
+	scoped.globalScopes = nil
+	userConditions := scoped.whereConditions
+	scoped.whereConditions = make([]WhereCondition, 0, len(qb.globalScopes))
+	for _, scope := range qb.globalScopes {
+		scope.fn(scoped)
+	}
+	if scoped.err != nil {
+		qb.setErr(scoped.err)
+	}
+	scopeConditions := scoped.whereConditions
+
+	if len(scopeConditions) == 0 {
+		scoped.whereConditions = userConditions
+		return scoped
+	}
+	if len(userConditions) == 0 {
+		return scoped
+	}
+
+	scopeConditions = qb.groupWhereConditions(scopeConditions)
+	scopeConditions[0].Logic = "AND"
+	scoped.whereConditions = append(qb.groupWhereConditions(userConditions), scopeConditions...)
+	return scoped
+}
+
+// groupWhereConditions 条件中包含OR时合并为一个带括号的原生条件
+func (qb *QueryBuilder) groupWhereConditions(conditions []WhereCondition) []WhereCondition {
+	hasOr := false
+	for i := 1; i < len(conditions); i++ {
+		if strings.EqualFold(conditions[i].Logic, "OR") {
+			hasOr = true
+			break
+		}
+	}
+	if !hasOr {
+		return conditions
+	}
+
+	raw, values := qb.joinWhereConditions(conditions)
+	return []WhereCondition{{Raw: "(" + raw + ")", Values: values, Logic: conditions[0].Logic}}
+}
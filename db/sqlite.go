@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -83,6 +84,7 @@ func (c *SQLiteConnection) Close() error {
 		if c.logger != nil {
 			c.logger.Debug("Closing SQLite connection")
 		}
+		releaseStmtCache(c.db)
 		err := c.db.Close()
 		c.db = nil
 		return err
@@ -113,7 +115,7 @@ func (c *SQLiteConnection) Query(query string, args ...interface{}) (*sql.Rows,
 	}
 
 	start := time.Now()
-	rows, err := c.db.Query(query, args...)
+	rows, err := queryDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -167,7 +169,7 @@ func (c *SQLiteConnection) Exec(query string, args ...interface{}) (sql.Result,
 	}
 
 	start := time.Now()
-	result, err := c.db.Exec(query, args...)
+	result, err := execDB(context.Background(), c.db, c.config, query, args...)
 	duration := time.Since(start)
 
 	// 统一SQL日志记录
@@ -198,16 +200,16 @@ func (c *SQLiteConnection) Exec(query string, args ...interface{}) (sql.Result,
 
 // Begin 开始事务
 func (c *SQLiteConnection) Begin() (TransactionInterface, error) {
-	return c.BeginTx(nil)
+	return c.BeginTx(context.Background(), nil)
 }
 
-// BeginTx 开始事务（带选项）
-func (c *SQLiteConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, error) {
+// BeginTx 开始事务（带选项），opts 为空时使用连接配置的默认隔离级别和只读模式
+func (c *SQLiteConnection) BeginTx(ctx context.Context, opts *TxOptions) (TransactionInterface, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("database connection is not established")
 	}
 
-	tx, err := c.db.Begin()
+	tx, err := c.db.BeginTx(txContext(ctx), c.config.txOptions(opts))
 	if err != nil {
 		if c.logger != nil {
 			c.logger.Error("Failed to begin SQLite transaction", "error", err)
@@ -223,6 +225,7 @@ func (c *SQLiteConnection) BeginTx(opts *sql.TxOptions) (TransactionInterface, e
 		tx:     tx,
 		logger: c.logger,
 		config: c.config,
+		conn:   c,
 	}, nil
 }
 
@@ -249,6 +252,7 @@ type SQLiteTransaction struct {
 	tx     *sql.Tx
 	logger LoggerInterface
 	config *Config
+	conn   ConnectionInterface
 }
 
 // Query 在事务中执行查询
@@ -317,6 +321,11 @@ func (t *SQLiteTransaction) Exec(query string, args ...interface{}) (sql.Result,
 	return result, nil
 }
 
+// connection 返回开启事务的连接
+func (t *SQLiteTransaction) connection() ConnectionInterface {
+	return t.conn
+}
+
 // Commit 提交事务
 func (t *SQLiteTransaction) Commit() error {
 	if t.tx == nil {
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// stmtCaches 每个 *sql.DB 的预处理语句缓存，连接关闭时移除
+var stmtCaches sync.Map
+
+// stmtCache 以SQL字符串为键的预处理语句LRU缓存
+type stmtCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 最近使用的在前
+	items    map[string]*list.Element
+}
+
+// stmtCacheEntry LRU链表元素
+type stmtCacheEntry struct {
+	query string
+	stmt  *sql.Stmt
+}
+
+// stmtCacheFor 获取连接的预处理语句缓存，未开启（PrepareStmtCacheSize <= 0）时返回nil
+func stmtCacheFor(db *sql.DB, config *Config) *stmtCache {
+	if db == nil || config == nil || config.PrepareStmtCacheSize <= 0 {
+		return nil
+	}
+	if cache, ok := stmtCaches.Load(db); ok {
+		return cache.(*stmtCache)
+	}
+	cache, _ := stmtCaches.LoadOrStore(db, &stmtCache{
+		capacity: config.PrepareStmtCacheSize,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	})
+	return cache.(*stmtCache)
+}
+
+// releaseStmtCache 关闭并移除连接的预处理语句缓存，在关闭 *sql.DB 之前调用
+func releaseStmtCache(db *sql.DB) {
+	if cache, ok := stmtCaches.LoadAndDelete(db); ok {
+		cache.(*stmtCache).close()
+	}
+}
+
+// prepare 获取缓存的预处理语句，未命中时预处理并加入缓存，超出容量时关闭最久未使用的语句
+func (c *stmtCache) prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	if element, ok := c.items[query]; ok {
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*stmtCacheEntry).stmt, nil
+	}
+	c.mu.Unlock()
+
+	// 预处理不持有锁，并发未命中时以先加入缓存的语句为准
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(element)
+		return element.Value.(*stmtCacheEntry).stmt, nil
+	}
+	c.items[query] = c.order.PushFront(&stmtCacheEntry{query: query, stmt: stmt})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		entry := c.order.Remove(oldest).(*stmtCacheEntry)
+		delete(c.items, entry.query)
+		// 仍在使用中的语句由 database/sql 在结果集关闭后才真正释放
+		entry.stmt.Close()
+	}
+	return stmt, nil
+}
+
+// remove 移除并关闭失效的预处理语句
+func (c *stmtCache) remove(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[query]; ok {
+		c.order.Remove(element)
+		delete(c.items, query)
+		element.Value.(*stmtCacheEntry).stmt.Close()
+	}
+}
+
+// close 关闭所有缓存的预处理语句
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, element := range c.items {
+		element.Value.(*stmtCacheEntry).stmt.Close()
+	}
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// len 缓存的语句数量
+func (c *stmtCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// isStaleStmtError 判断错误是否表示预处理语句或其底层连接已失效，需要重新预处理
+func isStaleStmtError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	return strings.Contains(err.Error(), "statement is closed")
+}
+
+// queryDB 执行查询，连接开启预处理语句缓存时复用缓存的语句
+// 语句失效（如连接断开重连）时从缓存移除并直接执行一次；预处理失败时同样直接执行
+func queryDB(ctx context.Context, db *sql.DB, config *Config, query string, args ...interface{}) (*sql.Rows, error) {
+	cache := stmtCacheFor(db, config)
+	if cache == nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+
+	stmt, err := cache.prepare(ctx, db, query)
+	if err != nil {
+		// 部分语句（如某些DDL）不支持预处理协议，直接执行
+		return db.QueryContext(ctx, query, args...)
+	}
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil && isStaleStmtError(err) {
+		cache.remove(query)
+		return db.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+// execDB 执行SQL语句，连接开启预处理语句缓存时复用缓存的语句
+func execDB(ctx context.Context, db *sql.DB, config *Config, query string, args ...interface{}) (sql.Result, error) {
+	cache := stmtCacheFor(db, config)
+	if cache == nil {
+		return db.ExecContext(ctx, query, args...)
+	}
+
+	stmt, err := cache.prepare(ctx, db, query)
+	if err != nil {
+		// 预处理失败时直接执行
+		return db.ExecContext(ctx, query, args...)
+	}
+	result, err := stmt.ExecContext(ctx, args...)
+	if err != nil && isStaleStmtError(err) {
+		cache.remove(query)
+		return db.ExecContext(ctx, query, args...)
+	}
+	return result, err
+}
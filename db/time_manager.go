@@ -10,8 +10,9 @@ import (
 
 // TimeFieldManager 时间字段管理器
 type TimeFieldManager struct {
-	createTimeFields []string // 自动创建时间字段列表
-	updateTimeFields []string // 自动更新时间字段列表
+	createTimeFields []string       // 自动创建时间字段列表
+	updateTimeFields []string       // 自动更新时间字段列表
+	location         *time.Location // 解析和格式化时间使用的时区，默认服务器本地时区
 }
 
 // NewTimeFieldManager 创建时间字段管理器
@@ -22,6 +23,21 @@ func NewTimeFieldManager() *TimeFieldManager {
 	}
 }
 
+// WithLocation 返回使用指定时区的副本，不修改原管理器，多个构建器共享同一管理器时并发安全
+func (tfm *TimeFieldManager) WithLocation(loc *time.Location) *TimeFieldManager {
+	copied := *tfm
+	copied.location = loc
+	return &copied
+}
+
+// Location 获取解析和格式化时间使用的时区
+func (tfm *TimeFieldManager) Location() *time.Location {
+	if tfm.location == nil {
+		return time.Local
+	}
+	return tfm.location
+}
+
 // TimeFieldInfo 时间字段信息
 type TimeFieldInfo struct {
 	FieldName    string      // 字段名
@@ -225,7 +241,7 @@ func (tfm *TimeFieldManager) ProcessInsertData(data map[string]interface{}, time
 		result[k] = v
 	}
 
-	now := time.Now()
+	now := time.Now().In(tfm.Location())
 
 	for _, fieldInfo := range timeFields {
 		if fieldInfo.IsCreateTime {
@@ -256,7 +272,7 @@ func (tfm *TimeFieldManager) ProcessUpdateData(data map[string]interface{}, time
 		result[k] = v
 	}
 
-	now := time.Now()
+	now := time.Now().In(tfm.Location())
 
 	for _, fieldInfo := range timeFields {
 		if fieldInfo.IsUpdateTime {
@@ -359,7 +375,7 @@ func (tfm *TimeFieldManager) ParseTimeValue(value interface{}, fieldType reflect
 func (tfm *TimeFieldManager) convertFromTime(t time.Time, fieldType reflect.Type) interface{} {
 	switch fieldType.Kind() {
 	case reflect.String:
-		return t.Format("2006-01-02 15:04:05")
+		return t.In(tfm.Location()).Format("2006-01-02 15:04:05")
 	case reflect.Int64:
 		return t.Unix()
 	case reflect.Int:
@@ -397,8 +413,10 @@ func (tfm *TimeFieldManager) parseTimeString(timeStr string) (time.Time, error)
 		"01-02-2006",
 	}
 
+	// 不带时区的格式按配置的时区解析
+	loc := tfm.Location()
 	for _, format := range timeFormats {
-		if t, err := time.Parse(format, timeStr); err == nil {
+		if t, err := time.ParseInLocation(format, timeStr, loc); err == nil {
 			return t, nil
 		}
 	}
@@ -407,9 +425,9 @@ func (tfm *TimeFieldManager) parseTimeString(timeStr string) (time.Time, error)
 	if timestamp, err := strconv.ParseInt(timeStr, 10, 64); err == nil {
 		// 区分秒级和毫秒级时间戳
 		if timestamp > 1e10 { // 毫秒级时间戳
-			return time.Unix(timestamp/1000, (timestamp%1000)*1000000), nil
+			return time.Unix(timestamp/1000, (timestamp%1000)*1000000).In(loc), nil
 		} else { // 秒级时间戳
-			return time.Unix(timestamp, 0), nil
+			return time.Unix(timestamp, 0).In(loc), nil
 		}
 	}
 
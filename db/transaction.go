@@ -6,14 +6,34 @@ import (
 	"fmt"
 )
 
+// TxOptions 事务选项
+type TxOptions struct {
+	Isolation sql.IsolationLevel // 隔离级别，sql.LevelDefault 表示使用驱动默认值
+	ReadOnly  bool               // 是否为只读事务
+}
+
+// txContext 上下文为空时使用 context.Background()
+func txContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
 // DBTransaction 事务实现
 type DBTransaction struct {
-	tx  *sql.Tx
-	ctx context.Context
+	tx   *sql.Tx
+	ctx  context.Context
+	conn ConnectionInterface
 }
 
-// NewTransaction 创建新事务
+// NewTransaction 创建新事务，使用连接配置的默认事务选项
 func NewTransaction(conn ConnectionInterface) (*DBTransaction, error) {
+	return NewTransactionWithOptions(context.Background(), conn, nil)
+}
+
+// NewTransactionWithOptions 使用指定的上下文和事务选项创建新事务，opts 为空时使用连接配置的默认值
+func NewTransactionWithOptions(ctx context.Context, conn ConnectionInterface, opts *TxOptions) (*DBTransaction, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("连接不能为空")
 	}
@@ -23,14 +43,16 @@ func NewTransaction(conn ConnectionInterface) (*DBTransaction, error) {
 		return nil, fmt.Errorf("数据库连接未初始化")
 	}
 
-	tx, err := db.Begin()
+	ctx = txContext(ctx)
+	tx, err := db.BeginTx(ctx, conn.GetConfig().txOptions(opts))
 	if err != nil {
 		return nil, fmt.Errorf("开始事务失败: %w", err)
 	}
 
 	return &DBTransaction{
-		tx:  tx,
-		ctx: context.Background(),
+		tx:   tx,
+		ctx:  ctx,
+		conn: conn,
 	}, nil
 }
 
@@ -49,6 +71,26 @@ func (t *DBTransaction) Exec(query string, args ...interface{}) (sql.Result, err
 	return t.tx.ExecContext(t.ctx, query, args...)
 }
 
+// QueryContext 使用指定上下文执行查询
+func (t *DBTransaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext 使用指定上下文执行查询单行
+func (t *DBTransaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+// ExecContext 使用指定上下文执行语句
+func (t *DBTransaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+// connection 返回开启事务的连接
+func (t *DBTransaction) connection() ConnectionInterface {
+	return t.conn
+}
+
 // Commit 提交事务
 func (t *DBTransaction) Commit() error {
 	return t.tx.Commit()
@@ -59,8 +101,15 @@ func (t *DBTransaction) Rollback() error {
 	return t.tx.Rollback()
 }
 
-// Transaction 便捷的事务执行函数
+// Transaction 便捷的事务执行函数，默认连接为 default
+// fn 返回nil时提交，返回错误或panic时回滚，panic在回滚后继续抛出
 func Transaction(fn func(tx TransactionInterface) error, connectionName ...string) error {
+	return TransactionWithOptions(context.Background(), nil, fn, connectionName...)
+}
+
+// TransactionWithOptions 使用指定的隔离级别和只读模式执行事务，opts 为空时使用连接配置的默认值
+// 例如：db.TransactionWithOptions(ctx, &db.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+func TransactionWithOptions(ctx context.Context, opts *TxOptions, fn func(tx TransactionInterface) error, connectionName ...string) error {
 	connName := "default"
 	if len(connectionName) > 0 {
 		connName = connectionName[0]
@@ -71,7 +120,22 @@ func Transaction(fn func(tx TransactionInterface) error, connectionName ...strin
 		return fmt.Errorf("获取数据库连接失败: %w", err)
 	}
 
-	tx, err := NewTransaction(conn)
+	return runTransaction(ctx, conn, opts, fn)
+}
+
+// RunInTransaction 在指定连接上执行事务：fn 返回nil时提交，返回错误或panic时回滚，panic在回滚后继续抛出
+// 例如：conn, _ := db.DB("default"); err := db.RunInTransaction(conn, func(tx db.TransactionInterface) error { ... })
+func RunInTransaction(conn ConnectionInterface, fn func(tx TransactionInterface) error) error {
+	return runTransaction(context.Background(), conn, nil, fn)
+}
+
+// runTransaction 开始事务并执行 fn，根据结果提交或回滚
+func runTransaction(ctx context.Context, conn ConnectionInterface, opts *TxOptions, fn func(tx TransactionInterface) error) error {
+	if fn == nil {
+		return fmt.Errorf("事务函数不能为空")
+	}
+
+	tx, err := NewTransactionWithOptions(ctx, conn, opts)
 	if err != nil {
 		return err
 	}
@@ -105,3 +169,62 @@ func BeginTransaction(connectionName ...string) (*DBTransaction, error) {
 
 	return NewTransaction(conn)
 }
+
+// BeginTransactionWithOptions 使用指定的上下文和事务选项开始事务
+func BeginTransactionWithOptions(ctx context.Context, opts *TxOptions, connectionName ...string) (*DBTransaction, error) {
+	connName := "default"
+	if len(connectionName) > 0 {
+		connName = connectionName[0]
+	}
+
+	conn, err := DefaultManager().Connection(connName)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+
+	return NewTransactionWithOptions(ctx, conn, opts)
+}
+
+// contextTransaction 支持上下文的事务
+type contextTransaction interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryTxWithContext 在事务中执行查询，事务支持上下文时传递上下文
+// 驱动返回连接级错误时通知连接监听器
+func queryTxWithContext(ctx context.Context, tx TransactionInterface, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	defer func() { reportTxExecutionError(tx, err) }()
+	profileQuery(ctx, query)
+	if txCtx, ok := tx.(contextTransaction); ok {
+		return txCtx.QueryContext(ctx, query, args...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return tx.Query(query, args...)
+}
+
+// queryRowTxWithContext 在事务中执行单行查询，事务支持上下文时传递上下文
+func queryRowTxWithContext(ctx context.Context, tx TransactionInterface, query string, args ...interface{}) *sql.Row {
+	profileQuery(ctx, query)
+	if txCtx, ok := tx.(contextTransaction); ok {
+		return txCtx.QueryRowContext(ctx, query, args...)
+	}
+	return tx.QueryRow(query, args...)
+}
+
+// execTxWithContext 在事务中执行语句，事务支持上下文时传递上下文
+// 驱动返回连接级错误时通知连接监听器
+func execTxWithContext(ctx context.Context, tx TransactionInterface, query string, args ...interface{}) (result sql.Result, err error) {
+	defer func() { reportTxExecutionError(tx, err) }()
+	profileQuery(ctx, query)
+	if txCtx, ok := tx.(contextTransaction); ok {
+		return txCtx.ExecContext(ctx, query, args...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return tx.Exec(query, args...)
+}
@@ -0,0 +1,175 @@
+package db
+
+import "context"
+
+// TypedQuery 类型化查询构建器，包装 *QueryBuilder，Get/First 返回 T 而不是 map
+// 表名通过 T 的 TableName 方法推断，列与字段的对应规则与 LoadModel 相同
+// 链式条件委托给底层构建器，未包装的方法可通过 Builder() 调用
+type TypedQuery[T any] struct {
+	builder *QueryBuilder
+}
+
+// QueryOf 创建类型化查询构建器
+// 例如：
+//
+//	query, err := db.QueryOf[User]()
+//	users, err := query.Where("status", "=", "active").OrderBy("id", "DESC").Get()
+func QueryOf[T any](connectionName ...string) (*TypedQuery[T], error) {
+	var model T
+	builder, err := Model(&model, connectionName...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedQuery[T]{builder: builder}, nil
+}
+
+// Builder 返回底层的查询构建器
+func (q *TypedQuery[T]) Builder() *QueryBuilder {
+	return q.builder
+}
+
+// Where 添加WHERE条件，参数格式与 QueryBuilder.Where 相同
+func (q *TypedQuery[T]) Where(args ...interface{}) *TypedQuery[T] {
+	q.builder.Where(args...)
+	return q
+}
+
+// OrWhere 添加OR WHERE条件
+func (q *TypedQuery[T]) OrWhere(args ...interface{}) *TypedQuery[T] {
+	q.builder.OrWhere(args...)
+	return q
+}
+
+// WhereIn WHERE IN条件
+func (q *TypedQuery[T]) WhereIn(field string, values []interface{}) *TypedQuery[T] {
+	q.builder.WhereIn(field, values)
+	return q
+}
+
+// WhereNull WHERE IS NULL条件
+func (q *TypedQuery[T]) WhereNull(field string) *TypedQuery[T] {
+	q.builder.WhereNull(field)
+	return q
+}
+
+// WhereNotNull WHERE IS NOT NULL条件
+func (q *TypedQuery[T]) WhereNotNull(field string) *TypedQuery[T] {
+	q.builder.WhereNotNull(field)
+	return q
+}
+
+// WhereGroup 添加带括号的AND条件组
+func (q *TypedQuery[T]) WhereGroup(fn func(*QueryBuilder)) *TypedQuery[T] {
+	q.builder.WhereGroup(fn)
+	return q
+}
+
+// Select 指定查询列
+func (q *TypedQuery[T]) Select(args ...interface{}) *TypedQuery[T] {
+	q.builder.Select(args...)
+	return q
+}
+
+// OrderBy 排序
+func (q *TypedQuery[T]) OrderBy(column, direction string) *TypedQuery[T] {
+	q.builder.OrderBy(column, direction)
+	return q
+}
+
+// Limit 限制返回记录数
+func (q *TypedQuery[T]) Limit(limit int) *TypedQuery[T] {
+	q.builder.Limit(limit)
+	return q
+}
+
+// Offset 跳过记录数
+func (q *TypedQuery[T]) Offset(offset int) *TypedQuery[T] {
+	q.builder.Offset(offset)
+	return q
+}
+
+// Page 分页设置，page 从1开始
+func (q *TypedQuery[T]) Page(page, pageSize int) *TypedQuery[T] {
+	q.builder.Page(page, pageSize)
+	return q
+}
+
+// WithContext 设置查询上下文
+func (q *TypedQuery[T]) WithContext(ctx context.Context) *TypedQuery[T] {
+	q.builder.WithContext(ctx)
+	return q
+}
+
+// InTransaction 在指定事务中执行查询
+func (q *TypedQuery[T]) InTransaction(tx TransactionInterface) *TypedQuery[T] {
+	q.builder.InTransaction(tx)
+	return q
+}
+
+// Get 执行查询并返回类型化结果
+func (q *TypedQuery[T]) Get() ([]T, error) {
+	rows, err := q.builder.GetRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, 0, len(rows))
+	if err := LoadModels(rows, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// First 返回第一条记录，未找到记录时返回 ErrRecordNotFound
+func (q *TypedQuery[T]) First() (*T, error) {
+	row, err := q.builder.FirstRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	model := new(T)
+	if err := LoadModel(row, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Find 按主键查找，主键列取自 torm:"primary_key" 标签（默认 id），未找到记录时返回 ErrRecordNotFound
+func (q *TypedQuery[T]) Find(id interface{}) (*T, error) {
+	model := new(T)
+	if err := q.builder.FindModel(id, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Count 统计记录数
+func (q *TypedQuery[T]) Count() (int64, error) {
+	return q.builder.Count()
+}
+
+// Exists 判断是否存在匹配的记录
+func (q *TypedQuery[T]) Exists() (bool, error) {
+	return q.builder.Exists()
+}
+
+// All 查询模型对应表的所有记录并填充为类型化切片，表名通过 T 的 TableName 方法推断
+// 例如：users, err := db.All[User]("default")
+func All[T any](connName string) ([]T, error) {
+	query, err := QueryOf[T](connName)
+	if err != nil {
+		return nil, err
+	}
+	return query.Get()
+}
+
+// FindByID 按主键查找一条记录并填充为 *T，主键列取自 torm:"primary_key" 标签（默认 id）
+// 未找到记录时返回 ErrRecordNotFound
+// 例如：user, err := db.FindByID[User]("default", 1)
+func FindByID[T any](connName string, id interface{}) (*T, error) {
+	query, err := QueryOf[T](connName)
+	if err != nil {
+		return nil, err
+	}
+	return query.Find(id)
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/zhoudm1743/torm/db"
@@ -17,6 +18,7 @@ type AutoMigrator struct {
 	cacheEnabled   bool            // 是否启用缓存
 	skipIfExists   bool            // 如果表存在则跳过检查（快速模式）
 	structureCache map[string]bool // 表结构检查缓存
+	serverVersion  string          // 数据库服务器版本，按需查询后缓存
 }
 
 // NewAutoMigrator 创建自动迁移器
@@ -52,6 +54,9 @@ func (am *AutoMigrator) SetSkipIfExists(skip bool) {
 
 // MigrateModel 迁移模型到数据库
 func (am *AutoMigrator) MigrateModel(modelInstance interface{}, tableName string) error {
+	// 与查询构建器一致，为表名加上连接配置的表前缀
+	tableName = prefixTable(am.connection, tableName)
+
 	// 获取模型类型
 	modelType := reflect.TypeOf(modelInstance)
 	if modelType.Kind() == reflect.Ptr {
@@ -114,35 +119,11 @@ func (am *AutoMigrator) tableExists(tableName string) (bool, error) {
 		}
 	}
 
-	// 获取数据库驱动类型
-	driver := am.getDriverType()
-
-	var query string
-	var args []interface{}
-
-	switch driver {
-	case "mysql":
-		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
-		args = []interface{}{tableName}
-	case "sqlite", "sqlite3":
-		query = "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?"
-		args = []interface{}{tableName}
-	case "postgres", "postgresql":
-		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1"
-		args = []interface{}{tableName}
-	default:
-		return false, fmt.Errorf("不支持的数据库驱动: %s", driver)
-	}
-
-	row := am.connection.QueryRow(query, args...)
-	var count int
-	err := row.Scan(&count)
+	exists, err := NewInspector(am.connection).HasTable(tableName)
 	if err != nil {
 		return false, err
 	}
 
-	exists := count > 0
-
 	// 缓存结果
 	if am.cacheEnabled {
 		am.tableCache[tableName] = exists
@@ -209,6 +190,12 @@ func (am *AutoMigrator) buildCreateTableSQL(tableName string, columns []ModelCol
 		sql.WriteString(")")
 	}
 
+	// 表级唯一约束
+	for _, constraint := range am.collectUniqueConstraints(columns) {
+		sql.WriteString(",\n  ")
+		sql.WriteString(am.buildUniqueConstraintDefinition(constraint, driver))
+	}
+
 	sql.WriteString("\n)")
 
 	// MySQL特定的表选项
@@ -294,6 +281,21 @@ func (am *AutoMigrator) buildColumnDefinition(col ModelColumn, driver string) st
 		}
 	}
 
+	// 枚举取值约束（MySQL使用ENUM类型本身约束）
+	if col.Type == ColumnTypeEnum && len(col.EnumValues) > 0 && driver != "mysql" {
+		def.WriteString(fmt.Sprintf(" CHECK (%s IN (%s))",
+			am.quoteIdentifier(col.Name, driver), quoteEnumValues(col.EnumValues)))
+	}
+
+	// CHECK约束（MySQL 8.0.16之前只解析不执行，跳过并给出警告）
+	if col.Check != "" {
+		if am.supportsCheckConstraints(driver) {
+			def.WriteString(fmt.Sprintf(" CHECK (%s)", col.Check))
+		} else {
+			fmt.Printf("  ⚠️ MySQL %s 不支持CHECK约束，已跳过列 %s 的约束: %s\n", am.serverVersion, col.Name, col.Check)
+		}
+	}
+
 	// 外键约束（SQLite在列定义中处理，其他数据库在表级别处理）
 	if col.ForeignKey != "" && (driver == "sqlite" || driver == "sqlite3") {
 		refTable, refColumn := am.parseForeignKeyReference(col.ForeignKey)
@@ -322,6 +324,31 @@ func (am *AutoMigrator) buildColumnDefinition(col ModelColumn, driver string) st
 	return def.String()
 }
 
+// supportsCheckConstraints 判断数据库是否执行CHECK约束
+// MySQL 8.0.16+ 和 MariaDB 10.2+ 支持，版本查询失败时按支持处理
+func (am *AutoMigrator) supportsCheckConstraints(driver string) bool {
+	if driver != "mysql" {
+		return true
+	}
+	if am.serverVersion == "" {
+		var version string
+		if err := am.connection.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+			return true
+		}
+		am.serverVersion = version
+	}
+
+	var major, minor, patch int
+	fmt.Sscanf(am.serverVersion, "%d.%d.%d", &major, &minor, &patch)
+	if strings.Contains(strings.ToLower(am.serverVersion), "mariadb") {
+		return major > 10 || (major == 10 && minor >= 2)
+	}
+	if major != 8 {
+		return major > 8
+	}
+	return minor > 0 || patch >= 16
+}
+
 // getColumnTypeSQL 获取列类型的SQL表示
 func (am *AutoMigrator) getColumnTypeSQL(col ModelColumn, driver string) string {
 	baseType := string(col.Type)
@@ -341,6 +368,17 @@ func (am *AutoMigrator) getColumnTypeSQL(col ModelColumn, driver string) string
 		}
 		return fmt.Sprintf("CHAR(%d)", length)
 
+	case ColumnTypeEnum:
+		if driver == "mysql" && len(col.EnumValues) > 0 {
+			return fmt.Sprintf("ENUM(%s)", quoteEnumValues(col.EnumValues))
+		}
+		// 其他数据库使用VARCHAR，取值由CHECK约束限制
+		length := col.Length
+		if length <= 0 {
+			length = 255
+		}
+		return fmt.Sprintf("VARCHAR(%d)", length)
+
 	case ColumnTypeDecimal:
 		if col.Precision > 0 && col.Scale > 0 {
 			return fmt.Sprintf("DECIMAL(%d,%d)", col.Precision, col.Scale)
@@ -429,6 +467,15 @@ func (am *AutoMigrator) getColumnTypeSQL(col ModelColumn, driver string) string
 	}
 }
 
+// quoteEnumValues 将枚举取值格式化为 'a','b' 形式的字符串字面量列表
+func quoteEnumValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ",")
+}
+
 // formatDefaultValue 格式化默认值
 func (am *AutoMigrator) formatDefaultValue(value interface{}, driver string) string {
 	if value == nil {
@@ -467,8 +514,8 @@ func (am *AutoMigrator) formatDefaultValue(value interface{}, driver string) str
 // createIndexes 创建索引
 func (am *AutoMigrator) createIndexes(tableName string, columns []ModelColumn, driver string) error {
 	for _, col := range columns {
-		// 普通索引
-		if col.Index {
+		// 普通索引（命名索引在下方按名称统一创建）
+		if col.Index && col.IndexName == "" {
 			indexName := fmt.Sprintf("idx_%s_%s", tableName, col.Name)
 			indexType := col.IndexType
 			if indexType == "" {
@@ -549,9 +596,162 @@ func (am *AutoMigrator) createIndexes(tableName string, columns []ModelColumn, d
 		}
 	}
 
+	// 命名索引和复合索引
+	for _, index := range am.collectNamedIndexes(columns) {
+		sql := am.buildCreateIndexSQL(tableName, index, driver)
+		if _, err := am.connection.Exec(sql); err != nil {
+			return fmt.Errorf("创建索引 %s 失败: %w", index.Name, err)
+		}
+	}
+
 	return nil
 }
 
+// collectNamedIndexes 按索引名称收集命名索引，相同名称的列按 priority 排序组成复合索引
+// 复合唯一键由 collectUniqueConstraints 作为表级唯一约束处理
+func (am *AutoMigrator) collectNamedIndexes(columns []ModelColumn) []*Index {
+	type indexColumn struct {
+		name     string
+		priority int
+	}
+
+	var indexes []*Index
+	indexMap := make(map[string]*Index)
+	indexColumns := make(map[string][]indexColumn)
+
+	addColumn := func(indexName string, col ModelColumn) {
+		index, exists := indexMap[indexName]
+		if !exists {
+			index = &Index{Name: indexName}
+			indexMap[indexName] = index
+			indexes = append(indexes, index)
+		}
+		if index.Type == "" && col.IndexType != "" {
+			index.Type = col.IndexType
+		}
+
+		priority := col.IndexPriority
+		if priority == 0 {
+			priority = 10
+		}
+		indexColumns[indexName] = append(indexColumns[indexName], indexColumn{name: col.Name, priority: priority})
+	}
+
+	for _, col := range columns {
+		if col.IndexName != "" {
+			addColumn(col.IndexName, col)
+		}
+	}
+
+	for _, index := range indexes {
+		cols := indexColumns[index.Name]
+		sort.SliceStable(cols, func(i, j int) bool {
+			return cols[i].priority < cols[j].priority
+		})
+		for _, col := range cols {
+			index.Columns = append(index.Columns, col.name)
+		}
+	}
+
+	return indexes
+}
+
+// collectUniqueConstraints 按约束名称收集表级唯一约束，相同名称的列按 priority 排序
+func (am *AutoMigrator) collectUniqueConstraints(columns []ModelColumn) []*Index {
+	type constraintColumn struct {
+		name     string
+		priority int
+	}
+
+	var constraints []*Index
+	constraintMap := make(map[string]*Index)
+	constraintColumns := make(map[string][]constraintColumn)
+
+	for _, col := range columns {
+		if col.UniqueConstraint == "" {
+			continue
+		}
+		if _, exists := constraintMap[col.UniqueConstraint]; !exists {
+			constraint := &Index{Name: col.UniqueConstraint, Unique: true}
+			constraintMap[col.UniqueConstraint] = constraint
+			constraints = append(constraints, constraint)
+		}
+		priority := col.IndexPriority
+		if priority == 0 {
+			priority = 10
+		}
+		constraintColumns[col.UniqueConstraint] = append(constraintColumns[col.UniqueConstraint],
+			constraintColumn{name: col.Name, priority: priority})
+	}
+
+	for _, constraint := range constraints {
+		cols := constraintColumns[constraint.Name]
+		sort.SliceStable(cols, func(i, j int) bool {
+			return cols[i].priority < cols[j].priority
+		})
+		for _, col := range cols {
+			constraint.Columns = append(constraint.Columns, col.name)
+		}
+	}
+	return constraints
+}
+
+// buildUniqueConstraintDefinition 构建建表语句中的唯一约束定义
+// MySQL: UNIQUE KEY name (cols)，PostgreSQL/SQLite: CONSTRAINT name UNIQUE (cols)
+func (am *AutoMigrator) buildUniqueConstraintDefinition(constraint *Index, driver string) string {
+	columns := make([]string, len(constraint.Columns))
+	for i, col := range constraint.Columns {
+		columns[i] = am.quoteIdentifier(col, driver)
+	}
+	if driver == "mysql" {
+		return fmt.Sprintf("UNIQUE KEY %s (%s)", am.quoteIdentifier(constraint.Name, driver), strings.Join(columns, ", "))
+	}
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)", am.quoteIdentifier(constraint.Name, driver), strings.Join(columns, ", "))
+}
+
+// buildAddUniqueConstraintSQL 构建为已存在的表添加唯一约束的SQL
+// SQLite 不支持 ALTER TABLE ADD CONSTRAINT，使用同名的唯一索引代替
+func (am *AutoMigrator) buildAddUniqueConstraintSQL(tableName string, constraint *Index, driver string) string {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return am.buildCreateIndexSQL(tableName, constraint, driver)
+	default:
+		return fmt.Sprintf("ALTER TABLE %s ADD %s", am.quoteIdentifier(tableName, driver),
+			am.buildUniqueConstraintDefinition(constraint, driver))
+	}
+}
+
+// buildCreateIndexSQL 构建创建（复合）索引的SQL
+func (am *AutoMigrator) buildCreateIndexSQL(tableName string, index *Index, driver string) string {
+	columns := make([]string, len(index.Columns))
+	for i, col := range index.Columns {
+		columns[i] = am.quoteIdentifier(col, driver)
+	}
+
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+
+	sql := fmt.Sprintf("CREATE %sINDEX %s ON %s", unique, am.quoteIdentifier(index.Name, driver), am.quoteIdentifier(tableName, driver))
+	switch driver {
+	case "mysql":
+		sql += fmt.Sprintf(" (%s)", strings.Join(columns, ", "))
+		if index.Type != "" {
+			sql += " USING " + strings.ToUpper(index.Type)
+		}
+	case "postgres", "postgresql":
+		if index.Type != "" {
+			sql += " USING " + index.Type
+		}
+		sql += fmt.Sprintf(" (%s)", strings.Join(columns, ", "))
+	default:
+		sql += fmt.Sprintf(" (%s)", strings.Join(columns, ", "))
+	}
+
+	return sql
+}
+
 // createForeignKeys 创建外键约束
 func (am *AutoMigrator) createForeignKeys(tableName string, columns []ModelColumn, driver string) error {
 	// SQLite外键约束必须在创建表时指定，不能后续添加
@@ -600,15 +800,16 @@ func (am *AutoMigrator) createForeignKeys(tableName string, columns []ModelColum
 // parseForeignKeyReference 解析外键引用
 func (am *AutoMigrator) parseForeignKeyReference(reference string) (table, column string) {
 	// 支持 "table.column" 和 "table(column)" 格式
+	// 被引用的表同样加上表前缀
 	if strings.Contains(reference, ".") {
 		parts := strings.Split(reference, ".")
 		if len(parts) == 2 {
-			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			return prefixTable(am.connection, strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
 		}
 	} else if strings.Contains(reference, "(") && strings.Contains(reference, ")") {
 		table = strings.TrimSpace(reference[:strings.Index(reference, "(")])
 		column = strings.TrimSpace(reference[strings.Index(reference, "(")+1 : strings.Index(reference, ")")])
-		return table, column
+		return prefixTable(am.connection, table), column
 	}
 
 	return "", ""
@@ -638,7 +839,7 @@ func (am *AutoMigrator) updateTableStructure(tableName string, modelColumns []Mo
 	}
 
 	// 比较列差异
-	toAdd, toModify, err := am.compareColumns(existingColumns, modelColumns)
+	toRename, toAdd, toModify, err := am.compareColumns(existingColumns, modelColumns)
 	if err != nil {
 		return fmt.Errorf("比较列差异失败: %w", err)
 	}
@@ -646,6 +847,14 @@ func (am *AutoMigrator) updateTableStructure(tableName string, modelColumns []Mo
 	// 执行表结构更新
 	var alterCount int
 
+	// 重命名列（先于添加，避免 rename_from 列被当作新列添加导致数据丢失）
+	for _, column := range toRename {
+		if err := am.renameColumn(tableName, column.RenameFrom, column); err != nil {
+			return fmt.Errorf("重命名列 %s 为 %s 失败: %w", column.RenameFrom, column.Name, err)
+		}
+		alterCount++
+	}
+
 	// 添加新列
 	for _, column := range toAdd {
 		if err := am.addColumn(tableName, column); err != nil {
@@ -672,82 +881,21 @@ func (am *AutoMigrator) updateTableStructure(tableName string, modelColumns []Mo
 
 // getTableColumns 获取表的现有列信息
 func (am *AutoMigrator) getTableColumns(tableName string) (map[string]ModelColumn, error) {
-	driver := am.getDriverType()
-	columns := make(map[string]ModelColumn)
-
-	var query string
-	var args []interface{}
-
-	switch driver {
-	case "mysql":
-		query = "DESCRIBE " + tableName
-	case "postgres":
-		query = `SELECT 
-			column_name,
-			data_type,
-			is_nullable,
-			column_default,
-			character_maximum_length,
-			numeric_precision,
-			numeric_scale
-		FROM information_schema.columns 
-		WHERE table_name = $1 
-		ORDER BY ordinal_position`
-		args = []interface{}{tableName}
-	case "sqlite":
-		query = "PRAGMA table_info(" + tableName + ")"
-	default:
-		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
-	}
-
-	rows, err := am.connection.Query(query, args...)
+	list, err := NewInspector(am.connection).Columns(tableName)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var column ModelColumn
-		switch driver {
-		case "mysql":
-			var field, fieldType, null, key, extra string
-			var defaultVal sql.NullString
-			if err := rows.Scan(&field, &fieldType, &null, &key, &defaultVal, &extra); err != nil {
-				return nil, err
-			}
-			defaultValue := ""
-			if defaultVal.Valid {
-				defaultValue = defaultVal.String
-			}
-			column = am.parseMySQLColumn(field, fieldType, null, key, defaultValue, extra)
-		case "postgres":
-			var columnName, dataType, isNullable string
-			var columnDefault sql.NullString
-			var charMaxLength, numericPrecision, numericScale sql.NullInt64
-			if err := rows.Scan(&columnName, &dataType, &isNullable, &columnDefault,
-				&charMaxLength, &numericPrecision, &numericScale); err != nil {
-				return nil, err
-			}
-			column = am.parsePostgreSQLColumn(columnName, dataType, isNullable, columnDefault,
-				charMaxLength, numericPrecision, numericScale)
-		case "sqlite":
-			var cid int
-			var name, colType string
-			var notNull, pk int
-			var defaultVal sql.NullString
-			if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
-				return nil, err
-			}
-			column = am.parseSQLiteColumn(name, colType, notNull, pk, defaultVal)
-		}
+	columns := make(map[string]ModelColumn, len(list))
+	for _, column := range list {
 		columns[column.Name] = column
 	}
-
 	return columns, nil
 }
 
-// compareColumns 比较现有列和模型列，返回需要添加和修改的列
-func (am *AutoMigrator) compareColumns(existing map[string]ModelColumn, model []ModelColumn) ([]ModelColumn, []ModelColumn, error) {
+// compareColumns 比较现有列和模型列，返回需要重命名、添加和修改的列
+func (am *AutoMigrator) compareColumns(existing map[string]ModelColumn, model []ModelColumn) ([]ModelColumn, []ModelColumn, []ModelColumn, error) {
+	var toRename []ModelColumn
 	var toAdd []ModelColumn
 	var toModify []ModelColumn
 
@@ -755,6 +903,17 @@ func (am *AutoMigrator) compareColumns(existing map[string]ModelColumn, model []
 	for _, modelCol := range model {
 		existingCol, exists := existing[modelCol.Name]
 
+		if !exists && modelCol.RenameFrom != "" {
+			// 新列名不存在但旧列名存在，执行重命名
+			if oldCol, found := existing[modelCol.RenameFrom]; found {
+				toRename = append(toRename, modelCol)
+				if am.columnNeedsUpdate(oldCol, modelCol) {
+					toModify = append(toModify, modelCol)
+				}
+				continue
+			}
+		}
+
 		if !exists {
 			// 列不存在，需要添加
 			toAdd = append(toAdd, modelCol)
@@ -766,7 +925,7 @@ func (am *AutoMigrator) compareColumns(existing map[string]ModelColumn, model []
 		}
 	}
 
-	return toAdd, toModify, nil
+	return toRename, toAdd, toModify, nil
 }
 
 // columnNeedsUpdate 检查列是否需要更新
@@ -800,6 +959,14 @@ func (am *AutoMigrator) columnNeedsUpdate(existing, model ModelColumn) bool {
 		}
 	}
 
+	// 非MySQL数据库的枚举列以VARCHAR存储
+	if model.Type == ColumnTypeEnum && (driver != "mysql" || len(model.EnumValues) == 0) {
+		model.Type = ColumnTypeVarchar
+		if model.Length <= 0 {
+			model.Length = 255
+		}
+	}
+
 	// 简单比较，后续可以扩展
 	if existing.Type != model.Type {
 		return true
@@ -823,6 +990,40 @@ func (am *AutoMigrator) addColumn(tableName string, column ModelColumn) error {
 	return am.execSQL(sql)
 }
 
+// RenameColumn 重命名表中的列
+func (am *AutoMigrator) RenameColumn(tableName, oldName, newName string) error {
+	if err := NewSchemaBuilder(am.connection).RenameColumn(tableName, oldName, newName); err != nil {
+		return err
+	}
+
+	// 表结构已变化，清除结构缓存
+	delete(am.structureCache, tableName)
+	return nil
+}
+
+// renameColumn 按模型列定义重命名列
+func (am *AutoMigrator) renameColumn(tableName, oldName string, column ModelColumn) error {
+	legacy := am.getDriverType() == "mysql" && !mysqlSupportsRenameColumn(am.connection)
+	sql := am.buildRenameColumnSQL(tableName, oldName, column, legacy)
+	return am.execSQL(sql)
+}
+
+// buildRenameColumnSQL 构建重命名列的SQL，legacy 为 true 时使用旧版本MySQL的 CHANGE 语法
+func (am *AutoMigrator) buildRenameColumnSQL(tableName, oldName string, column ModelColumn, legacy bool) string {
+	driver := am.getDriverType()
+
+	if driver == "mysql" && legacy {
+		// CHANGE 需要完整的新列定义
+		columnSQL := am.buildColumnDefinition(column, driver)
+		return fmt.Sprintf("ALTER TABLE %s CHANGE %s %s",
+			am.quoteIdentifier(tableName, driver), am.quoteIdentifier(oldName, driver), columnSQL)
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+		am.quoteIdentifier(tableName, driver), am.quoteIdentifier(oldName, driver),
+		am.quoteIdentifier(column.Name, driver))
+}
+
 // modifyColumn 修改现有列
 func (am *AutoMigrator) modifyColumn(tableName string, column ModelColumn) error {
 	sql := am.buildModifyColumnSQL(tableName, column)
@@ -890,205 +1091,128 @@ func (am *AutoMigrator) getDriverType() string {
 	return "mysql"
 }
 
-// parseMySQLColumn 解析MySQL列信息
-func (am *AutoMigrator) parseMySQLColumn(field, fieldType, null, key, defaultVal, extra string) ModelColumn {
-	column := ModelColumn{
-		Name:    field,
-		NotNull: null == "NO",
-	}
-
-	// 解析类型和长度
-	if strings.Contains(fieldType, "(") {
-		parts := strings.Split(fieldType, "(")
-		column.Type = ColumnType(strings.ToUpper(parts[0]))
-		if len(parts) > 1 {
-			sizeStr := strings.TrimSuffix(parts[1], ")")
-			if sizeStr != "" {
-				// 简单处理长度，转换为int
-				var length int
-				fmt.Sscanf(sizeStr, "%d", &length)
-				column.Length = length
+// addIndexesAndConstraints 添加索引和约束
+func (am *AutoMigrator) addIndexesAndConstraints(tableName string, columns []ModelColumn) error {
+	for _, column := range columns {
+		// 创建普通索引
+		if column.Index && column.IndexName == "" && !column.Unique && !column.PrimaryKey {
+			index := &Index{Name: fmt.Sprintf("idx_%s_%s", tableName, column.Name), Columns: []string{column.Name}}
+			if err := am.createIndex(tableName, index); err != nil {
+				return err
 			}
 		}
-	} else {
-		column.Type = ColumnType(strings.ToUpper(fieldType))
-	}
 
-	// 处理默认值
-	if defaultVal != "" && defaultVal != "NULL" {
-		column.Default = defaultVal
+		// 创建单列唯一索引
+		if column.Unique && !column.PrimaryKey {
+			index := &Index{Name: fmt.Sprintf("idx_%s_%s_unique", tableName, column.Name), Columns: []string{column.Name}, Unique: true}
+			if err := am.createIndex(tableName, index); err != nil {
+				return err
+			}
+		}
 	}
 
-	// 处理主键
-	if key == "PRI" {
-		column.PrimaryKey = true
+	// 创建命名索引和复合索引
+	for _, index := range am.collectNamedIndexes(columns) {
+		if err := am.createIndex(tableName, index); err != nil {
+			return err
+		}
 	}
 
-	// 处理自增
-	if strings.Contains(extra, "auto_increment") {
-		column.AutoIncrement = true
+	// 添加表级唯一约束，已存在（同名或相同列的唯一索引）时跳过
+	driver := am.getDriverType()
+	for _, constraint := range am.collectUniqueConstraints(columns) {
+		if am.uniqueConstraintExists(tableName, constraint) {
+			continue
+		}
+		if err := am.execSQL(am.buildAddUniqueConstraintSQL(tableName, constraint, driver)); err != nil {
+			return fmt.Errorf("添加唯一约束 %s 失败: %w", constraint.Name, err)
+		}
 	}
-
-	return column
+	return nil
 }
 
-// parsePostgreSQLColumn 解析PostgreSQL列信息
-func (am *AutoMigrator) parsePostgreSQLColumn(columnName, dataType, isNullable string,
-	columnDefault sql.NullString, charMaxLength, numericPrecision, numericScale sql.NullInt64) ModelColumn {
-
-	column := ModelColumn{
-		Name:    columnName,
-		NotNull: isNullable == "NO",
+// uniqueConstraintExists 检查唯一约束是否已存在
+// MySQL 和 PostgreSQL 的唯一约束会创建同名索引；SQLite 建表时定义的约束使用自动生成的索引名，按列比较
+func (am *AutoMigrator) uniqueConstraintExists(tableName string, constraint *Index) bool {
+	if am.indexExists(tableName, constraint.Name) {
+		return true
+	}
+	driver := am.getDriverType()
+	if driver != "sqlite" && driver != "sqlite3" {
+		return false
 	}
 
-	// 解析类型
-	switch strings.ToLower(dataType) {
-	case "integer", "int4":
-		column.Type = ColumnTypeInt
-		// 检查是否是SERIAL类型（通过默认值判断）
-		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
-			column.Type = ColumnType("SERIAL")
-			column.AutoIncrement = true
-		}
-	case "bigint", "int8":
-		column.Type = ColumnTypeBigInt
-		// 检查是否是BIGSERIAL类型
-		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
-			column.Type = ColumnType("BIGSERIAL")
-			column.AutoIncrement = true
+	rows, err := am.connection.Query(fmt.Sprintf("PRAGMA index_list(%s)", am.quoteIdentifier(tableName, driver)))
+	if err != nil {
+		return false
+	}
+	var uniqueIndexes []string
+	for rows.Next() {
+		values, err := scanRowValues(rows)
+		if err != nil {
+			rows.Close()
+			return false
 		}
-	case "smallint", "int2":
-		column.Type = ColumnTypeSmallInt
-		// 检查是否是SMALLSERIAL类型
-		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
-			column.Type = ColumnType("SMALLSERIAL")
-			column.AutoIncrement = true
+		// index_list 列：seq, name, unique, origin, partial
+		if len(values) >= 3 && fmt.Sprint(values[2]) == "1" {
+			uniqueIndexes = append(uniqueIndexes, fmt.Sprint(values[1]))
 		}
-	case "character varying", "varchar":
-		column.Type = ColumnTypeVarchar
-		if charMaxLength.Valid {
-			column.Length = int(charMaxLength.Int64)
+	}
+	rows.Close()
+
+	for _, indexName := range uniqueIndexes {
+		rows, err := am.connection.Query(fmt.Sprintf("PRAGMA index_info(%s)", am.quoteIdentifier(indexName, driver)))
+		if err != nil {
+			continue
 		}
-	case "text":
-		column.Type = ColumnTypeText
-	case "timestamp without time zone", "timestamp":
-		column.Type = ColumnTypeTimestamp
-	case "boolean":
-		column.Type = ColumnTypeBoolean
-	case "numeric", "decimal":
-		column.Type = ColumnTypeDecimal
-		if numericPrecision.Valid {
-			column.Precision = int(numericPrecision.Int64)
+		var columns []string
+		for rows.Next() {
+			values, err := scanRowValues(rows)
+			// index_info 列：seqno, cid, name
+			if err == nil && len(values) >= 3 {
+				columns = append(columns, fmt.Sprint(values[2]))
+			}
 		}
-		if numericScale.Valid {
-			column.Scale = int(numericScale.Int64)
+		rows.Close()
+		if strings.Join(columns, ",") == strings.Join(constraint.Columns, ",") {
+			return true
 		}
-	default:
-		column.Type = ColumnType(strings.ToUpper(dataType))
 	}
-
-	// 处理默认值
-	if columnDefault.Valid && columnDefault.String != "" {
-		column.Default = columnDefault.String
-	}
-
-	return column
+	return false
 }
 
-// parseSQLiteColumn 解析SQLite列信息
-func (am *AutoMigrator) parseSQLiteColumn(name, colType string, notNull, pk int, defaultVal sql.NullString) ModelColumn {
-	column := ModelColumn{
-		Name:    name,
-		NotNull: notNull == 1,
-	}
-
-	// 解析类型
-	upperType := strings.ToUpper(colType)
-	if strings.Contains(upperType, "(") {
-		parts := strings.Split(upperType, "(")
-		column.Type = ColumnType(parts[0])
-		if len(parts) > 1 {
-			sizeStr := strings.TrimSuffix(parts[1], ")")
-			if sizeStr != "" {
-				var length int
-				fmt.Sscanf(sizeStr, "%d", &length)
-				column.Length = length
-			}
-		}
-	} else {
-		column.Type = ColumnType(upperType)
+// scanRowValues 按结果集的列数扫描当前行，用于列数随数据库版本变化的 PRAGMA 结果
+func scanRowValues(rows *sql.Rows) ([]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
 	}
-
-	// 处理默认值
-	if defaultVal.Valid && defaultVal.String != "" {
-		column.Default = defaultVal.String
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
 	}
-
-	// 处理主键
-	if pk == 1 {
-		column.PrimaryKey = true
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
 	}
-
-	return column
-}
-
-// addIndexesAndConstraints 添加索引和约束
-func (am *AutoMigrator) addIndexesAndConstraints(tableName string, columns []ModelColumn) error {
-	for _, column := range columns {
-		// 创建普通索引
-		if column.Index && !column.Unique && !column.PrimaryKey {
-			indexName := fmt.Sprintf("idx_%s_%s", tableName, column.Name)
-			if err := am.createIndex(tableName, indexName, column.Name, false); err != nil {
-				fmt.Printf("  ⚠️ 创建索引 %s 失败: %v\n", indexName, err)
-			}
-		}
-
-		// 创建唯一约束/唯一索引
-		if column.Unique && !column.PrimaryKey {
-			indexName := fmt.Sprintf("idx_%s_%s_unique", tableName, column.Name)
-			if err := am.createIndex(tableName, indexName, column.Name, true); err != nil {
-				fmt.Printf("  ⚠️ 创建唯一约束 %s 失败: %v\n", indexName, err)
-			}
+	for i, value := range values {
+		if b, ok := value.([]byte); ok {
+			values[i] = string(b)
 		}
 	}
-	return nil
+	return values, nil
 }
 
-// createIndex 创建索引
-func (am *AutoMigrator) createIndex(tableName, indexName, columnName string, unique bool) error {
-	var sql string
-
-	// 检查索引是否已存在
-	if am.indexExists(tableName, indexName) {
-		return nil // 索引已存在，跳过
+// createIndex 为已存在的表创建索引，索引已存在时跳过
+func (am *AutoMigrator) createIndex(tableName string, index *Index) error {
+	if am.indexExists(tableName, index.Name) {
+		return nil
 	}
 
-	driver := am.connection.GetDriver()
-	switch driver {
-	case "mysql":
-		if unique {
-			sql = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		} else {
-			sql = fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		}
-	case "postgres":
-		if unique {
-			sql = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		} else {
-			sql = fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		}
-	case "sqlite":
-		if unique {
-			sql = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		} else {
-			sql = fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, columnName)
-		}
-	default:
-		return fmt.Errorf("不支持的数据库类型: %s", driver)
+	if _, err := am.connection.Exec(am.buildCreateIndexSQL(tableName, index, am.connection.GetDriver())); err != nil {
+		return fmt.Errorf("创建索引 %s 失败: %w", index.Name, err)
 	}
-
-	_, err := am.connection.Exec(sql)
-	return err
+	return nil
 }
 
 // indexExists 检查索引是否存在
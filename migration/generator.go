@@ -0,0 +1,284 @@
+package migration
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/zhoudm1743/torm/db"
+)
+
+// commonInitialisms 生成字段名时保持全大写的常见缩写
+var commonInitialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "html": true,
+	"http": true, "sql": true, "uid": true, "url": true, "uuid": true,
+}
+
+// reservedFieldNames 不能直接用作字段名的标识符：嵌入的 BaseModel 和 model.BaseModel 的方法
+// （包括生成的 TableName），同名字段会与方法冲突或遮蔽方法，BaseModel 新增方法时需要同步
+var reservedFieldNames = map[string]bool{
+	"BaseModel": true, "AutoMigrate": true, "BelongsTo": true, "BelongsToMany": true,
+	"ClearAttributes": true, "Context": true, "Count": true, "CountOnlyTrashed": true,
+	"CountWithTrashed": true, "Delete": true, "DisableSoftDeletes": true, "DisableTimestamps": true,
+	"EnableSoftDeletes": true, "EnableTimestamps": true, "Exists": true, "ExistsOnlyTrashed": true,
+	"ExistsWithTrashed": true, "Fill": true, "FillGuarded": true, "Find": true, "FindByKeys": true,
+	"FindByPK": true, "First": true, "FirstRaw": true, "ForceDelete": true, "FromJSON": true,
+	"Get": true, "GetAttribute": true, "GetAttributes": true, "GetChanges": true,
+	"GetConnection": true, "GetCreatedAtField": true, "GetDirty": true, "GetFillable": true,
+	"GetGuarded": true, "GetKey": true, "GetKeys": true, "GetOriginal": true, "GetPrimaryKey": true,
+	"GetPrimaryKeys": true, "GetRaw": true, "GetTableName": true, "GetTransaction": true,
+	"GetUpdatedAtField": true, "GroupBy": true, "Has": true, "HasCompositePrimaryKey": true,
+	"HasMany": true, "HasManyThrough": true, "HasOne": true, "Having": true, "IsDirty": true,
+	"IsExists": true, "IsFillable": true, "IsNew": true, "Join": true, "LastInsertID": true,
+	"LeftJoin": true, "Limit": true, "MarkAsExists": true, "MarkAsNew": true, "MorphMany": true,
+	"MorphTo": true, "Offset": true, "On": true, "OnlyTrashed": true, "OrWhere": true,
+	"OrderBy": true, "Page": true, "Query": true, "Refresh": true, "RegisterGlobalScope": true,
+	"RemoveGlobalScope": true, "Restore": true, "RightJoin": true, "RowsAffected": true, "Save": true,
+	"SaveAndRefresh": true, "Select": true, "SetAttribute": true, "SetAttributeWithAccessor": true,
+	"SetAttributes": true, "SetAttributesWithAccessor": true, "SetConnection": true,
+	"SetConnectionResolver": true, "SetCreatedAtField": true, "SetDeletedAtField": true,
+	"SetFillable": true, "SetGuarded": true, "SetInstance": true, "SetKey": true,
+	"SetPrimaryKey": true, "SetPrimaryKeys": true, "SetTable": true, "SetUpdatedAtField": true,
+	"SoftDelete": true, "TableName": true, "ToJSON": true, "ToMap": true, "UseDefault": true,
+	"Where": true, "WhereBetween": true, "WhereDoesntHave": true, "WhereHas": true, "WhereIn": true,
+	"WhereKey": true, "WhereKeyNot": true, "WhereNotBetween": true, "WhereNotIn": true,
+	"WhereNotNull": true, "WhereNull": true, "WhereRaw": true, "WithContext": true,
+	"WithTransaction": true, "WithTrashed": true, "WithoutTransaction": true,
+}
+
+// GenerateModels 根据现有数据库表生成模型结构体文件（反向迁移）
+// 每张表生成一个 <表名>.go 文件，包名取输出目录名；不指定表时生成所有表
+// 配置了表前缀时，生成的 TableName 返回去掉前缀的表名，与查询构建器保持一致
+func GenerateModels(conn db.ConnectionInterface, outputDir string, tables ...string) error {
+	inspector := NewInspector(conn)
+	if len(tables) == 0 {
+		all, err := inspector.Tables()
+		if err != nil {
+			return fmt.Errorf("获取表列表失败: %w", err)
+		}
+		tables = all
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	prefix := ""
+	if config := conn.GetConfig(); config != nil {
+		prefix = config.Prefix
+	}
+	packageName := goPackageName(outputDir)
+
+	for _, table := range tables {
+		columns, err := inspector.Columns(table)
+		if err != nil {
+			return fmt.Errorf("获取表 %s 的列信息失败: %w", table, err)
+		}
+		if len(columns) == 0 {
+			return fmt.Errorf("表 %s 不存在或没有列", table)
+		}
+
+		source, err := generateModelSource(packageName, strings.TrimPrefix(table, prefix), columns)
+		if err != nil {
+			return fmt.Errorf("生成表 %s 的模型失败: %w", table, err)
+		}
+
+		filename := filepath.Join(outputDir, strings.TrimPrefix(table, prefix)+".go")
+		if err := os.WriteFile(filename, source, 0644); err != nil {
+			return fmt.Errorf("写入模型文件 %s 失败: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// generateModelSource 生成单张表的模型源码
+func generateModelSource(packageName, table string, columns []ModelColumn) ([]byte, error) {
+	structName := goIdentifier(table)
+
+	var fields strings.Builder
+	usesTime, usesBig := false, false
+	usedNames := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		goType := goTypeForColumn(column)
+		if strings.Contains(goType, "time.Time") {
+			usesTime = true
+		}
+		if strings.Contains(goType, "big.Rat") {
+			usesBig = true
+		}
+
+		// 字段名与保留名或其他字段冲突时改名，并用 column 标签保留原列名
+		tag := tormTagForColumn(column)
+		fieldName := goIdentifier(column.Name)
+		if reservedFieldNames[fieldName] {
+			fieldName += "Field"
+		}
+		for base, n := fieldName, 2; usedNames[fieldName]; n++ {
+			fieldName = fmt.Sprintf("%s%d", base, n)
+		}
+		usedNames[fieldName] = true
+		if fieldName != goIdentifier(column.Name) {
+			tag = "column:" + column.Name + "," + tag
+		}
+
+		fields.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\" torm:\"%s\"`\n",
+			fieldName, goType, column.Name, tag))
+	}
+
+	var src strings.Builder
+	src.WriteString("// 由 torm GenerateModels 根据数据库表结构生成，可按需修改\n\n")
+	src.WriteString("package " + packageName + "\n\n")
+	src.WriteString("import (\n")
+	if usesBig {
+		src.WriteString("\t\"math/big\"\n")
+	}
+	if usesTime {
+		src.WriteString("\t\"time\"\n")
+	}
+	if usesBig || usesTime {
+		src.WriteString("\n")
+	}
+	src.WriteString("\t\"github.com/zhoudm1743/torm/model\"\n)\n\n")
+	src.WriteString(fmt.Sprintf("// %s 对应数据表 %s\n", structName, table))
+	src.WriteString(fmt.Sprintf("type %s struct {\n\tmodel.BaseModel\n", structName))
+	src.WriteString(fields.String())
+	src.WriteString("}\n\n")
+	src.WriteString("// TableName 返回表名\n")
+	src.WriteString(fmt.Sprintf("func (m *%s) TableName() string {\n\treturn %q\n}\n", structName, table))
+
+	return format.Source([]byte(src.String()))
+}
+
+// goTypeForColumn 将数据库列类型映射为Go类型，可为空的列使用指针
+// 定点数列按 db.GetDecimalMode 映射为 float64、string 或 *big.Rat，与查询结果的类型一致
+func goTypeForColumn(column ModelColumn) string {
+	var goType string
+
+	dbType := strings.ToUpper(string(column.Type))
+	switch {
+	case dbType == "TINYINT" && column.Length == 1, dbType == "BOOLEAN", dbType == "BOOL", dbType == "BIT":
+		goType = "bool"
+	case dbType == "TINYINT":
+		goType = "int8"
+	case dbType == "SMALLINT", dbType == "SMALLSERIAL":
+		goType = "int16"
+	case strings.Contains(dbType, "INT"), strings.Contains(dbType, "SERIAL"):
+		goType = "int64"
+	case dbType == "FLOAT", dbType == "REAL":
+		goType = "float32"
+	case dbType == "DECIMAL", dbType == "NUMERIC", dbType == "MONEY", dbType == "SMALLMONEY":
+		switch db.GetDecimalMode() {
+		case db.DecimalAsString:
+			goType = "string"
+		case db.DecimalAsRat:
+			return "*big.Rat"
+		default:
+			goType = "float64"
+		}
+	case dbType == "DOUBLE", dbType == "DOUBLE PRECISION":
+		goType = "float64"
+	case strings.HasPrefix(dbType, "DATETIME"), strings.HasPrefix(dbType, "TIMESTAMP"), dbType == "DATE":
+		goType = "time.Time"
+	case strings.Contains(dbType, "BLOB"), strings.Contains(dbType, "BINARY"), dbType == "BYTEA":
+		return "[]byte"
+	default:
+		goType = "string"
+	}
+
+	if !column.NotNull && !column.PrimaryKey {
+		return "*" + goType
+	}
+	return goType
+}
+
+// tormTagForColumn 生成列的torm标签
+func tormTagForColumn(column ModelColumn) string {
+	var parts []string
+	if column.PrimaryKey {
+		parts = append(parts, "primary_key")
+	}
+	if column.AutoIncrement {
+		parts = append(parts, "auto_increment")
+	}
+
+	parts = append(parts, "type:"+strings.ToLower(string(column.Type)))
+	if column.Length > 0 {
+		parts = append(parts, fmt.Sprintf("size:%d", column.Length))
+	}
+	if column.Precision > 0 {
+		parts = append(parts, fmt.Sprintf("precision:%d", column.Precision))
+	}
+	if column.Scale > 0 {
+		parts = append(parts, fmt.Sprintf("scale:%d", column.Scale))
+	}
+
+	if column.NotNull && !column.PrimaryKey {
+		parts = append(parts, "not_null")
+	} else if !column.NotNull && !column.PrimaryKey {
+		parts = append(parts, "nullable")
+	}
+
+	// 函数和表达式默认值（如 nextval(...)、CURRENT_TIMESTAMP）由数据库维护，不写入标签
+	if value, ok := column.Default.(string); ok {
+		value = strings.Trim(value, "'")
+		if value != "" && !strings.ContainsAny(value, "(),\"`") && !strings.EqualFold(value, "CURRENT_TIMESTAMP") {
+			parts = append(parts, "default:"+value)
+		}
+	}
+
+	switch column.Name {
+	case "created_at":
+		parts = append(parts, "auto_create_time")
+	case "updated_at":
+		parts = append(parts, "auto_update_time")
+	case "deleted_at":
+		parts = append(parts, "soft_delete")
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// goIdentifier 将蛇形命名转换为导出的驼峰命名，常见缩写保持全大写
+func goIdentifier(name string) string {
+	var result strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		lower := strings.ToLower(word)
+		if commonInitialisms[lower] {
+			result.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		runes := []rune(lower)
+		runes[0] = unicode.ToUpper(runes[0])
+		result.WriteString(string(runes))
+	}
+
+	identifier := result.String()
+	if identifier == "" || unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "T" + identifier
+	}
+	return identifier
+}
+
+// goPackageName 由输出目录名得到合法的包名
+func goPackageName(outputDir string) string {
+	abs, err := filepath.Abs(outputDir)
+	if err != nil {
+		abs = outputDir
+	}
+
+	var name strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(abs)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			name.WriteRune(r)
+		}
+	}
+	if name.Len() == 0 || unicode.IsDigit([]rune(name.String())[0]) {
+		return "models"
+	}
+	return name.String()
+}
@@ -0,0 +1,449 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zhoudm1743/torm/db"
+)
+
+// Inspector 数据库结构查询器，列出表并读取列信息，可用于管理界面、结构对比等工具
+type Inspector struct {
+	connection db.ConnectionInterface
+}
+
+// NewInspector 创建结构查询器
+func NewInspector(conn db.ConnectionInterface) *Inspector {
+	return &Inspector{connection: conn}
+}
+
+// Inspect 创建指定连接的结构查询器，默认连接为 default
+func Inspect(connectionName ...string) (*Inspector, error) {
+	connName := "default"
+	if len(connectionName) > 0 {
+		connName = connectionName[0]
+	}
+
+	conn, err := db.DB(connName)
+	if err != nil {
+		return nil, err
+	}
+	return NewInspector(conn), nil
+}
+
+// Tables 列出当前数据库中的所有表，按名称排序
+func (i *Inspector) Tables() ([]string, error) {
+	var query string
+
+	switch driver := i.connection.GetDriver(); driver {
+	case "mysql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case "sqlite", "sqlite3":
+		query = "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	case "postgres", "postgresql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case "sqlserver", "mssql":
+		query = "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = SCHEMA_NAME() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+
+	rows, err := i.connection.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// HasTable 检查表是否存在
+func (i *Inspector) HasTable(tableName string) (bool, error) {
+	var query string
+
+	switch driver := i.connection.GetDriver(); driver {
+	case "mysql":
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case "sqlite", "sqlite3":
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?"
+	case "postgres", "postgresql":
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	case "sqlserver", "mssql":
+		query = "SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = SCHEMA_NAME() AND TABLE_TYPE = 'BASE TABLE' AND TABLE_NAME = @p1"
+	default:
+		return false, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+
+	var count int
+	if err := i.connection.QueryRow(query, tableName).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Columns 按定义顺序返回表的列信息（名称、类型、是否可空、默认值、主键、自增）
+func (i *Inspector) Columns(tableName string) ([]ModelColumn, error) {
+	driver := i.connection.GetDriver()
+
+	var query string
+	args := []interface{}{tableName}
+
+	switch driver {
+	case "mysql":
+		// DESCRIBE 不支持参数绑定，表名按标识符转义后引用
+		query = "DESCRIBE `" + strings.ReplaceAll(tableName, "`", "``") + "`"
+		args = nil
+	case "postgres", "postgresql":
+		query = `SELECT 
+			column_name,
+			data_type,
+			is_nullable,
+			column_default,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale
+		FROM information_schema.columns 
+		WHERE table_schema = current_schema() AND table_name = $1 
+		ORDER BY ordinal_position`
+	case "sqlserver", "mssql":
+		query = `SELECT 
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.IS_NULLABLE,
+			c.COLUMN_DEFAULT,
+			c.CHARACTER_MAXIMUM_LENGTH,
+			c.NUMERIC_PRECISION,
+			c.NUMERIC_SCALE,
+			COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity')
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		WHERE c.TABLE_SCHEMA = SCHEMA_NAME() AND c.TABLE_NAME = @p1
+		ORDER BY c.ORDINAL_POSITION`
+	case "sqlite", "sqlite3":
+		// 使用 pragma_table_info 表值函数绑定表名，避免拼接 PRAGMA 语句
+		query = `SELECT cid, name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+
+	rows, err := i.connection.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ModelColumn
+	for rows.Next() {
+		var column ModelColumn
+		switch driver {
+		case "mysql":
+			var field, fieldType, null, key, extra string
+			var defaultVal sql.NullString
+			if err := rows.Scan(&field, &fieldType, &null, &key, &defaultVal, &extra); err != nil {
+				return nil, err
+			}
+			defaultValue := ""
+			if defaultVal.Valid {
+				defaultValue = defaultVal.String
+			}
+			column = parseMySQLColumn(field, fieldType, null, key, defaultValue, extra)
+		case "postgres", "postgresql":
+			var columnName, dataType, isNullable string
+			var columnDefault sql.NullString
+			var charMaxLength, numericPrecision, numericScale sql.NullInt64
+			if err := rows.Scan(&columnName, &dataType, &isNullable, &columnDefault,
+				&charMaxLength, &numericPrecision, &numericScale); err != nil {
+				return nil, err
+			}
+			column = parsePostgreSQLColumn(columnName, dataType, isNullable, columnDefault,
+				charMaxLength, numericPrecision, numericScale)
+		case "sqlserver", "mssql":
+			var columnName, dataType, isNullable string
+			var columnDefault sql.NullString
+			var charMaxLength, numericPrecision, numericScale, isIdentity sql.NullInt64
+			if err := rows.Scan(&columnName, &dataType, &isNullable, &columnDefault,
+				&charMaxLength, &numericPrecision, &numericScale, &isIdentity); err != nil {
+				return nil, err
+			}
+			column = parseSQLServerColumn(columnName, dataType, isNullable, columnDefault,
+				charMaxLength, numericPrecision, numericScale, isIdentity.Int64 == 1)
+		default:
+			var cid int
+			var name, colType string
+			var notNull, pk int
+			var defaultVal sql.NullString
+			if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+				return nil, err
+			}
+			column = parseSQLiteColumn(name, colType, notNull, pk, defaultVal)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	switch driver {
+	case "postgres", "postgresql":
+		if err := i.markPrimaryKeys(`SELECT kcu.column_name
+			FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage kcu
+				ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+			WHERE tc.table_schema = current_schema() AND tc.table_name = $1 AND tc.constraint_type = 'PRIMARY KEY'`, tableName, columns); err != nil {
+			return nil, err
+		}
+	case "sqlserver", "mssql":
+		if err := i.markPrimaryKeys(`SELECT kcu.COLUMN_NAME
+			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+				ON tc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME AND tc.TABLE_SCHEMA = kcu.TABLE_SCHEMA
+			WHERE tc.TABLE_SCHEMA = SCHEMA_NAME() AND tc.TABLE_NAME = @p1 AND tc.CONSTRAINT_TYPE = 'PRIMARY KEY'`, tableName, columns); err != nil {
+			return nil, err
+		}
+	case "sqlite", "sqlite3":
+		// 复合主键中的 INTEGER 列不是 rowid 的别名，不会自动分配
+		primaryKeys := 0
+		for _, column := range columns {
+			if column.PrimaryKey {
+				primaryKeys++
+			}
+		}
+		if primaryKeys > 1 {
+			for index := range columns {
+				columns[index].AutoIncrement = false
+			}
+		}
+	}
+	return columns, nil
+}
+
+// markPrimaryKeys 标记主键列，PostgreSQL 和 SQL Server 的 information_schema.columns 中不包含主键信息
+func (i *Inspector) markPrimaryKeys(query, tableName string, columns []ModelColumn) error {
+	rows, err := i.connection.Query(query, tableName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		for index := range columns {
+			if columns[index].Name == name {
+				columns[index].PrimaryKey = true
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// parseMySQLColumn 解析MySQL列信息
+func parseMySQLColumn(field, fieldType, null, key, defaultVal, extra string) ModelColumn {
+	column := ModelColumn{
+		Name:    field,
+		NotNull: null == "NO",
+	}
+
+	// 解析类型和长度
+	if strings.Contains(fieldType, "(") {
+		parts := strings.Split(fieldType, "(")
+		column.Type = ColumnType(strings.ToUpper(parts[0]))
+		if len(parts) > 1 {
+			sizeStr := strings.TrimSuffix(parts[1], ")")
+			if sizeStr != "" {
+				// 简单处理长度，转换为int
+				var length int
+				fmt.Sscanf(sizeStr, "%d", &length)
+				column.Length = length
+			}
+		}
+	} else {
+		column.Type = ColumnType(strings.ToUpper(fieldType))
+	}
+
+	// 处理默认值
+	if defaultVal != "" && defaultVal != "NULL" {
+		column.Default = defaultVal
+	}
+
+	// 处理主键
+	if key == "PRI" {
+		column.PrimaryKey = true
+	}
+
+	// 处理自增
+	if strings.Contains(extra, "auto_increment") {
+		column.AutoIncrement = true
+	}
+
+	return column
+}
+
+// parsePostgreSQLColumn 解析PostgreSQL列信息
+func parsePostgreSQLColumn(columnName, dataType, isNullable string,
+	columnDefault sql.NullString, charMaxLength, numericPrecision, numericScale sql.NullInt64) ModelColumn {
+
+	column := ModelColumn{
+		Name:    columnName,
+		NotNull: isNullable == "NO",
+	}
+
+	// 解析类型
+	switch strings.ToLower(dataType) {
+	case "integer", "int4":
+		column.Type = ColumnTypeInt
+		// 检查是否是SERIAL类型（通过默认值判断）
+		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
+			column.Type = ColumnType("SERIAL")
+			column.AutoIncrement = true
+		}
+	case "bigint", "int8":
+		column.Type = ColumnTypeBigInt
+		// 检查是否是BIGSERIAL类型
+		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
+			column.Type = ColumnType("BIGSERIAL")
+			column.AutoIncrement = true
+		}
+	case "smallint", "int2":
+		column.Type = ColumnTypeSmallInt
+		// 检查是否是SMALLSERIAL类型
+		if columnDefault.Valid && strings.Contains(columnDefault.String, "nextval") {
+			column.Type = ColumnType("SMALLSERIAL")
+			column.AutoIncrement = true
+		}
+	case "character varying", "varchar":
+		column.Type = ColumnTypeVarchar
+		if charMaxLength.Valid {
+			column.Length = int(charMaxLength.Int64)
+		}
+	case "text":
+		column.Type = ColumnTypeText
+	case "timestamp without time zone", "timestamp":
+		column.Type = ColumnTypeTimestamp
+	case "boolean":
+		column.Type = ColumnTypeBoolean
+	case "numeric", "decimal":
+		column.Type = ColumnTypeDecimal
+		if numericPrecision.Valid {
+			column.Precision = int(numericPrecision.Int64)
+		}
+		if numericScale.Valid {
+			column.Scale = int(numericScale.Int64)
+		}
+	default:
+		column.Type = ColumnType(strings.ToUpper(dataType))
+	}
+
+	// 处理默认值
+	if columnDefault.Valid && columnDefault.String != "" {
+		column.Default = columnDefault.String
+	}
+
+	return column
+}
+
+// parseSQLServerColumn 解析SQL Server列信息
+func parseSQLServerColumn(columnName, dataType, isNullable string,
+	columnDefault sql.NullString, charMaxLength, numericPrecision, numericScale sql.NullInt64, identity bool) ModelColumn {
+
+	column := ModelColumn{
+		Name:          columnName,
+		NotNull:       isNullable == "NO",
+		Type:          ColumnType(strings.ToUpper(dataType)),
+		AutoIncrement: identity,
+	}
+
+	switch column.Type {
+	case "DECIMAL", "NUMERIC":
+		if numericPrecision.Valid {
+			column.Precision = int(numericPrecision.Int64)
+		}
+		if numericScale.Valid {
+			column.Scale = int(numericScale.Int64)
+		}
+	default:
+		// -1 表示 MAX 长度
+		if charMaxLength.Valid && charMaxLength.Int64 > 0 {
+			column.Length = int(charMaxLength.Int64)
+		}
+	}
+
+	// 处理默认值，SQL Server 返回的默认值带有括号，如 ((0))、('guest')
+	if columnDefault.Valid && columnDefault.String != "" {
+		defaultVal := columnDefault.String
+		for isWrappedInParens(defaultVal) {
+			defaultVal = defaultVal[1 : len(defaultVal)-1]
+		}
+		column.Default = defaultVal
+	}
+
+	return column
+}
+
+// isWrappedInParens 判断表达式是否整体被一对括号包裹，如 (1) 是而 (1)+(2) 不是
+func isWrappedInParens(expr string) bool {
+	if len(expr) < 2 || expr[0] != '(' || expr[len(expr)-1] != ')' {
+		return false
+	}
+	depth := 0
+	for index, ch := range expr {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && index < len(expr)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// parseSQLiteColumn 解析SQLite列信息
+func parseSQLiteColumn(name, colType string, notNull, pk int, defaultVal sql.NullString) ModelColumn {
+	column := ModelColumn{
+		Name:    name,
+		NotNull: notNull == 1,
+	}
+
+	// 解析类型
+	upperType := strings.ToUpper(colType)
+	if strings.Contains(upperType, "(") {
+		parts := strings.Split(upperType, "(")
+		column.Type = ColumnType(parts[0])
+		if len(parts) > 1 {
+			sizeStr := strings.TrimSuffix(parts[1], ")")
+			if sizeStr != "" {
+				var length int
+				fmt.Sscanf(sizeStr, "%d", &length)
+				column.Length = length
+			}
+		}
+	} else {
+		column.Type = ColumnType(upperType)
+	}
+
+	// 处理默认值
+	if defaultVal.Valid && defaultVal.String != "" {
+		column.Default = defaultVal.String
+	}
+
+	// 处理主键，pk 为列在主键中的序号（从1开始），INTEGER 主键是 rowid 的别名，插入时自动分配
+	if pk > 0 {
+		column.PrimaryKey = true
+		column.AutoIncrement = column.Type == "INTEGER"
+	}
+
+	return column
+}
@@ -3,6 +3,7 @@ package migration
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -193,14 +194,50 @@ func (ma *ModelAnalyzer) ParseTormKeyValue(part string, column *ModelColumn) err
 	case "on_update":
 		// 更新时动作
 		column.OnUpdate = strings.ToLower(value)
+	case "rename_from", "renamed_from":
+		// 列重命名，值为旧列名，自动迁移时执行重命名而不是新增列
+		column.RenameFrom = value
 	case "generated":
 		// 生成列，值可以是 "virtual" 或 "stored"
 		column.Generated = strings.ToLower(value)
+	case "values", "enum":
+		// 枚举允许的取值，以 | 分隔: values:active|inactive|pending
+		column.EnumValues = ParseEnumValues(value)
+		if column.Type == "" || column.Type == ColumnTypeVarchar {
+			column.Type = ColumnTypeEnum
+		}
+	case "check":
+		// CHECK约束: check:age >= 0，表达式中不能包含逗号（逗号用于分隔标签项）
+		if err := ValidateCheckExpression(value); err != nil {
+			return err
+		}
+		column.Check = value
 	case "index":
 		// 带类型的索引: index:btree, index:hash, index:rtree
+		// 带名称的索引: index:idx_tenant_created，相同名称的列组成复合索引
 		column.Index = true
-		if value != "" {
+		switch strings.ToLower(value) {
+		case "":
+		case "btree", "hash", "rtree", "gin", "gist", "brin":
 			column.IndexType = strings.ToLower(value)
+		default:
+			column.IndexName = value
+		}
+	case "unique", "unique_index", "uniqueindex", "unique_idx":
+		// 带名称的表级唯一约束: unique:uk_tenant_email，相同名称的列组成复合唯一约束
+		// unique_index:name 是 unique:name 的别名
+		switch strings.ToLower(value) {
+		case "", "true":
+			column.Unique = true
+		case "false":
+			column.Unique = false
+		default:
+			column.UniqueConstraint = value
+		}
+	case "priority":
+		// 列在复合索引中的顺序
+		if priority, err := strconv.Atoi(value); err == nil {
+			column.IndexPriority = priority
 		}
 	case "length", "len":
 		// 长度的别名支持
@@ -461,8 +498,12 @@ func (ma *ModelAnalyzer) SetColumnType(typeStr string, column *ModelColumn) {
 			column.Length = 36 // UUID标准长度
 		}
 
-	// 枚举类型 (作为VARCHAR处理)
-	case "enum", "set":
+	// 枚举类型，取值由 values 标签指定
+	case "enum":
+		column.Type = ColumnTypeEnum
+
+	// 集合类型 (作为VARCHAR处理)
+	case "set":
 		column.Type = ColumnTypeVarchar
 		if column.Length == 0 {
 			column.Length = 255
@@ -485,6 +526,48 @@ func (ma *ModelAnalyzer) SetColumnType(typeStr string, column *ModelColumn) {
 	}
 }
 
+// ParseEnumValues 解析以 | 分隔的枚举取值，忽略空值
+func ParseEnumValues(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, "|") {
+		item = strings.Trim(strings.TrimSpace(item), "'\"")
+		if item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// checkDangerousTokenRegex CHECK表达式中不允许出现的语句分隔符、注释和语句关键字
+var checkDangerousTokenRegex = regexp.MustCompile(`(?i);|--|/\*|\*/|\b(select|insert|update|delete|drop|alter|create|truncate|grant|revoke|exec|execute|union|attach|pragma)\b`)
+
+// ValidateCheckExpression 校验CHECK约束表达式，拒绝空表达式、括号不匹配和明显危险的片段
+func ValidateCheckExpression(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return fmt.Errorf("CHECK约束表达式不能为空")
+	}
+	if token := checkDangerousTokenRegex.FindString(expr); token != "" {
+		return fmt.Errorf("CHECK约束表达式包含不允许的内容 %q: %s", token, expr)
+	}
+
+	depth := 0
+	for _, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("CHECK约束表达式括号不匹配: %s", expr)
+	}
+	return nil
+}
+
 // ParseDefaultValue 解析默认值
 func (ma *ModelAnalyzer) ParseDefaultValue(value string) string {
 	value = strings.TrimSpace(value)
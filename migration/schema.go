@@ -37,6 +37,9 @@ const (
 	ColumnTypeBlob    ColumnType = "BLOB"
 	ColumnTypeJSON    ColumnType = "JSON"
 
+	// 枚举类型：MySQL 使用 ENUM，其他数据库使用 VARCHAR + CHECK 约束
+	ColumnTypeEnum ColumnType = "ENUM"
+
 	// PostgreSQL SERIAL类型
 	ColumnTypeSerial      ColumnType = "SERIAL"
 	ColumnTypeBigSerial   ColumnType = "BIGSERIAL"
@@ -67,20 +70,33 @@ type ModelColumn struct {
 	Readonly  bool   // 只读字段
 	Generated string // 生成列类型: "virtual", "stored", ""
 
+	// 枚举允许的取值，来自标签 values:a|b|c
+	EnumValues []string
+
+	// CHECK约束表达式，例如 "age >= 0"
+	Check string
+
 	// 索引相关
 	Index         bool   // 普通索引
 	FulltextIndex bool   // 全文索引
 	SpatialIndex  bool   // 空间索引
 	IndexType     string // 索引类型: "btree", "hash", "rtree"
+	IndexName     string // 索引名称，相同名称的列组成复合索引
+	// 表级唯一约束名称，相同名称的列组成 UNIQUE (col1, col2) 约束
+	UniqueConstraint string
+	IndexPriority    int // 列在复合索引中的顺序，越小越靠前（默认10）
 
 	// 外键相关
 	ForeignKey string // 外键表.字段
 	OnDelete   string // 删除时动作: "cascade", "restrict", "set null", "set default"
 	OnUpdate   string // 更新时动作: "cascade", "restrict", "set null", "set default"
-	
+
 	// 时间管理
 	AutoCreateTime bool // 自动创建时间字段
 	AutoUpdateTime bool // 自动更新时间字段
+
+	// 重命名
+	RenameFrom string // 重命名前的旧列名
 }
 
 // Column 列定义
@@ -172,7 +188,7 @@ func (sb *SchemaBuilder) CreateTable(table *Table) error {
 
 // DropTable 删除表
 func (sb *SchemaBuilder) DropTable(tableName string) error {
-	sql := fmt.Sprintf("DROP TABLE IF EXISTS %s", sb.quoteName(tableName))
+	sql := fmt.Sprintf("DROP TABLE IF EXISTS %s", sb.quoteTable(tableName))
 	_, err := sb.conn.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("failed to drop table %s: %w", tableName, err)
@@ -218,6 +234,43 @@ func (sb *SchemaBuilder) ModifyColumn(tableName string, column *Column) error {
 	return nil
 }
 
+// RenameColumn 重命名列
+// MySQL 8.0+/PostgreSQL/SQLite 使用 RENAME COLUMN，旧版本MySQL使用 CHANGE 并保留原列定义
+func (sb *SchemaBuilder) RenameColumn(tableName, oldName, newName string) error {
+	definition := ""
+	if sb.driver == "mysql" && !mysqlSupportsRenameColumn(sb.conn) {
+		def, err := sb.mysqlColumnDefinition(tableName, oldName)
+		if err != nil {
+			return fmt.Errorf("failed to rename column %s in table %s: %w", oldName, tableName, err)
+		}
+		definition = def
+	}
+
+	sql := sb.generateRenameColumnSQL(tableName, oldName, newName, definition)
+	_, err := sb.conn.Exec(sql)
+	if err != nil {
+		return fmt.Errorf("failed to rename column %s to %s in table %s: %w", oldName, newName, tableName, err)
+	}
+	return nil
+}
+
+// mysqlColumnDefinition 从 SHOW CREATE TABLE 中提取列定义（不含列名）
+func (sb *SchemaBuilder) mysqlColumnDefinition(tableName, columnName string) (string, error) {
+	var name, createSQL string
+	if err := sb.conn.QueryRow("SHOW CREATE TABLE "+sb.quoteTable(tableName)).Scan(&name, &createSQL); err != nil {
+		return "", err
+	}
+
+	prefix := sb.quoteName(columnName) + " "
+	for _, line := range strings.Split(createSQL, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSuffix(strings.TrimPrefix(line, prefix), ","), nil
+		}
+	}
+	return "", fmt.Errorf("column %s not found in table %s", columnName, tableName)
+}
+
 // CreateIndex 创建索引
 func (sb *SchemaBuilder) CreateIndex(tableName string, index *Index) error {
 	sql := sb.generateCreateIndexSQL(tableName, index)
@@ -303,7 +356,7 @@ func (sb *SchemaBuilder) generateCreateTableSQL(table *Table) (string, error) {
 
 	// 构建完整的CREATE TABLE语句
 	sql := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)",
-		sb.quoteName(table.Name),
+		sb.quoteTable(table.Name),
 		strings.Join(parts, ",\n  "))
 
 	// 添加表选项
@@ -624,11 +677,11 @@ func (sb *SchemaBuilder) generateAddColumnSQL(tableName string, column *Column)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", sb.quoteName(tableName), columnSQL), nil
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", sb.quoteTable(tableName), columnSQL), nil
 }
 
 func (sb *SchemaBuilder) generateDropColumnSQL(tableName, columnName string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", sb.quoteName(tableName), sb.quoteName(columnName))
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", sb.quoteTable(tableName), sb.quoteName(columnName))
 }
 
 func (sb *SchemaBuilder) generateModifyColumnSQL(tableName string, column *Column) (string, error) {
@@ -639,19 +692,64 @@ func (sb *SchemaBuilder) generateModifyColumnSQL(tableName string, column *Colum
 
 	switch sb.driver {
 	case "mysql":
-		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", sb.quoteName(tableName), columnSQL), nil
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", sb.quoteTable(tableName), columnSQL), nil
 	case "postgres", "postgresql":
 		// PostgreSQL 需要分步修改
 		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
-			sb.quoteName(tableName), sb.quoteName(column.Name), columnSQL), nil
+			sb.quoteTable(tableName), sb.quoteName(column.Name), columnSQL), nil
 	case "sqlite", "sqlite3":
 		// SQLite 不支持修改列，需要重建表
 		return "", fmt.Errorf("SQLite does not support modifying columns")
 	default:
-		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", sb.quoteName(tableName), columnSQL), nil
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", sb.quoteTable(tableName), columnSQL), nil
 	}
 }
 
+// generateRenameColumnSQL 生成重命名列的SQL，definition 非空时生成MySQL旧版本的 CHANGE 语法
+func (sb *SchemaBuilder) generateRenameColumnSQL(tableName, oldName, newName, definition string) string {
+	switch sb.driver {
+	case "mysql":
+		if definition != "" {
+			return fmt.Sprintf("ALTER TABLE %s CHANGE %s %s %s",
+				sb.quoteTable(tableName), sb.quoteName(oldName), sb.quoteName(newName), definition)
+		}
+	case "sqlserver", "mssql":
+		return fmt.Sprintf("EXEC sp_rename '%s.%s', '%s', 'COLUMN'", prefixTable(sb.conn, tableName), oldName, newName)
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+		sb.quoteTable(tableName), sb.quoteName(oldName), sb.quoteName(newName))
+}
+
+// mysqlSupportsRenameColumn 检查MySQL服务器是否支持 RENAME COLUMN 语法
+func mysqlSupportsRenameColumn(conn db.ConnectionInterface) bool {
+	var version string
+	if err := conn.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return false
+	}
+	return !isLegacyMySQLVersion(version)
+}
+
+// isLegacyMySQLVersion 判断版本是否早于 MySQL 8.0 / MariaDB 10.5.2（不支持 RENAME COLUMN）
+func isLegacyMySQLVersion(version string) bool {
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	nums := make([]int, 3)
+	for i, p := range parts {
+		fmt.Sscanf(p, "%d", &nums[i])
+	}
+
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		if nums[0] != 10 {
+			return nums[0] < 10
+		}
+		if nums[1] != 5 {
+			return nums[1] < 5
+		}
+		return nums[2] < 2
+	}
+	return nums[0] < 8
+}
+
 func (sb *SchemaBuilder) generateCreateIndexSQL(tableName string, index *Index) string {
 	indexType := ""
 	if index.Unique {
@@ -664,13 +762,13 @@ func (sb *SchemaBuilder) generateCreateIndexSQL(tableName string, index *Index)
 	}
 
 	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)",
-		indexType, sb.quoteName(index.Name), sb.quoteName(tableName), strings.Join(columns, ", "))
+		indexType, sb.quoteName(index.Name), sb.quoteTable(tableName), strings.Join(columns, ", "))
 }
 
 func (sb *SchemaBuilder) generateDropIndexSQL(tableName, indexName string) string {
 	switch sb.driver {
 	case "mysql":
-		return fmt.Sprintf("DROP INDEX %s ON %s", sb.quoteName(indexName), sb.quoteName(tableName))
+		return fmt.Sprintf("DROP INDEX %s ON %s", sb.quoteName(indexName), sb.quoteTable(tableName))
 	default:
 		return fmt.Sprintf("DROP INDEX %s", sb.quoteName(indexName))
 	}
@@ -688,8 +786,8 @@ func (sb *SchemaBuilder) generateCreateForeignKeySQL(tableName string, fk *Forei
 	}
 
 	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
-		sb.quoteName(tableName), sb.quoteName(fk.Name),
-		strings.Join(columns, ", "), sb.quoteName(fk.ReferencedTable), strings.Join(refColumns, ", "))
+		sb.quoteTable(tableName), sb.quoteName(fk.Name),
+		strings.Join(columns, ", "), sb.quoteTable(fk.ReferencedTable), strings.Join(refColumns, ", "))
 
 	if fk.OnUpdate != "" {
 		sql += " ON UPDATE " + fk.OnUpdate
@@ -702,7 +800,7 @@ func (sb *SchemaBuilder) generateCreateForeignKeySQL(tableName string, fk *Forei
 }
 
 func (sb *SchemaBuilder) generateDropForeignKeySQL(tableName, fkName string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", sb.quoteName(tableName), sb.quoteName(fkName))
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", sb.quoteTable(tableName), sb.quoteName(fkName))
 }
 
 func (sb *SchemaBuilder) generateInlineForeignKeySQL(fk *ForeignKey) string {
@@ -717,7 +815,7 @@ func (sb *SchemaBuilder) generateInlineForeignKeySQL(fk *ForeignKey) string {
 	}
 
 	sql := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
-		strings.Join(columns, ", "), sb.quoteName(fk.ReferencedTable), strings.Join(refColumns, ", "))
+		strings.Join(columns, ", "), sb.quoteTable(fk.ReferencedTable), strings.Join(refColumns, ", "))
 
 	if fk.OnUpdate != "" {
 		sql += " ON UPDATE " + fk.OnUpdate
@@ -729,6 +827,19 @@ func (sb *SchemaBuilder) generateInlineForeignKeySQL(fk *ForeignKey) string {
 	return sql
 }
 
+// quoteTable 引用表名，并加上连接配置的表前缀，与查询构建器保持一致
+func (sb *SchemaBuilder) quoteTable(tableName string) string {
+	return sb.quoteName(prefixTable(sb.conn, tableName))
+}
+
+// prefixTable 为表名加上连接配置的表前缀
+func prefixTable(conn db.ConnectionInterface, tableName string) string {
+	if conn == nil {
+		return tableName
+	}
+	return conn.GetConfig().PrefixTable(tableName)
+}
+
 // quoteName 引用名称
 func (sb *SchemaBuilder) quoteName(name string) string {
 	switch sb.driver {
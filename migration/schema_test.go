@@ -0,0 +1,502 @@
+package migration
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zhoudm1743/torm/db"
+)
+
+// fakeConnection 仅提供驱动名称的测试连接
+type fakeConnection struct {
+	db.ConnectionInterface
+	driver string
+	config *db.Config
+}
+
+func (c *fakeConnection) GetDriver() string {
+	return c.driver
+}
+
+func (c *fakeConnection) GetConfig() *db.Config {
+	return c.config
+}
+
+// 测试各数据库的重命名列SQL
+func TestGenerateRenameColumnSQL(t *testing.T) {
+	tests := []struct {
+		driver     string
+		definition string
+		expected   string
+	}{
+		{"mysql", "", "ALTER TABLE `users` RENAME COLUMN `name` TO `nickname`"},
+		{"mysql", "varchar(50) NOT NULL", "ALTER TABLE `users` CHANGE `name` `nickname` varchar(50) NOT NULL"},
+		{"postgres", "", `ALTER TABLE "users" RENAME COLUMN "name" TO "nickname"`},
+		{"sqlite", "", `ALTER TABLE "users" RENAME COLUMN "name" TO "nickname"`},
+	}
+
+	for _, tt := range tests {
+		sb := &SchemaBuilder{driver: tt.driver}
+		sql := sb.generateRenameColumnSQL("users", "name", "nickname", tt.definition)
+		if sql != tt.expected {
+			t.Errorf("[%s] Expected '%s', got '%s'", tt.driver, tt.expected, sql)
+		}
+	}
+}
+
+// 测试MySQL版本判断
+func TestIsLegacyMySQLVersion(t *testing.T) {
+	tests := map[string]bool{
+		"5.7.44-log":      true,
+		"8.0.33":          false,
+		"10.4.32-MariaDB": true,
+		"10.5.2-MariaDB":  false,
+		"11.2.2-MariaDB":  false,
+	}
+
+	for version, expected := range tests {
+		if got := isLegacyMySQLVersion(version); got != expected {
+			t.Errorf("Expected isLegacyMySQLVersion(%s) = %v, got %v", version, expected, got)
+		}
+	}
+}
+
+// 测试rename_from标签使自动迁移执行重命名而不是新增列
+func TestCompareColumnsWithRename(t *testing.T) {
+	type RenamedModel struct {
+		ID       int    `torm:"primary_key"`
+		Nickname string `torm:"type:varchar,size:50,rename_from:name"`
+	}
+
+	analyzer := NewModelAnalyzer()
+	columns, err := analyzer.AnalyzeModel(reflect.TypeOf(RenamedModel{}))
+	if err != nil {
+		t.Fatalf("AnalyzeModel failed: %v", err)
+	}
+
+	am := NewAutoMigrator(&fakeConnection{driver: "sqlite"})
+	existing := map[string]ModelColumn{
+		"id":   {Name: "id", Type: ColumnTypeInt, PrimaryKey: true},
+		"name": {Name: "name", Type: ColumnTypeVarchar, Length: 50},
+	}
+
+	toRename, toAdd, _, err := am.compareColumns(existing, columns)
+	if err != nil {
+		t.Fatalf("compareColumns failed: %v", err)
+	}
+	if len(toAdd) != 0 {
+		t.Errorf("Expected no columns to add, got %d", len(toAdd))
+	}
+	if len(toRename) != 1 || toRename[0].RenameFrom != "name" || toRename[0].Name != "nickname" {
+		t.Fatalf("Expected rename name -> nickname, got %+v", toRename)
+	}
+
+	drivers := map[string]string{
+		"sqlite":   `ALTER TABLE "users" RENAME COLUMN "name" TO "nickname"`,
+		"postgres": `ALTER TABLE "users" RENAME COLUMN "name" TO "nickname"`,
+		"mysql":    "ALTER TABLE `users` RENAME COLUMN `name` TO `nickname`",
+	}
+	for driver, expected := range drivers {
+		am := NewAutoMigrator(&fakeConnection{driver: driver})
+		if sql := am.buildRenameColumnSQL("users", "name", toRename[0], false); sql != expected {
+			t.Errorf("[%s] Expected '%s', got '%s'", driver, expected, sql)
+		}
+	}
+
+	am = NewAutoMigrator(&fakeConnection{driver: "mysql"})
+	expected := "ALTER TABLE `users` CHANGE `name` `nickname` VARCHAR(50)"
+	if sql := am.buildRenameColumnSQL("users", "name", toRename[0], true); sql != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, sql)
+	}
+}
+
+// 测试相同索引名称的列组成复合索引
+func TestCompositeIndexes(t *testing.T) {
+	type OrderModel struct {
+		ID        int    `torm:"primary_key"`
+		CreatedAt int64  `torm:"index:idx_tenant_created,priority:2"`
+		TenantID  int    `torm:"index:idx_tenant_created,priority:1"`
+		Code      string `torm:"type:varchar,size:32,unique_index:uk_tenant_code"`
+		ShopID    int    `torm:"unique_index:uk_tenant_code"`
+		Status    int    `torm:"index:btree"`
+	}
+
+	columns, err := NewModelAnalyzer().AnalyzeModel(reflect.TypeOf(OrderModel{}))
+	if err != nil {
+		t.Fatalf("AnalyzeModel failed: %v", err)
+	}
+
+	am := NewAutoMigrator(&fakeConnection{driver: "mysql"})
+	indexes := am.collectNamedIndexes(columns)
+	if len(indexes) != 1 {
+		t.Fatalf("Expected 1 named index, got %d", len(indexes))
+	}
+	if sql := am.buildCreateIndexSQL("orders", indexes[0], "mysql"); sql != "CREATE INDEX `idx_tenant_created` ON `orders` (`tenant_id`, `created_at`)" {
+		t.Errorf("Unexpected index SQL: %s", sql)
+	}
+
+	// unique_index:name 与 unique:name 相同，作为表级唯一约束创建
+	constraints := am.collectUniqueConstraints(columns)
+	if len(constraints) != 1 || constraints[0].Name != "uk_tenant_code" || strings.Join(constraints[0].Columns, ",") != "code,shop_id" {
+		t.Fatalf("Expected unique constraint uk_tenant_code (code, shop_id), got %+v", constraints)
+	}
+
+	pgSQL := am.buildCreateIndexSQL("orders", &Index{Name: "idx_a_b", Columns: []string{"a", "b"}, Type: "btree"}, "postgres")
+	if pgSQL != `CREATE INDEX "idx_a_b" ON "orders" USING btree ("a", "b")` {
+		t.Errorf("Unexpected PostgreSQL index SQL: %s", pgSQL)
+	}
+}
+
+// 测试结构构建器和自动迁移为表名加上连接配置的表前缀
+func TestSchemaTablePrefix(t *testing.T) {
+	conn := &fakeConnection{driver: "mysql", config: &db.Config{Prefix: "app_"}}
+
+	sb := NewSchemaBuilder(conn)
+	sql := sb.generateCreateForeignKeySQL("posts", &ForeignKey{
+		Name:              "fk_posts_user",
+		Columns:           []string{"user_id"},
+		ReferencedTable:   "users",
+		ReferencedColumns: []string{"id"},
+	})
+	if !strings.Contains(sql, "ALTER TABLE `app_posts`") || !strings.Contains(sql, "REFERENCES `app_users`") {
+		t.Errorf("Expected prefixed tables, got '%s'", sql)
+	}
+
+	am := NewAutoMigrator(conn)
+	if table, column := am.parseForeignKeyReference("users.id"); table != "app_users" || column != "id" {
+		t.Errorf("Expected app_users.id, got %s.%s", table, column)
+	}
+	if table, _ := am.parseForeignKeyReference("app_users.id"); table != "app_users" {
+		t.Errorf("Expected already prefixed table to be kept, got %s", table)
+	}
+}
+
+// 测试结构查询器列出表并读取列信息
+func TestInspector(t *testing.T) {
+	if err := db.AddConnection("inspector_test", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	inspector, err := Inspect("inspector_test")
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if _, err := inspector.connection.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name VARCHAR(50) NOT NULL DEFAULT 'guest', bio TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	inspector.connection.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+
+	tables, err := inspector.Tables()
+	if err != nil || !reflect.DeepEqual(tables, []string{"posts", "users"}) {
+		t.Fatalf("Expected [posts users], got %v, %v", tables, err)
+	}
+
+	columns, err := inspector.Columns("users")
+	if err != nil || len(columns) != 3 {
+		t.Fatalf("Expected 3 columns, got %v, %v", columns, err)
+	}
+	if id := columns[0]; id.Name != "id" || !id.PrimaryKey || !id.AutoIncrement {
+		t.Errorf("Unexpected id column: %+v", id)
+	}
+	if name := columns[1]; name.Type != "VARCHAR" || name.Length != 50 || !name.NotNull || name.Default != "'guest'" {
+		t.Errorf("Unexpected name column: %+v", name)
+	}
+	if bio := columns[2]; bio.NotNull || bio.PrimaryKey {
+		t.Errorf("Unexpected bio column: %+v", bio)
+	}
+
+	if exists, _ := inspector.HasTable("comments"); exists {
+		t.Error("Expected comments table to be missing")
+	}
+
+	// 复合主键的每一列都是主键，但不是 rowid 别名
+	inspector.connection.Exec("CREATE TABLE post_tags (post_id INTEGER, tag_id INTEGER, PRIMARY KEY (post_id, tag_id))")
+	columns, err = inspector.Columns("post_tags")
+	if err != nil || len(columns) != 2 {
+		t.Fatalf("Expected 2 columns, got %v, %v", columns, err)
+	}
+	for _, column := range columns {
+		if !column.PrimaryKey || column.AutoIncrement {
+			t.Errorf("Unexpected composite key column: %+v", column)
+		}
+	}
+
+	// 表名作为参数绑定，不会拼接进 SQL
+	columns, err = inspector.Columns("users); DROP TABLE users; --")
+	if err != nil || len(columns) != 0 {
+		t.Errorf("Expected no columns for unknown table, got %v, %v", columns, err)
+	}
+	if exists, _ := inspector.HasTable("users"); !exists {
+		t.Error("Expected users table to still exist")
+	}
+
+	if !isWrappedInParens("((0))") || isWrappedInParens("(1)+(2)") {
+		t.Error("Unexpected parenthesis detection")
+	}
+	if column := parseSQLServerColumn("name", "nvarchar", "NO", sql.NullString{String: "(N'guest')", Valid: true},
+		sql.NullInt64{Int64: 50, Valid: true}, sql.NullInt64{}, sql.NullInt64{}, false); column.Type != "NVARCHAR" || column.Length != 50 || column.Default != "N'guest'" {
+		t.Errorf("Unexpected SQL Server column: %+v", column)
+	}
+}
+
+// 测试根据数据库表结构生成模型
+func TestGenerateModels(t *testing.T) {
+	if err := db.AddConnection("generator_test", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	conn, _ := db.DB("generator_test")
+	if _, err := conn.Exec(`CREATE TABLE user_profiles (
+		id INTEGER PRIMARY KEY,
+		nick_name VARCHAR(50) NOT NULL DEFAULT 'guest',
+		avatar_url TEXT,
+		score DOUBLE NOT NULL,
+		created_at DATETIME NOT NULL,
+		deleted_at DATETIME
+	)`); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "models")
+	if err := GenerateModels(conn, dir); err != nil {
+		t.Fatalf("GenerateModels failed: %v", err)
+	}
+	source, err := os.ReadFile(filepath.Join(dir, "user_profiles.go"))
+	if err != nil {
+		t.Fatalf("read generated file failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"package models",
+		"type UserProfiles struct {",
+		"ID        int64      `json:\"id\" torm:\"primary_key,auto_increment,type:integer\"`",
+		"NickName  string     `json:\"nick_name\" torm:\"type:varchar,size:50,not_null,default:guest\"`",
+		"AvatarURL *string    `json:\"avatar_url\" torm:\"type:text,nullable\"`",
+		"CreatedAt time.Time  `json:\"created_at\" torm:\"type:datetime,not_null,auto_create_time\"`",
+		"DeletedAt *time.Time `json:\"deleted_at\" torm:\"type:datetime,nullable,soft_delete\"`",
+		`return "user_profiles"`,
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("Generated source missing %q:\n%s", expected, source)
+		}
+	}
+
+	// 与 BaseModel 方法同名的字段改名，定点数按 DECIMAL 模式映射
+	columns := []ModelColumn{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true},
+		{Name: "table_name", Type: "VARCHAR", NotNull: true},
+		{Name: "count", Type: "INTEGER", NotNull: true},
+		{Name: "price", Type: "DECIMAL", NotNull: true, Precision: 10, Scale: 2},
+		{Name: "discount", Type: "NUMERIC"},
+	}
+	for mode, expected := range map[db.DecimalMode][]string{
+		db.DecimalAsFloat:  {"Price float64", "Discount *float64"},
+		db.DecimalAsString: {"Price string", "Discount *string"},
+		db.DecimalAsRat:    {`"math/big"`, "Price *big.Rat", "Discount *big.Rat"},
+	} {
+		db.SetDecimalMode(mode)
+		source, err := generateModelSource("models", "products", columns)
+		db.SetDecimalMode(db.DecimalAsFloat)
+		if err != nil {
+			t.Fatalf("generateModelSource failed: %v", err)
+		}
+		expected = append(expected,
+			"TableNameField string `json:\"table_name\" torm:\"column:table_name,type:varchar,not_null\"`",
+			"CountField int64 `json:\"count\" torm:\"column:count,type:integer,not_null\"`")
+		// 忽略 gofmt 的字段对齐
+		normalized := strings.Join(strings.Fields(string(source)), " ")
+		for _, want := range expected {
+			if !strings.Contains(normalized, want) {
+				t.Errorf("[mode %d] Generated source missing %q:\n%s", mode, want, source)
+			}
+		}
+	}
+}
+
+// 测试枚举列在各数据库上的列定义
+func TestEnumColumn(t *testing.T) {
+	type AccountModel struct {
+		ID     int    `torm:"primary_key"`
+		Status string `torm:"type:enum,values:active|inactive|pending,not_null"`
+	}
+
+	columns, err := NewModelAnalyzer().AnalyzeModel(reflect.TypeOf(AccountModel{}))
+	if err != nil {
+		t.Fatalf("AnalyzeModel failed: %v", err)
+	}
+	status := columns[1]
+	if status.Type != ColumnTypeEnum || !reflect.DeepEqual(status.EnumValues, []string{"active", "inactive", "pending"}) {
+		t.Fatalf("Unexpected enum column: %+v", status)
+	}
+
+	drivers := map[string]string{
+		"mysql":    "`status` ENUM('active','inactive','pending') NOT NULL",
+		"postgres": `"status" VARCHAR(255) NOT NULL CHECK ("status" IN ('active','inactive','pending'))`,
+		"sqlite":   `"status" VARCHAR(255) NOT NULL CHECK ("status" IN ('active','inactive','pending'))`,
+	}
+	for driver, expected := range drivers {
+		am := NewAutoMigrator(&fakeConnection{driver: driver})
+		if def := am.buildColumnDefinition(status, driver); def != expected {
+			t.Errorf("[%s] Expected '%s', got '%s'", driver, expected, def)
+		}
+	}
+
+	// 非MySQL数据库上已存在的VARCHAR列不需要修改
+	am := NewAutoMigrator(&fakeConnection{driver: "sqlite"})
+	existing := ModelColumn{Name: "status", Type: ColumnTypeVarchar, Length: 255, NotNull: true}
+	if am.columnNeedsUpdate(existing, status) {
+		t.Error("Expected enum column stored as VARCHAR to be up to date")
+	}
+}
+
+// 测试表级复合唯一约束
+func TestUniqueConstraints(t *testing.T) {
+	type MemberModel struct {
+		ID       int    `torm:"primary_key"`
+		Email    string `torm:"type:varchar,size:100,unique:uk_tenant_email,priority:2"`
+		TenantID int    `torm:"unique:uk_tenant_email,priority:1"`
+	}
+
+	columns, err := NewModelAnalyzer().AnalyzeModel(reflect.TypeOf(MemberModel{}))
+	if err != nil {
+		t.Fatalf("AnalyzeModel failed: %v", err)
+	}
+
+	drivers := map[string]string{
+		"mysql":    "UNIQUE KEY `uk_tenant_email` (`tenant_id`, `email`)\n) ENGINE=InnoDB",
+		"postgres": `CONSTRAINT "uk_tenant_email" UNIQUE ("tenant_id", "email")` + "\n)",
+		"sqlite":   `CONSTRAINT "uk_tenant_email" UNIQUE ("tenant_id", "email")` + "\n)",
+	}
+	for driver, expected := range drivers {
+		am := NewAutoMigrator(&fakeConnection{driver: driver})
+		if sql := am.buildCreateTableSQL("members", columns, driver); !strings.Contains(sql, ",\n  "+expected) {
+			t.Errorf("[%s] Expected create table SQL to contain %q, got:\n%s", driver, expected, sql)
+		}
+	}
+
+	type PlainModel struct {
+		Code string `torm:"unique:false"`
+	}
+	plain, err := NewModelAnalyzer().AnalyzeModel(reflect.TypeOf(PlainModel{}))
+	if err != nil || plain[0].Unique || plain[0].UniqueConstraint != "" {
+		t.Errorf("Expected unique:false to leave the column non-unique, got %+v (%v)", plain, err)
+	}
+
+	am := NewAutoMigrator(&fakeConnection{driver: "postgres"})
+	constraint := am.collectUniqueConstraints(columns)[0]
+	if sql := am.buildAddUniqueConstraintSQL("members", constraint, "postgres"); sql != `ALTER TABLE "members" ADD CONSTRAINT "uk_tenant_email" UNIQUE ("tenant_id", "email")` {
+		t.Errorf("Unexpected PostgreSQL add constraint SQL: %s", sql)
+	}
+	if sql := am.buildAddUniqueConstraintSQL("members", constraint, "mysql"); sql != "ALTER TABLE `members` ADD UNIQUE KEY `uk_tenant_email` (`tenant_id`, `email`)" {
+		t.Errorf("Unexpected MySQL add constraint SQL: %s", sql)
+	}
+
+	// SQLite：建表时的约束在再次迁移时被识别，已存在的表补充唯一索引
+	if err := db.AddConnection("unique_constraint_test", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	conn, err := db.DB("unique_constraint_test")
+	if err != nil {
+		t.Fatalf("DB failed: %v", err)
+	}
+	migrator := NewAutoMigrator(conn)
+	for i := 0; i < 2; i++ {
+		if err := migrator.MigrateModel(MemberModel{}, "members"); err != nil {
+			t.Fatalf("MigrateModel #%d failed: %v", i+1, err)
+		}
+	}
+	if migrator.indexExists("members", "uk_tenant_email") {
+		t.Error("Expected no duplicate unique index for the table constraint")
+	}
+	conn.Exec("INSERT INTO members (id, email, tenant_id) VALUES (1, 'a@x.com', 1)")
+	if _, err := conn.Exec("INSERT INTO members (id, email, tenant_id) VALUES (2, 'a@x.com', 1)"); err == nil {
+		t.Error("Expected duplicate (tenant_id, email) to be rejected")
+	}
+
+	if _, err := conn.Exec("CREATE TABLE legacy_members (id INTEGER PRIMARY KEY, email VARCHAR(100), tenant_id INTEGER)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if err := migrator.MigrateModel(MemberModel{}, "legacy_members"); err != nil {
+		t.Fatalf("MigrateModel on existing table failed: %v", err)
+	}
+	if !migrator.indexExists("legacy_members", "uk_tenant_email") {
+		t.Error("Expected unique index to be added to the existing table")
+	}
+
+	// 已存在的表无法创建索引时返回错误
+	type AccountModel struct {
+		ID    int    `torm:"primary_key"`
+		Email string `torm:"type:varchar,size:100,unique"`
+	}
+	if _, err := conn.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY, email VARCHAR(100))"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	conn.Exec("INSERT INTO accounts (id, email) VALUES (1, 'a@x.com'), (2, 'a@x.com')")
+	if err := migrator.MigrateModel(AccountModel{}, "accounts"); err == nil || !strings.Contains(err.Error(), "idx_accounts_email_unique") {
+		t.Errorf("Expected unique index creation error, got %v", err)
+	}
+}
+
+// 测试CHECK约束
+func TestCheckConstraint(t *testing.T) {
+	type PersonModel struct {
+		ID  int `torm:"primary_key"`
+		Age int `torm:"check:age >= 0"`
+	}
+
+	columns, err := NewModelAnalyzer().AnalyzeModel(reflect.TypeOf(PersonModel{}))
+	if err != nil {
+		t.Fatalf("AnalyzeModel failed: %v", err)
+	}
+	age := columns[1]
+	if age.Check != "age >= 0" {
+		t.Fatalf("Expected check expression 'age >= 0', got '%s'", age.Check)
+	}
+
+	tests := []struct {
+		driver, version, expected string
+	}{
+		{"mysql", "8.0.36", "`age` INT CHECK (age >= 0)"},
+		{"mysql", "10.6.12-MariaDB", "`age` INT CHECK (age >= 0)"},
+		{"mysql", "5.7.44-log", "`age` INT"},
+		{"postgres", "", `"age" INTEGER CHECK (age >= 0)`},
+		{"sqlite", "", `"age" INTEGER CHECK (age >= 0)`},
+	}
+	for _, tt := range tests {
+		am := NewAutoMigrator(&fakeConnection{driver: tt.driver})
+		am.serverVersion = tt.version
+		if def := am.buildColumnDefinition(age, tt.driver); def != tt.expected {
+			t.Errorf("[%s %s] Expected '%s', got '%s'", tt.driver, tt.version, tt.expected, def)
+		}
+	}
+
+	for _, expr := range []string{"", "age > 0; DROP TABLE users", "age > 0 -- x", "age IN (SELECT 1)", "(age > 0"} {
+		if err := ValidateCheckExpression(expr); err == nil {
+			t.Errorf("Expected check expression %q to be rejected", expr)
+		}
+	}
+	for _, expr := range []string{"age >= 0", "(price > 0) AND (discount <= price)", "updated_at >= created_at"} {
+		if err := ValidateCheckExpression(expr); err != nil {
+			t.Errorf("Expected check expression %q to be accepted, got %v", expr, err)
+		}
+	}
+
+	if err := db.AddConnection("check_constraint_test", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	conn, err := db.DB("check_constraint_test")
+	if err != nil {
+		t.Fatalf("DB failed: %v", err)
+	}
+	if err := NewAutoMigrator(conn).MigrateModel(PersonModel{}, "people"); err != nil {
+		t.Fatalf("MigrateModel failed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO people (id, age) VALUES (1, 30)"); err != nil {
+		t.Fatalf("Expected valid insert to succeed: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO people (id, age) VALUES (2, -1)"); err == nil {
+		t.Error("Expected negative age to violate the CHECK constraint")
+	}
+}
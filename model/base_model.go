@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -11,16 +12,26 @@ import (
 	"github.com/zhoudm1743/torm/migration"
 )
 
+// ErrRecordNotFound 记录不存在，与 db.ErrRecordNotFound 为同一个错误
+// First、Find 等方法未找到记录时返回的错误可以用 errors.Is(err, model.ErrRecordNotFound) 判断
+var ErrRecordNotFound = db.ErrRecordNotFound
+
 // ModelConfig 模型配置
 type ModelConfig struct {
 	TableName    string
 	PrimaryKey   string
+	PrimaryKeys  []string // 复合主键，为空时使用PrimaryKey
 	Connection   string
 	Timestamps   bool
 	CreatedAtCol string
 	UpdatedAtCol string
 	SoftDeletes  bool
 	DeletedAtCol string
+
+	// EnumValues 列允许的枚举取值，来自标签 values:a|b|c，Save 时校验写入的值
+	EnumValues map[string][]string
+	// SkipEnumValidation 为 true 时 Save 不校验枚举取值，交由数据库约束处理
+	SkipEnumValidation bool
 }
 
 // DefaultModelConfig 默认模型配置
@@ -36,6 +47,12 @@ func DefaultModelConfig() ModelConfig {
 	}
 }
 
+// modelScope 模型上注册的命名全局作用域
+type modelScope struct {
+	name string
+	fn   db.ScopeFunc
+}
+
 // BaseModel 基础模型 - 重构版本
 // 职责分离：模型专注于数据操作，查询通过组合方式提供
 type BaseModel struct {
@@ -44,13 +61,39 @@ type BaseModel struct {
 
 	// 模型数据
 	attributes map[string]interface{}
+	original   map[string]interface{} // 最近一次加载或保存时的属性快照，用于脏检查
+	changes    map[string]interface{} // 最近一次保存时实际写入的变更
 
 	// 模型状态
 	exists bool
 
+	// 最近一次 Save 的结果，见 LastInsertID 和 RowsAffected
+	lastInsertID int64
+	rowsAffected int64
+
+	// 批量赋值保护
+	fillable []string
+	guarded  []string
+
+	// 全局查询作用域
+	globalScopes []modelScope
+
+	// 插入时省略的列，由数据库默认值生效，见 UseDefault
+	useDefaults map[string]bool
+
+	// 当前参与的事务，设置后 Query() 创建的查询都在该事务中执行
+	transaction db.TransactionInterface
+
+	// 请求上下文，见 WithContext；连接解析器按上下文选择连接，见 SetConnectionResolver
+	ctx                context.Context
+	connectionResolver func(ctx context.Context) string
+
 	// 时间管理
 	timeManager *db.TimeFieldManager
 	timeFields  []db.TimeFieldInfo
+
+	// 模型结构体实例，用于按方法名解析关联（Has/WhereHas）
+	instance interface{}
 }
 
 // NewModel 创建模型 - 简化和优化版本
@@ -106,6 +149,7 @@ func NewModel(args ...interface{}) *BaseModel {
 		exists:      false,
 		timeManager: db.NewTimeFieldManager(),
 		timeFields:  make([]db.TimeFieldInfo, 0),
+		instance:    structInstance,
 	}
 
 	// 如果有结构体实例，分析时间字段
@@ -201,25 +245,79 @@ func (m *BaseModel) TableName() string {
 // SetPrimaryKey 设置主键
 func (m *BaseModel) SetPrimaryKey(key string) *BaseModel {
 	m.config.PrimaryKey = key
+	m.config.PrimaryKeys = nil
 	return m
 }
 
-// GetPrimaryKey 获取主键
+// GetPrimaryKey 获取主键，复合主键时返回第一个主键列
 func (m *BaseModel) GetPrimaryKey() string {
 	return m.config.PrimaryKey
 }
 
+// SetPrimaryKeys 设置复合主键
+func (m *BaseModel) SetPrimaryKeys(keys []string) *BaseModel {
+	if len(keys) == 0 {
+		return m
+	}
+	m.config.PrimaryKeys = append([]string(nil), keys...)
+	m.config.PrimaryKey = keys[0]
+	return m
+}
+
+// GetPrimaryKeys 获取所有主键列
+func (m *BaseModel) GetPrimaryKeys() []string {
+	if len(m.config.PrimaryKeys) > 0 {
+		return m.config.PrimaryKeys
+	}
+	return []string{m.config.PrimaryKey}
+}
+
+// HasCompositePrimaryKey 是否为复合主键
+func (m *BaseModel) HasCompositePrimaryKey() bool {
+	return len(m.config.PrimaryKeys) > 1
+}
+
 // SetConnection 设置连接
 func (m *BaseModel) SetConnection(connection string) *BaseModel {
 	m.config.Connection = connection
 	return m
 }
 
-// GetConnection 获取连接
+// GetConnection 获取连接，设置了连接解析器时返回按当前上下文解析出的连接
 func (m *BaseModel) GetConnection() string {
+	if m.connectionResolver != nil {
+		if connName := m.connectionResolver(m.Context()); connName != "" {
+			return connName
+		}
+	}
 	return m.config.Connection
 }
 
+// SetConnectionResolver 设置连接解析器，Query() 等操作按 WithContext 传入的上下文动态选择连接
+// 解析器返回空字符串时使用配置的连接，适用于按租户分库等连接取决于请求的场景；传入 nil 取消解析器
+// 例如：
+//
+//	user.SetConnectionResolver(func(ctx context.Context) string { return tenantConnection(ctx) })
+//	user.WithContext(r.Context()).Find(1)
+func (m *BaseModel) SetConnectionResolver(resolver func(ctx context.Context) string) *BaseModel {
+	m.connectionResolver = resolver
+	return m
+}
+
+// WithContext 设置模型后续查询使用的上下文，用于连接解析、超时和取消
+func (m *BaseModel) WithContext(ctx context.Context) *BaseModel {
+	m.ctx = ctx
+	return m
+}
+
+// Context 获取模型的上下文，未设置时返回 context.Background()
+func (m *BaseModel) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
 // EnableTimestamps 启用时间戳
 func (m *BaseModel) EnableTimestamps() *BaseModel {
 	m.config.Timestamps = true
@@ -278,17 +376,114 @@ func (m *BaseModel) SetDeletedAtField(field string) *BaseModel {
 
 // Query 创建查询构建器
 func (m *BaseModel) Query() (*db.QueryBuilder, error) {
+	return m.queryOn(m.GetConnection(), m.transaction)
+}
+
+// On 在指定连接上创建模型查询，保留表名、访问器、软删除和全局作用域，用于分库、按租户分库等场景
+// 模型绑定的事务属于模型自身的连接，On 创建的查询不使用该事务
+// 例如：query, err := user.On("shard_2"); rows, err := query.Where("status", "=", "active").Get()
+func (m *BaseModel) On(connName string) (*db.QueryBuilder, error) {
+	if connName == "" {
+		return nil, fmt.Errorf("连接名不能为空")
+	}
+	return m.queryOn(connName, nil)
+}
+
+// queryOn 在指定连接上创建绑定模型的查询构建器
+func (m *BaseModel) queryOn(connName string, tx db.TransactionInterface) (*db.QueryBuilder, error) {
 	if m.config.TableName == "" {
 		return nil, fmt.Errorf("表名未设置，请使用 SetTable() 方法设置表名")
 	}
 
-	query, err := db.NewQueryBuilder(m.config.Connection)
+	query, err := db.NewQueryBuilder(connName)
 	if err != nil {
 		return nil, fmt.Errorf("创建查询构建器失败: %w", err)
 	}
 
 	// 绑定模型实例以支持访问器处理
-	return query.From(m.config.TableName).WithModel(m), nil
+	query = query.From(m.config.TableName).WithModel(m)
+	if tx != nil {
+		query = query.InTransaction(tx)
+	}
+	if m.ctx != nil {
+		query = query.WithContext(m.ctx)
+	}
+
+	// 软删除作为内置全局作用域，可通过 WithoutGlobalScope(db.SoftDeleteScope) 取消
+	if m.config.SoftDeletes {
+		deletedAtCol := m.config.DeletedAtCol
+		query.WithGlobalScope(db.SoftDeleteScope, func(q *db.QueryBuilder) *db.QueryBuilder {
+			return q.WhereNull(deletedAtCol)
+		})
+	}
+	for _, scope := range m.globalScopes {
+		query.WithGlobalScope(scope.name, scope.fn)
+	}
+
+	return query, nil
+}
+
+// WithTransaction 让模型的后续操作（Save、Delete、Find等）都在指定事务中执行
+// 例如：db.Transaction(func(tx db.TransactionInterface) error { return user.WithTransaction(tx).Save() })
+func (m *BaseModel) WithTransaction(tx db.TransactionInterface) *BaseModel {
+	m.transaction = tx
+	return m
+}
+
+// WithoutTransaction 取消模型绑定的事务
+func (m *BaseModel) WithoutTransaction() *BaseModel {
+	m.transaction = nil
+	return m
+}
+
+// GetTransaction 获取模型绑定的事务
+func (m *BaseModel) GetTransaction() db.TransactionInterface {
+	return m.transaction
+}
+
+// RegisterGlobalScope 注册全局作用域，Query() 创建的查询会自动应用
+// 例如：model.RegisterGlobalScope("tenant", func(q *db.QueryBuilder) *db.QueryBuilder { return q.Where("tenant_id", "=", tid) })
+func (m *BaseModel) RegisterGlobalScope(name string, fn db.ScopeFunc) *BaseModel {
+	for i := range m.globalScopes {
+		if m.globalScopes[i].name == name {
+			m.globalScopes[i].fn = fn
+			return m
+		}
+	}
+	m.globalScopes = append(m.globalScopes, modelScope{name: name, fn: fn})
+	return m
+}
+
+// RemoveGlobalScope 移除模型上注册的全局作用域
+func (m *BaseModel) RemoveGlobalScope(name string) *BaseModel {
+	for i := range m.globalScopes {
+		if m.globalScopes[i].name == name {
+			m.globalScopes = append(m.globalScopes[:i], m.globalScopes[i+1:]...)
+			break
+		}
+	}
+	return m
+}
+
+// WithTrashed 查询包含已软删除的记录
+func (m *BaseModel) WithTrashed() (*db.QueryBuilder, error) {
+	query, err := m.Query()
+	if err != nil {
+		return nil, err
+	}
+	return query.WithoutGlobalScope(db.SoftDeleteScope), nil
+}
+
+// OnlyTrashed 只查询已软删除的记录
+func (m *BaseModel) OnlyTrashed() (*db.QueryBuilder, error) {
+	if !m.config.SoftDeletes {
+		return nil, fmt.Errorf("该模型未启用软删除")
+	}
+	query, err := m.WithTrashed()
+	if err != nil {
+		return nil, err
+	}
+	return query.WhereNotNull(m.config.DeletedAtCol), nil
 }
 
 // Where 支持多种参数式查询格式，返回QueryBuilder以支持链式调用
@@ -490,6 +685,19 @@ func (m *BaseModel) SetAttributes(attributes map[string]interface{}) *BaseModel
 	return m
 }
 
+// UseDefault 插入时省略这些列，即使设置了属性也不写入，让数据库的列默认值生效
+// 只影响插入，插入后模型中不保留这些列的属性，需要数据库生成的值时使用 SaveAndRefresh
+// 例如：order.Fill(input).UseDefault("status", "created_at").Save()
+func (m *BaseModel) UseDefault(columns ...string) *BaseModel {
+	if m.useDefaults == nil {
+		m.useDefaults = make(map[string]bool, len(columns))
+	}
+	for _, column := range columns {
+		m.useDefaults[column] = true
+	}
+	return m
+}
+
 // GetAttributes 获取所有属性
 func (m *BaseModel) GetAttributes() map[string]interface{} {
 	return m.attributes
@@ -501,7 +709,7 @@ func (m *BaseModel) ClearAttributes() *BaseModel {
 	return m
 }
 
-// Fill 填充模型属性
+// Fill 填充模型属性（不做批量赋值保护，外部输入请使用FillGuarded）
 func (m *BaseModel) Fill(data map[string]interface{}) *BaseModel {
 	for key, value := range data {
 		m.attributes[key] = value
@@ -509,6 +717,49 @@ func (m *BaseModel) Fill(data map[string]interface{}) *BaseModel {
 	return m
 }
 
+// SetFillable 设置允许批量赋值的字段（白名单），设置后优先于guarded
+func (m *BaseModel) SetFillable(fields ...string) *BaseModel {
+	m.fillable = fields
+	return m
+}
+
+// GetFillable 获取允许批量赋值的字段
+func (m *BaseModel) GetFillable() []string {
+	return m.fillable
+}
+
+// SetGuarded 设置禁止批量赋值的字段（黑名单），"*" 表示禁止所有字段
+func (m *BaseModel) SetGuarded(fields ...string) *BaseModel {
+	m.guarded = fields
+	return m
+}
+
+// GetGuarded 获取禁止批量赋值的字段
+func (m *BaseModel) GetGuarded() []string {
+	return m.guarded
+}
+
+// IsFillable 检查字段是否允许批量赋值
+func (m *BaseModel) IsFillable(key string) bool {
+	if len(m.fillable) > 0 {
+		return containsString(m.fillable, key)
+	}
+	if len(m.guarded) > 0 {
+		return !containsString(m.guarded, "*") && !containsString(m.guarded, key)
+	}
+	return true
+}
+
+// FillGuarded 按fillable/guarded过滤后填充模型属性，用于处理外部输入（如请求体）
+func (m *BaseModel) FillGuarded(data map[string]interface{}) *BaseModel {
+	for key, value := range data {
+		if m.IsFillable(key) {
+			m.attributes[key] = value
+		}
+	}
+	return m
+}
+
 // ============================================================================
 // 状态管理方法
 // ============================================================================
@@ -546,6 +797,189 @@ func (m *BaseModel) SetKey(key interface{}) *BaseModel {
 	return m
 }
 
+// GetKeys 获取所有主键列的值
+func (m *BaseModel) GetKeys() map[string]interface{} {
+	keys := make(map[string]interface{})
+	for _, key := range m.GetPrimaryKeys() {
+		keys[key] = m.GetAttribute(key)
+	}
+	return keys
+}
+
+// isPrimaryKey 检查列是否为主键列
+func (m *BaseModel) isPrimaryKey(column string) bool {
+	return containsString(m.GetPrimaryKeys(), column)
+}
+
+// whereKeys 按所有主键列添加 WHERE k1 = ? AND k2 = ? 条件
+func (m *BaseModel) whereKeys(query *db.QueryBuilder, values map[string]interface{}) (*db.QueryBuilder, error) {
+	for _, key := range m.GetPrimaryKeys() {
+		value, exists := values[key]
+		if !exists || value == nil {
+			return nil, fmt.Errorf("主键值不能为空: %s", key)
+		}
+		query = query.Where(key, "=", value)
+	}
+	return query, nil
+}
+
+// WhereKey 按主键过滤，使用模型配置的主键列，调用方不需要硬编码 "id"
+// 单主键时 id 可以是单个值或切片（生成 IN 条件）；复合主键时传入 map[string]interface{} 或其切片
+func (m *BaseModel) WhereKey(id interface{}) (*db.QueryBuilder, error) {
+	return m.whereKey(id, false)
+}
+
+// WhereKeyNot 排除指定主键的记录，参数形式与 WhereKey 相同
+func (m *BaseModel) WhereKeyNot(id interface{}) (*db.QueryBuilder, error) {
+	return m.whereKey(id, true)
+}
+
+// whereKey 构建主键条件，not 为 true 时取反
+func (m *BaseModel) whereKey(id interface{}, not bool) (*db.QueryBuilder, error) {
+	if id == nil {
+		return nil, fmt.Errorf("主键值不能为空")
+	}
+	query, err := m.Query()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.HasCompositePrimaryKey() {
+		return m.whereCompositeKey(query, id, not)
+	}
+
+	key := m.config.PrimaryKey
+	if keys, ok := id.(map[string]interface{}); ok {
+		if id = keys[key]; id == nil {
+			return nil, fmt.Errorf("主键值不能为空: %s", key)
+		}
+	}
+	if values, ok := keyValueList(id); ok {
+		if not {
+			return query.WhereNotIn(key, values), nil
+		}
+		return query.WhereIn(key, values), nil
+	}
+	if not {
+		return query.Where(key, "!=", id), nil
+	}
+	return query.Where(key, "=", id), nil
+}
+
+// whereCompositeKey 构建复合主键条件
+// 多组主键生成 (k1 = ? AND k2 = ?) OR (...)，取反时每组生成 (k1 != ? OR k2 != ?)
+func (m *BaseModel) whereCompositeKey(query *db.QueryBuilder, id interface{}, not bool) (*db.QueryBuilder, error) {
+	var keySets []map[string]interface{}
+	switch v := id.(type) {
+	case map[string]interface{}:
+		keySets = []map[string]interface{}{v}
+	case []map[string]interface{}:
+		keySets = v
+	default:
+		return nil, fmt.Errorf("复合主键模型请传入 map[string]interface{} 或其切片")
+	}
+
+	keys := m.GetPrimaryKeys()
+	for _, values := range keySets {
+		for _, key := range keys {
+			if value, exists := values[key]; !exists || value == nil {
+				return nil, fmt.Errorf("主键值不能为空: %s", key)
+			}
+		}
+	}
+
+	if not {
+		for _, values := range keySets {
+			values := values
+			query = query.WhereGroup(func(group *db.QueryBuilder) {
+				for _, key := range keys {
+					group.OrWhere(key, "!=", values[key])
+				}
+			})
+		}
+		return query, nil
+	}
+
+	if len(keySets) == 1 {
+		return m.whereKeys(query, keySets[0])
+	}
+	return query.WhereGroup(func(group *db.QueryBuilder) {
+		for _, values := range keySets {
+			values := values
+			group.OrWhereGroup(func(set *db.QueryBuilder) {
+				for _, key := range keys {
+					set.Where(key, "=", values[key])
+				}
+			})
+		}
+	}), nil
+}
+
+// keyValueList 将切片或数组形式的主键值转换为 []interface{}，[]byte 视为单个值
+func keyValueList(id interface{}) ([]interface{}, bool) {
+	value := reflect.ValueOf(id)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		values[i] = value.Index(i).Interface()
+	}
+	return values, true
+}
+
+// IsDirty 检查属性自加载或上次保存后是否被修改，不传字段时检查所有属性
+func (m *BaseModel) IsDirty(fields ...string) bool {
+	dirty := m.GetDirty()
+	if len(fields) == 0 {
+		return len(dirty) > 0
+	}
+	for _, field := range fields {
+		if _, ok := dirty[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDirty 获取自加载或上次保存后被修改的属性
+// 未加载过的模型没有快照，所有属性都视为已修改
+func (m *BaseModel) GetDirty() map[string]interface{} {
+	dirty := make(map[string]interface{})
+	for key, value := range m.attributes {
+		original, exists := m.original[key]
+		if !exists || !attributeEqual(original, value) {
+			dirty[key] = value
+		}
+	}
+	return dirty
+}
+
+// GetChanges 获取最近一次保存时写入数据库的属性
+func (m *BaseModel) GetChanges() map[string]interface{} {
+	changes := make(map[string]interface{}, len(m.changes))
+	for key, value := range m.changes {
+		changes[key] = value
+	}
+	return changes
+}
+
+// GetOriginal 获取属性的原始值
+func (m *BaseModel) GetOriginal(key string) interface{} {
+	return m.original[key]
+}
+
+// syncOriginal 将当前属性保存为原始快照
+func (m *BaseModel) syncOriginal() {
+	m.original = make(map[string]interface{}, len(m.attributes))
+	for key, value := range m.attributes {
+		m.original[key] = value
+	}
+}
+
 // ============================================================================
 // 查询执行方法 - 直接在BaseModel上执行查询
 // ============================================================================
@@ -568,7 +1002,7 @@ func (m *BaseModel) GetRaw() ([]map[string]interface{}, error) {
 	return query.GetRaw()
 }
 
-// First 获取第一条记录（应用访问器处理）
+// First 获取第一条记录（应用访问器处理），未找到记录时返回 ErrRecordNotFound
 func (m *BaseModel) First() (map[string]interface{}, error) {
 	query, err := m.Query()
 	if err != nil {
@@ -577,7 +1011,7 @@ func (m *BaseModel) First() (map[string]interface{}, error) {
 	return query.First()
 }
 
-// FirstRaw 获取第一条记录的原始数据（不应用访问器处理）
+// FirstRaw 获取第一条记录的原始数据（不应用访问器处理），未找到记录时返回 ErrRecordNotFound
 func (m *BaseModel) FirstRaw() (map[string]interface{}, error) {
 	query, err := m.Query()
 	if err != nil {
@@ -586,7 +1020,7 @@ func (m *BaseModel) FirstRaw() (map[string]interface{}, error) {
 	return query.FirstRaw()
 }
 
-// Count 计算记录数量
+// Count 计算记录数量，启用软删除时不包含已软删除的记录
 func (m *BaseModel) Count() (int64, error) {
 	query, err := m.Query()
 	if err != nil {
@@ -595,6 +1029,51 @@ func (m *BaseModel) Count() (int64, error) {
 	return query.Count()
 }
 
+// CountWithTrashed 计算记录数量，包含已软删除的记录
+func (m *BaseModel) CountWithTrashed() (int64, error) {
+	query, err := m.WithTrashed()
+	if err != nil {
+		return 0, err
+	}
+	return query.Count()
+}
+
+// CountOnlyTrashed 计算已软删除的记录数量，模型未启用软删除时返回错误
+func (m *BaseModel) CountOnlyTrashed() (int64, error) {
+	query, err := m.OnlyTrashed()
+	if err != nil {
+		return 0, err
+	}
+	return query.Count()
+}
+
+// Exists 检查是否存在记录，启用软删除时不包含已软删除的记录
+func (m *BaseModel) Exists() (bool, error) {
+	query, err := m.Query()
+	if err != nil {
+		return false, err
+	}
+	return query.Exists()
+}
+
+// ExistsWithTrashed 检查是否存在记录，包含已软删除的记录
+func (m *BaseModel) ExistsWithTrashed() (bool, error) {
+	query, err := m.WithTrashed()
+	if err != nil {
+		return false, err
+	}
+	return query.Exists()
+}
+
+// ExistsOnlyTrashed 检查是否存在已软删除的记录，模型未启用软删除时返回错误
+func (m *BaseModel) ExistsOnlyTrashed() (bool, error) {
+	query, err := m.OnlyTrashed()
+	if err != nil {
+		return false, err
+	}
+	return query.Exists()
+}
+
 // ============================================================================
 // 访问器支持方法
 // ============================================================================
@@ -624,7 +1103,10 @@ func (m *BaseModel) SetAttributesWithAccessor(model interface{}, data map[string
 // ============================================================================
 
 // Save 保存模型
+// 插入或更新的结果可通过 LastInsertID 和 RowsAffected 获取，成功后通知 Observe 注册的观察者
 func (m *BaseModel) Save() error {
+	m.lastInsertID, m.rowsAffected = 0, 0
+
 	query, err := m.Query()
 	if err != nil {
 		return err
@@ -636,18 +1118,28 @@ func (m *BaseModel) Save() error {
 		if len(data) == 0 {
 			return fmt.Errorf("没有要插入的数据")
 		}
+		if err := m.validateEnumValues(data); err != nil {
+			return err
+		}
 
 		id, err := query.Insert(data)
 		if err != nil {
 			return fmt.Errorf("模型插入失败: %w", err)
 		}
+		m.lastInsertID, m.rowsAffected = id, 1
+		for column := range m.useDefaults {
+			delete(m.attributes, column)
+		}
 
-		// 设置主键值（如果是自增的）
-		if id > 0 {
+		// 设置主键值（如果是自增的），复合主键由调用方赋值
+		if id > 0 && !m.HasCompositePrimaryKey() {
 			m.SetAttribute(m.config.PrimaryKey, id)
 		}
 
 		m.MarkAsExists()
+		m.changes = data
+		m.syncOriginal()
+		m.notifyObservers(eventCreated)
 		return nil
 	} else {
 		// 更新现有记录
@@ -655,43 +1147,174 @@ func (m *BaseModel) Save() error {
 		if len(data) == 0 {
 			return nil // 没有需要更新的数据
 		}
+		if err := m.validateEnumValues(data); err != nil {
+			return err
+		}
 
-		pk := m.GetKey()
-		if pk == nil {
-			return fmt.Errorf("主键值不能为空")
+		query, err = m.whereKeys(query, m.GetKeys())
+		if err != nil {
+			return err
 		}
 
-		affected, err := query.Where(m.config.PrimaryKey, "=", pk).Update(data)
+		affected, err := query.Update(data)
 		if err != nil {
 			return fmt.Errorf("模型更新失败: %w", err)
 		}
+		m.rowsAffected = affected
 
+		// MySQL 对值没有变化的行返回0，确认记录仍然存在时按成功处理
 		if affected == 0 {
-			return fmt.Errorf("没有找到要更新的记录")
+			exists, err := m.keysExist()
+			if err != nil {
+				return fmt.Errorf("模型更新失败: %w", err)
+			}
+			if !exists {
+				return fmt.Errorf("没有找到要更新的记录")
+			}
 		}
 
+		m.changes = data
+		for key, value := range data {
+			m.attributes[key] = value
+		}
+		m.syncOriginal()
+		m.notifyObservers(eventUpdated)
 		return nil
 	}
 }
 
-// FindByPK 根据主键查找
+// keysExist 按当前主键检查记录是否存在，与更新使用相同的查询作用域
+func (m *BaseModel) keysExist() (bool, error) {
+	query, err := m.Query()
+	if err != nil {
+		return false, err
+	}
+	query, err = m.whereKeys(query, m.GetKeys())
+	if err != nil {
+		return false, err
+	}
+	return query.Exists()
+}
+
+// LastInsertID 返回最近一次 Save 插入记录时数据库生成的自增ID，更新或未生成ID时为0
+func (m *BaseModel) LastInsertID() int64 {
+	return m.lastInsertID
+}
+
+// RowsAffected 返回最近一次 Save 影响的行数，插入成功为1，没有变更的属性而跳过更新时为0
+// 更新时为数据库报告的行数，MySQL 对值没有变化的行报告0
+func (m *BaseModel) RowsAffected() int64 {
+	return m.rowsAffected
+}
+
+// SaveAndRefresh 保存模型并重新加载数据库中的完整行，使模型包含数据库默认值和生成列
+// 支持RETURNING的数据库（PostgreSQL、SQLite）插入时一次往返完成
+func (m *BaseModel) SaveAndRefresh() error {
+	if m.IsNew() {
+		query, err := m.Query()
+		if err != nil {
+			return err
+		}
+
+		if query.SupportsReturning() {
+			m.lastInsertID, m.rowsAffected = 0, 0
+			data := m.prepareForInsert()
+			if len(data) == 0 {
+				return fmt.Errorf("没有要插入的数据")
+			}
+			if err := m.validateEnumValues(data); err != nil {
+				return err
+			}
+
+			row, err := query.InsertReturning(data)
+			if err != nil {
+				return fmt.Errorf("模型插入失败: %w", err)
+			}
+			m.rowsAffected = 1
+			if id, ok := row[m.config.PrimaryKey].(int64); ok && !m.HasCompositePrimaryKey() {
+				m.lastInsertID = id
+			}
+
+			m.attributes = row
+			m.MarkAsExists()
+			m.changes = data
+			m.syncOriginal()
+			m.notifyObservers(eventCreated)
+			return nil
+		}
+	}
+
+	if err := m.Save(); err != nil {
+		return err
+	}
+	return m.Refresh()
+}
+
+// Refresh 按已知主键从数据库重新加载模型属性，用数据库中的最新行替换全部属性（不保留行中不存在的键）
+// 适用于其他进程或并发请求修改记录之后；模型尚未保存（IsNew）时返回错误
+func (m *BaseModel) Refresh() error {
+	if m.IsNew() {
+		return fmt.Errorf("模型尚未保存，无法刷新")
+	}
+
+	query, err := m.WithTrashed()
+	if err != nil {
+		return err
+	}
+
+	query, err = m.whereKeys(query, m.GetKeys())
+	if err != nil {
+		return err
+	}
+
+	result, err := query.FirstRaw()
+	if err != nil {
+		return fmt.Errorf("刷新模型失败: %w", err)
+	}
+
+	m.attributes = result
+	m.MarkAsExists()
+	m.syncOriginal()
+	return nil
+}
+
+// FindByPK 根据主键查找，复合主键时传入 map[string]interface{}
+// 未找到记录时返回包装了 ErrRecordNotFound 的错误，可以用 errors.Is 判断
 func (m *BaseModel) FindByPK(key interface{}) error {
 	if key == nil {
 		return fmt.Errorf("主键值不能为空")
 	}
 
+	if keys, ok := key.(map[string]interface{}); ok {
+		return m.FindByKeys(keys)
+	}
+	if m.HasCompositePrimaryKey() {
+		return fmt.Errorf("复合主键模型请使用 FindByKeys 查找")
+	}
+
+	return m.FindByKeys(map[string]interface{}{m.config.PrimaryKey: key})
+}
+
+// FindByKeys 根据所有主键列的值查找记录
+func (m *BaseModel) FindByKeys(keys map[string]interface{}) error {
 	query, err := m.Query()
 	if err != nil {
 		return err
 	}
 
-	result, err := query.Where(m.config.PrimaryKey, "=", key).FirstRaw()
+	query, err = m.whereKeys(query, keys)
+	if err != nil {
+		return err
+	}
+
+	result, err := query.FirstRaw()
 	if err != nil {
 		return fmt.Errorf("查找模型失败: %w", err)
 	}
 
 	m.Fill(result)
 	m.MarkAsExists()
+	m.syncOriginal()
 	return nil
 }
 
@@ -719,16 +1342,16 @@ func (m *BaseModel) SoftDelete() error {
 		return err
 	}
 
-	pk := m.GetKey()
-	if pk == nil {
-		return fmt.Errorf("主键值不能为空")
+	query, err = m.whereKeys(query, m.GetKeys())
+	if err != nil {
+		return err
 	}
 
 	data := map[string]interface{}{
 		m.config.DeletedAtCol: time.Now(),
 	}
 
-	affected, err := query.Where(m.config.PrimaryKey, "=", pk).Update(data)
+	affected, err := query.Update(data)
 	if err != nil {
 		return fmt.Errorf("软删除失败: %w", err)
 	}
@@ -737,6 +1360,7 @@ func (m *BaseModel) SoftDelete() error {
 		return fmt.Errorf("没有找到要删除的记录")
 	}
 
+	m.notifyObservers(eventDeleted)
 	return nil
 }
 
@@ -751,16 +1375,16 @@ func (m *BaseModel) Restore() error {
 		return err
 	}
 
-	pk := m.GetKey()
-	if pk == nil {
-		return fmt.Errorf("主键值不能为空")
+	query, err = m.whereKeys(query.WithoutGlobalScope(db.SoftDeleteScope), m.GetKeys())
+	if err != nil {
+		return err
 	}
 
 	data := map[string]interface{}{
 		m.config.DeletedAtCol: nil,
 	}
 
-	_, err = query.Where(m.config.PrimaryKey, "=", pk).Update(data)
+	_, err = query.Update(data)
 	return err
 }
 
@@ -771,12 +1395,12 @@ func (m *BaseModel) ForceDelete() error {
 		return err
 	}
 
-	pk := m.GetKey()
-	if pk == nil {
-		return fmt.Errorf("主键值不能为空")
+	query, err = m.whereKeys(query.WithoutGlobalScope(db.SoftDeleteScope), m.GetKeys())
+	if err != nil {
+		return err
 	}
 
-	affected, err := query.Where(m.config.PrimaryKey, "=", pk).Delete()
+	affected, err := query.Delete()
 	if err != nil {
 		return fmt.Errorf("强制删除失败: %w", err)
 	}
@@ -786,6 +1410,7 @@ func (m *BaseModel) ForceDelete() error {
 	}
 
 	m.MarkAsNew()
+	m.notifyObservers(eventDeleted)
 	return nil
 }
 
@@ -828,28 +1453,104 @@ func (m *BaseModel) FromJSON(jsonStr string) error {
 func (m *BaseModel) HasOne(modelType interface{}, foreignKey, localKey string) *HasOne {
 	relatedType := getReflectType(modelType)
 	relatedTable := getTableNameFromModel(modelType)
-	return NewHasOneWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation := NewHasOneWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation.relatedModel = relatedModelOf(modelType)
+	return relation
 }
 
 // HasMany 一对多关联
 func (m *BaseModel) HasMany(modelType interface{}, foreignKey, localKey string) *HasMany {
 	relatedType := getReflectType(modelType)
 	relatedTable := getTableNameFromModel(modelType)
-	return NewHasManyWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation := NewHasManyWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation.relatedModel = relatedModelOf(modelType)
+	return relation
 }
 
 // BelongsTo 反向关联（多对一/一对一）
 func (m *BaseModel) BelongsTo(modelType interface{}, foreignKey, localKey string) *BelongsTo {
 	relatedType := getReflectType(modelType)
 	relatedTable := getTableNameFromModel(modelType)
-	return NewBelongsToWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation := NewBelongsToWithTable(m, relatedType, relatedTable, foreignKey, localKey)
+	relation.relatedModel = relatedModelOf(modelType)
+	return relation
 }
 
 // BelongsToMany 多对多关联
 func (m *BaseModel) BelongsToMany(modelType interface{}, pivotTable, foreignKey, localKey string) *BelongsToMany {
 	relatedType := getReflectType(modelType)
 	relatedTable := getTableNameFromModel(modelType)
-	return NewBelongsToManyWithTable(m, relatedType, relatedTable, pivotTable, foreignKey, localKey)
+	relation := NewBelongsToManyWithTable(m, relatedType, relatedTable, pivotTable, foreignKey, localKey)
+	relation.relatedModel = relatedModelOf(modelType)
+	return relation
+}
+
+// HasManyThrough 通过中间模型的远程一对多关联
+// firstKey 为中间表上指向当前模型的外键，secondKey 为关联表上指向中间表的外键
+// localKey 为当前模型的键（默认主键），secondLocalKey 为中间表的键（默认 id）
+func (m *BaseModel) HasManyThrough(related, through interface{}, firstKey, secondKey, localKey, secondLocalKey string) *HasManyThrough {
+	relatedType := getReflectType(related)
+	relatedTable := getTableNameFromModel(related)
+	throughTable := getTableNameFromModel(through)
+	relation := NewHasManyThroughWithTable(m, relatedType, relatedTable, throughTable, firstKey, secondKey, localKey, secondLocalKey)
+	relation.relatedModel = relatedModelOf(related)
+	return relation
+}
+
+// MorphMany 多态一对多关联，name 对应关联表的 {name}_type 和 {name}_id 列
+func (m *BaseModel) MorphMany(related interface{}, name string) *MorphMany {
+	relatedType := getReflectType(related)
+	relatedTable := getTableNameFromModel(related)
+	relation := NewMorphManyWithTable(m, relatedType, relatedTable, name)
+	relation.relatedModel = relatedModelOf(related)
+	return relation
+}
+
+// MorphTo 多态反向关联，根据当前模型的 {name}_type 和 {name}_id 列查询父模型
+// ownerKey 为父表中被引用的列，默认使用 RegisterMorphType 注册的键或 id
+func (m *BaseModel) MorphTo(name string, ownerKey ...string) *MorphTo {
+	return NewMorphTo(m, name, ownerKey...)
+}
+
+// SetInstance 绑定模型结构体实例，Has/WhereHas 通过实例上的同名方法获取关联定义
+// 使用 NewModel(structInstance) 创建的模型已自动绑定
+func (m *BaseModel) SetInstance(instance interface{}) *BaseModel {
+	m.instance = instance
+	return m
+}
+
+// Has 查询至少存在一条关联记录的模型，relation 为结构体上返回关联的方法名
+// 例如：query, err := userModel.Has("Posts")
+func (m *BaseModel) Has(relation string) (*db.QueryBuilder, error) {
+	return m.WhereHas(relation, nil)
+}
+
+// WhereHas 查询存在满足条件的关联记录的模型，生成关联的 EXISTS 子查询
+// 子查询带有关联模型的软删除和全局作用域，自关联时子查询中的关联表别名为 {表名}_related
+// 例如：userModel.WhereHas("Posts", func(q *db.QueryBuilder) { q.Where("status", "=", "published") })
+func (m *BaseModel) WhereHas(relation string, fn func(*db.QueryBuilder)) (*db.QueryBuilder, error) {
+	subQuery, err := m.relationExistsQuery(relation, fn)
+	if err != nil {
+		return nil, err
+	}
+	query, err := m.Query()
+	if err != nil {
+		return nil, err
+	}
+	return query.WhereExists(subQuery), nil
+}
+
+// WhereDoesntHave 查询不存在满足条件的关联记录的模型，fn 为 nil 时表示没有任何关联记录
+func (m *BaseModel) WhereDoesntHave(relation string, fn func(*db.QueryBuilder)) (*db.QueryBuilder, error) {
+	subQuery, err := m.relationExistsQuery(relation, fn)
+	if err != nil {
+		return nil, err
+	}
+	query, err := m.Query()
+	if err != nil {
+		return nil, err
+	}
+	return query.WhereNotExists(subQuery), nil
 }
 
 // ============================================================================
@@ -860,7 +1561,7 @@ func (m *BaseModel) BelongsToMany(modelType interface{}, pivotTable, foreignKey,
 func (m *BaseModel) AutoMigrate(models ...interface{}) error {
 	// 获取数据库管理器并创建连接
 	manager := db.DefaultManager()
-	conn, err := manager.Connection(m.config.Connection)
+	conn, err := manager.Connection(m.GetConnection())
 	if err != nil {
 		return fmt.Errorf("获取数据库连接失败: %w", err)
 	}
@@ -934,38 +1635,89 @@ func (m *BaseModel) prepareForInsert() map[string]interface{} {
 
 	// 处理时间戳字段
 	if m.config.Timestamps {
-		now := time.Now()
+		now := time.Now().In(m.location())
 		data[m.config.CreatedAtCol] = now
 		data[m.config.UpdatedAtCol] = now
 	}
 
 	// 处理时间字段管理
 	if m.timeManager != nil && len(m.timeFields) > 0 {
-		data = m.timeManager.ProcessInsertData(data, m.timeFields)
+		data = m.timeManager.WithLocation(m.location()).ProcessInsertData(data, m.timeFields)
+	}
+
+	// 使用数据库默认值的列不写入
+	for column := range m.useDefaults {
+		delete(data, column)
 	}
 
 	return data
 }
 
-// prepareForUpdate 准备更新数据
+// validateEnumValues 校验写入数据中的枚举列取值，nil 表示写入NULL不校验
+func (m *BaseModel) validateEnumValues(data map[string]interface{}) error {
+	if m.config.SkipEnumValidation || len(m.config.EnumValues) == 0 {
+		return nil
+	}
+	for column, allowed := range m.config.EnumValues {
+		value, ok := data[column]
+		if !ok || value == nil {
+			continue
+		}
+		str := fmt.Sprint(value)
+		if b, isBytes := value.([]byte); isBytes {
+			str = string(b)
+		}
+		valid := false
+		for _, candidate := range allowed {
+			if candidate == str {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("字段 %s 的值 %q 不在允许的取值 %v 中", column, str, allowed)
+		}
+	}
+	return nil
+}
+
+// location 获取模型连接配置的时区
+func (m *BaseModel) location() *time.Location {
+	connName := m.GetConnection()
+	if connName == "" {
+		connName = "default"
+	}
+	conn, err := db.DefaultManager().Connection(connName)
+	if err != nil || conn.GetConfig() == nil {
+		return time.Local
+	}
+	return conn.GetConfig().GetLocation()
+}
+
+// prepareForUpdate 准备更新数据，只包含被修改的属性
 func (m *BaseModel) prepareForUpdate() map[string]interface{} {
 	data := make(map[string]interface{})
 
-	// 获取所有属性，除了主键
-	for key, value := range m.attributes {
-		if key != m.config.PrimaryKey {
+	// 获取被修改的属性，除了主键
+	for key, value := range m.GetDirty() {
+		if !m.isPrimaryKey(key) {
 			data[key] = value
 		}
 	}
 
+	// 没有修改时不更新时间戳
+	if len(data) == 0 {
+		return data
+	}
+
 	// 处理时间戳字段
 	if m.config.Timestamps {
-		data[m.config.UpdatedAtCol] = time.Now()
+		data[m.config.UpdatedAtCol] = time.Now().In(m.location())
 	}
 
 	// 处理时间字段管理
 	if m.timeManager != nil && len(m.timeFields) > 0 {
-		data = m.timeManager.ProcessUpdateData(data, m.timeFields)
+		data = m.timeManager.WithLocation(m.location()).ProcessUpdateData(data, m.timeFields)
 	}
 
 	return data
@@ -978,6 +1730,10 @@ func parseTagsIntoConfig(structInstance interface{}, config *ModelConfig) {
 		modelType = modelType.Elem()
 	}
 
+	// 标签中声明的主键覆盖配置中的主键
+	configKeys := config.PrimaryKeys
+	config.PrimaryKeys = nil
+
 	// 解析模型的标签
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
@@ -995,6 +1751,10 @@ func parseTagsIntoConfig(structInstance interface{}, config *ModelConfig) {
 		// 解析torm标签
 		parseFieldTag(field, tormTag, config)
 	}
+
+	if len(config.PrimaryKeys) == 0 {
+		config.PrimaryKeys = configKeys
+	}
 }
 
 // parseFieldTag 解析单个字段的标签
@@ -1039,7 +1799,7 @@ func parseTormKeyValue(part string, field reflect.StructField, config *ModelConf
 	}
 
 	key := strings.ToLower(strings.TrimSpace(kv[0]))
-	_ = strings.TrimSpace(kv[1]) // value currently not used in model config, handled by migration
+	value := strings.TrimSpace(kv[1])
 
 	switch key {
 	case "type":
@@ -1089,6 +1849,22 @@ func parseTormKeyValue(part string, field reflect.StructField, config *ModelConf
 	case "index":
 		// 带类型的索引：index:btree, index:hash
 		// 模型配置层面不处理，由migration包处理
+
+	case "values", "enum":
+		// 枚举取值：values:active|inactive|pending，Save 时校验
+		var allowed []string
+		for _, item := range strings.Split(value, "|") {
+			item = strings.Trim(strings.TrimSpace(item), "'\"")
+			if item != "" {
+				allowed = append(allowed, item)
+			}
+		}
+		if len(allowed) > 0 {
+			if config.EnumValues == nil {
+				config.EnumValues = make(map[string][]string)
+			}
+			config.EnumValues[getColumnNameFromField(field)] = allowed
+		}
 	}
 }
 
@@ -1099,8 +1875,9 @@ func parseTormFlagAdvanced(flag string, field reflect.StructField, config *Model
 
 	switch flag {
 	case "primary_key", "pk", "primary", "primarykey":
-		// 设置主键
-		config.PrimaryKey = columnName
+		// 设置主键，多个字段标记时组成复合主键
+		config.PrimaryKeys = append(config.PrimaryKeys, columnName)
+		config.PrimaryKey = config.PrimaryKeys[0]
 
 	case "auto_increment", "autoincrement", "auto_inc", "autoinc":
 		// 自增字段 - 这通常与primary_key一起使用
@@ -1264,3 +2041,55 @@ func getTableNameFromModel(modelType interface{}) string {
 	}
 	return toSnakeCase(reflectType.Name())
 }
+
+// containsString 检查字符串切片是否包含指定值
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeEqual 比较两个属性值是否相等，数字类型按数值比较，[]byte 按字符串比较
+func attributeEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+
+	if bytes, ok := a.([]byte); ok {
+		a = string(bytes)
+	}
+	if bytes, ok := b.([]byte); ok {
+		b = string(bytes)
+	}
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isNumberKind(va.Kind()) && isNumberKind(vb.Kind()) {
+		return va.Convert(reflect.TypeOf(float64(0))).Float() == vb.Convert(reflect.TypeOf(float64(0))).Float()
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && sa == sb
+	}
+	return false
+}
+
+// isNumberKind 是否为数字类型
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
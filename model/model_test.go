@@ -1,9 +1,15 @@
 package model
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/zhoudm1743/torm/db"
 )
 
 // TestUser 测试用户模型
@@ -836,3 +842,1276 @@ func TestCustomTableName(t *testing.T) {
 		t.Errorf("Expected table name 'my_custom_table' from empty struct, got '%s'", tableName)
 	}
 }
+
+func TestFillGuarded(t *testing.T) {
+	input := map[string]interface{}{
+		"username": "alice",
+		"email":    "alice@example.com",
+		"is_admin": true,
+	}
+
+	// 白名单优先
+	model := NewModel("users").SetFillable("username", "email").SetGuarded("email")
+	model.FillGuarded(input)
+	if model.GetAttribute("is_admin") != nil {
+		t.Error("Expected is_admin to be filtered by fillable")
+	}
+	if model.GetAttribute("email") != "alice@example.com" {
+		t.Error("Expected fillable to take precedence over guarded")
+	}
+
+	// 黑名单
+	model = NewModel("users").SetGuarded("is_admin")
+	model.FillGuarded(input)
+	if model.GetAttribute("is_admin") != nil || model.GetAttribute("username") != "alice" {
+		t.Errorf("Unexpected attributes with guarded: %v", model.GetAttributes())
+	}
+
+	// "*" 禁止所有字段
+	model = NewModel("users").SetGuarded("*")
+	model.FillGuarded(input)
+	if len(model.GetAttributes()) != 0 {
+		t.Errorf("Expected no attributes, got %v", model.GetAttributes())
+	}
+
+	// Fill 保持原有行为
+	model = NewModel("users").SetFillable("username")
+	model.Fill(input)
+	if model.GetAttribute("is_admin") != true {
+		t.Error("Expected Fill to keep assigning all keys")
+	}
+}
+
+func TestDirtyTracking(t *testing.T) {
+	model := NewModel("users")
+	model.Fill(map[string]interface{}{
+		"id":       int64(1),
+		"username": "alice",
+		"age":      int64(20),
+		"profile":  []byte("{}"),
+	})
+	model.MarkAsExists()
+	model.syncOriginal()
+
+	if model.IsDirty() {
+		t.Errorf("Expected clean model after sync, got dirty %v", model.GetDirty())
+	}
+
+	// 数值相同但类型不同不算修改
+	model.SetAttribute("age", 20)
+	model.SetAttribute("profile", "{}")
+	if model.IsDirty() {
+		t.Errorf("Expected equal values to be clean, got dirty %v", model.GetDirty())
+	}
+
+	model.SetAttribute("username", "bob")
+	if !model.IsDirty("username") || model.IsDirty("age") {
+		t.Error("Expected only username to be dirty")
+	}
+	if model.GetOriginal("username") != "alice" {
+		t.Errorf("Expected original username 'alice', got '%v'", model.GetOriginal("username"))
+	}
+
+	data := model.prepareForUpdate()
+	if len(data) != 2 || data["username"] != "bob" {
+		t.Errorf("Expected only username and updated_at in UPDATE, got %v", data)
+	}
+	if _, ok := data[model.GetUpdatedAtField()]; !ok {
+		t.Errorf("Expected updated_at in UPDATE, got %v", data)
+	}
+
+	// 没有修改时不产生更新
+	model.syncOriginal()
+	if data := model.prepareForUpdate(); len(data) != 0 {
+		t.Errorf("Expected empty UPDATE for clean model, got %v", data)
+	}
+}
+
+func TestModelGlobalScopes(t *testing.T) {
+	model := NewModel("users").EnableSoftDeletes()
+	model.RegisterGlobalScope("tenant", func(q *db.QueryBuilder) *db.QueryBuilder {
+		return q.Where("tenant_id", "=", 1)
+	})
+
+	query, err := model.Query()
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	sql, _, _ := query.Where("name", "=", "alice").ToSQL()
+	if sql != "SELECT * FROM users WHERE name = ? AND deleted_at IS NULL AND tenant_id = ?" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+
+	query, _ = model.WithTrashed()
+	sql, _, _ = query.ToSQL()
+	if sql != "SELECT * FROM users WHERE tenant_id = ?" {
+		t.Errorf("Unexpected WithTrashed SQL: %s", sql)
+	}
+
+	query, _ = model.OnlyTrashed()
+	sql, _, _ = query.ToSQL()
+	if sql != "SELECT * FROM users WHERE deleted_at IS NOT NULL AND tenant_id = ?" {
+		t.Errorf("Unexpected OnlyTrashed SQL: %s", sql)
+	}
+
+	model.RemoveGlobalScope("tenant").DisableSoftDeletes()
+	query, _ = model.Query()
+	sql, _, _ = query.ToSQL()
+	if sql != "SELECT * FROM users" {
+		t.Errorf("Unexpected SQL after removing scopes: %s", sql)
+	}
+}
+
+// TestOrderItem 复合主键测试模型
+type TestOrderItem struct {
+	BaseModel
+	OrderID   int `json:"order_id" torm:"primary_key"`
+	ProductID int `json:"product_id" torm:"primary_key"`
+	Quantity  int `json:"quantity"`
+}
+
+func TestCompositePrimaryKey(t *testing.T) {
+	model := NewModel(&TestOrderItem{})
+	if !model.HasCompositePrimaryKey() {
+		t.Fatalf("Expected composite primary key, got %v", model.GetPrimaryKeys())
+	}
+	keys := model.GetPrimaryKeys()
+	if len(keys) != 2 || keys[0] != "order_id" || keys[1] != "product_id" {
+		t.Errorf("Unexpected primary keys: %v", keys)
+	}
+
+	model.Fill(map[string]interface{}{"order_id": 1, "product_id": 2, "quantity": 3})
+	model.MarkAsExists()
+	model.syncOriginal()
+	model.SetAttribute("quantity", 5)
+
+	data := model.prepareForUpdate()
+	if _, ok := data["order_id"]; ok {
+		t.Errorf("Expected primary keys excluded from UPDATE, got %v", data)
+	}
+	if _, ok := data["product_id"]; ok {
+		t.Errorf("Expected primary keys excluded from UPDATE, got %v", data)
+	}
+
+	query, _ := model.Query()
+	query, err := model.whereKeys(query, model.GetKeys())
+	if err != nil {
+		t.Fatalf("whereKeys failed: %v", err)
+	}
+	sql, args, _ := query.ToSQL()
+	if sql != "SELECT * FROM test_order_item WHERE order_id = ? AND product_id = ?" || len(args) != 2 {
+		t.Errorf("Unexpected SQL: %s %v", sql, args)
+	}
+
+	query, _ = model.Query()
+	if _, err := model.whereKeys(query, map[string]interface{}{"order_id": 1}); err == nil {
+		t.Error("Expected error when a key value is missing")
+	}
+	if err := model.FindByPK(1); err == nil {
+		t.Error("Expected error when finding composite model by a single value")
+	}
+
+	model.SetPrimaryKey("id")
+	if model.HasCompositePrimaryKey() || model.GetPrimaryKeys()[0] != "id" {
+		t.Errorf("Expected single primary key after SetPrimaryKey, got %v", model.GetPrimaryKeys())
+	}
+}
+
+func TestSaveAndRefresh(t *testing.T) {
+	err := db.AddConnection("model_refresh", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_refresh",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status TEXT DEFAULT 'draft')"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	item := NewModel("items", "model_refresh").DisableTimestamps()
+	item.SetAttribute("name", "widget")
+	if err := item.SaveAndRefresh(); err != nil {
+		t.Fatalf("SaveAndRefresh failed: %v", err)
+	}
+	if item.GetAttribute("status") != "draft" {
+		t.Errorf("Expected database default 'draft', got '%v'", item.GetAttribute("status"))
+	}
+	if item.GetKey() == nil || item.IsNew() || item.IsDirty() {
+		t.Errorf("Expected persisted clean model, got %v", item.GetAttributes())
+	}
+
+	// 更新后刷新
+	item.SetAttribute("name", "gadget")
+	if err := item.SaveAndRefresh(); err != nil {
+		t.Fatalf("SaveAndRefresh update failed: %v", err)
+	}
+	if item.GetAttribute("name") != "gadget" || item.GetAttribute("status") != "draft" {
+		t.Errorf("Unexpected attributes after update: %v", item.GetAttributes())
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	err := db.AddConnection("model_reload", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_reload",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	item := NewModel("items", "model_reload").DisableTimestamps()
+	if err := item.Refresh(); err == nil {
+		t.Error("Expected Refresh on unsaved model to fail")
+	}
+
+	item.SetAttribute("name", "widget")
+	item.SetAttribute("status", "draft")
+	if err := item.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// 模拟其他请求修改记录，并在模型上留下不属于表的临时属性
+	if _, err := db.DefaultManager().Exec("model_reload", "UPDATE items SET status = 'published'"); err != nil {
+		t.Fatalf("external update failed: %v", err)
+	}
+	item.SetAttribute("name", "unsaved")
+	item.SetAttribute("tmp_flag", true)
+
+	if err := item.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if item.GetAttribute("status") != "published" || item.GetAttribute("name") != "widget" {
+		t.Errorf("Expected fresh row, got %v", item.GetAttributes())
+	}
+	if _, exists := item.GetAttributes()["tmp_flag"]; exists {
+		t.Error("Expected stale attribute to be cleared")
+	}
+	if item.IsDirty() {
+		t.Errorf("Expected clean model after Refresh, dirty: %v", item.GetDirty())
+	}
+}
+
+// 测试模型操作参与外部事务
+func TestModelWithTransaction(t *testing.T) {
+	err := db.AddConnection("model_tx", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_tx",
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	// 事务回滚后记录不存在
+	rollback := errors.New("rollback")
+	err = db.Transaction(func(tx db.TransactionInterface) error {
+		account := NewModel("accounts", "model_tx").DisableTimestamps().WithTransaction(tx)
+		account.SetAttribute("name", "temp")
+		if err := account.Save(); err != nil {
+			return err
+		}
+		// 单连接下只有在同一事务中才能读到未提交的数据
+		found := NewModel("accounts", "model_tx").WithTransaction(tx)
+		if err := found.FindByPK(account.GetKey()); err != nil {
+			return err
+		}
+		if found.GetAttribute("name") != "temp" {
+			t.Errorf("Expected uncommitted row inside transaction, got %v", found.GetAttributes())
+		}
+		return rollback
+	}, "model_tx")
+	if err != rollback {
+		t.Fatalf("Expected rollback error, got %v", err)
+	}
+
+	query, _ := NewModel("accounts", "model_tx").Query()
+	count, err := query.Count()
+	if err != nil || count != 0 {
+		t.Errorf("Expected rolled back insert, got %d rows (%v)", count, err)
+	}
+
+	// 提交后记录存在，且取消事务后模型可继续使用
+	account := NewModel("accounts", "model_tx").DisableTimestamps()
+	err = db.Transaction(func(tx db.TransactionInterface) error {
+		account.WithTransaction(tx).SetAttribute("name", "kept")
+		return account.Save()
+	}, "model_tx")
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	account.WithoutTransaction()
+	if account.GetTransaction() != nil {
+		t.Error("Expected transaction to be cleared")
+	}
+	query, _ = account.Query()
+	if count, _ := query.Count(); count != 1 {
+		t.Errorf("Expected committed insert, got %d rows", count)
+	}
+}
+
+// 测试远程一对多关联
+func TestHasManyThrough(t *testing.T) {
+	err := db.AddConnection("model_through", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	statements := []string{
+		"CREATE TABLE countries (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, country_id INTEGER, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, title TEXT)",
+		"INSERT INTO countries (id, name) VALUES (1, 'cn'), (2, 'us')",
+		"INSERT INTO users (id, country_id, name) VALUES (1, 1, 'alice'), (2, 1, 'bob'), (3, 2, 'carol')",
+		"INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'a1'), (2, 2, 'b1'), (3, 2, 'b2'), (4, 3, 'c1')",
+	}
+	for _, statement := range statements {
+		if _, err := db.DefaultManager().Exec("model_through", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	country := NewModel("countries", "model_through")
+	country.SetAttribute("id", 1)
+
+	// 默认键：posts.user_id、countries.id、users.id
+	posts, err := country.HasManyThrough(NewModel("posts"), NewModel("users"), "country_id", "", "", "").
+		OrderBy("posts.id", "ASC").Get()
+	if err != nil {
+		t.Fatalf("HasManyThrough Get failed: %v", err)
+	}
+	if len(posts) != 3 {
+		t.Fatalf("Expected 3 posts, got %d: %v", len(posts), posts)
+	}
+	for i, title := range []string{"a1", "b1", "b2"} {
+		if posts[i]["title"] != title {
+			t.Errorf("Expected post %d title %s, got %v", i, title, posts[i]["title"])
+		}
+		if _, ok := posts[i]["country_id"]; ok {
+			t.Errorf("Expected only related columns, got %v", posts[i])
+		}
+	}
+
+	// 显式键与附加约束
+	relation := country.HasManyThrough(NewModel("posts"), NewModel("users"), "country_id", "user_id", "id", "id")
+	first, err := relation.Where("users.name", "=", "bob").First()
+	if err != nil {
+		t.Fatalf("HasManyThrough First failed: %v", err)
+	}
+	if first == nil || first["user_id"] != int64(2) {
+		t.Errorf("Expected bob's post, got %v", first)
+	}
+
+	// 本地键为空时返回空结果
+	empty := NewModel("countries", "model_through")
+	posts, err = empty.HasManyThrough(NewModel("posts"), NewModel("users"), "", "", "", "").Get()
+	if err != nil || len(posts) != 0 {
+		t.Errorf("Expected no posts without local key, got %v (%v)", posts, err)
+	}
+}
+
+// 测试多态关联
+func TestMorphRelations(t *testing.T) {
+	err := db.AddConnection("model_morph", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	statements := []string{
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)",
+		"CREATE TABLE videos (id INTEGER PRIMARY KEY, url TEXT)",
+		"CREATE TABLE comments (id INTEGER PRIMARY KEY, body TEXT, commentable_type TEXT, commentable_id INTEGER)",
+		"INSERT INTO posts (id, title) VALUES (1, 'hello'), (2, 'world')",
+		"INSERT INTO videos (id, url) VALUES (1, 'v.mp4')",
+		"INSERT INTO comments (id, body, commentable_type, commentable_id) VALUES " +
+			"(1, 'p1', 'posts', 1), (2, 'p2', 'posts', 1), (3, 'v1', 'videos', 1), (4, 'q1', 'posts', 2)",
+	}
+	for _, statement := range statements {
+		if _, err := db.DefaultManager().Exec("model_morph", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	// MorphMany 只返回类型匹配的子记录
+	post := NewModel("posts", "model_morph")
+	post.SetAttribute("id", 1)
+	comments, err := post.MorphMany(NewModel("comments"), "commentable").OrderBy("id", "ASC").Get()
+	if err != nil {
+		t.Fatalf("MorphMany Get failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0]["body"] != "p1" || comments[1]["body"] != "p2" {
+		t.Errorf("Expected post comments p1, p2, got %v", comments)
+	}
+
+	video := NewModel("videos", "model_morph")
+	video.SetAttribute("id", 1)
+	comments, err = video.MorphMany(NewModel("comments"), "commentable").Get()
+	if err != nil || len(comments) != 1 || comments[0]["body"] != "v1" {
+		t.Errorf("Expected video comment v1, got %v (%v)", comments, err)
+	}
+
+	// MorphTo 根据类型列查询父表
+	comment := NewModel("comments", "model_morph")
+	comment.SetAttributes(map[string]interface{}{"commentable_type": "videos", "commentable_id": 1})
+	parent, err := comment.MorphTo("commentable").First()
+	if err != nil {
+		t.Fatalf("MorphTo First failed: %v", err)
+	}
+	if parent["url"] != "v.mp4" {
+		t.Errorf("Expected video parent, got %v", parent)
+	}
+
+	if _, err := NewModel("comments", "model_morph").MorphTo("commentable").First(); err == nil {
+		t.Error("Expected error for empty morph type")
+	}
+
+	// 批量加载：按类型分组，缺失的父记录为 nil
+	rows, err := db.Table("comments", "model_morph")
+	if err != nil {
+		t.Fatalf("Table failed: %v", err)
+	}
+	records, err := rows.OrderBy("id", "ASC").GetRaw()
+	if err != nil {
+		t.Fatalf("GetRaw failed: %v", err)
+	}
+	children := make([]*BaseModel, 0, len(records)+1)
+	for _, record := range records {
+		child := NewModel("comments", "model_morph")
+		child.SetAttributes(record)
+		children = append(children, child)
+	}
+	orphan := NewModel("comments", "model_morph")
+	orphan.SetAttributes(map[string]interface{}{"commentable_type": "posts", "commentable_id": 99})
+	children = append(children, orphan)
+
+	parents, err := LoadMorphTo(children, "commentable")
+	if err != nil {
+		t.Fatalf("LoadMorphTo failed: %v", err)
+	}
+	if len(parents) != len(children) {
+		t.Fatalf("Expected %d parents, got %d", len(children), len(parents))
+	}
+	expected := []interface{}{"hello", "hello", "v.mp4", "world"}
+	for i, want := range expected {
+		if parents[i] == nil {
+			t.Errorf("Expected parent for child %d", i)
+			continue
+		}
+		got := parents[i]["title"]
+		if i == 2 {
+			got = parents[i]["url"]
+		}
+		if got != want {
+			t.Errorf("Child %d: expected %v, got %v", i, want, parents[i])
+		}
+	}
+	if parents[4] != nil {
+		t.Errorf("Expected nil parent for orphan, got %v", parents[4])
+	}
+
+	// 未注册映射时类型值必须是合法的表名
+	invalid := NewModel("comments", "model_morph")
+	invalid.SetAttributes(map[string]interface{}{"commentable_type": "posts; DROP TABLE posts", "commentable_id": 1})
+	if _, err := invalid.MorphTo("commentable").First(); err == nil {
+		t.Error("Expected error for invalid morph type")
+	}
+
+	// 注册类型映射后按别名查询父表，父表的被引用键可配置
+	for _, statement := range []string{
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY, slug TEXT, title TEXT)",
+		"INSERT INTO articles (id, slug, title) VALUES (7, 'go', 'Go')",
+		"INSERT INTO comments (id, body, commentable_type, commentable_id) VALUES (5, 'a1', 'article', 7)",
+	} {
+		if _, err := db.DefaultManager().Exec("model_morph", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+	RegisterMorphType("article", "articles")
+	defer RemoveMorphType("article")
+
+	article := NewModel("articles", "model_morph")
+	article.SetAttribute("id", 7)
+	comments, err = article.MorphMany(NewModel("comments"), "commentable").Get()
+	if err != nil || len(comments) != 1 || comments[0]["body"] != "a1" {
+		t.Errorf("Expected article comment a1, got %v (%v)", comments, err)
+	}
+
+	comment = NewModel("comments", "model_morph")
+	comment.SetAttributes(map[string]interface{}{"commentable_type": "article", "commentable_id": 7})
+	parent, err = comment.MorphTo("commentable").First()
+	if err != nil || parent["title"] != "Go" {
+		t.Errorf("Expected article parent, got %v (%v)", parent, err)
+	}
+	if _, err := NewModel("comments", "model_morph").SetAttributes(map[string]interface{}{"commentable_type": "videos", "commentable_id": 1}).MorphTo("commentable").First(); err == nil {
+		t.Error("Expected error for unregistered morph type once a morph map is registered")
+	}
+
+	bySlug := NewModel("comments", "model_morph")
+	bySlug.SetAttributes(map[string]interface{}{"commentable_type": "article", "commentable_id": "go"})
+	parents, err = LoadMorphTo([]*BaseModel{bySlug}, "commentable", "slug")
+	if err != nil || len(parents) != 1 || parents[0] == nil || parents[0]["title"] != "Go" {
+		t.Errorf("Expected article loaded by slug, got %v (%v)", parents, err)
+	}
+	parent, err = bySlug.MorphTo("commentable", "slug").First()
+	if err != nil || parent["title"] != "Go" {
+		t.Errorf("Expected article parent by slug, got %v (%v)", parent, err)
+	}
+}
+
+// hasAuthor 关联存在性查询的测试模型
+type hasAuthor struct {
+	BaseModel
+	ID   int    `json:"id" torm:"primary_key,auto_increment"`
+	Name string `json:"name"`
+}
+
+func (a *hasAuthor) GetTableName() string {
+	return "authors"
+}
+
+func (a *hasAuthor) Posts() *HasMany {
+	return a.HasMany(&hasArticle{}, "author_id", "id")
+}
+
+func (a *hasAuthor) PublishedPosts() *HasMany {
+	published := NewModel("articles").EnableSoftDeletes().RegisterGlobalScope("published", func(q *db.QueryBuilder) *db.QueryBuilder {
+		return q.Where("status", "=", "published")
+	})
+	return a.HasMany(published, "author_id", "id")
+}
+
+func (a *hasAuthor) Mentor() *BelongsTo {
+	return a.BelongsTo(NewModel("authors"), "mentor_id", "id")
+}
+
+func (a *hasAuthor) Mentees() *HasMany {
+	return a.HasMany(NewModel("authors"), "mentor_id", "id")
+}
+
+// hasArticle 带软删除的关联模型
+type hasArticle struct {
+	BaseModel
+	ID        int        `json:"id" torm:"primary_key,auto_increment"`
+	AuthorID  int        `json:"author_id"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at" torm:"soft_delete"`
+}
+
+func (a *hasArticle) GetTableName() string {
+	return "articles"
+}
+
+func (a *hasAuthor) Country() *BelongsTo {
+	return a.BelongsTo(NewModel("countries"), "country_id", "id")
+}
+
+// 测试关联存在性查询
+func TestWhereHas(t *testing.T) {
+	err := db.AddConnection("model_has", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	statements := []string{
+		"CREATE TABLE countries (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT, country_id INTEGER, mentor_id INTEGER)",
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY, author_id INTEGER, status TEXT, deleted_at DATETIME)",
+		"INSERT INTO countries (id, name) VALUES (1, 'cn')",
+		"INSERT INTO authors (id, name, country_id, mentor_id) VALUES (1, 'alice', 1, NULL), (2, 'bob', NULL, 1), (3, 'carol', 9, 2)",
+		"INSERT INTO articles (id, author_id, status, deleted_at) VALUES (1, 1, 'published', NULL), (2, 2, 'draft', NULL), (3, 3, 'published', '2024-01-01 00:00:00')",
+	}
+	for _, statement := range statements {
+		if _, err := db.DefaultManager().Exec("model_has", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	author := NewModel(&hasAuthor{}).SetConnection("model_has")
+	names := func(query *db.QueryBuilder, err error) []interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		rows, err := query.OrderBy("id", "ASC").GetRaw()
+		if err != nil {
+			t.Fatalf("GetRaw failed: %v", err)
+		}
+		result := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			result = append(result, row["name"])
+		}
+		return result
+	}
+	expect := func(label string, got []interface{}, want ...interface{}) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %v, got %v", label, want, got)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected %v, got %v", label, want, got)
+				return
+			}
+		}
+	}
+
+	// carol 的文章已软删除，不计入关联
+	expect("Has", names(author.Has("Posts")), "alice", "bob")
+	expect("WhereHas", names(author.WhereHas("Posts", func(q *db.QueryBuilder) {
+		q.Where("status", "=", "published")
+	})), "alice")
+	expect("WhereDoesntHave", names(author.WhereDoesntHave("Posts", nil)), "carol")
+	expect("WhereDoesntHave with constraint", names(author.WhereDoesntHave("Posts", func(q *db.QueryBuilder) {
+		q.Where("status", "=", "published")
+	})), "bob", "carol")
+	expect("Has BelongsTo", names(author.Has("Country")), "alice")
+
+	// 关联模型的全局作用域同样应用到子查询
+	expect("Has with related global scope", names(author.Has("PublishedPosts")), "alice")
+
+	// 自关联时子查询的关联表使用别名，不与外层表混淆
+	expect("Has self HasMany", names(author.Has("Mentees")), "alice", "bob")
+	expect("Has self BelongsTo", names(author.Has("Mentor")), "bob", "carol")
+	expect("WhereHas self relation", names(author.WhereHas("Mentees", func(q *db.QueryBuilder) {
+		q.Where("name", "=", "carol")
+	})), "bob")
+	expect("WhereDoesntHave self relation", names(author.WhereDoesntHave("Mentees", nil)), "carol")
+
+	// 子查询带参数时与外层条件的占位符顺序正确
+	query, err := author.WhereHas("Posts", func(q *db.QueryBuilder) {
+		q.Where("status", "=", "draft")
+	})
+	expect("WhereHas with outer condition", names(query.Where("name", "!=", "alice"), err), "bob")
+
+	if _, err := author.Has("Missing"); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+	if _, err := NewModel("authors", "model_has").Has("Posts"); err == nil {
+		t.Error("Expected error for model without struct instance")
+	}
+}
+
+// 测试多对多中间表的维护
+func TestBelongsToManyPivot(t *testing.T) {
+	err := db.AddConnection("model_pivot", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	statements := []string{
+		"CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE role_users (user_id INTEGER, role_id INTEGER, role TEXT)",
+		"INSERT INTO roles (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd')",
+	}
+	for _, statement := range statements {
+		if _, err := db.DefaultManager().Exec("model_pivot", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	user := NewModel("users", "model_pivot")
+	user.SetAttribute("id", 7)
+	roles := func() *BelongsToMany {
+		return user.BelongsToMany(NewModel("roles"), "role_users", "role_id", "user_id")
+	}
+	pivotRows := func() []map[string]interface{} {
+		t.Helper()
+		query, _ := db.Table("role_users", "model_pivot")
+		rows, err := query.OrderBy("role_id", "ASC").GetRaw()
+		if err != nil {
+			t.Fatalf("query pivot failed: %v", err)
+		}
+		return rows
+	}
+	roleIDs := func() []int64 {
+		t.Helper()
+		ids := make([]int64, 0)
+		for _, row := range pivotRows() {
+			ids = append(ids, row["role_id"].(int64))
+		}
+		return ids
+	}
+	expectIDs := func(label string, want ...int64) {
+		t.Helper()
+		got := roleIDs()
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected role ids %v, got %v", label, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: expected role ids %v, got %v", label, want, got)
+			}
+		}
+	}
+
+	// 附加列写入中间表
+	if err := roles().Attach([]interface{}{1, 2}, map[string]interface{}{"role": "admin"}); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	expectIDs("Attach", 1, 2)
+	for _, row := range pivotRows() {
+		if row["role"] != "admin" || row["user_id"] != int64(7) {
+			t.Errorf("Expected pivot data on attached row, got %v", row)
+		}
+	}
+
+	related, err := roles().Get()
+	if err != nil || len(related) != 2 {
+		t.Errorf("Expected 2 related roles, got %v (%v)", related, err)
+	}
+
+	// Sync 只删除多余的、添加缺少的，已有行的附加列保留
+	if err := roles().Sync([]interface{}{int64(2), 3, 3, 4}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	expectIDs("Sync", 2, 3, 4)
+	for _, row := range pivotRows() {
+		kept := row["role_id"] == int64(2)
+		if kept != (row["role"] == "admin") {
+			t.Errorf("Expected pivot data only on kept row, got %v", row)
+		}
+	}
+
+	// Detach 支持多个ID或单个切片，不传ID时不删除
+	if err := roles().Detach(); err != nil {
+		t.Fatalf("Detach without ids failed: %v", err)
+	}
+	expectIDs("Detach none", 2, 3, 4)
+	if err := roles().Detach([]int64{2, 3}); err != nil {
+		t.Fatalf("Detach failed: %v", err)
+	}
+	expectIDs("Detach slice", 4)
+	if err := roles().DetachAll(); err != nil {
+		t.Fatalf("DetachAll failed: %v", err)
+	}
+	expectIDs("DetachAll")
+}
+
+// 测试 Save 的插入ID和影响行数
+func TestSaveResult(t *testing.T) {
+	err := db.AddConnection("model_save_result", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_save_result",
+		"CREATE TABLE notes (id INTEGER PRIMARY KEY AUTOINCREMENT, body TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	note := NewModel("notes", "model_save_result").DisableTimestamps()
+	note.SetAttribute("body", "first")
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save insert failed: %v", err)
+	}
+	if note.LastInsertID() != 1 || note.RowsAffected() != 1 {
+		t.Errorf("Expected insert id 1 and 1 row, got %d, %d", note.LastInsertID(), note.RowsAffected())
+	}
+
+	// 更新后插入ID清零
+	note.SetAttribute("body", "changed")
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save update failed: %v", err)
+	}
+	if note.LastInsertID() != 0 || note.RowsAffected() != 1 {
+		t.Errorf("Expected update to affect 1 row, got id %d, %d rows", note.LastInsertID(), note.RowsAffected())
+	}
+
+	// 没有变更时不执行更新
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save without changes failed: %v", err)
+	}
+	if note.RowsAffected() != 0 {
+		t.Errorf("Expected 0 rows without changes, got %d", note.RowsAffected())
+	}
+
+	second := NewModel("notes", "model_save_result").DisableTimestamps()
+	second.SetAttribute("body", "second")
+	if err := second.SaveAndRefresh(); err != nil {
+		t.Fatalf("SaveAndRefresh failed: %v", err)
+	}
+	if second.LastInsertID() != 2 || second.RowsAffected() != 1 {
+		t.Errorf("Expected insert id 2 and 1 row, got %d, %d", second.LastInsertID(), second.RowsAffected())
+	}
+
+	// 记录存在但数据库报告0行（如 MySQL 的值未变化）时不报错，用触发器跳过更新模拟
+	if _, err := db.DefaultManager().Exec("model_save_result",
+		"CREATE TRIGGER skip_note_update BEFORE UPDATE ON notes BEGIN SELECT RAISE(IGNORE); END"); err != nil {
+		t.Fatalf("create trigger failed: %v", err)
+	}
+	second.SetAttribute("body", "unchanged")
+	if err := second.Save(); err != nil || second.RowsAffected() != 0 {
+		t.Errorf("Expected no-op update of an existing row to succeed with 0 rows, got %d, %v", second.RowsAffected(), err)
+	}
+
+	// 记录不存在时仍然报错
+	missing := NewModel("notes", "model_save_result").DisableTimestamps()
+	missing.SetAttributes(map[string]interface{}{"id": 99, "body": "gone"})
+	missing.MarkAsExists()
+	missing.SetAttribute("body", "changed")
+	if err := missing.Save(); err == nil {
+		t.Error("Expected error when updating a missing row")
+	}
+}
+
+// observedNote 观察者测试模型
+type observedNote struct {
+	BaseModel
+	ID   int    `json:"id" torm:"primary_key,auto_increment"`
+	Body string `json:"body"`
+}
+
+func (n *observedNote) GetTableName() string {
+	return "observed_notes"
+}
+
+// recordingObserver 记录收到的事件
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) Created(m interface{}) { o.record("created", m) }
+func (o *recordingObserver) Updated(m interface{}) { o.record("updated", m) }
+func (o *recordingObserver) Deleted(m interface{}) { o.record("deleted", m) }
+
+func (o *recordingObserver) record(event string, m interface{}) {
+	if _, ok := m.(*observedNote); !ok {
+		event += fmt.Sprintf("(%T)", m)
+	}
+	o.events = append(o.events, event)
+}
+
+// 测试模型观察者
+func TestObserve(t *testing.T) {
+	err := db.AddConnection("model_observe", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_observe",
+		"CREATE TABLE observed_notes (id INTEGER PRIMARY KEY AUTOINCREMENT, body TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	observer := &recordingObserver{}
+	Observe(observedNote{}, observer)
+	defer RemoveObservers(&observedNote{})
+
+	note := NewModel(&observedNote{}).SetConnection("model_observe").DisableTimestamps()
+	note.SetAttribute("body", "first")
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save insert failed: %v", err)
+	}
+	note.SetAttribute("body", "changed")
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save update failed: %v", err)
+	}
+	// 没有变更时不通知
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save without changes failed: %v", err)
+	}
+	if err := note.Delete(); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	// 删除失败时不通知
+	if err := note.ForceDelete(); err == nil {
+		t.Error("Expected error deleting a missing record")
+	}
+
+	want := []string{"created", "updated", "deleted"}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("Expected events %v, got %v", want, observer.events)
+	}
+
+	// 其他模型不触发
+	plain := NewModel("observed_notes", "model_observe").DisableTimestamps()
+	plain.SetAttribute("body", "plain")
+	if err := plain.Save(); err != nil {
+		t.Fatalf("Save plain model failed: %v", err)
+	}
+	if len(observer.events) != 3 {
+		t.Errorf("Expected no events for models without the observed type, got %v", observer.events)
+	}
+}
+
+type enumAccount struct {
+	BaseModel
+	ID     int64  `json:"id" torm:"primary_key,auto_increment"`
+	Status string `json:"status" torm:"type:enum,values:active|inactive|pending"`
+}
+
+// 测试 Save 校验枚举列的取值
+func TestEnumValidation(t *testing.T) {
+	err := db.AddConnection("model_enum", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_enum",
+		"CREATE TABLE enum_account (id INTEGER PRIMARY KEY AUTOINCREMENT, status VARCHAR(255))"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	account := NewModel(&enumAccount{}).SetConnection("model_enum").DisableTimestamps()
+	if !reflect.DeepEqual(account.config.EnumValues["status"], []string{"active", "inactive", "pending"}) {
+		t.Fatalf("Unexpected enum values: %v", account.config.EnumValues)
+	}
+
+	account.SetAttribute("status", "deleted")
+	if err := account.Save(); err == nil {
+		t.Fatal("Expected error inserting a value outside the enum")
+	}
+	account.SetAttribute("status", "active")
+	if err := account.Save(); err != nil {
+		t.Fatalf("Save insert failed: %v", err)
+	}
+	account.SetAttribute("status", "archived")
+	if err := account.Save(); err == nil {
+		t.Fatal("Expected error updating to a value outside the enum")
+	}
+
+	// 关闭校验后交由数据库处理
+	account.config.SkipEnumValidation = true
+	if err := account.Save(); err != nil {
+		t.Fatalf("Save with validation skipped failed: %v", err)
+	}
+}
+
+func TestWhereKey(t *testing.T) {
+	if err := db.AddConnection("model_where_key", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+
+	user := NewModel("users", "model_where_key").SetPrimaryKey("user_id")
+	tests := []struct {
+		not      bool
+		id       interface{}
+		expected string
+	}{
+		{false, 7, "SELECT * FROM users WHERE user_id = ?"},
+		{true, 7, "SELECT * FROM users WHERE user_id != ?"},
+		{false, []int{1, 2}, "SELECT * FROM users WHERE user_id IN (?, ?)"},
+		{true, []interface{}{1, 2}, "SELECT * FROM users WHERE user_id NOT IN (?, ?)"},
+	}
+	for _, tt := range tests {
+		query, err := user.whereKey(tt.id, tt.not)
+		if err != nil {
+			t.Fatalf("whereKey(%v, %v) failed: %v", tt.id, tt.not, err)
+		}
+		if sql, _, _ := query.ToSQL(); sql != tt.expected {
+			t.Errorf("whereKey(%v, %v): expected '%s', got '%s'", tt.id, tt.not, tt.expected, sql)
+		}
+	}
+	if _, err := user.WhereKey(nil); err == nil {
+		t.Error("Expected error for nil key")
+	}
+
+	member := NewModel("members", "model_where_key").SetPrimaryKeys([]string{"tenant_id", "user_id"})
+	query, err := member.WhereKey(map[string]interface{}{"tenant_id": 1, "user_id": 2})
+	if err != nil {
+		t.Fatalf("WhereKey failed: %v", err)
+	}
+	if sql, args, _ := query.ToSQL(); sql != "SELECT * FROM members WHERE tenant_id = ? AND user_id = ?" || len(args) != 2 {
+		t.Errorf("Unexpected composite key SQL: %s %v", sql, args)
+	}
+
+	query, err = member.WhereKey([]map[string]interface{}{
+		{"tenant_id": 1, "user_id": 2},
+		{"tenant_id": 1, "user_id": 3},
+	})
+	if err != nil {
+		t.Fatalf("WhereKey failed: %v", err)
+	}
+	if sql, _, _ := query.ToSQL(); sql != "SELECT * FROM members WHERE ((tenant_id = ? AND user_id = ?) OR (tenant_id = ? AND user_id = ?))" {
+		t.Errorf("Unexpected composite key list SQL: %s", sql)
+	}
+
+	query, err = member.WhereKeyNot(map[string]interface{}{"tenant_id": 1, "user_id": 2})
+	if err != nil {
+		t.Fatalf("WhereKeyNot failed: %v", err)
+	}
+	if sql, _, _ := query.ToSQL(); sql != "SELECT * FROM members WHERE (tenant_id != ? OR user_id != ?)" {
+		t.Errorf("Unexpected composite key exclusion SQL: %s", sql)
+	}
+
+	if _, err := member.WhereKey(5); err == nil {
+		t.Error("Expected error for scalar key on composite primary key model")
+	}
+	if _, err := member.WhereKey(map[string]interface{}{"tenant_id": 1}); err == nil {
+		t.Error("Expected error for missing composite key column")
+	}
+}
+
+func TestCountAndExistsWithTrashed(t *testing.T) {
+	if err := db.AddConnection("model_trashed_count", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_trashed_count",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, deleted_at DATETIME)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_trashed_count",
+		"INSERT INTO posts (title, deleted_at) VALUES ('a', NULL), ('b', NULL), ('c', '2024-01-01 00:00:00')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	posts := NewModel("posts", "model_trashed_count").DisableTimestamps().EnableSoftDeletes()
+	counts := map[string]func() (int64, error){
+		"Count":            posts.Count,
+		"CountWithTrashed": posts.CountWithTrashed,
+		"CountOnlyTrashed": posts.CountOnlyTrashed,
+	}
+	expected := map[string]int64{"Count": 2, "CountWithTrashed": 3, "CountOnlyTrashed": 1}
+	for name, count := range counts {
+		n, err := count()
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if n != expected[name] {
+			t.Errorf("%s: expected %d, got %d", name, expected[name], n)
+		}
+	}
+
+	if _, err := db.DefaultManager().Exec("model_trashed_count", "UPDATE posts SET deleted_at = '2024-01-01 00:00:00'"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if exists, err := posts.Exists(); err != nil || exists {
+		t.Errorf("Expected no live posts, got %v, %v", exists, err)
+	}
+	if exists, err := posts.ExistsWithTrashed(); err != nil || !exists {
+		t.Errorf("Expected posts including trashed, got %v, %v", exists, err)
+	}
+	if exists, err := posts.ExistsOnlyTrashed(); err != nil || !exists {
+		t.Errorf("Expected trashed posts, got %v, %v", exists, err)
+	}
+
+	if _, err := posts.DisableSoftDeletes().CountOnlyTrashed(); err == nil {
+		t.Error("Expected CountOnlyTrashed to fail without soft deletes")
+	}
+}
+
+func TestModelOnConnection(t *testing.T) {
+	for _, name := range []string{"model_shard_1", "model_shard_2"} {
+		if err := db.AddConnection(name, &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+			t.Fatalf("AddConnection failed: %v", err)
+		}
+		if _, err := db.DefaultManager().Exec(name,
+			"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, deleted_at DATETIME)"); err != nil {
+			t.Fatalf("create table failed: %v", err)
+		}
+	}
+	if _, err := db.DefaultManager().Exec("model_shard_2",
+		"INSERT INTO posts (title, deleted_at) VALUES ('remote', NULL), ('removed', '2024-01-01 00:00:00')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	posts := NewModel("posts", "model_shard_1").DisableTimestamps().EnableSoftDeletes()
+	if count, err := posts.Count(); err != nil || count != 0 {
+		t.Fatalf("Expected empty default connection, got %d, %v", count, err)
+	}
+
+	query, err := posts.On("model_shard_2")
+	if err != nil {
+		t.Fatalf("On failed: %v", err)
+	}
+	rows, err := query.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["title"] != "remote" {
+		t.Errorf("Expected only the live remote post, got %v", rows)
+	}
+	if posts.GetConnection() != "model_shard_1" {
+		t.Errorf("Expected model connection to be unchanged, got %s", posts.GetConnection())
+	}
+
+	if _, err := posts.On(""); err == nil {
+		t.Error("Expected error for empty connection name")
+	}
+}
+
+func TestFindRecordNotFound(t *testing.T) {
+	err := db.AddConnection("model_not_found", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_not_found",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	item := NewModel("items", "model_not_found").DisableTimestamps()
+	if err := item.Find(42); !errors.Is(err, ErrRecordNotFound) || !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound from Find, got %v", err)
+	}
+	if _, err := item.First(); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound from First, got %v", err)
+	}
+}
+
+type tenantKey struct{}
+
+func TestConnectionResolver(t *testing.T) {
+	for _, name := range []string{"model_tenant_a", "model_tenant_b"} {
+		if err := db.AddConnection(name, &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1}); err != nil {
+			t.Fatalf("AddConnection failed: %v", err)
+		}
+		if _, err := db.DefaultManager().Exec(name, "CREATE TABLE notes (id INTEGER PRIMARY KEY AUTOINCREMENT, body TEXT)"); err != nil {
+			t.Fatalf("create table failed: %v", err)
+		}
+	}
+
+	resolver := func(ctx context.Context) string {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return "model_tenant_" + tenant
+		}
+		return ""
+	}
+
+	ctxB := context.WithValue(context.Background(), tenantKey{}, "b")
+	note := NewModel("notes", "model_tenant_a").DisableTimestamps().SetConnectionResolver(resolver).WithContext(ctxB)
+	note.SetAttribute("body", "tenant b note")
+	if err := note.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if note.GetConnection() != "model_tenant_b" {
+		t.Errorf("Expected resolved connection model_tenant_b, got %s", note.GetConnection())
+	}
+
+	if count, err := NewModel("notes", "model_tenant_b").Count(); err != nil || count != 1 {
+		t.Errorf("Expected note in tenant b, got %d, %v", count, err)
+	}
+	// 上下文中没有租户时使用配置的连接
+	fallback := NewModel("notes", "model_tenant_a").SetConnectionResolver(resolver)
+	if count, err := fallback.Count(); err != nil || count != 0 {
+		t.Errorf("Expected configured connection to be empty, got %d, %v", count, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctxB)
+	cancel()
+	if _, err := fallback.WithContext(cancelled).Get(); err == nil {
+		t.Error("Expected cancelled context to abort the query")
+	}
+}
+
+func TestUseDefault(t *testing.T) {
+	err := db.AddConnection("model_defaults", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	if _, err := db.DefaultManager().Exec("model_defaults",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, item TEXT, status TEXT NOT NULL DEFAULT 'pending')"); err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	order := NewModel("orders", "model_defaults").DisableTimestamps()
+	order.Fill(map[string]interface{}{"item": "book", "status": nil}).UseDefault("status")
+	if err := order.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, ok := order.GetAttributes()["status"]; ok {
+		t.Error("Expected defaulted column to be dropped from attributes after insert")
+	}
+	row, err := NewModel("orders", "model_defaults").Query()
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	saved, err := row.Where("id", "=", order.LastInsertID()).FirstRaw()
+	if err != nil || saved["status"] != "pending" {
+		t.Errorf("Expected database default status, got %v, %v", saved, err)
+	}
+
+	refreshed := NewModel("orders", "model_defaults").DisableTimestamps().UseDefault("status")
+	refreshed.SetAttribute("item", "pen").SetAttribute("status", "ignored")
+	if err := refreshed.SaveAndRefresh(); err != nil {
+		t.Fatalf("SaveAndRefresh failed: %v", err)
+	}
+	if refreshed.GetAttribute("status") != "pending" {
+		t.Errorf("Expected refreshed default status, got %v", refreshed.GetAttribute("status"))
+	}
+}
+
+// scanAuthor ScanWith 关联加载的测试模型
+type scanAuthor struct {
+	BaseModel
+	ID        int           `json:"id" torm:"primary_key"`
+	Name      string        `json:"name"`
+	CountryID *int          `json:"country_id"`
+	Articles  []scanArticle `relation:"Posts"`
+	Nation    *scanCountry  `relation:"Country"`
+}
+
+type scanArticle struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+type scanCountry struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (a *scanAuthor) GetTableName() string {
+	return "authors"
+}
+
+func (a *scanAuthor) Posts() *HasMany {
+	return a.HasMany(NewModel("articles"), "author_id", "id")
+}
+
+func (a *scanAuthor) Country() *BelongsTo {
+	return a.BelongsTo(NewModel("countries"), "country_id", "id")
+}
+
+func TestScanWithRelations(t *testing.T) {
+	err := db.AddConnection("model_scan_with", &db.Config{Driver: "sqlite", Database: ":memory:", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("AddConnection failed: %v", err)
+	}
+	statements := []string{
+		"CREATE TABLE countries (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT, country_id INTEGER)",
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY, author_id INTEGER, status TEXT)",
+		"INSERT INTO countries (id, name) VALUES (1, 'cn')",
+		"INSERT INTO authors (id, name, country_id) VALUES (1, 'alice', 1), (2, 'bob', NULL), (3, 'carol', 9)",
+		"INSERT INTO articles (id, author_id, status) VALUES (1, 1, 'published'), (2, 1, 'draft')",
+	}
+	for _, statement := range statements {
+		if _, err := db.DefaultManager().Exec("model_scan_with", statement); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+	authors := func() *db.QueryBuilder {
+		query, err := db.Table("authors", "model_scan_with")
+		if err != nil {
+			t.Fatalf("Table failed: %v", err)
+		}
+		return query
+	}
+
+	var alice scanAuthor
+	if err := authors().Where("id", "=", 1).ScanWith(&alice, "Posts", "Country"); err != nil {
+		t.Fatalf("ScanWith failed: %v", err)
+	}
+	if alice.Name != "alice" || len(alice.Articles) != 2 || alice.Articles[0].Status != "published" {
+		t.Errorf("Unexpected author or articles: %+v", alice)
+	}
+	if alice.Nation == nil || alice.Nation.Name != "cn" {
+		t.Errorf("Expected country to be loaded, got %+v", alice.Nation)
+	}
+	if alice.IsNew() || alice.GetConnection() != "model_scan_with" {
+		t.Errorf("Expected embedded model to be hydrated, new=%v connection=%s", alice.IsNew(), alice.GetConnection())
+	}
+
+	// 外键为空和关联记录不存在时关联字段保持零值
+	for _, id := range []int{2, 3} {
+		var author scanAuthor
+		if err := authors().Where("id", "=", id).ScanWith(&author, "Posts", "Country"); err != nil {
+			t.Fatalf("ScanWith(%d) failed: %v", id, err)
+		}
+		if author.Nation != nil || len(author.Articles) != 0 {
+			t.Errorf("Expected empty relations for author %d, got %+v", id, author)
+		}
+	}
+
+	var missing scanAuthor
+	if err := authors().Where("id", "=", 99).ScanWith(&missing, "Posts"); !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+	if err := authors().Where("id", "=", 1).ScanWith(&scanAuthor{}, "Comments"); err == nil {
+		t.Error("Expected error for unknown relation")
+	}
+}
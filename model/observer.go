@@ -0,0 +1,87 @@
+package model
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ModelObserver 模型观察者，在模型创建、更新、删除成功后调用
+// m 为模型结构体实例（NewModel 传入的结构体指针），适合审计日志、清理缓存等横切逻辑
+type ModelObserver interface {
+	Created(m interface{})
+	Updated(m interface{})
+	Deleted(m interface{})
+}
+
+var (
+	// observers 按模型结构体类型注册的观察者
+	observers      = make(map[reflect.Type][]ModelObserver)
+	observersMutex sync.RWMutex
+)
+
+// Observe 为模型类型注册观察者，modelType 可以是结构体、结构体指针或 reflect.Type
+// 通常在程序启动时注册，同一类型可以注册多个观察者，按注册顺序调用
+// 例如：model.Observe(&User{}, &UserAuditObserver{})
+func Observe(modelType interface{}, observer ModelObserver) {
+	if modelType == nil || observer == nil {
+		return
+	}
+	key := observerKey(modelType)
+
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+	observers[key] = append(observers[key], observer)
+}
+
+// RemoveObservers 移除模型类型的所有观察者
+func RemoveObservers(modelType interface{}) {
+	if modelType == nil {
+		return
+	}
+	key := observerKey(modelType)
+
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+	delete(observers, key)
+}
+
+// observerKey 观察者注册表的键，统一为非指针类型
+func observerKey(modelType interface{}) reflect.Type {
+	t := getReflectType(modelType)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// modelEvent 模型事件
+type modelEvent int
+
+const (
+	eventCreated modelEvent = iota
+	eventUpdated
+	eventDeleted
+)
+
+// notifyObservers 通知模型结构体类型上注册的观察者，没有结构体实例的模型不触发
+// 在事务中保存时观察者立即调用，不等待事务提交
+func (m *BaseModel) notifyObservers(event modelEvent) {
+	if m.instance == nil {
+		return
+	}
+
+	observersMutex.RLock()
+	registered := observers[observerKey(m.instance)]
+	observersMutex.RUnlock()
+
+	for _, observer := range registered {
+		switch event {
+		case eventCreated:
+			observer.Created(m.instance)
+		case eventUpdated:
+			observer.Updated(m.instance)
+		case eventDeleted:
+			observer.Deleted(m.instance)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/zhoudm1743/torm/db"
 )
@@ -36,6 +39,8 @@ type BaseRelation struct {
 	localKey string
 	// 关联表名
 	relatedTable string
+	// 关联模型，存在性查询通过它应用软删除和全局作用域
+	relatedModel *BaseModel
 }
 
 // NewBaseRelation 创建基础关联
@@ -71,6 +76,32 @@ func (r *BaseRelation) GetQuery() *db.QueryBuilder {
 	return r.query
 }
 
+// baseRelation 返回基础关联，供存在性查询获取关联模型和表名
+func (r *BaseRelation) baseRelation() *BaseRelation {
+	return r
+}
+
+// relatedBaseModel 返回关联模型，通过类型创建的关联按结构体标签解析配置
+func (r *BaseRelation) relatedBaseModel() *BaseModel {
+	related := r.relatedModel
+	if related == nil {
+		related = relatedModelOf(r.related)
+	}
+	if related == nil || related.config.TableName == "" {
+		related = NewModel(r.relatedTable)
+	}
+	return related
+}
+
+// relatedQuery 在指定连接上创建关联模型的查询，带有关联模型的软删除和全局作用域，table 可以带别名
+func (r *BaseRelation) relatedQuery(connName, table string) (*db.QueryBuilder, error) {
+	query, err := r.relatedBaseModel().queryOn(connName, nil)
+	if err != nil {
+		return nil, err
+	}
+	return query.From(table), nil
+}
+
 // ============================================================================
 // HasOne 一对一关联
 // ============================================================================
@@ -424,80 +455,774 @@ func (b *BelongsToMany) Limit(limit int) *BelongsToMany {
 	return b
 }
 
-// Attach 添加关联关系
-func (b *BelongsToMany) Attach(relatedID interface{}) error {
+// pivotQuery 创建中间表查询构建器，父模型绑定了事务时在该事务中执行
+func (b *BelongsToMany) pivotQuery(tx db.TransactionInterface) (*db.QueryBuilder, error) {
+	query, err := db.NewQueryBuilder(b.parent.GetConnection())
+	if err != nil {
+		return nil, fmt.Errorf("创建查询构建器失败: %w", err)
+	}
+	query = query.From(b.pivotTable)
+	if tx == nil {
+		tx = b.parent.transaction
+	}
+	if tx != nil {
+		query = query.InTransaction(tx)
+	}
+	return query, nil
+}
+
+// Attach 添加关联关系，pivotData 为写入中间表的附加列（如 role、created_at），每行相同
+// 例如：user.Roles().Attach([]interface{}{1, 2}, map[string]interface{}{"role": "admin"})
+func (b *BelongsToMany) Attach(ids []interface{}, pivotData map[string]interface{}) error {
+	return b.attach(nil, ids, pivotData)
+}
+
+// attach 向中间表批量插入关联行
+func (b *BelongsToMany) attach(tx db.TransactionInterface, ids []interface{}, pivotData map[string]interface{}) error {
+	ids = flattenIDs(ids)
+	if len(ids) == 0 {
+		return nil
+	}
 	localValue := b.parent.GetAttribute(b.parent.GetPrimaryKey())
 	if localValue == nil {
 		return fmt.Errorf("主键值为空")
 	}
 
-	query, err := db.NewQueryBuilder(b.parent.GetConnection())
+	rows := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		row := make(map[string]interface{}, len(pivotData)+2)
+		for column, value := range pivotData {
+			row[column] = value
+		}
+		row[b.pivotLocalKey] = localValue
+		row[b.pivotForeignKey] = id
+		rows = append(rows, row)
+	}
+
+	query, err := b.pivotQuery(tx)
 	if err != nil {
-		return fmt.Errorf("创建查询构建器失败: %w", err)
+		return err
+	}
+	if _, err := query.InsertBatch(rows); err != nil {
+		return fmt.Errorf("添加关联失败: %w", err)
 	}
+	return nil
+}
 
-	_, err = query.From(b.pivotTable).Insert(map[string]interface{}{
-		b.pivotLocalKey:   localValue,
-		b.pivotForeignKey: relatedID,
-	})
+// Detach 移除指定的关联关系，也可传入单个切片；未传入任何ID时不做任何操作，移除全部请使用 DetachAll
+func (b *BelongsToMany) Detach(ids ...interface{}) error {
+	return b.detach(nil, flattenIDs(ids))
+}
+
+// DetachAll 移除父模型的所有关联关系
+func (b *BelongsToMany) DetachAll() error {
+	localValue := b.parent.GetAttribute(b.parent.GetPrimaryKey())
+	if localValue == nil {
+		return fmt.Errorf("主键值为空")
+	}
 
+	query, err := b.pivotQuery(nil)
+	if err != nil {
+		return err
+	}
+	_, err = query.Where(b.pivotLocalKey, "=", localValue).Delete()
 	return err
 }
 
-// Detach 移除关联关系
-func (b *BelongsToMany) Detach(relatedID interface{}) error {
+// detach 从中间表删除指定的关联行
+func (b *BelongsToMany) detach(tx db.TransactionInterface, ids []interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
 	localValue := b.parent.GetAttribute(b.parent.GetPrimaryKey())
 	if localValue == nil {
 		return fmt.Errorf("主键值为空")
 	}
 
-	query, err := db.NewQueryBuilder(b.parent.GetConnection())
+	query, err := b.pivotQuery(tx)
 	if err != nil {
-		return fmt.Errorf("创建查询构建器失败: %w", err)
+		return err
 	}
-
-	_, err = query.From(b.pivotTable).
+	_, err = query.
 		Where(b.pivotLocalKey, "=", localValue).
-		Where(b.pivotForeignKey, "=", relatedID).
+		WhereIn(b.pivotForeignKey, ids).
 		Delete()
-
 	return err
 }
 
-// Sync 同步关联关系（替换所有关联）
-func (b *BelongsToMany) Sync(relatedIDs []interface{}) error {
+// Sync 同步关联关系：移除不在 ids 中的关联，添加缺少的关联，已存在的关联保持不变（包括其中间表附加列）
+// 父模型未绑定事务时在新事务中执行
+func (b *BelongsToMany) Sync(ids []interface{}) error {
 	localValue := b.parent.GetAttribute(b.parent.GetPrimaryKey())
 	if localValue == nil {
 		return fmt.Errorf("主键值为空")
 	}
 
-	query, err := db.NewQueryBuilder(b.parent.GetConnection())
+	sync := func(tx db.TransactionInterface) error {
+		query, err := b.pivotQuery(tx)
+		if err != nil {
+			return err
+		}
+		rows, err := query.Select(b.pivotForeignKey).Where(b.pivotLocalKey, "=", localValue).GetRaw()
+		if err != nil {
+			return fmt.Errorf("查询现有关联失败: %w", err)
+		}
+
+		wanted := make(map[string]bool)
+		for _, id := range flattenIDs(ids) {
+			wanted[pivotKey(id)] = true
+		}
+		current := make(map[string]bool, len(rows))
+		var detachIDs []interface{}
+		for _, row := range rows {
+			id := row[b.pivotForeignKey]
+			current[pivotKey(id)] = true
+			if !wanted[pivotKey(id)] {
+				detachIDs = append(detachIDs, id)
+			}
+		}
+		var attachIDs []interface{}
+		for _, id := range flattenIDs(ids) {
+			key := pivotKey(id)
+			if !current[key] {
+				attachIDs = append(attachIDs, id)
+				// 避免 ids 中的重复值插入多行
+				current[key] = true
+			}
+		}
+
+		if err := b.detach(tx, detachIDs); err != nil {
+			return fmt.Errorf("删除现有关联失败: %w", err)
+		}
+		return b.attach(tx, attachIDs, nil)
+	}
+
+	if b.parent.transaction != nil {
+		return sync(b.parent.transaction)
+	}
+	return db.Transaction(sync, b.parent.GetConnection())
+}
+
+// ============================================================================
+// HasManyThrough 远程一对多关联
+// ============================================================================
+
+// HasManyThrough 通过中间模型的远程一对多关联
+// 例如 Country -> User -> Post：
+//
+//	SELECT posts.* FROM posts INNER JOIN users ON users.id = posts.user_id WHERE users.country_id = ?
+type HasManyThrough struct {
+	*BaseRelation
+	// 中间表
+	throughTable string
+	// 中间表上指向父模型的外键（如 users.country_id）
+	firstKey string
+	// 关联表上指向中间表的外键（如 posts.user_id）
+	secondKey string
+	// 中间表上被 secondKey 引用的键（如 users.id）
+	secondLocalKey string
+}
+
+// NewHasManyThrough 创建远程一对多关联
+func NewHasManyThrough(parent *BaseModel, related reflect.Type, throughTable, firstKey, secondKey, localKey, secondLocalKey string) *HasManyThrough {
+	return NewHasManyThroughWithTable(parent, related, getTableNameFromType(related), throughTable, firstKey, secondKey, localKey, secondLocalKey)
+}
+
+// NewHasManyThroughWithTable 创建带自定义表名的远程一对多关联
+func NewHasManyThroughWithTable(parent *BaseModel, related reflect.Type, tableName, throughTable, firstKey, secondKey, localKey, secondLocalKey string) *HasManyThrough {
+	if firstKey == "" {
+		firstKey = getDefaultForeignKey(parent.GetTableName())
+	}
+	if secondKey == "" {
+		secondKey = getDefaultForeignKey(throughTable)
+	}
+	if localKey == "" {
+		localKey = parent.GetPrimaryKey()
+	}
+	if secondLocalKey == "" {
+		secondLocalKey = "id"
+	}
+
+	baseRelation := NewBaseRelationWithTable(parent, related, tableName, secondKey, localKey)
+
+	return &HasManyThrough{
+		BaseRelation:   baseRelation,
+		throughTable:   throughTable,
+		firstKey:       firstKey,
+		secondKey:      secondKey,
+		secondLocalKey: secondLocalKey,
+	}
+}
+
+// GetResults 获取关联结果
+func (h *HasManyThrough) GetResults() (interface{}, error) {
+	return h.Get()
+}
+
+// First 获取第一个关联结果
+func (h *HasManyThrough) First() (map[string]interface{}, error) {
+	results, err := h.Get()
 	if err != nil {
-		return fmt.Errorf("创建查询构建器失败: %w", err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
 	}
+	return results[0], nil
+}
 
-	// 删除现有关联
-	_, err = query.From(b.pivotTable).
-		Where(b.pivotLocalKey, "=", localValue).
-		Delete()
+// Get 获取所有关联结果，只返回关联表的列
+func (h *HasManyThrough) Get() ([]map[string]interface{}, error) {
+	localValue := h.parent.GetAttribute(h.localKey)
+	if localValue == nil {
+		return []map[string]interface{}{}, nil
+	}
+
+	return h.query.
+		Join(h.throughTable, fmt.Sprintf("%s.%s", h.throughTable, h.secondLocalKey), "=", fmt.Sprintf("%s.%s", h.relatedTable, h.secondKey)).
+		Where(fmt.Sprintf("%s.%s", h.throughTable, h.firstKey), "=", localValue).
+		Select(fmt.Sprintf("%s.*", h.relatedTable)).
+		GetRaw()
+}
+
+// Where 添加查询约束，关联表与中间表同名的列需要带表名前缀
+func (h *HasManyThrough) Where(column, operator string, value interface{}) RelationInterface {
+	if h.query != nil {
+		h.query = h.query.Where(column, operator, value)
+	}
+	return h
+}
+
+// OrderBy 添加排序
+func (h *HasManyThrough) OrderBy(column, direction string) *HasManyThrough {
+	if h.query != nil {
+		h.query = h.query.OrderBy(column, direction)
+	}
+	return h
+}
+
+// Limit 限制结果数量
+func (h *HasManyThrough) Limit(limit int) *HasManyThrough {
+	if h.query != nil {
+		h.query = h.query.Limit(limit)
+	}
+	return h
+}
+
+// ============================================================================
+// MorphMany / MorphTo 多态关联
+// ============================================================================
+
+var (
+	// morphTypes 多态类型值到父表的映射，morphAliases 为父表到类型值的反向映射
+	morphTypes      = make(map[string]morphTarget)
+	morphAliases    = make(map[string]string)
+	morphTypesMutex sync.RWMutex
+
+	// morphTableRegex 未注册映射时类型值必须是合法的表名
+	morphTableRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// morphTarget 多态类型对应的父表和父表中被引用的键
+type morphTarget struct {
+	table    string
+	ownerKey string
+}
+
+// RegisterMorphType 注册多态类型映射，类型列中存储 morphType，查询时映射到 table
+// ownerKey 为父表中被 {name}_id 引用的列，默认 id
+// 注册任意映射后，MorphTo 只接受已注册的类型值，MorphMany 写入和匹配的类型值也改为 morphType
+// 例如：model.RegisterMorphType("post", "posts")
+func RegisterMorphType(morphType, table string, ownerKey ...string) {
+	target := morphTarget{table: table, ownerKey: "id"}
+	if len(ownerKey) > 0 && ownerKey[0] != "" {
+		target.ownerKey = ownerKey[0]
+	}
+
+	morphTypesMutex.Lock()
+	defer morphTypesMutex.Unlock()
+	if previous, ok := morphTypes[morphType]; ok && morphAliases[previous.table] == morphType {
+		delete(morphAliases, previous.table)
+	}
+	morphTypes[morphType] = target
+	morphAliases[table] = morphType
+}
+
+// RemoveMorphType 移除多态类型映射
+func RemoveMorphType(morphType string) {
+	morphTypesMutex.Lock()
+	defer morphTypesMutex.Unlock()
+	if target, ok := morphTypes[morphType]; ok && morphAliases[target.table] == morphType {
+		delete(morphAliases, target.table)
+	}
+	delete(morphTypes, morphType)
+}
+
+// resolveMorphType 将类型列的值解析为父表和被引用的键
+// 未注册任何映射时类型值按表名使用，但必须是合法的标识符
+func resolveMorphType(morphType string) (morphTarget, error) {
+	morphTypesMutex.RLock()
+	defer morphTypesMutex.RUnlock()
+
+	if target, ok := morphTypes[morphType]; ok {
+		return target, nil
+	}
+	if len(morphTypes) > 0 {
+		return morphTarget{}, fmt.Errorf("未注册的多态类型: %s", morphType)
+	}
+	if !morphTableRegex.MatchString(morphType) {
+		return morphTarget{}, fmt.Errorf("无效的多态类型: %s", morphType)
+	}
+	return morphTarget{table: morphType, ownerKey: "id"}, nil
+}
+
+// morphTypeForTable 返回父表在类型列中存储的值，未注册映射时为表名本身
+// 同一张表注册了多个类型值时使用最后注册的
+func morphTypeForTable(table string) string {
+	morphTypesMutex.RLock()
+	defer morphTypesMutex.RUnlock()
+
+	if morphType, ok := morphAliases[table]; ok {
+		return morphType
+	}
+	return table
+}
+
+// MorphMany 多态一对多关联，子表通过 {name}_type 和 {name}_id 引用父模型
+// {name}_type 存储父模型的表名（或 RegisterMorphType 注册的类型值），例如 comments.commentable_type = 'posts'
+type MorphMany struct {
+	*BaseRelation
+	// 类型列
+	morphType string
+}
+
+// NewMorphMany 创建多态一对多关联
+func NewMorphMany(parent *BaseModel, related reflect.Type, name string) *MorphMany {
+	return NewMorphManyWithTable(parent, related, getTableNameFromType(related), name)
+}
+
+// NewMorphManyWithTable 创建带自定义表名的多态一对多关联
+func NewMorphManyWithTable(parent *BaseModel, related reflect.Type, tableName, name string) *MorphMany {
+	baseRelation := NewBaseRelationWithTable(parent, related, tableName, name+"_id", parent.GetPrimaryKey())
+	return &MorphMany{BaseRelation: baseRelation, morphType: name + "_type"}
+}
+
+// GetResults 获取关联结果
+func (m *MorphMany) GetResults() (interface{}, error) {
+	return m.Get()
+}
+
+// First 获取第一个关联结果
+func (m *MorphMany) First() (map[string]interface{}, error) {
+	localValue := m.parent.GetAttribute(m.localKey)
+	if localValue == nil {
+		return nil, fmt.Errorf("本地键值为空")
+	}
+
+	return m.query.
+		Where(m.morphType, "=", morphTypeForTable(m.parent.GetTableName())).
+		Where(m.foreignKey, "=", localValue).
+		FirstRaw()
+}
+
+// Get 获取所有关联结果
+func (m *MorphMany) Get() ([]map[string]interface{}, error) {
+	localValue := m.parent.GetAttribute(m.localKey)
+	if localValue == nil {
+		return []map[string]interface{}{}, nil
+	}
+
+	return m.query.
+		Where(m.morphType, "=", morphTypeForTable(m.parent.GetTableName())).
+		Where(m.foreignKey, "=", localValue).
+		GetRaw()
+}
+
+// Where 添加查询约束
+func (m *MorphMany) Where(column, operator string, value interface{}) RelationInterface {
+	if m.query != nil {
+		m.query = m.query.Where(column, operator, value)
+	}
+	return m
+}
+
+// OrderBy 添加排序
+func (m *MorphMany) OrderBy(column, direction string) *MorphMany {
+	if m.query != nil {
+		m.query = m.query.OrderBy(column, direction)
+	}
+	return m
+}
+
+// Limit 限制结果数量
+func (m *MorphMany) Limit(limit int) *MorphMany {
+	if m.query != nil {
+		m.query = m.query.Limit(limit)
+	}
+	return m
+}
+
+// MorphTo 多态反向关联，根据子模型的 {name}_type 确定父表，{name}_id 匹配父表的被引用键
+type MorphTo struct {
+	*BaseRelation
+	// 类型列
+	morphType string
+}
+
+// NewMorphTo 创建多态反向关联，关联表在查询时由类型列决定
+// ownerKey 为父表中被引用的列，为空时使用类型映射注册的键，默认 id
+func NewMorphTo(child *BaseModel, name string, ownerKey ...string) *MorphTo {
+	localKey := ""
+	if len(ownerKey) > 0 {
+		localKey = ownerKey[0]
+	}
+	baseRelation := NewBaseRelationWithTable(child, nil, "", name+"_id", localKey)
+	return &MorphTo{BaseRelation: baseRelation, morphType: name + "_type"}
+}
+
+// GetResults 获取关联结果
+func (m *MorphTo) GetResults() (interface{}, error) {
+	return m.First()
+}
+
+// First 获取关联的父模型记录
+func (m *MorphTo) First() (map[string]interface{}, error) {
+	morphType := morphTypeValue(m.parent.GetAttribute(m.morphType))
+	if morphType == "" {
+		return nil, fmt.Errorf("多态类型值为空")
+	}
+	foreignValue := m.parent.GetAttribute(m.foreignKey)
+	if foreignValue == nil {
+		return nil, fmt.Errorf("外键值为空")
+	}
+	target, err := resolveMorphType(morphType)
 	if err != nil {
-		return fmt.Errorf("删除现有关联失败: %w", err)
+		return nil, err
+	}
+
+	ownerKey := m.localKey
+	if ownerKey == "" {
+		ownerKey = target.ownerKey
 	}
+	m.relatedTable = target.table
+	return m.query.From(target.table).Where(ownerKey, "=", foreignValue).FirstRaw()
+}
 
-	// 添加新关联
-	for _, relatedID := range relatedIDs {
-		_, err = query.From(b.pivotTable).Insert(map[string]interface{}{
-			b.pivotLocalKey:   localValue,
-			b.pivotForeignKey: relatedID,
-		})
+// Get 获取所有关联结果
+func (m *MorphTo) Get() ([]map[string]interface{}, error) {
+	result, err := m.First()
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return []map[string]interface{}{}, nil
+	}
+	return []map[string]interface{}{result}, nil
+}
+
+// Where 添加查询约束
+func (m *MorphTo) Where(column, operator string, value interface{}) RelationInterface {
+	if m.query != nil {
+		m.query = m.query.Where(column, operator, value)
+	}
+	return m
+}
+
+// LoadMorphTo 批量加载多个子模型的多态父记录，按类型列分组后每个父表只查询一次
+// 返回结果与 children 一一对应，类型或外键为空、父记录不存在时对应位置为 nil
+// ownerKey 为父表中被引用的列，为空时使用类型映射注册的键，默认 id
+func LoadMorphTo(children []*BaseModel, name string, ownerKey ...string) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, len(children))
+	if len(children) == 0 {
+		return results, nil
+	}
+
+	morphType, morphID := name+"_type", name+"_id"
+
+	// 按类型值分组，记录每个子模型的位置
+	groups := make(map[string][]int)
+	types := make([]string, 0)
+	for i, child := range children {
+		if child == nil {
+			continue
+		}
+		value := morphTypeValue(child.GetAttribute(morphType))
+		if value == "" || child.GetAttribute(morphID) == nil {
+			continue
+		}
+		if _, ok := groups[value]; !ok {
+			types = append(types, value)
+		}
+		groups[value] = append(groups[value], i)
+	}
+
+	for _, value := range types {
+		target, err := resolveMorphType(value)
+		if err != nil {
+			return nil, err
+		}
+		table, key := target.table, target.ownerKey
+		if len(ownerKey) > 0 && ownerKey[0] != "" {
+			key = ownerKey[0]
+		}
+
+		indexes := groups[value]
+		ids := make([]interface{}, 0, len(indexes))
+		seen := make(map[string]bool, len(indexes))
+		for _, i := range indexes {
+			id := children[i].GetAttribute(morphID)
+			if key := fmt.Sprint(id); !seen[key] {
+				seen[key] = true
+				ids = append(ids, id)
+			}
+		}
+
+		query, err := db.NewQueryBuilder(children[indexes[0]].GetConnection())
 		if err != nil {
-			return fmt.Errorf("添加关联失败: %w", err)
+			return nil, fmt.Errorf("创建查询构建器失败: %w", err)
+		}
+		rows, err := query.From(table).WhereIn(key, ids).GetRaw()
+		if err != nil {
+			return nil, fmt.Errorf("加载多态关联 %s 失败: %w", table, err)
+		}
+
+		byID := make(map[string]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			byID[fmt.Sprint(row[key])] = row
+		}
+		for _, i := range indexes {
+			results[i] = byID[fmt.Sprint(children[i].GetAttribute(morphID))]
 		}
 	}
 
+	return results, nil
+}
+
+// morphTypeValue 将类型列的值转换为字符串
+func morphTypeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ============================================================================
+// 关联存在性查询
+// ============================================================================
+
+// resolveRelation 调用模型结构体实例上的同名方法获取关联定义
+func (m *BaseModel) resolveRelation(name string) (RelationInterface, error) {
+	if m.instance == nil {
+		return nil, fmt.Errorf("模型未绑定结构体实例，无法解析关联 %s", name)
+	}
+
+	method := reflect.ValueOf(m.instance).MethodByName(name)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, fmt.Errorf("关联方法 %s 不存在或签名不正确", name)
+	}
+	relation, ok := method.Call(nil)[0].Interface().(RelationInterface)
+	if !ok || relation == nil {
+		return nil, fmt.Errorf("方法 %s 未返回关联", name)
+	}
+	return relation, nil
+}
+
+// relationExistsQuery 构建关联的相关子查询：SELECT 1 FROM related WHERE related.fk = parent.pk AND <constraints>
+// 子查询带有关联模型的软删除和全局作用域；关联表与当前表相同（自关联）时，子查询中的关联表使用别名 {表名}_related
+func (m *BaseModel) relationExistsQuery(name string, fn func(*db.QueryBuilder)) (*db.QueryBuilder, error) {
+	relation, err := m.resolveRelation(name)
+	if err != nil {
+		return nil, err
+	}
+	holder, ok := relation.(interface{ baseRelation() *BaseRelation })
+	if !ok {
+		return nil, fmt.Errorf("关联 %s 不支持存在性查询", name)
+	}
+	base := holder.baseRelation()
+
+	parentTable := m.GetTableName()
+	relatedTable, tableRef := base.relatedTable, base.relatedTable
+	if relatedTable == parentTable {
+		relatedTable = base.relatedTable + "_related"
+		tableRef = base.relatedTable + " " + relatedTable
+	}
+	query, err := base.relatedQuery(m.GetConnection(), tableRef)
+	if err != nil {
+		return nil, fmt.Errorf("创建关联查询失败: %w", err)
+	}
+
+	// 关联定义所在的结构体实例未初始化配置时，本地键回退为当前模型的主键
+	localKey := func(key string) string {
+		if key == "" {
+			return m.GetPrimaryKey()
+		}
+		return key
+	}
+	correlate := func(left, leftColumn, rightColumn string) string {
+		return fmt.Sprintf("%s.%s = %s.%s", left, leftColumn, parentTable, rightColumn)
+	}
+
+	switch r := relation.(type) {
+	case *HasOne:
+		query.Where(correlate(relatedTable, r.foreignKey, localKey(r.localKey)))
+	case *HasMany:
+		query.Where(correlate(relatedTable, r.foreignKey, localKey(r.localKey)))
+	case *BelongsTo:
+		query.Where(correlate(relatedTable, r.localKey, r.foreignKey))
+	case *MorphMany:
+		query.Where(correlate(relatedTable, r.foreignKey, localKey(r.localKey))).
+			Where(fmt.Sprintf("%s.%s", relatedTable, r.morphType), "=", morphTypeForTable(parentTable))
+	case *HasManyThrough:
+		query.Join(r.throughTable, fmt.Sprintf("%s.%s", r.throughTable, r.secondLocalKey), "=", fmt.Sprintf("%s.%s", relatedTable, r.secondKey)).
+			Where(correlate(r.throughTable, r.firstKey, localKey(r.localKey)))
+	case *BelongsToMany:
+		relatedKey := base.relatedBaseModel().GetPrimaryKey()
+		if relatedKey == "" {
+			relatedKey = "id"
+		}
+		query.Join(r.pivotTable, fmt.Sprintf("%s.%s", relatedTable, relatedKey), "=", fmt.Sprintf("%s.%s", r.pivotTable, r.pivotForeignKey)).
+			Where(correlate(r.pivotTable, r.pivotLocalKey, m.GetPrimaryKey()))
+	default:
+		return nil, fmt.Errorf("关联 %s 不支持存在性查询", name)
+	}
+
+	query.Select("1")
+	if fn != nil {
+		fn(query)
+	}
+	return query, nil
+}
+
+// ============================================================================
+// 结构体关联加载
+// ============================================================================
+
+func init() {
+	db.RegisterRelationLoader(loadStructRelations)
+}
+
+// loadStructRelations db.QueryBuilder.ScanWith 的关联加载器
+// dest 必须嵌入 BaseModel，关联名为结构体上返回关联的方法名（与 Has/WhereHas 相同），
+// 结果写入标签 relation:"<关联名>" 的字段，没有该标签时写入同名字段；
+// 字段可以是结构体、结构体指针、结构体切片、map[string]interface{} 或 []map[string]interface{}，
+// 单个关联不存在（外键为空或未找到记录）时字段保持零值
+func loadStructRelations(dest interface{}, row map[string]interface{}, connName string, relations []string) error {
+	structValue := reflect.ValueOf(dest).Elem()
+	baseField := structValue.FieldByName("BaseModel")
+	if !baseField.IsValid() || baseField.Type() != reflect.TypeOf(BaseModel{}) {
+		return fmt.Errorf("结构体 %T 未嵌入 BaseModel，无法加载关联", dest)
+	}
+
+	// 关联方法通过嵌入的 BaseModel 读取键值，需要先用查询结果初始化
+	base := baseField.Addr().Interface().(*BaseModel)
+	if base.attributes == nil {
+		*base = *NewModel(dest)
+	}
+	base.instance = dest
+	if connName != "" {
+		base.SetConnection(connName)
+	}
+	base.attributes = make(map[string]interface{}, len(row))
+	for key, value := range row {
+		base.attributes[key] = value
+	}
+	base.MarkAsExists()
+	base.syncOriginal()
+
+	for _, name := range relations {
+		field, ok := relationField(structValue, name)
+		if !ok {
+			return fmt.Errorf("结构体 %T 没有关联 %s 对应的字段", dest, name)
+		}
+		relation, err := base.resolveRelation(name)
+		if err != nil {
+			return err
+		}
+		if err := fillRelationField(field, relation); err != nil {
+			return fmt.Errorf("加载关联 %s 失败: %w", name, err)
+		}
+	}
 	return nil
 }
 
+// relationField 查找关联对应的可写字段，relation 标签优先于字段名
+func relationField(structValue reflect.Value, name string) (reflect.Value, bool) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).Tag.Get("relation") == name && structValue.Field(i).CanSet() {
+			return structValue.Field(i), true
+		}
+	}
+	field := structValue.FieldByName(name)
+	return field, field.IsValid() && field.CanSet()
+}
+
+// fillRelationField 执行关联查询并按字段类型写入结果
+func fillRelationField(field reflect.Value, relation RelationInterface) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		rows, err := relation.Get()
+		if err != nil {
+			if isMissingRelation(relation, err) {
+				field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+				return nil
+			}
+			return err
+		}
+		if field.Type().Elem() == reflect.TypeOf(map[string]interface{}{}) {
+			field.Set(reflect.ValueOf(rows))
+			return nil
+		}
+		return db.LoadModels(rows, field.Addr().Interface())
+	case reflect.Map, reflect.Struct, reflect.Ptr:
+		row, err := relation.First()
+		if err != nil {
+			if isMissingRelation(relation, err) {
+				field.Set(reflect.Zero(field.Type()))
+				return nil
+			}
+			return err
+		}
+		switch field.Kind() {
+		case reflect.Map:
+			field.Set(reflect.ValueOf(row))
+		case reflect.Ptr:
+			item := reflect.New(field.Type().Elem())
+			if err := db.LoadModel(row, item.Interface()); err != nil {
+				return err
+			}
+			field.Set(item)
+		default:
+			return db.LoadModel(row, field.Addr().Interface())
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的关联字段类型 %s", field.Type())
+	}
+}
+
+// isMissingRelation 判断单个关联是否不存在：未找到记录，或关联键值为空
+func isMissingRelation(relation RelationInterface, err error) bool {
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return true
+	}
+	switch r := relation.(type) {
+	case *HasOne:
+		return r.parent.GetAttribute(r.localKey) == nil
+	case *HasMany:
+		return r.parent.GetAttribute(r.localKey) == nil
+	case *BelongsTo:
+		return r.parent.GetAttribute(r.foreignKey) == nil
+	}
+	return false
+}
+
 // ============================================================================
 // 辅助函数
 // ============================================================================
@@ -524,6 +1249,65 @@ func getTableNameFromType(t reflect.Type) string {
 	return toSnakeCase(t.Name())
 }
 
+// relatedModelOf 获取定义关联时传入的关联模型
+// 已初始化的模型直接使用，未初始化的结构体实例或类型按结构体标签创建模型，无法确定时返回 nil
+func relatedModelOf(modelType interface{}) *BaseModel {
+	switch v := modelType.(type) {
+	case nil:
+		return nil
+	case *BaseModel:
+		return v
+	case reflect.Type:
+		return relatedModelOfType(v)
+	}
+
+	value := reflect.ValueOf(modelType)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return relatedModelOfType(value.Type())
+	}
+	if embedded := value.Elem().FieldByName("BaseModel"); embedded.IsValid() && embedded.Type() == reflect.TypeOf(BaseModel{}) {
+		if base := embedded.Addr().Interface().(*BaseModel); base.config.TableName != "" {
+			return base
+		}
+	}
+	return NewModel(modelType)
+}
+
+// relatedModelOfType 按结构体类型的标签创建模型
+func relatedModelOfType(t reflect.Type) *BaseModel {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(BaseModel{}) {
+		return nil
+	}
+	return NewModel(reflect.New(t).Interface())
+}
+
+// flattenIDs 展开ID列表中的切片（如 []int64{1, 2}），[]byte 视为单个值
+func flattenIDs(ids []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		value := reflect.ValueOf(id)
+		if (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < value.Len(); i++ {
+				result = append(result, value.Index(i).Interface())
+			}
+			continue
+		}
+		result = append(result, id)
+	}
+	return result
+}
+
+// pivotKey 将中间表的ID转换为可比较的键，不同整数类型与数据库返回的值一致
+func pivotKey(id interface{}) string {
+	if v, ok := id.([]byte); ok {
+		return string(v)
+	}
+	return fmt.Sprint(id)
+}
+
 // getDefaultForeignKey 获取默认外键名 - 改进版本
 func getDefaultForeignKey(tableName string) string {
 	if tableName == "" {
@@ -17,6 +17,10 @@ type (
 	TransactionInterface = db.TransactionInterface
 	QueryBuilder         = db.QueryBuilder
 	Manager              = db.Manager
+	Paginator            = db.Paginator
+	ScopeFunc            = db.ScopeFunc
+	DecimalMode          = db.DecimalMode
+	TxOptions            = db.TxOptions
 
 	// 错误相关
 	TormError = db.TormError
@@ -40,8 +44,23 @@ var (
 	AddConnection  = db.AddConnection
 	DB             = db.DB
 	Table          = db.Table
+	RawTable       = db.RawTable
+	SetTablePrefix = db.SetTablePrefix
+	Exec           = db.Exec
+	Query          = db.Query
 	Model          = db.Model
 	Transaction    = db.Transaction
+	LoadModel      = db.LoadModel
+	LoadModels     = db.LoadModels
+
+	// 事务
+	TransactionWithOptions = db.TransactionWithOptions
+	RunInTransaction       = db.RunInTransaction
+
+	// 查询结果类型转换
+	SetDecimalMode     = db.SetDecimalMode
+	SetSmartConversion = db.SetSmartConversion
+	SetTimezone        = db.SetTimezone
 
 	// 模型相关
 	NewModel = model.NewModel
@@ -58,6 +77,7 @@ var (
 
 	// 迁移相关
 	NewMigration = migration.NewMigration
+	Inspect      = migration.Inspect
 
 	// 缓存相关
 	ClearCacheByTags = db.ClearCacheByTags